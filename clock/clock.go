@@ -0,0 +1,76 @@
+// Package clock abstracts the current time so that time dependent logic can be tested deterministically
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the current time
+type Clock interface {
+	// Now returns the wall clock time
+	Now() time.Time
+	// Elapsed returns the time passed since the clock was created, it is not affected by wall clock jumps
+	Elapsed() time.Duration
+}
+
+// System is the clock of the operating system
+type System struct {
+	start time.Time
+}
+
+// NewSystem returns the system clock, its elapsed time is measured on the monotonic clock
+func NewSystem() *System {
+	return &System{start: time.Now()}
+}
+
+// Now returns the wall clock time without monotonic reading
+func (c *System) Now() time.Time {
+	return time.Now().Round(0)
+}
+
+// Elapsed returns the monotonic time since the clock was created
+func (c *System) Elapsed() time.Duration {
+	return time.Since(c.start)
+}
+
+// Fake is a clock that only moves when told to
+type Fake struct {
+	mutex   sync.Mutex
+	now     time.Time
+	elapsed time.Duration
+}
+
+// NewFake returns a clock standing at now
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now returns the wall clock time of the fake clock
+func (c *Fake) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// Elapsed returns the time the clock was advanced by
+func (c *Fake) Elapsed() time.Duration {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.elapsed
+}
+
+// Advance lets time pass
+func (c *Fake) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+	c.elapsed += d
+}
+
+// Jump sets the wall clock without time passing, like an NTP correction or a manual change
+func (c *Fake) Jump(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
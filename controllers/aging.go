@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+	"todo-rest-backend/models"
+)
+
+// defaultStaleDays is the age of the todos in the stale view without days parameter
+const defaultStaleDays = 30
+
+// StaleMeta is the meta information of the stale view
+type StaleMeta struct {
+	Days int `json:"days"`
+}
+
+// StaleViewGet Handler for the stale view action, the open todos created at least days ago, the oldest first
+// GET /views/stale?days=30
+func StaleViewGet(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	days := defaultStaleDays
+	if value := request.URL.Query().Get("days"); value != "" {
+		var err error
+		days, err = strconv.Atoi(value)
+		if err != nil || days < 0 {
+			return handleTodoNotProperlyTransmittedGeneral(writer, "Days Must Be A Number Of Days")
+		}
+	}
+
+	response := models.JsonExtendedResponse{Meta: StaleMeta{Days: days}, Data: models.StaleTodos(days)}
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
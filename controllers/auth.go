@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"todo-rest-backend/models"
+)
+
+// apiKey is the token the requests must present as bearer token, authentication is disabled without
+var apiKey string
+
+// authenticateReads requires the token for reading requests as well, otherwise only changing requests need it
+var authenticateReads bool
+
+// users maps the tokens of the users to their names. With users every request must present the token of
+// a user and the todos are scoped to that user, see models.SetOwner.
+var users map[string]string
+
+// admins are the users allowed to call the /admin routes and to override the denylist, see denylistOverrideHeader
+var admins map[string]bool
+
+// SetAuthentication requires the API key as bearer token in the Authorization header of all requests changing
+// the todos, and of the reading requests as well with reads set. An empty key disables the authentication.
+func SetAuthentication(key string, reads bool) {
+	apiKey, authenticateReads = key, reads
+}
+
+// SetUsers maps the tokens of the users to their names, no users disable the scoping of the todos.
+// The API key of SetAuthentication is not accepted then.
+func SetUsers(tokens map[string]string) {
+	users = tokens
+}
+
+// SetAdmins names the users allowed to call the /admin routes and to override the denylist. Without users
+// the requests allowed to change todos may do both.
+func SetAdmins(names []string) {
+	admins = make(map[string]bool)
+	for _, name := range names {
+		admins[strings.TrimSpace(name)] = true
+	}
+}
+
+// isAdmin tells whether the user of an authenticated request may call the /admin routes and override the denylist
+func isAdmin(user string) bool {
+	return len(users) == 0 || admins[user]
+}
+
+// adminRoute tells whether the route administers the todos of all users, like restoring snapshots or
+// listing the events of the outbox
+func adminRoute(route route) bool {
+	return strings.HasPrefix(route.path, "/admin/")
+}
+
+// ReadUsersFile reads the users from a file with a line "name token" per user, empty lines and lines
+// starting with # are skipped
+func ReadUsersFile(fileName string) (map[string]string, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	tokens := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d must be the name and the token of a user", line)
+		}
+		if _, ok := tokens[fields[1]]; ok {
+			return nil, fmt.Errorf("line %d repeats the token of another user", line)
+		}
+		tokens[fields[1]] = fields[0]
+	}
+	return tokens, scanner.Err()
+}
+
+// ownAuthentication are the routes authenticating their requests themselves: the simple API with its key
+// and the webhook of the issue tracker with its secret
+func ownAuthentication(path string) bool {
+	return strings.HasPrefix(path, "/simple/") || path == "/sync/webhook"
+}
+
+// needsAuthentication tells whether requests of the route must present the API key or the token of a user.
+// The simple API takes the tokens of the users as its key.
+func needsAuthentication(route route) bool {
+	if len(users) > 0 {
+		return route.path != readinessPath && route.path != "/sync/webhook"
+	}
+	if apiKey == "" || route.path == readinessPath || ownAuthentication(route.path) {
+		return false
+	}
+	reading := route.method == http.MethodGet || route.method == http.MethodHead
+	return reading == false || authenticateReads
+}
+
+// authenticate checks the bearer token of the request in constant time and returns the user of the token,
+// an empty user for the API key. The token of a user is also accepted in the X-Api-Key header.
+func authenticate(request *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(request.Header.Get("Authorization"), " ")
+	if found == false || strings.EqualFold(scheme, "Bearer") == false {
+		token = ""
+	}
+	token = strings.TrimSpace(token)
+
+	if len(users) > 0 {
+		if token == "" {
+			token = request.Header.Get("X-Api-Key")
+		}
+		user := ""
+		for candidate, name := range users {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(candidate)) == 1 {
+				user = name
+			}
+		}
+		return user, user != ""
+	}
+	return "", token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(apiKey)) == 1
+}
+
+// rejectUnauthenticated answers requests without valid token with 401
+func rejectUnauthenticated(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("WWW-Authenticate", `Bearer realm="todos"`)
+	logFailure(request, writeError(writer, http.StatusUnauthorized, models.CodeUnauthorized, "Unauthorized"))
+}
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-rest-backend/models"
+)
+
+func TestRegisterRoutes_RequiresTokenForChanges(t *testing.T) {
+	// Arrange
+	//
+	defer SetAuthentication("", false)
+	SetAuthentication("s3cret", false)
+	serve := func(method, path, authorization string) int {
+		return serveRoute(t, method, path, `{"title": "Buy milk"}`, "Authorization", authorization).Code
+	}
+
+	// Act
+	//
+	missing := serve(http.MethodPost, "/todos", "")
+	wrong := serve(http.MethodPost, "/todos", "Bearer guess")
+	valid := serve(http.MethodPost, "/todos", "Bearer s3cret")
+	read := serve(http.MethodGet, "/todos", "")
+	SetAuthentication("s3cret", true)
+	protectedRead := serve(http.MethodGet, "/todos", "")
+	ready := serve(http.MethodGet, readinessPath, "")
+
+	// Assert
+	//
+	if missing != http.StatusUnauthorized || wrong != http.StatusUnauthorized || valid != http.StatusCreated {
+		t.Error("Fehler", missing, wrong, valid)
+	}
+	if read != http.StatusOK || protectedRead != http.StatusUnauthorized || ready != http.StatusOK {
+		t.Error("Fehler", read, protectedRead, ready)
+	}
+}
+
+func TestRegisterRoutes_ScopesTodosToTheUser(t *testing.T) {
+	// Arrange
+	//
+	defer SetUsers(nil)
+	SetUsers(map[string]string{"token-a": "alice", "token-b": "bob"})
+	serve := func(method, path, token string) *httptest.ResponseRecorder {
+		return serveRoute(t, method, path, `{"title": "Call the dentist"}`, "Authorization", "Bearer "+token)
+	}
+	var created struct {
+		Data models.Todo `json:"data"`
+	}
+	json.NewDecoder(serve(http.MethodPost, "/todos", "token-a").Body).Decode(&created)
+	defer models.RemoveTodo(created.Data.Id)
+
+	// Act
+	//
+	ofAlice := serve(http.MethodGet, "/todos/"+created.Data.Id, "token-a")
+	ofBob := serve(http.MethodGet, "/todos/"+created.Data.Id, "token-b")
+	listOfBob := serve(http.MethodGet, "/todos", "token-b")
+	changedByBob := serve(http.MethodPut, "/todos/"+created.Data.Id, "token-b")
+
+	// Assert
+	//
+	if created.Data.Owner != "alice" || ofAlice.Code != http.StatusOK {
+		t.Error("Fehler", created.Data, ofAlice.Code)
+	}
+	if ofBob.Code != http.StatusNotFound || changedByBob.Code != http.StatusNotFound ||
+		strings.Contains(listOfBob.Body.String(), "Call the dentist") {
+		t.Error("Fehler", ofBob.Code, changedByBob.Code, listOfBob.Body.String())
+	}
+}
+
+func TestRegisterRoutes_AllowsOnlyAdminsToOverrideTheDenylist(t *testing.T) {
+	// Arrange
+	//
+	defer SetUsers(nil)
+	defer SetAdmins(nil)
+	SetUsers(map[string]string{"token-a": "alice", "token-b": "bob"})
+	SetAdmins([]string{"alice"})
+	serve := func(token string) *httptest.ResponseRecorder {
+		return serveRoute(t, http.MethodPost, "/todos", `{"title": "Renew the certificate"}`,
+			"Authorization", "Bearer "+token, denylistOverrideHeader, "true")
+	}
+
+	// Act
+	//
+	byAlice := serve("token-a")
+	byBob := serve("token-b")
+
+	// Assert
+	//
+	var created struct {
+		Data models.Todo `json:"data"`
+	}
+	json.NewDecoder(byAlice.Body).Decode(&created)
+	models.RemoveTodo(created.Data.Id)
+	if byAlice.Code != http.StatusCreated || byBob.Code != http.StatusForbidden {
+		t.Error("Fehler", byAlice.Code, byBob.Code)
+	}
+}
+
+func TestRegisterRoutes_AllowsOnlyAdminsToCallTheAdminRoutes(t *testing.T) {
+	// Arrange
+	//
+	defer SetUsers(nil)
+	defer SetAdmins(nil)
+	SetUsers(map[string]string{"token-a": "alice", "token-b": "bob"})
+	SetAdmins([]string{"alice"})
+
+	// Act
+	//
+	outboxOfAlice := serveRoute(t, http.MethodGet, "/admin/outbox", "", "Authorization", "Bearer token-a")
+	outboxOfBob := serveRoute(t, http.MethodGet, "/admin/outbox", "", "Authorization", "Bearer token-b")
+	restoreOfBob := serveRoute(t, http.MethodPost, "/admin/restore/missing", "", "Authorization", "Bearer token-b")
+	retentionOfBob := serveRoute(t, http.MethodPost, "/admin/retention/run", "", "Authorization", "Bearer token-b")
+
+	// Assert
+	//
+	if outboxOfAlice.Code != http.StatusOK || outboxOfBob.Code != http.StatusForbidden {
+		t.Error("Fehler", outboxOfAlice.Code, outboxOfBob.Code)
+	}
+	if restoreOfBob.Code != http.StatusForbidden || retentionOfBob.Code != http.StatusForbidden {
+		t.Error("Fehler", restoreOfBob.Code, retentionOfBob.Code)
+	}
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"todo-rest-backend/models"
+	"todo-rest-backend/search"
+)
+
+const defaultAutocompleteLimit = 10
+const maxAutocompleteLimit = 50
+
+// Suggestions is returned by the autocomplete action
+type Suggestions struct {
+	Titles []string `json:"titles"`
+	Tags   []string `json:"tags"`
+}
+
+// autocompleteIndex holds the tries of the titles and tags, it is rebuilt after the store changed.
+// The mutex guards the rebuild by concurrent reading requests.
+var autocompleteIndex struct {
+	mutex      sync.Mutex
+	built      bool
+	generation uint64
+	owner      string
+	titles     *search.Trie
+	tags       *search.Trie
+}
+
+// autocompleteTries returns the tries of the current store, the store mutex must be held for reading.
+// Titles are found by the start of the title and of each of their words. The most recently created todos
+// rank first, todos without creation time last.
+func autocompleteTries() (*search.Trie, *search.Trie) {
+	autocompleteIndex.mutex.Lock()
+	defer autocompleteIndex.mutex.Unlock()
+	generation := currentGeneration()
+	if autocompleteIndex.built && autocompleteIndex.generation == generation && autocompleteIndex.owner == models.Owner() {
+		return autocompleteIndex.titles, autocompleteIndex.tags
+	}
+
+	titles, tags := search.NewTrie(), search.NewTrie()
+	for _, todo := range models.AllTodos() {
+		rank := 0
+		if todo.CreatedAt != nil {
+			rank = int(todo.CreatedAt.Unix())
+		}
+		title := strings.TrimSpace(todo.Title)
+		for i, word := range strings.Fields(title) {
+			if i == 0 {
+				titles.Insert(title, title, rank)
+			} else {
+				titles.Insert(word, title, rank)
+			}
+		}
+		for _, tag := range todo.Tags {
+			tags.Insert(tag, tag, rank)
+		}
+	}
+
+	autocompleteIndex.built = true
+	autocompleteIndex.generation = generation
+	autocompleteIndex.owner = models.Owner()
+	autocompleteIndex.titles, autocompleteIndex.tags = titles, tags
+	return titles, tags
+}
+
+// TodosAutocomplete Handler for the autocomplete action, it suggests titles and tags starting with q
+// GET /todos/autocomplete?q=bu&limit=10
+func TodosAutocomplete(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	query := request.URL.Query()
+	prefix := strings.TrimSpace(query.Get("q"))
+	if prefix == "" {
+		return handleTodoNotProperlyTransmittedGeneral(writer, "Missing Query")
+	}
+
+	limit := defaultAutocompleteLimit
+	if query.Get("limit") != "" {
+		var err error
+		limit, err = strconv.Atoi(query.Get("limit"))
+		if err != nil || limit < 1 || limit > maxAutocompleteLimit {
+			return handleTodoNotProperlyTransmittedGeneral(writer, "Invalid Limit")
+		}
+	}
+
+	titles, tags := autocompleteTries()
+	suggestions := Suggestions{Titles: titles.Complete(prefix, limit), Tags: tags.Complete(prefix, limit)}
+
+	response := models.JsonExtendedResponse{Data: suggestions}
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+	"todo-rest-backend/models"
+)
+
+func TestTodosAutocomplete_RanksByCreationTime(t *testing.T) {
+	// Arrange
+	//
+	defer models.SetRepository(models.NewMemoryRepository())
+	defer func() { autocompleteIndex.built = false }()
+	repository := models.NewMemoryRepository()
+	models.SetRepository(repository)
+	earlier := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+	// imported todos keep their creation time, so the higher id is not the more recent todo
+	repository.Add(models.Todo{Id: "1", Title: "Buy bread", CreatedAt: &later})
+	repository.Add(models.Todo{Id: "2", Title: "Buy milk", CreatedAt: &earlier})
+	repository.Add(models.Todo{Id: "3", Title: "Buy stamps"})
+	autocompleteIndex.built = false
+
+	// Act
+	//
+	recorder := serveRoute(t, http.MethodGet, "/todos/autocomplete?q=bu", "")
+	var response struct {
+		Data Suggestions `json:"data"`
+	}
+	json.Unmarshal(recorder.Body.Bytes(), &response)
+
+	// Assert
+	//
+	if reflect.DeepEqual(response.Data.Titles, []string{"Buy bread", "Buy milk", "Buy stamps"}) == false {
+		t.Error("Fehler", recorder.Code, recorder.Body.String())
+	}
+}
@@ -0,0 +1,288 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"todo-rest-backend/models"
+	"todo-rest-backend/plugins"
+)
+
+// maxBatchOperations limits the operations of a batch, the store is locked while they run
+const maxBatchOperations = 100
+
+// BatchOperation is an operation of a batch: create takes the todo, update the id and the todo, delete the id
+// and move the id, the board column and the position. Ids like "$0" refer to the todo created by the operation
+// with that index. IfMatch is the ETag the todo of update, delete and move must still have, see ifMatch.
+type BatchOperation struct {
+	Op       string          `json:"op"`
+	Id       string          `json:"id,omitempty"`
+	IfMatch  string          `json:"if_match,omitempty"`
+	Todo     json.RawMessage `json:"todo,omitempty"`
+	Column   string          `json:"column,omitempty"`
+	Position int             `json:"position,omitempty"`
+}
+
+// BatchResult is the result of an operation of a batch with the status the single request would have got.
+// Data is the created, updated, moved or deleted todo.
+type BatchResult struct {
+	Op     string           `json:"op"`
+	Status int              `json:"status"`
+	Data   *models.Todo     `json:"data,omitempty"`
+	Error  *models.ApiError `json:"error,omitempty"`
+}
+
+// BatchMeta is the meta information of the batch response
+type BatchMeta struct {
+	Revision uint64 `json:"revision"`
+	// Committed tells whether the operations were applied, they are applied all or none
+	Committed bool `json:"committed"`
+	// Failed is the index of the operation that failed, the operations before it were rolled back
+	Failed *int `json:"failed,omitempty"`
+}
+
+// BatchPost Handler for the batch action, the operations are applied in their order and all or none of them
+// POST /batch
+func BatchPost(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	describedBy(writer, "batch.json")
+	body, err := validatedBody(request, "batch.json")
+	if err != nil {
+		return handleInvalidBody(writer, err)
+	}
+	var batch struct {
+		Operations []BatchOperation `json:"operations"`
+	}
+	err = json.Unmarshal(body, &batch)
+	if err != nil {
+		return handleTodoNotProperlyTransmitted(writer)
+	}
+	return applyBatch(writer, request, batch.Operations)
+}
+
+// TodosBulkPost Handler for the bulk action, it takes the create, update and delete operations of a batch
+// as array and applies them all or none
+// POST /todos/bulk
+func TodosBulkPost(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	describedBy(writer, "bulk.json")
+	body, err := validatedBody(request, "bulk.json")
+	if err != nil {
+		return handleInvalidBody(writer, err)
+	}
+	var operations []BatchOperation
+	err = json.Unmarshal(body, &operations)
+	if err != nil {
+		return handleTodoNotProperlyTransmitted(writer)
+	}
+	return applyBatch(writer, request, operations)
+}
+
+// applyBatch runs the operations in their order and commits them if all succeed, otherwise they are rolled back
+func applyBatch(writer http.ResponseWriter, request *http.Request, operations []BatchOperation) error {
+	if len(operations) == 0 {
+		return writeError(writer, http.StatusUnprocessableEntity, models.CodeValidationFailed, "No Operations")
+	}
+	if len(operations) > maxBatchOperations {
+		return writeError(writer, http.StatusUnprocessableEntity, models.CodeTooManyItems, "Too Many Operations")
+	}
+
+	transaction := models.BeginTransaction()
+	defer func() {
+		// a failing store panics, the changes made before are undone as well
+		if recovered := recover(); recovered != nil {
+			err := transaction.Rollback()
+			if err != nil {
+				log.Println("Cannot roll back the batch:", err)
+			}
+			panic(recovered)
+		}
+	}()
+	results := make([]BatchResult, len(operations))
+	created := make([]string, len(operations))
+	for i, operation := range operations {
+		results[i].Op = operation.Op
+	}
+	for i, operation := range operations {
+		results[i] = runBatchOperation(operation, created[:i])
+		if results[i].Error != nil {
+			return rollbackBatch(writer, transaction, results, i)
+		}
+		if operation.Op == "create" {
+			created[i] = results[i].Data.Id
+		}
+	}
+
+	// the issue sync and the plugins only learn about the committed changes
+	for i, result := range results {
+		switch result.Op {
+		case "create", "update", "move":
+			todo, ok := models.FindTodo(result.Data.Id)
+			if ok == false {
+				// removed by a later operation of the batch
+				todo = *result.Data
+			} else {
+				syncTodo(todo)
+			}
+			results[i].Data = &todo
+			if result.Op == "create" {
+				plugins.Emit(plugins.TodoCreated, todo)
+			} else {
+				plugins.Emit(plugins.TodoUpdated, todo)
+			}
+		case "delete":
+			syncRemovedTodos(*result.Data)
+			plugins.Emit(plugins.TodoDeleted, *result.Data)
+		}
+	}
+
+	response := models.JsonExtendedResponse{Meta: BatchMeta{Revision: models.Revision(), Committed: true}, Data: results}
+	err := models.UpdateDataInFile()
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// rollbackBatch undoes the operations of a batch and answers with the status of the failed operation,
+// the other operations are reported as not applied
+func rollbackBatch(writer http.ResponseWriter, transaction *models.Transaction, results []BatchResult, failed int) error {
+	err := transaction.Rollback()
+	if err != nil {
+		return err
+	}
+	for i := range results {
+		switch {
+		case i < failed:
+			results[i] = batchError(results[i].Op, http.StatusFailedDependency, models.CodeNotExecuted, "Rolled Back", nil)
+		case i > failed:
+			results[i] = batchError(results[i].Op, http.StatusFailedDependency, models.CodeNotExecuted, "Not Executed", nil)
+		}
+	}
+	response := models.JsonExtendedResponse{Meta: BatchMeta{Revision: models.Revision(), Failed: &failed}, Data: results}
+	writer.WriteHeader(results[failed].Status)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// runBatchOperation applies an operation like the route of the single action does, created are the ids of
+// the todos created by the operations before
+func runBatchOperation(operation BatchOperation, created []string) BatchResult {
+	id, ok := batchTodoId(operation.Id, created)
+	if ok == false {
+		return batchError(operation.Op, http.StatusUnprocessableEntity, models.CodeValidationFailed, "Unknown Reference", nil)
+	}
+	if operation.Op != "create" && id == "" {
+		return batchError(operation.Op, http.StatusUnprocessableEntity, models.CodeValidationFailed, "Validation Failed",
+			models.ValidationErrors{{Field: "id", Message: "is required"}})
+	}
+	if operation.Op != "create" && strictCoercion && isId(id) == false {
+		return batchError(operation.Op, http.StatusUnprocessableEntity, models.CodeValidationFailed, "Validation Failed",
+			models.ValidationErrors{{Field: "id", Message: "must be an id like 42"}})
+	}
+	todo, found := models.FindTodo(id)
+	if operation.Op != "create" && found == false && ifMatchesAny(operation.IfMatch) {
+		return batchError(operation.Op, http.StatusPreconditionFailed, models.CodeVersionConflict, "Precondition Failed", nil)
+	}
+	if operation.Op != "create" && found == false {
+		return batchError(operation.Op, http.StatusNotFound, models.CodeTodoNotFound, "Record Not Found", nil)
+	}
+	// the todos created by the batch are not known to the client
+	if operation.Op != "create" && strings.HasPrefix(operation.Id, "$") == false {
+		if operation.IfMatch == "" && requireIfMatch {
+			return batchError(operation.Op, http.StatusPreconditionRequired, models.CodeVersionRequired, "Precondition Required", nil)
+		}
+		if operation.IfMatch != "" && ifMatchMatches(operation.IfMatch, todoEtag(todo)) == false {
+			return batchError(operation.Op, http.StatusPreconditionFailed, models.CodeVersionConflict, "Precondition Failed", nil)
+		}
+	}
+
+	switch operation.Op {
+	case "create", "update":
+		var todoReceived models.Todo
+		err := decodeTodoJson(operation.Todo, &todoReceived, operation.Op == "create")
+		if err != nil {
+			return batchInvalidTodo(operation.Op, err)
+		}
+		if operation.Op == "create" {
+			todoReceived, err = plugins.BeforeWrite(plugins.ActionCreate, models.ApplyDefaults(todoReceived))
+			if err != nil {
+				return batchHookError(operation.Op, err)
+			}
+			todo = models.AddTodo(todoReceived)
+			return BatchResult{Op: operation.Op, Status: http.StatusCreated, Data: &todo}
+		}
+		todoReceived.Id = id
+		todoReceived, err = plugins.BeforeWrite(plugins.ActionUpdate, todoReceived)
+		if err != nil {
+			return batchHookError(operation.Op, err)
+		}
+		todo, _ = models.UpdateTodo(id, todoReceived)
+	case "delete":
+		models.RemoveTodo(id)
+	case "move":
+		if models.IsBoardColumn(operation.Column) == false {
+			return batchError(operation.Op, http.StatusUnprocessableEntity, models.CodeValidationFailed, "Unknown Board Column", nil)
+		}
+		// write hooks see the todo in its new column
+		todo.Status = operation.Column
+		todo.Terminated = operation.Column == models.DoneColumn()
+		var err error
+		todo, err = plugins.BeforeWrite(plugins.ActionUpdate, todo)
+		if err != nil {
+			return batchHookError(operation.Op, err)
+		}
+		// the changes of the write hooks are kept, the todo ends up in the column of the move
+		models.UpdateTodo(id, todo)
+		todo, err = models.MoveTodoToColumn(id, operation.Column, operation.Position)
+		if err != nil {
+			return batchError(operation.Op, http.StatusBadRequest, models.CodeInvalidRequest, "Move Failed", nil)
+		}
+	}
+	return BatchResult{Op: operation.Op, Status: http.StatusOK, Data: &todo}
+}
+
+// batchTodoId resolves a reference like "$0" to the id of the todo created by the operation with the index
+func batchTodoId(id string, created []string) (string, bool) {
+	if strings.HasPrefix(id, "$") == false {
+		return id, true
+	}
+	index, err := strconv.Atoi(id[1:])
+	if err != nil || index < 0 || index >= len(created) || created[index] == "" {
+		return "", false
+	}
+	return created[index], true
+}
+
+func batchError(op string, status int, code models.ErrorCode, title string, details models.ValidationErrors) BatchResult {
+	return BatchResult{Op: op, Status: status, Error: &models.ApiError{Status: int16(status), Code: code, Title: title,
+		Details: details}}
+}
+
+// batchInvalidTodo reports an invalid todo like handleInvalidBody, the fields are named below "todo"
+func batchInvalidTodo(op string, err error) BatchResult {
+	var violations models.ValidationErrors
+	if errors.As(err, &violations) == false {
+		return batchError(op, http.StatusBadRequest, models.CodeInvalidBody, "Invalid Body", nil)
+	}
+	var details models.ValidationErrors
+	for _, violation := range violations {
+		details = append(details, models.FieldError{Field: "todo." + violation.Field, Message: violation.Message})
+	}
+	return batchError(op, http.StatusUnprocessableEntity, models.CodeValidationFailed, "Validation Failed", details)
+}
+
+// batchHookError reports a failed write hook like handleWriteHookError
+func batchHookError(op string, err error) BatchResult {
+	var rejected *plugins.RejectedError
+	if errors.As(err, &rejected) {
+		return batchError(op, http.StatusUnprocessableEntity, models.CodeWriteRejected, rejected.Reason, nil)
+	}
+	log.Println("Write hook failed:", err)
+	return batchError(op, http.StatusInternalServerError, models.CodeInternalError, "Write Hook Failed", nil)
+}
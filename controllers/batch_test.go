@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-rest-backend/models"
+	"todo-rest-backend/plugins"
+)
+
+func TestBatchPost_AppliesAllOperations(t *testing.T) {
+	// Arrange
+	//
+	existing := models.AddTodo(models.Todo{Title: "Call the bank"})
+	body := `{"operations": [
+		{"op": "create", "todo": {"title": "Pay rent"}},
+		{"op": "update", "id": "$0", "todo": {"title": "Pay the rent", "tags": ["home"]}},
+		{"op": "delete", "id": "` + existing.Id + `", "if_match": "*"}]}`
+	recorder := httptest.NewRecorder()
+
+	// Act
+	//
+	BatchPost(recorder, httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(body)), nil)
+
+	// Assert
+	//
+	var response struct {
+		Meta BatchMeta     `json:"meta"`
+		Data []BatchResult `json:"data"`
+	}
+	json.NewDecoder(recorder.Body).Decode(&response)
+	if recorder.Code != http.StatusOK || response.Meta.Committed == false || len(response.Data) != 3 {
+		t.Fatal("Fehler", recorder.Code, response)
+	}
+	if response.Data[0].Status != http.StatusCreated || response.Data[1].Data.Id != response.Data[0].Data.Id {
+		t.Error("Fehler", response.Data)
+	}
+	updated, ok := models.FindTodo(response.Data[0].Data.Id)
+	if ok == false || updated.Title != "Pay the rent" || updated.HasTag("home") == false {
+		t.Error("Fehler", updated)
+	}
+	if _, ok := models.FindTodo(existing.Id); ok {
+		t.Error("Fehler", existing)
+	}
+}
+
+func TestBatchPost_RollsBackOnFailure(t *testing.T) {
+	// Arrange
+	//
+	existing := models.AddTodo(models.Todo{Title: "Water the plants"})
+	before := len(models.AllTodos())
+	revision := models.Revision()
+	body := `{"operations": [
+		{"op": "create", "todo": {"title": "Buy seeds"}},
+		{"op": "update", "id": "` + existing.Id + `", "if_match": "*", "todo": {"title": "Water all plants"}},
+		{"op": "delete", "id": "unknown"},
+		{"op": "create", "todo": {"title": "Buy soil"}}]}`
+	recorder := httptest.NewRecorder()
+
+	// Act
+	//
+	BatchPost(recorder, httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(body)), nil)
+
+	// Assert
+	//
+	var response struct {
+		Meta BatchMeta     `json:"meta"`
+		Data []BatchResult `json:"data"`
+	}
+	json.NewDecoder(recorder.Body).Decode(&response)
+	if recorder.Code != http.StatusNotFound || response.Meta.Committed || response.Meta.Failed == nil || *response.Meta.Failed != 2 {
+		t.Fatal("Fehler", recorder.Code, response.Meta)
+	}
+	for i, status := range []int{http.StatusFailedDependency, http.StatusFailedDependency, http.StatusNotFound, http.StatusFailedDependency} {
+		if response.Data[i].Status != status || response.Data[i].Error == nil {
+			t.Error("Fehler", i, response.Data[i])
+		}
+	}
+	unchanged, _ := models.FindTodo(existing.Id)
+	if len(models.AllTodos()) != before || unchanged.Title != "Water the plants" || models.Revision() != revision {
+		t.Error("Fehler", len(models.AllTodos()), unchanged, models.Revision())
+	}
+	created := models.AddTodo(models.Todo{Title: "Buy soil"})
+	if created.Id == "" || created.Number != existing.Number+1 {
+		t.Error("Fehler", created, existing)
+	}
+}
+
+func TestTodosBulkPost_RejectsMoves(t *testing.T) {
+	// Arrange
+	//
+	todo := models.AddTodo(models.Todo{Title: "Book the flight"})
+	body := `[{"op": "update", "id": "` + todo.Id + `", "todo": {"title": "Book the train"}},
+		{"op": "move", "id": "` + todo.Id + `", "column": "done"}]`
+	recorder := httptest.NewRecorder()
+
+	// Act
+	//
+	TodosBulkPost(recorder, httptest.NewRequest(http.MethodPost, "/todos/bulk", strings.NewReader(body)), nil)
+
+	// Assert
+	//
+	unchanged, _ := models.FindTodo(todo.Id)
+	if recorder.Code != http.StatusUnprocessableEntity || unchanged.Title != "Book the flight" {
+		t.Error("Fehler", recorder.Code, unchanged)
+	}
+}
+
+// tagging tags the todos with the title, the other todos are left as they are
+type tagging struct {
+	title string
+	tag   string
+}
+
+func (h tagging) BeforeWrite(_ string, todo models.Todo) (models.Todo, error) {
+	if todo.Title == h.title {
+		todo.Tags = append(todo.Tags, h.tag)
+	}
+	return todo, nil
+}
+
+func TestBatchPost_MoveKeepsTheChangesOfTheWriteHooks(t *testing.T) {
+	// Arrange
+	//
+	plugins.RegisterWriteHook(tagging{title: "Ship the parcel", tag: "tracked"})
+	todo := models.AddTodo(models.Todo{Title: "Ship the parcel"})
+	// the result of a todo removed later in the batch is the todo the move returned
+	removed := models.AddTodo(models.Todo{Title: "Ship the parcel"})
+	body := `{"operations": [{"op": "move", "id": "` + todo.Id + `", "if_match": "*", "column": "` + models.DoneColumn() + `"},
+		{"op": "move", "id": "` + removed.Id + `", "if_match": "*", "column": "` + models.DoneColumn() + `"},
+		{"op": "delete", "id": "` + removed.Id + `", "if_match": "*"}]}`
+	recorder := httptest.NewRecorder()
+
+	// Act
+	//
+	BatchPost(recorder, httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(body)), nil)
+	moved, _ := models.FindTodo(todo.Id)
+
+	// Assert
+	//
+	var response struct {
+		Data []BatchResult `json:"data"`
+	}
+	json.NewDecoder(recorder.Body).Decode(&response)
+	if recorder.Code != http.StatusOK || moved.HasTag("tracked") == false || moved.Status != models.DoneColumn() {
+		t.Fatal("Fehler", recorder.Code, moved)
+	}
+	if len(response.Data) != 3 || response.Data[0].Data.HasTag("tracked") == false ||
+		response.Data[0].Data.UpdatedAt.Equal(*moved.UpdatedAt) == false {
+		t.Fatal("Fehler", response.Data)
+	}
+	if response.Data[1].Data.HasTag("tracked") == false || response.Data[1].Data.Status != models.DoneColumn() {
+		t.Error("Fehler", response.Data[1].Data)
+	}
+}
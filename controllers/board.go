@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"os"
+	"strings"
+	"todo-rest-backend/models"
+	"todo-rest-backend/plugins"
+)
+
+// BoardColumn is a status column of a board with its todos in the order of the list
+type BoardColumn struct {
+	Status string        `json:"status"`
+	Count  int           `json:"count"`
+	Todos  []models.Todo `json:"todos"`
+}
+
+// Board groups the todos of a list into status columns
+type Board struct {
+	List    string        `json:"list"`
+	Columns []BoardColumn `json:"columns"`
+}
+
+// ColumnMove is the body of the move column action, Position 1 is the top of the column
+type ColumnMove struct {
+	Column   string `json:"column"`
+	Position int    `json:"position"`
+}
+
+// configureBoard reads the board columns from TODO_BOARD_COLUMNS, e.g. "backlog,todo,doing,done".
+// The last column holds the terminated todos.
+func configureBoard() error {
+	columns := os.Getenv("TODO_BOARD_COLUMNS")
+	if columns == "" {
+		return nil
+	}
+	return models.SetBoardColumns(strings.Split(columns, ","))
+}
+
+// ListBoardGet Handler for the board of a list action
+// GET /lists/:id/board
+func ListBoardGet(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) error {
+	board := Board{List: params.ByName("id"), Columns: []BoardColumn{}}
+	columns := make(map[string]int)
+	for i, status := range models.BoardColumns() {
+		board.Columns = append(board.Columns, BoardColumn{Status: status, Todos: []models.Todo{}})
+		columns[status] = i
+	}
+	for _, todo := range models.ListTodos(board.List) {
+		column := &board.Columns[columns[todo.Status]]
+		column.Todos = append(column.Todos, todo)
+		column.Count++
+	}
+
+	response := models.JsonExtendedResponse{Data: board}
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// TodoMoveColumnPost Handler for the move column action, it changes status and position of a todo at once
+// POST /todos/:id/move-column
+func TodoMoveColumnPost(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	id := params.ByName("id")
+	todo, ok := models.FindTodo(id)
+	if ok == false {
+		return handleTodoIdNotFound(writer)
+	}
+
+	var move ColumnMove
+	if request.Body == nil || json.NewDecoder(request.Body).Decode(&move) != nil {
+		return handleTodoNotProperlyTransmitted(writer)
+	}
+	if models.IsBoardColumn(move.Column) == false {
+		return writeError(writer, http.StatusUnprocessableEntity, models.CodeValidationFailed, "Unknown Board Column")
+	}
+
+	// write hooks see the todo in its new column
+	todo.Status = move.Column
+	todo.Terminated = move.Column == models.DoneColumn()
+	todo, err := plugins.BeforeWrite(plugins.ActionUpdate, todo)
+	if err != nil {
+		return handleWriteHookError(writer, err)
+	}
+	// the changes of the write hooks are kept, the todo ends up in the column of the move
+	models.UpdateTodo(id, todo)
+
+	todoMoved, err := models.MoveTodoToColumn(id, move.Column, move.Position)
+	if err != nil {
+		return handleTodoNotProperlyTransmittedGeneral(writer, "Move Failed")
+	}
+	syncTodo(todoMoved)
+	plugins.Emit(plugins.TodoUpdated, todoMoved)
+
+	response := models.JsonExtendedResponse{Data: todoMoved}
+	err = models.UpdateDataInFile()
+	if err != nil {
+		return err
+	}
+
+	writer.Header().Set("ETag", todoEtag(todoMoved))
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
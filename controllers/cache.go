@@ -0,0 +1,255 @@
+package controllers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"golang.org/x/sync/singleflight"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"todo-rest-backend/models"
+)
+
+// defaultMaxAge is the Cache-Control max-age of read routes without configuration
+const defaultMaxAge = 5 * time.Second
+
+// defaultResponseCacheTtl is the time identical list queries are answered from the response cache
+const defaultResponseCacheTtl = time.Second
+
+// cachedResponse is a recorded response of a read route
+type cachedResponse struct {
+	status     int
+	header     http.Header
+	body       []byte
+	etag       string
+	generation uint64
+	storedAt   time.Time
+}
+
+// responseRecorder captures a response so that its ETag can be computed before it is sent
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(data)
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+var cacheMutex sync.Mutex
+var responseCache = make(map[string]cachedResponse)
+
+// storeGeneration is increased by every mutation, cached responses of older generations are stale
+var storeGeneration uint64
+
+// coalescedRequests runs the handler once for identical concurrent requests of a read route
+var coalescedRequests singleflight.Group
+
+var routeMaxAges = make(map[string]time.Duration)
+var responseCacheTtl = defaultResponseCacheTtl
+
+// configureCaching reads the Cache-Control max-age per route from TODO_CACHE_ROUTES,
+// e.g. "GET /todos=10s,GET /todos/:id=0s", and the response cache TTL from TODO_RESPONSE_CACHE_TTL
+func configureCaching() error {
+	routes := os.Getenv("TODO_CACHE_ROUTES")
+	for _, entry := range strings.Split(routes, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid TODO_CACHE_ROUTES entry %q, expected METHOD /path=duration", entry)
+		}
+		maxAge, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil || maxAge < 0 {
+			return fmt.Errorf("invalid max-age in TODO_CACHE_ROUTES entry %q", entry)
+		}
+		routeMaxAges[strings.Join(strings.Fields(parts[0]), " ")] = maxAge
+	}
+
+	ttl := os.Getenv("TODO_RESPONSE_CACHE_TTL")
+	if ttl != "" {
+		var err error
+		responseCacheTtl, err = time.ParseDuration(ttl)
+		if err != nil || responseCacheTtl < 0 {
+			return fmt.Errorf("invalid TODO_RESPONSE_CACHE_TTL %q", ttl)
+		}
+	}
+	return nil
+}
+
+// cacheable serves a read route with an ETag and a Cache-Control max-age and answers
+// If-None-Match requests with 304 Not Modified. Identical requests arriving while the handler runs for one of
+// them wait for its response instead of running the handler again.
+// With useResponseCache identical requests within the response cache TTL are answered without running the handler.
+func cacheable(route string, handle Handle, useResponseCache bool) Handle {
+	maxAge, ok := routeMaxAges["GET "+route]
+	if ok == false {
+		maxAge = defaultMaxAge
+	}
+
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		// the responses differ by the language of the collation and by the user the todos belong to
+		key := request.URL.RequestURI() + "\n" + request.Header.Get("Accept-Language") + "\n" + models.Owner()
+		response, found := cachedResponse{}, false
+		if useResponseCache {
+			response, found = lookupResponse(key)
+		}
+
+		if found == false {
+			// identical requests arriving while the handler runs share its response
+			generation := currentGeneration()
+			shared, err, _ := coalescedRequests.Do(fmt.Sprintf("%s\n%d", key, generation), func() (interface{}, error) {
+				recorder := &responseRecorder{header: make(http.Header)}
+				err := handle(recorder, request, params)
+				if err != nil {
+					return nil, err
+				}
+				response := cachedResponse{
+					status:     recorder.status,
+					header:     recorder.header,
+					body:       recorder.body.Bytes(),
+					generation: generation,
+					storedAt:   time.Now(),
+				}
+				if response.status == 0 {
+					response.status = http.StatusOK
+				}
+				// handlers of a single resource set its ETag, e.g. the version of a todo
+				response.etag = recorder.header.Get("ETag")
+				if response.etag == "" {
+					sum := sha256.Sum256(response.body)
+					response.etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+				}
+
+				if useResponseCache && response.status == http.StatusOK {
+					storeResponse(key, response)
+				}
+				return response, nil
+			})
+			if err != nil {
+				return err
+			}
+			response = shared.(cachedResponse)
+		}
+
+		for name, values := range response.header {
+			// the Vary header of a middleware like the CORS one and the Link of a deprecation are kept
+			if name == "Vary" || name == "Link" {
+				values = append(writer.Header()[name], values...)
+			}
+			writer.Header()[name] = values
+		}
+		if response.status != http.StatusOK {
+			writer.Header().Set("Cache-Control", "no-store")
+			writer.WriteHeader(response.status)
+			_, err := writer.Write(response.body)
+			return err
+		}
+
+		writer.Header().Set("ETag", response.etag)
+		if maxAge > 0 {
+			writer.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(maxAge.Seconds())))
+		} else {
+			writer.Header().Set("Cache-Control", "no-cache")
+		}
+		if etagMatches(request.Header.Get("If-None-Match"), response.etag) {
+			writer.WriteHeader(http.StatusNotModified)
+			return nil
+		}
+		writer.WriteHeader(response.status)
+		_, err := writer.Write(response.body)
+		return err
+	}
+}
+
+// mutation marks a route changing the store: its responses are not stored and it invalidates the response cache
+func mutation(handle Handle) Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		writer.Header().Set("Cache-Control", "no-store")
+		defer invalidateResponseCache()
+		return handle(writer, request, params)
+	}
+}
+
+// noStore marks a route whose responses must not be cached
+func noStore(handle Handle) Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		writer.Header().Set("Cache-Control", "no-store")
+		return handle(writer, request, params)
+	}
+}
+
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func currentGeneration() uint64 {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	return storeGeneration
+}
+
+func lookupResponse(key string) (cachedResponse, bool) {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+
+	response, ok := responseCache[key]
+	if ok == false || response.generation != storeGeneration || time.Since(response.storedAt) > responseCacheTtl {
+		return cachedResponse{}, false
+	}
+	return response, true
+}
+
+func storeResponse(key string, response cachedResponse) {
+	if responseCacheTtl == 0 {
+		return
+	}
+
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+
+	// expired entries are dropped on the way so that the cache does not grow with every distinct query
+	for cachedKey, cached := range responseCache {
+		if cached.generation != storeGeneration || time.Since(cached.storedAt) > responseCacheTtl {
+			delete(responseCache, cachedKey)
+		}
+	}
+	if response.generation == storeGeneration {
+		responseCache[key] = response
+	}
+}
+
+// invalidateResponseCache is called after every change of the store
+func invalidateResponseCache() {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+
+	storeGeneration++
+	responseCache = make(map[string]cachedResponse)
+}
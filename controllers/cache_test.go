@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheable_NotModified(t *testing.T) {
+	// Arrange
+	//
+	calls := 0
+	handle := cacheable("/test", func(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+		calls++
+		_, err := fmt.Fprint(writer, "content")
+		return err
+	}, true)
+	first := httptest.NewRecorder()
+	handle(first, httptest.NewRequest(http.MethodGet, "/test", nil), nil)
+
+	// Act
+	//
+	request := httptest.NewRequest(http.MethodGet, "/test", nil)
+	request.Header.Set("If-None-Match", first.Header().Get("ETag"))
+	second := httptest.NewRecorder()
+	handle(second, request, nil)
+
+	// Assert
+	//
+	if second.Code != http.StatusNotModified {
+		t.Errorf("got status %d, want 304", second.Code)
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want the second response from the cache", calls)
+	}
+}
+
+func TestMutation_InvalidatesResponseCache(t *testing.T) {
+	// Arrange
+	//
+	calls := 0
+	handle := cacheable("/test/mutation", func(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+		calls++
+		return nil
+	}, true)
+	handle(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test/mutation", nil), nil)
+
+	// Act
+	//
+	mutation(func(http.ResponseWriter, *http.Request, httprouter.Params) error { return nil })(httptest.NewRecorder(), nil, nil)
+	handle(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test/mutation", nil), nil)
+
+	// Assert
+	//
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want 2", calls)
+	}
+}
+
+func TestCacheable_CoalescesConcurrentRequests(t *testing.T) {
+	// Arrange
+	//
+	var calls int32
+	release := make(chan struct{})
+	handle := cacheable("/test/coalesce", func(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		_, err := fmt.Fprint(writer, "content")
+		return err
+	}, false)
+	recorders := make([]*httptest.ResponseRecorder, 5)
+
+	// Act
+	//
+	var wait sync.WaitGroup
+	for i := range recorders {
+		recorders[i] = httptest.NewRecorder()
+		wait.Add(1)
+		go func(recorder *httptest.ResponseRecorder) {
+			defer wait.Done()
+			handle(recorder, httptest.NewRequest(http.MethodGet, "/test/coalesce?filter=open", nil), nil)
+		}(recorders[i])
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wait.Wait()
+
+	// Assert
+	//
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want one run shared by the identical requests", calls)
+	}
+	for _, recorder := range recorders {
+		if recorder.Body.String() != "content" {
+			t.Error("Fehler", recorder.Code, recorder.Body.String())
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+	"todo-rest-backend/models"
+)
+
+// ChaosConfig is the fault injection for testing the retry logic of clients, each request is
+// delayed with LatencyRate, answered with 500 with ErrorRate or has its connection dropped with DropRate
+type ChaosConfig struct {
+	Latency     time.Duration
+	LatencyRate float64
+	ErrorRate   float64
+	DropRate    float64
+}
+
+// chaosConfig is nil unless fault injection is enabled
+var chaosConfig *ChaosConfig
+
+// chaosRandom returns the random numbers deciding the faults, tests replace it
+var chaosRandom = rand.Float64
+
+// configureChaos enables fault injection if any of TODO_CHAOS_LATENCY_RATE, TODO_CHAOS_ERROR_RATE and
+// TODO_CHAOS_DROP_RATE is set. The rates are probabilities between 0 and 1, TODO_CHAOS_LATENCY (default 1s)
+// is the injected delay. It must not be enabled in production.
+func configureChaos() error {
+	chaosConfig = nil
+	config := ChaosConfig{Latency: time.Second}
+	enabled := false
+	for name, rate := range map[string]*float64{
+		"TODO_CHAOS_LATENCY_RATE": &config.LatencyRate,
+		"TODO_CHAOS_ERROR_RATE":   &config.ErrorRate,
+		"TODO_CHAOS_DROP_RATE":    &config.DropRate,
+	} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		var err error
+		*rate, err = strconv.ParseFloat(value, 64)
+		if err != nil || *rate < 0 || *rate > 1 {
+			return errors.New(name + " must be a probability between 0 and 1")
+		}
+		enabled = true
+	}
+	if config.ErrorRate+config.DropRate > 1 {
+		return errors.New("TODO_CHAOS_ERROR_RATE and TODO_CHAOS_DROP_RATE must not add up to more than 1")
+	}
+	if latency := os.Getenv("TODO_CHAOS_LATENCY"); latency != "" {
+		var err error
+		config.Latency, err = time.ParseDuration(latency)
+		if err != nil || config.Latency < 0 {
+			return errors.New("TODO_CHAOS_LATENCY must be a duration like 500ms")
+		}
+	}
+
+	if enabled {
+		log.Printf("Fault injection enabled: %+v", config)
+		chaosConfig = &config
+	}
+	return nil
+}
+
+// chaos injects the configured faults before the requests are handled. The readiness is answered without
+// faults, so that orchestrators do not restart the server under test.
+func chaos(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		config := chaosConfig
+		if config == nil || request.URL.Path == readinessPath {
+			handler.ServeHTTP(writer, request)
+			return
+		}
+
+		if chaosRandom() < config.LatencyRate {
+			time.Sleep(config.Latency)
+		}
+		fault := chaosRandom()
+		if fault < config.DropRate {
+			// the server closes the connection without a response
+			panic(http.ErrAbortHandler)
+		}
+		if fault < config.DropRate+config.ErrorRate {
+			logFailure(request, writeError(writer, http.StatusInternalServerError, models.CodeInternalError, "Injected Fault"))
+			return
+		}
+		handler.ServeHTTP(writer, request)
+	})
+}
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"math/rand"
+	"net/http"
+	"testing"
+)
+
+func TestChaos_InjectsFaults(t *testing.T) {
+	// Arrange
+	//
+	defer func() { chaosConfig, chaosRandom = nil, rand.Float64 }()
+	chaosConfig = &ChaosConfig{ErrorRate: 0.2, DropRate: 0.1}
+	serve := func(random float64, path string) (status int, dropped bool) {
+		chaosRandom = func() float64 { return random }
+		defer func() { dropped = recover() == http.ErrAbortHandler }()
+		return serveRoute(t, http.MethodGet, path, "").Code, false
+	}
+
+	// Act
+	//
+	_, dropped := serve(0.05, "/todos")
+	failed, _ := serve(0.25, "/todos")
+	passed, _ := serve(0.5, "/todos")
+	ready, _ := serve(0.05, readinessPath)
+
+	// Assert
+	//
+	if dropped == false {
+		t.Error("Fehler: connection not dropped")
+	}
+	if failed != http.StatusInternalServerError || passed != http.StatusOK || ready != http.StatusOK {
+		t.Error("Fehler", failed, passed, ready)
+	}
+}
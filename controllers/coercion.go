@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"todo-rest-backend/models"
+)
+
+// Coercion modes of TODO_COERCION
+const (
+	// CoercionLenient coerces values like earlier versions: a boolean that is neither true nor false is false and
+	// ids are looked up as given
+	CoercionLenient = "lenient"
+	// CoercionStrict rejects boolean parameters other than true and false and ids other than those the backend
+	// assigns with 422
+	CoercionStrict = "strict"
+)
+
+// strictCoercion is set by TODO_COERCION=strict
+var strictCoercion bool
+
+func configureCoercion() error {
+	switch os.Getenv("TODO_COERCION") {
+	case "", CoercionLenient:
+		strictCoercion = false
+	case CoercionStrict:
+		strictCoercion = true
+	default:
+		return errors.New("TODO_COERCION must be lenient or strict")
+	}
+	return nil
+}
+
+// idPattern matches the ids the backend assigns to todos, goals and checklist items
+var idPattern = regexp.MustCompile(`^(0|[1-9][0-9]*)$`)
+
+// idSegments are the path segments followed by an id, the ids of lists are their names
+var idSegments = map[string]bool{"todos": true, "goals": true, "habits": true, "done": true, "items": true}
+
+// isStrictBool tells whether the value is a boolean in strict mode, an absent value is false
+func isStrictBool(value string) bool {
+	return value == "" || value == "true" || value == "false"
+}
+
+// isId tells whether the id has the form of the ids the backend assigns
+func isId(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+// rejectNonConforming answers requests with boolean query parameters or headers other than true and false and
+// path ids other than numbers with 422 in strict mode, the handlers coerce them leniently
+func rejectNonConforming(writer http.ResponseWriter, request *http.Request) bool {
+	if strictCoercion == false {
+		return false
+	}
+	if isStrictBool(request.Header.Get(denylistOverrideHeader)) == false {
+		return rejectValue(writer, request, denylistOverrideHeader, "must be true or false")
+	}
+	operation, ok := findApiOperation(request.Method, request.URL.Path)
+	if ok == false {
+		return false
+	}
+	query := request.URL.Query()
+	for _, name := range operation.booleans {
+		if isStrictBool(query.Get(name)) == false {
+			return rejectValue(writer, request, name, "must be true or false")
+		}
+	}
+	segments := strings.Split(request.URL.Path, "/")
+	for i, segment := range strings.Split(operation.path, "/") {
+		if strings.HasPrefix(segment, ":") && i > 0 && idSegments[segments[i-1]] && isId(segments[i]) == false {
+			return rejectValue(writer, request, segment[1:], fmt.Sprintf("must be an id like 42, not %q", segments[i]))
+		}
+	}
+	return false
+}
+
+// rejectValue answers with 422 and the violation of the parameter or header
+func rejectValue(writer http.ResponseWriter, request *http.Request, field string, message string) bool {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	logFailure(request, handleInvalidBody(writer, models.ValidationErrors{{Field: field, Message: message}}))
+	return true
+}
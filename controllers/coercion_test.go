@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"todo-rest-backend/models"
+)
+
+func TestRegisterRoutes_RejectsNonConformingValuesInStrictMode(t *testing.T) {
+	// Arrange
+	//
+	defer func() { strictCoercion = false }()
+	todo := models.AddTodo(models.Todo{Title: "Renew the passport"})
+
+	// Act
+	//
+	lenientBool := serveRoute(t, http.MethodGet, "/todos?terminated=false&overdue=no", "")
+	lenientId := serveRoute(t, http.MethodGet, "/todos/0"+todo.Id, "")
+	strictCoercion = true
+	strictBool := serveRoute(t, http.MethodGet, "/todos?terminated=false&overdue=no", "")
+	strictSuggest := serveRoute(t, http.MethodPost, "/todos?suggest=1", `{"title": "Renew the visa"}`)
+	strictId := serveRoute(t, http.MethodGet, "/todos/0"+todo.Id, "")
+	strictItemId := serveRoute(t, http.MethodDelete, "/todos/"+todo.Id+"/items/first", "")
+	strictBatch := serveRoute(t, http.MethodPost, "/todos/bulk", `[{"op": "delete", "id": "abc"}]`)
+	conforming := serveRoute(t, http.MethodGet, "/todos/"+todo.Id, "")
+	subRoute := serveRoute(t, http.MethodGet, "/todos/revision", "")
+	list := serveRoute(t, http.MethodGet, "/lists/home/todos", "")
+
+	// Assert
+	//
+	if lenientBool.Code != http.StatusBadRequest || lenientId.Code != http.StatusNotFound {
+		t.Error("Fehler", lenientBool.Code, lenientId.Code)
+	}
+	if strictBool.Code != http.StatusUnprocessableEntity || strings.Contains(strictBool.Body.String(), `"field":"overdue"`) == false {
+		t.Error("Fehler", strictBool.Code, strictBool.Body.String())
+	}
+	if strictSuggest.Code != http.StatusUnprocessableEntity || strictId.Code != http.StatusUnprocessableEntity ||
+		strictItemId.Code != http.StatusUnprocessableEntity || strictBatch.Code != http.StatusUnprocessableEntity {
+		t.Error("Fehler", strictSuggest.Code, strictId.Code, strictItemId.Code, strictBatch.Code)
+	}
+	if conforming.Code != http.StatusOK || subRoute.Code != http.StatusOK || list.Code != http.StatusOK {
+		t.Error("Fehler", conforming.Code, subRoute.Code, list.Code)
+	}
+}
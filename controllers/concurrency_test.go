@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"todo-rest-backend/models"
+)
+
+// TestRoutes_ConcurrentRequests is meant to be run with go test -race
+func TestRoutes_ConcurrentRequests(t *testing.T) {
+	// Arrange
+	//
+	models.SetRepository(models.NewMemoryRepository())
+	defer models.SetRepository(models.NewMemoryRepository())
+	router := httprouter.New()
+	RegisterRoutes(HttpRouter{Router: router})
+	requests := []func(worker int, i int) *http.Request{
+		func(worker int, i int) *http.Request {
+			body := fmt.Sprintf(`{"title": "Todo %d-%d", "tags": ["load"]}`, worker, i)
+			return httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(body))
+		},
+		func(worker int, i int) *http.Request {
+			body := fmt.Sprintf(`{"title": "Changed %d-%d", "terminated": true}`, worker, i)
+			return httptest.NewRequest(http.MethodPut, fmt.Sprintf("/todos/%d", i), strings.NewReader(body))
+		},
+		func(_ int, i int) *http.Request {
+			return httptest.NewRequest(http.MethodGet, fmt.Sprintf("/todos/%d", i), nil)
+		},
+		func(_ int, _ int) *http.Request {
+			return httptest.NewRequest(http.MethodGet, "/todos?sort=title", nil)
+		},
+		func(_ int, _ int) *http.Request {
+			return httptest.NewRequest(http.MethodGet, "/lists/inbox/board", nil)
+		},
+		func(_ int, i int) *http.Request {
+			body := `{"action": "start"}`
+			return httptest.NewRequest(http.MethodPost, fmt.Sprintf("/todos/%d/pomodoro", i), strings.NewReader(body))
+		},
+		func(_ int, i int) *http.Request {
+			return httptest.NewRequest(http.MethodGet, fmt.Sprintf("/todos/%d/pomodoro", i), nil)
+		},
+		func(_ int, _ int) *http.Request {
+			return httptest.NewRequest(http.MethodGet, "/reports/focus", nil)
+		},
+		func(_ int, i int) *http.Request {
+			return httptest.NewRequest(http.MethodGet, fmt.Sprintf("/todos/%d/similar", i), nil)
+		},
+		func(_ int, _ int) *http.Request {
+			return httptest.NewRequest(http.MethodGet, "/todos/autocomplete?q=to", nil)
+		},
+		func(_ int, i int) *http.Request {
+			return httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/todos/%d", i%3), nil)
+		},
+	}
+
+	// Act
+	//
+	var wait sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wait.Add(1)
+		go func(worker int) {
+			defer wait.Done()
+			for i := 0; i < 100; i++ {
+				recorder := httptest.NewRecorder()
+				router.ServeHTTP(recorder, requests[(worker+i)%len(requests)](worker, i))
+				if recorder.Code >= http.StatusInternalServerError {
+					t.Error("Fehler", recorder.Code, recorder.Body.String())
+				}
+			}
+		}(worker)
+	}
+	wait.Wait()
+
+	// Assert
+	//
+	numbers := make(map[int]bool)
+	for _, todo := range models.AllTodos() {
+		if numbers[todo.Number] {
+			t.Error("Fehler", "number", todo.Number, "given twice")
+		}
+		numbers[todo.Number] = true
+	}
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"os"
+	"strings"
+	"todo-rest-backend/models"
+)
+
+// requireIfMatch rejects changes of a todo without If-Match header, it is required unless
+// TODO_REQUIRE_IF_MATCH=false
+var requireIfMatch = true
+
+// configureConditionalRequests reads from TODO_REQUIRE_IF_MATCH whether changing or deleting a todo requires
+// the If-Match header, it does by default
+func configureConditionalRequests() {
+	value := os.Getenv("TODO_REQUIRE_IF_MATCH")
+	requireIfMatch = value == "" || models.ToBool(value)
+}
+
+// todoEtag is the ETag of a todo, it is the same for equal todos in every response
+func todoEtag(todo models.Todo) string {
+	return `"` + todo.Version() + `"`
+}
+
+// ifMatchMatches compares the If-Match header with the ETag strongly, weak ETags never match
+func ifMatchMatches(ifMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchesAny tells whether the If-Match header is "*", which only matches existing todos
+func ifMatchesAny(ifMatch string) bool {
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		if strings.TrimSpace(candidate) == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatch guards the change of the todo with the id against lost updates: the If-Match header must be the
+// ETag of the todo, otherwise the todo changed since the client read it and the request is answered with
+// 412 Precondition Failed and the current ETag. Without If-Match the request is answered with
+// 428 Precondition Required if the header is required. "*" matches every version of the todo, for unknown
+// todos it fails with 412, other unknown todos are left to the handler.
+func ifMatch(handle Handle) Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		header := request.Header.Get("If-Match")
+		todo, ok := models.FindTodo(params.ByName("id"))
+		if ok == false && ifMatchesAny(header) {
+			return writeError(writer, http.StatusPreconditionFailed, models.CodeVersionConflict, "Precondition Failed")
+		}
+		if ok == false {
+			return handle(writer, request, params)
+		}
+		if header == "" {
+			if requireIfMatch {
+				return writeError(writer, http.StatusPreconditionRequired, models.CodeVersionRequired, "Precondition Required")
+			}
+			return handle(writer, request, params)
+		}
+		if ifMatchMatches(header, todoEtag(todo)) == false {
+			writer.Header().Set("ETag", todoEtag(todo))
+			return writeError(writer, http.StatusPreconditionFailed, models.CodeVersionConflict, "Precondition Failed")
+		}
+		return handle(writer, request, params)
+	}
+}
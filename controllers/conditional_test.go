@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-rest-backend/models"
+)
+
+func TestRegisterRoutes_RejectsChangesOfOutdatedVersions(t *testing.T) {
+	// Arrange
+	//
+	todo := models.AddTodo(models.Todo{Title: "Renew the passport"})
+	serve := func(method, ifMatch string) *httptest.ResponseRecorder {
+		return serveRoute(t, method, "/todos/"+todo.Id, `{"title": "Renew the passport soon"}`, "If-Match", ifMatch)
+	}
+
+	// Act
+	//
+	read := serve(http.MethodGet, "")
+	etag := read.Header().Get("ETag")
+	updated := serve(http.MethodPut, etag)
+	outdated := serve(http.MethodPut, etag)
+	outdatedDelete := serve(http.MethodDelete, etag)
+	missing := serve(http.MethodDelete, "")
+	deleted := serve(http.MethodDelete, updated.Header().Get("ETag"))
+	deletedAgain := serve(http.MethodDelete, "*")
+
+	// Assert
+	//
+	if etag != todoEtag(todo) || updated.Code != http.StatusOK || updated.Header().Get("ETag") == etag {
+		t.Error("Fehler", etag, updated.Code, updated.Header())
+	}
+	if outdated.Code != http.StatusPreconditionFailed || outdated.Header().Get("ETag") != updated.Header().Get("ETag") {
+		t.Error("Fehler", outdated.Code, outdated.Header())
+	}
+	if outdatedDelete.Code != http.StatusPreconditionFailed || missing.Code != http.StatusPreconditionRequired {
+		t.Error("Fehler", outdatedDelete.Code, missing.Code)
+	}
+	if deleted.Code != http.StatusOK || deletedAgain.Code != http.StatusPreconditionFailed {
+		t.Error("Fehler", deleted.Code, deletedAgain.Code)
+	}
+}
+
+func TestConfigureConditionalRequests_RequiresIfMatchByDefault(t *testing.T) {
+	// Arrange
+	//
+	defer func() { requireIfMatch = true }()
+
+	// Act
+	//
+	t.Setenv("TODO_REQUIRE_IF_MATCH", "")
+	configureConditionalRequests()
+	byDefault := requireIfMatch
+	t.Setenv("TODO_REQUIRE_IF_MATCH", "false")
+	configureConditionalRequests()
+	optedOut := requireIfMatch
+
+	// Assert
+	//
+	if byDefault == false || optedOut {
+		t.Error("Fehler", byDefault, optedOut)
+	}
+}
@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"todo-rest-backend/models"
+)
+
+// contract is a Pact-style file of the interactions a consumer depends on
+type contract struct {
+	Consumer struct {
+		Name string `json:"name"`
+	} `json:"consumer"`
+	Interactions []struct {
+		Description   string `json:"description"`
+		ProviderState string `json:"providerState"`
+		Request       struct {
+			Method  string            `json:"method"`
+			Path    string            `json:"path"`
+			Query   string            `json:"query"`
+			Headers map[string]string `json:"headers"`
+			Body    json.RawMessage   `json:"body"`
+		} `json:"request"`
+		Response struct {
+			Status  int               `json:"status"`
+			Headers map[string]string `json:"headers"`
+			Body    interface{}       `json:"body"`
+		} `json:"response"`
+	} `json:"interactions"`
+}
+
+// providerStates prepare the store for the interactions, the returned values replace ${name} in request paths
+var providerStates = map[string]func() map[string]string{
+	"no todos exist": func() map[string]string {
+		return nil
+	},
+	"two todos exist": func() map[string]string {
+		todo := models.AddTodo(models.Todo{Title: "Buy milk", Description: "Oat milk", Tags: []string{"shopping"}})
+		models.AddTodo(models.Todo{Title: "Call the bank", Tags: []string{"finance"}})
+		return map[string]string{"id": todo.Id}
+	},
+}
+
+// TestContract verifies the provider against the contracts in the contracts directory, or the files
+// matching TODO_CONTRACTS, e.g. TODO_CONTRACTS=/tmp/pacts/*.json go test ./controllers -run Contract
+func TestContract(t *testing.T) {
+	pattern := os.Getenv("TODO_CONTRACTS")
+	if pattern == "" {
+		pattern = filepath.Join("..", "contracts", "*.json")
+	}
+	files, _ := filepath.Glob(pattern)
+	if len(files) == 0 {
+		t.Fatal("no contracts match", pattern)
+	}
+	defer models.SetRepository(models.NewMemoryRepository())
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var c contract
+		err = json.Unmarshal(content, &c)
+		if err != nil {
+			t.Fatal(file, err)
+		}
+
+		for _, interaction := range c.Interactions {
+			t.Run(c.Consumer.Name+"/"+interaction.Description, func(t *testing.T) {
+				// Arrange
+				//
+				state, ok := providerStates[interaction.ProviderState]
+				if ok == false {
+					t.Fatalf("unknown provider state %q", interaction.ProviderState)
+				}
+				models.SetRepository(models.NewMemoryRepository())
+				invalidateResponseCache()
+				path := interaction.Request.Path
+				for name, value := range state() {
+					path = strings.ReplaceAll(path, "${"+name+"}", value)
+				}
+				if interaction.Request.Query != "" {
+					path += "?" + interaction.Request.Query
+				}
+				request := httptest.NewRequest(interaction.Request.Method, path, strings.NewReader(string(interaction.Request.Body)))
+				for name, value := range interaction.Request.Headers {
+					request.Header.Set(name, value)
+				}
+				router := httprouter.New()
+				RegisterRoutes(HttpRouter{Router: router})
+				recorder := httptest.NewRecorder()
+
+				// Act
+				//
+				router.ServeHTTP(recorder, request)
+
+				// Assert
+				//
+				if recorder.Code != interaction.Response.Status {
+					t.Errorf("got status %d, want %d", recorder.Code, interaction.Response.Status)
+				}
+				for name, want := range interaction.Response.Headers {
+					if got := recorder.Header().Get(name); got != want {
+						t.Errorf("got header %s %q, want %q", name, got, want)
+					}
+				}
+				if interaction.Response.Body != nil {
+					var body interface{}
+					err := json.Unmarshal(recorder.Body.Bytes(), &body)
+					if err != nil {
+						t.Fatal("response is no JSON:", recorder.Body.String())
+					}
+					for _, mismatch := range matchShape("$", interaction.Response.Body, body) {
+						t.Error(mismatch)
+					}
+				}
+			})
+		}
+	}
+}
+
+// matchShape matches the actual body against the example of the contract like Pact's type matching:
+// objects need the fields of the example, values the type of the example and arrays elements like its first
+// element. Fields the contract does not mention are ignored, so that the provider can add fields.
+func matchShape(path string, want interface{}, got interface{}) []string {
+	switch want := want.(type) {
+	case map[string]interface{}:
+		object, ok := got.(map[string]interface{})
+		if ok == false {
+			return []string{fmt.Sprintf("%s: got %v, want an object", path, got)}
+		}
+		var mismatches []string
+		for name, value := range want {
+			field, ok := object[name]
+			if ok == false {
+				mismatches = append(mismatches, fmt.Sprintf("%s.%s: missing", path, name))
+				continue
+			}
+			mismatches = append(mismatches, matchShape(path+"."+name, value, field)...)
+		}
+		return mismatches
+	case []interface{}:
+		array, ok := got.([]interface{})
+		if ok == false {
+			return []string{fmt.Sprintf("%s: got %v, want an array", path, got)}
+		}
+		if len(want) == 0 {
+			return nil
+		}
+		if len(array) == 0 {
+			return []string{fmt.Sprintf("%s: got an empty array, want elements like %v", path, want[0])}
+		}
+		var mismatches []string
+		for i, element := range array {
+			mismatches = append(mismatches, matchShape(fmt.Sprintf("%s[%d]", path, i), want[0], element)...)
+		}
+		return mismatches
+	default:
+		if fmt.Sprintf("%T", want) != fmt.Sprintf("%T", got) {
+			return []string{fmt.Sprintf("%s: got %#v, want a value like %#v", path, got, want)}
+		}
+		return nil
+	}
+}
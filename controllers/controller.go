@@ -2,69 +2,491 @@
 package controllers
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/julienschmidt/httprouter"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
 	"sort"
 	"strconv"
+	"sync"
+	"syscall"
+	"time"
 	"todo-rest-backend/models"
+	"todo-rest-backend/plugins"
+	"todo-rest-backend/rules"
 )
 
+// BackendHostUrl is the listen address used when none is configured
 const BackendHostUrl string = ":8080"
 
-// Run does the running of the web server
-func Run(enablePersistence bool) {
+// Run does the running of the web server with the todos of the repository on the address, e.g. ":8080".
+// On SIGINT or SIGTERM the server shuts down gracefully, see serve.
+func Run(repository models.Repository, enablePersistence bool, address string) {
+	err := Configure(repository, enablePersistence)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	handler, err := newHandler(os.Getenv("TODO_ROUTER"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// the log lines of the backend are written as JSON like the ones of the requests
+	slog.SetDefault(logger)
+	logger.Info("backend running", slog.String("address", listener.Addr().String()))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	server := &http.Server{Handler: requestLogging(cors(chaos(recording(normalizedPaths(handler)))))}
+	err = serve(ctx, server, listener, repository)
+	if err != nil {
+		log.Fatal(err)
+	}
+	logger.Info("backend stopped")
+}
+
+// Configure loads the todos and sets up plugins, sync, retention and caching from the environment.
+// Embedders call it before RegisterRoutes.
+// With persistence enabled the todos of the repository are loaded from and saved to the storage selected
+// by TODO_STORAGE, repositories persisting the todos themselves are configured without.
+// With TODO_BACKGROUND_LOADING=true the todos are loaded after Configure returned, see initializeStore.
+func Configure(repository models.Repository, enablePersistence bool) error {
+	models.SetRepository(repository)
 	if enablePersistence {
 		models.EnableFilePersistence()
 	} else {
 		models.DisableFilePersistence()
 	}
 
-	models.Initialize()
+	err := configurePlugins()
+	if err != nil {
+		return err
+	}
+
+	err = configureBoard()
+	if err != nil {
+		return err
+	}
+
+	err = configureDefaults()
+	if err != nil {
+		return err
+	}
+
+	initializeStore()
+
+	err = configureIssueSync()
+	if err != nil {
+		return err
+	}
+
+	err = configureRetention()
+	if err != nil {
+		return err
+	}
 
-	fmt.Println("Backend running at:", BackendHostUrl)
-	router := httprouter.New()
-	router.GET("/", Index)
-	router.GET("/todos", TodosGet)
-	router.GET("/todos/:id", TodoGetById)
-	router.POST("/todos", TodoPost)
-	router.PUT("/todos/:id", TodoPut)
-	router.DELETE("/todos/:id", TodoDelete)
-	router.DELETE("/todos", DeleteAllTodos)
+	err = configureTrashRetention()
+	if err != nil {
+		return err
+	}
+
+	err = configureRouting()
+	if err != nil {
+		return err
+	}
+
+	err = configureSorting()
+	if err != nil {
+		return err
+	}
+
+	err = configureWeather()
+	if err != nil {
+		return err
+	}
+
+	err = configureReports()
+	if err != nil {
+		return err
+	}
+
+	err = configureWaiting()
+	if err != nil {
+		return err
+	}
+
+	configureSimpleApi()
+	configureApiDocs()
+
+	err = configureCoercion()
+	if err != nil {
+		return err
+	}
+
+	err = configureInboundWebhooks()
+	if err != nil {
+		return err
+	}
+	configureConditionalRequests()
 
-	err := http.ListenAndServe(BackendHostUrl, router)
-	log.Fatal(err)
+	err = configureSnapshots()
+	if err != nil {
+		return err
+	}
+
+	err = configureCors()
+	if err != nil {
+		return err
+	}
+
+	err = configureChaos()
+	if err != nil {
+		return err
+	}
+
+	err = configureRecording()
+	if err != nil {
+		return err
+	}
+
+	err = configureShutdown()
+	if err != nil {
+		return err
+	}
+
+	err = configureSaving()
+	if err != nil {
+		return err
+	}
+
+	return configureCaching()
+}
+
+// storeMutex guards the store across the request handlers and the background jobs: GET requests share
+// it, changing requests and jobs hold it exclusively, so a handler sees no change between its reads and writes
+var storeMutex sync.RWMutex
+
+// storeHold is how a request holds the store: shared or exclusively, for the user the store functions act for
+// and overriding the denylist or not
+type storeHold struct {
+	shared   bool
+	user     string
+	override bool
+}
+
+// storeHoldKey is the context key of the storeHold of a request
+type storeHoldKey struct{}
+
+func (h storeHold) acquire() {
+	if h.shared {
+		storeMutex.RLock()
+	} else {
+		storeMutex.Lock()
+	}
+	if len(users) > 0 {
+		models.SetOwner(h.user)
+	}
+	if h.shared == false {
+		plugins.OverrideDenylist(h.override)
+	}
+}
+
+func (h storeHold) release() {
+	if len(users) > 0 {
+		models.SetOwner("")
+	}
+	if h.shared == false {
+		plugins.OverrideDenylist(false)
+	}
+	if h.shared {
+		storeMutex.RUnlock()
+	} else {
+		storeMutex.Unlock()
+	}
+}
+
+// withoutStore runs wait with the store released, so that handlers waiting for changes do not block them.
+// The store is held again when wait returns, like sync.Cond.Wait does with its lock.
+func withoutStore(request *http.Request, wait func()) {
+	hold, ok := request.Context().Value(storeHoldKey{}).(storeHold)
+	if ok == false {
+		wait()
+		return
+	}
+	hold.release()
+	defer hold.acquire()
+	wait()
+}
+
+// configurePlugins selects the storage named by TODO_STORAGE (default "csv") and registers
+//   - a webhook event sink delivering through an outbox if TODO_WEBHOOK_URL is set, signing the events with
+//     TODO_WEBHOOK_SECRET
+//   - the external commands of the hooks file named by TODO_EXEC_HOOKS
+//   - the denylist of the file named by TODO_DENYLIST_FILE,
+//   - the classifiers configured by TODO_CLASSIFIER_KEYWORDS and TODO_CLASSIFIER_URL,
+//   - the Tengo scripts of the directory TODO_SCRIPTS_DIR and
+//   - the rules of the file named by TODO_RULES_FILE as write hooks, the rules see the changes of the scripts
+func configurePlugins() error {
+	storageName := os.Getenv("TODO_STORAGE")
+	if storageName == "" {
+		storageName = "csv"
+	}
+	storage, err := plugins.NewStorage(storageName)
+	if err != nil {
+		return err
+	}
+	models.SetStorage(storage)
+
+	webhookUrl := os.Getenv("TODO_WEBHOOK_URL")
+	if webhookUrl != "" {
+		sink := plugins.NewWebhookSink(webhookUrl)
+		sink.Secret = os.Getenv("TODO_WEBHOOK_SECRET")
+		outbox, err := plugins.NewOutbox("webhook", sink, models.DataPath(webhookOutboxFileName))
+		if err != nil {
+			return fmt.Errorf("cannot read the webhook outbox: %w", err)
+		}
+		outbox.MaxAttempts, err = webhookMaxAttempts()
+		if err != nil {
+			return err
+		}
+		plugins.RegisterOutbox(outbox)
+	}
+
+	// the events of lists with webhooks go through an outbox of their own
+	listIntegrations, err = plugins.NewIntegrations(models.DataPath(integrationsFileName))
+	if err != nil {
+		return fmt.Errorf("cannot read the list integrations: %w", err)
+	}
+	plugins.RegisterIntegrations(listIntegrations)
+	listsOutbox, err := plugins.NewOutbox("lists", listIntegrations, models.DataPath(listsOutboxFileName))
+	if err != nil {
+		return fmt.Errorf("cannot read the outbox of the lists: %w", err)
+	}
+	listsOutbox.MaxAttempts, err = webhookMaxAttempts()
+	if err != nil {
+		return err
+	}
+	listsOutbox.Filter = listIntegrations.Accepts
+	plugins.RegisterOutbox(listsOutbox)
+
+	hooksFile := os.Getenv("TODO_EXEC_HOOKS")
+	if hooksFile != "" {
+		hooks, err := plugins.LoadExecHooks(hooksFile)
+		if err != nil {
+			return err
+		}
+		for _, hook := range hooks {
+			plugins.RegisterEventSink(hook)
+		}
+	}
+
+	// the denylist runs first, sensitive content is neither passed to the classifiers nor to the scripts
+	denylistFile := os.Getenv("TODO_DENYLIST_FILE")
+	if denylistFile != "" {
+		denylist, err = plugins.LoadDenylist(denylistFile)
+		if err != nil {
+			return err
+		}
+		plugins.RegisterWriteHook(denylist)
+	}
+
+	keywordsFile := os.Getenv("TODO_CLASSIFIER_KEYWORDS")
+	if keywordsFile != "" {
+		classifier, err := plugins.LoadKeywordClassifier(keywordsFile)
+		if err != nil {
+			return err
+		}
+		plugins.RegisterWriteHook(&plugins.ClassifierHook{Classifier: classifier})
+	}
+
+	classifierUrl := os.Getenv("TODO_CLASSIFIER_URL")
+	if classifierUrl != "" {
+		plugins.RegisterWriteHook(&plugins.ClassifierHook{Classifier: plugins.NewHttpClassifier(classifierUrl)})
+	}
+
+	scriptsDir := os.Getenv("TODO_SCRIPTS_DIR")
+	if scriptsDir != "" {
+		scripts, err := plugins.LoadScriptHooks(scriptsDir)
+		if err != nil {
+			return err
+		}
+		for _, script := range scripts {
+			plugins.RegisterWriteHook(script)
+		}
+	}
+
+	rulesFile := os.Getenv("TODO_RULES_FILE")
+	if rulesFile != "" {
+		ruleEngine, err = rules.LoadFile(rulesFile)
+		if err != nil {
+			return err
+		}
+		plugins.RegisterWriteHook(ruleEngine)
+	}
+	return nil
+}
+
+// subRoutes maps fixed path segments below /todos (like /todos/export) to their handlers
+type subRoutes map[string]Handle
+
+// withSubRoutes dispatches the fixed segments of a /todos/:id route before falling back to the id handler.
+// httprouter does not allow static segments next to the :id wildcard, so they are served from here.
+// A nil fallback answers with 405 Method Not Allowed.
+func withSubRoutes(routes subRoutes, fallback Handle) Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		handle, ok := routes[params.ByName("id")]
+		if ok {
+			return handle(writer, request, params)
+		}
+		if fallback == nil {
+			writer.Header().Set("Allow", allowedMethods("/todos/:id", request.Method))
+			return writeError(writer, http.StatusMethodNotAllowed, models.CodeMethodNotAllowed, "Method Not Allowed")
+		}
+		return fallback(writer, request, params)
+	}
 }
 
 // Index Handler for the index action
 // GET /
-func Index(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+func Index(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
 	writer.WriteHeader(http.StatusOK)
 	_, err := fmt.Fprint(writer, "Welcome to the Todo REST API!\n")
+	return err
+}
+
+// TodosGet Handler for the todos get action, sort=title sorts by title in the language of the Accept-Language header.
+// near=lat,lon returns the todos within radius meters (default 1000), sort=distance the nearest first.
+// group_by=tag|status|list|due_bucket|assignee returns the todos in groups with their counts.
+// terminated, title_contains, description_contains, tag, overdue and due_before return the matching todos only.
+// page and per_page return a page of the todos, the meta field has the total count and the links to the
+// neighbouring pages.
+// GET /todos?sort=id|title|created_at|updated_at|priority|distance&order=asc|desc&page=2&per_page=50&near=47.37,8.54&radius=500&group_by=tag&terminated=false&title_contains=milk&tag=work&overdue=true&due_before=2024-05-07&updated_since=2024-05-01T12:00:00Z
+func TodosGet(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	query := request.URL.Query()
+	filter := models.TodoFilter{
+		TitleContains:       query.Get("title_contains"),
+		DescriptionContains: query.Get("description_contains"),
+		Tags:                query["tag"],
+	}
+	if query.Get("terminated") != "" {
+		terminated, err := strconv.ParseBool(query.Get("terminated"))
+		if err != nil {
+			return handleTodoNotProperlyTransmittedGeneral(writer, "Terminated Must Be true Or false")
+		}
+		filter.Terminated = &terminated
+	}
+	if query.Get("overdue") != "" {
+		overdue, err := strconv.ParseBool(query.Get("overdue"))
+		if err != nil {
+			return handleTodoNotProperlyTransmittedGeneral(writer, "Overdue Must Be true Or false")
+		}
+		filter.Overdue = &overdue
+	}
+	if query.Get("due_before") != "" {
+		dueBefore, _, err := models.ParseDue(query.Get("due_before"))
+		if err != nil {
+			return handleTodoNotProperlyTransmittedGeneral(writer, "Invalid Due Before Date")
+		}
+		filter.DueBefore = &dueBefore
+	}
+	if query.Get("updated_since") != "" {
+		updatedSince, err := time.Parse(time.RFC3339, query.Get("updated_since"))
+		if err != nil {
+			return handleTodoNotProperlyTransmittedGeneral(writer, "Invalid Updated Since Time")
+		}
+		filter.UpdatedSince = &updatedSince
+	}
+	todos := models.FilterTodos(models.AllTodos(), filter)
+
+	var near nearQuery
+	if query.Get("near") != "" {
+		var err error
+		near, err = parseNearQuery(query.Get("near"), query.Get("radius"))
+		if err != nil {
+			return handleTodoNotProperlyTransmittedGeneral(writer, "Invalid Near Query")
+		}
+		todos = near.filter(todos)
+	}
+
+	var sortedTodos []models.Todo
+	switch query.Get("sort") {
+	case "", "id":
+		sortedTodos = sortTodosAfterIdAscending(todos)
+	case "title":
+		sortedTodos = sortTodosAfterTitle(sortTodosAfterIdAscending(todos), titleCollator(request))
+	case "created_at":
+		sortedTodos = sortTodosAfterCreation(sortTodosAfterIdAscending(todos))
+	case "updated_at":
+		sortedTodos = sortTodosAfterUpdate(sortTodosAfterIdAscending(todos))
+	case "priority":
+		sortedTodos = sortTodosAfterPriority(sortTodosAfterIdAscending(todos))
+	case "distance":
+		if query.Get("near") == "" {
+			return handleTodoNotProperlyTransmittedGeneral(writer, "Sorting By Distance Needs A Near Query")
+		}
+		sortedTodos = near.sortTodosAfterDistance(sortTodosAfterIdAscending(todos))
+	default:
+		return handleTodoNotProperlyTransmittedGeneral(writer, "Invalid Sort Field")
+	}
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		sortedTodos = reverseTodos(sortedTodos)
+	default:
+		return handleTodoNotProperlyTransmittedGeneral(writer, "Order Must Be asc Or desc")
+	}
+
+	page, err := parsePageQuery(query)
 	if err != nil {
-		panic(err)
+		return handleTodoNotProperlyTransmittedGeneral(writer, "Invalid Page Query")
 	}
-}
 
-// TodosGet Handler for the todos get action
-// GET /todos
-func TodosGet(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
-	var todos []models.Todo
-	for _, todo := range models.TodoStore() {
-		todos = append(todos, todo)
+	writer.Header().Add("Vary", "Accept-Language")
+	if groupBy := query.Get("group_by"); groupBy != "" {
+		if page.page != 0 {
+			return handleTodoNotProperlyTransmittedGeneral(writer, "Groups Can Not Be Paginated")
+		}
+		groups, err := groupTodos(sortedTodos, groupBy)
+		if err != nil {
+			return handleTodoNotProperlyTransmittedGeneral(writer, "Invalid Group Field")
+		}
+
+		response := models.JsonExtendedResponse{
+			Meta: GroupsMeta{GroupBy: groupBy, Groups: len(groups), Total: len(sortedTodos), Revision: models.Revision()},
+			Data: groups,
+		}
+		writer.WriteHeader(http.StatusOK)
+		return json.NewEncoder(writer).Encode(response)
 	}
 
-	sortedTodos := sortTodosAfterIdAscending(todos)
-	response := models.JsonDataResponse{Data: sortedTodos}
+	pagedTodos, meta := page.apply(request, sortedTodos)
+	describedBy(writer, "todo.json")
+	response := models.JsonDataResponse{Meta: meta, Data: pagedTodos}
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// TodosRevisionGet Handler for the revision action, the revision of the store increases with every change.
+// Clients compare it with the revision of their last list to find out whether anything changed.
+// GET /todos/revision
+func TodosRevisionGet(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+	response := models.JsonExtendedResponse{Data: models.RevisionMeta{Revision: models.Revision()}}
 	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	writer.WriteHeader(http.StatusOK)
-	err := json.NewEncoder(writer).Encode(response)
-	if err != nil {
-		panic(err)
-	}
+	return json.NewEncoder(writer).Encode(response)
 }
 
 func sortTodosAfterIdAscending(todos []models.Todo) []models.Todo {
@@ -77,159 +499,339 @@ func sortTodosAfterIdAscending(todos []models.Todo) []models.Todo {
 	return todos
 }
 
-// TodoGetById Handler for a todo get by id action
-func TodoGetById(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+// TodoGetById Handler for a todo get by id action, outdoor todos with a location get a weather advisory of their due day in the meta information
+func TodoGetById(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) error {
 	// Get todo id from url parameters
 	id := params.ByName("id")
-	todo, ok := models.TodoStore()[id]
+	todo, ok := models.FindTodo(id)
 	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	describedBy(writer, "todo.json")
 	if ok == false {
-		handleTodoIdNotFound(writer)
-		return
-	}
-	response := models.JsonExtendedResponse{Data: todo}
-	err := json.NewEncoder(writer).Encode(response)
-	if err != nil {
-		panic(err)
+		return handleTodoIdNotFound(writer)
 	}
+	writer.Header().Set("ETag", todoEtag(todo))
+	response := models.JsonExtendedResponse{Meta: weatherMeta(todo), Data: todo}
+	return json.NewEncoder(writer).Encode(response)
 }
 
-func handleTodoIdNotFound(writer http.ResponseWriter) {
+func handleTodoIdNotFound(writer http.ResponseWriter) error {
 	// No todo with the id in the url parameters has been found
 	writer.WriteHeader(http.StatusNotFound)
-	response := models.JsonErrorResponse{Error: models.ApiError{Status: 404, Title: "Record Not Found"}}
-	err := json.NewEncoder(writer).Encode(response)
-	if err != nil {
-		panic(err)
-	}
+	response := models.JsonErrorResponse{Error: models.ApiError{Status: 404, Code: models.CodeTodoNotFound, Title: "Record Not Found"}}
+	return json.NewEncoder(writer).Encode(response)
 }
 
-// TodoPost Handler for the todos post action
-func TodoPost(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+// TodoPost Handler for the todos post action, suggest=true adds a normalized title to the meta information
+// POST /todos?suggest=true
+func TodoPost(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) error {
 	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	describedBy(writer, "todo.json")
+	body, err := readBody(request)
+	if err != nil {
+		return handleTodoNotProperlyTransmitted(writer)
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+		return postTodos(writer, body)
+	}
+
 	var todo models.Todo
-	err := decodeTodo(request, &todo)
+	err = decodeTodoJson(body, &todo, true)
+	if err != nil {
+		return handleInvalidBody(writer, err)
+	}
 
+	todo, err = plugins.BeforeWrite(plugins.ActionCreate, models.ApplyDefaults(todo))
 	if err != nil {
-		handleTodoNotProperlyTransmitted(writer)
-		return
+		return handleWriteHookError(writer, err)
 	}
 
 	todoAdded := models.AddTodo(todo)
+	syncTodo(todoAdded)
+	plugins.Emit(plugins.TodoCreated, todoAdded)
+
+	meta := models.CreationMeta{Revision: models.Revision()}
+	// clients asking for suggestions can offer to fix the title with a single tap
+	if models.ToBool(request.URL.Query().Get("suggest")) {
+		suggestedTitle := models.NormalizeTitle(todoAdded.Title)
+		if suggestedTitle != todoAdded.Title {
+			meta.SuggestedTitle = suggestedTitle
+		}
+	}
+	response := models.JsonExtendedResponse{Meta: meta, Data: todoAdded}
+	err = models.UpdateDataInFile()
+	if err != nil {
+		return err
+	}
 
-	response := models.JsonExtendedResponse{Data: todoAdded}
 	writer.WriteHeader(http.StatusCreated)
-	err = json.NewEncoder(writer).Encode(response)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// postTodos creates the todos of an array in their order, all of them or none if one is invalid or rejected
+// by a write hook. The store is saved once.
+func postTodos(writer http.ResponseWriter, body []byte) error {
+	var elements []json.RawMessage
+	err := json.Unmarshal(body, &elements)
 	if err != nil {
-		panic(err)
+		return handleTodoNotProperlyTransmitted(writer)
+	}
+	if len(elements) == 0 {
+		return writeError(writer, http.StatusUnprocessableEntity, models.CodeValidationFailed, "No Todos")
+	}
+	if len(elements) > maxBatchOperations {
+		return writeError(writer, http.StatusUnprocessableEntity, models.CodeTooManyItems, "Too Many Todos")
 	}
 
-	err = models.UpdateDataInFile()
+	todos := make([]models.Todo, len(elements))
+	var violations models.ValidationErrors
+	for i, element := range elements {
+		err = decodeTodoJson(element, &todos[i], true)
+		var invalid models.ValidationErrors
+		if err != nil && errors.As(err, &invalid) == false {
+			return handleTodoNotProperlyTransmitted(writer)
+		}
+		violations = append(violations, indexedViolations(i, invalid)...)
+	}
+	if len(violations) > 0 {
+		return handleInvalidBody(writer, violations)
+	}
+	for i := range todos {
+		todos[i], err = plugins.BeforeWrite(plugins.ActionCreate, models.ApplyDefaults(todos[i]))
+		if err != nil {
+			return handleWriteHookError(writer, err)
+		}
+	}
 
+	for i := range todos {
+		todos[i] = models.AddTodo(todos[i])
+		syncTodo(todos[i])
+		plugins.Emit(plugins.TodoCreated, todos[i])
+	}
+	response := models.JsonExtendedResponse{Meta: models.CreationMeta{Revision: models.Revision()}, Data: todos}
+	err = models.UpdateDataInFile()
 	if err != nil {
-		panic(err)
+		return err
 	}
+
+	writer.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// indexedViolations names the fields of the violations with the index of the todo, e.g. "2.title"
+func indexedViolations(index int, violations models.ValidationErrors) models.ValidationErrors {
+	var indexed models.ValidationErrors
+	for _, violation := range violations {
+		indexed = append(indexed, models.FieldError{Field: strconv.Itoa(index) + "." + violation.Field, Message: violation.Message})
+	}
+	return indexed
 }
 
-func handleTodoNotProperlyTransmitted(writer http.ResponseWriter) {
+func handleTodoNotProperlyTransmitted(writer http.ResponseWriter) error {
 	// todo was not properly transmitted
 	writer.WriteHeader(http.StatusBadRequest)
-	response := models.JsonErrorResponse{Error: models.ApiError{Status: 400, Title: "Invalid Body"}}
-	err := json.NewEncoder(writer).Encode(response)
+	response := models.JsonErrorResponse{Error: models.ApiError{Status: 400, Code: models.CodeInvalidBody, Title: "Invalid Body"}}
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// handleInvalidBody answers with the violated fields for bodies failing the validation and with
+// a plain bad request for bodies that are no JSON at all
+func handleInvalidBody(writer http.ResponseWriter, err error) error {
+	var violations models.ValidationErrors
+	if errors.As(err, &violations) == false {
+		return handleTodoNotProperlyTransmitted(writer)
+	}
+
+	writer.WriteHeader(http.StatusUnprocessableEntity)
+	response := models.JsonErrorResponse{Error: models.ApiError{Status: 422, Code: models.CodeValidationFailed, Title: "Validation Failed",
+		Details: violations}}
+	return json.NewEncoder(writer).Encode(response)
+}
+
+func handleWriteHookError(writer http.ResponseWriter, err error) error {
+	var rejected *plugins.RejectedError
+	if errors.As(err, &rejected) {
+		// todo was refused by a write hook
+		writer.WriteHeader(http.StatusUnprocessableEntity)
+		response := models.JsonErrorResponse{Error: models.ApiError{Status: 422, Code: models.CodeWriteRejected, Title: rejected.Reason}}
+		return json.NewEncoder(writer).Encode(response)
+	}
+
+	log.Println("Write hook failed:", err)
+	writer.WriteHeader(http.StatusInternalServerError)
+	response := models.JsonErrorResponse{Error: models.ApiError{Status: 500, Code: models.CodeInternalError, Title: "Write Hook Failed"}}
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// decodeTodo does decoding of the json request body into a Todo, see decodeTodoJson
+func decodeTodo(request *http.Request, todo *models.Todo, creating bool) error {
+	body, err := readBody(request)
 	if err != nil {
-		panic(err)
+		return err
 	}
+	return decodeTodoJson(body, todo, creating)
 }
 
-// decodeTodo does decoding of the json request body into a Todo
-func decodeTodo(request *http.Request, todo *models.Todo) error {
-	if request.Body == nil {
-		return errors.New("invalid body")
+// decodeTodoJson does decoding of a json todo into a Todo, violations are returned as
+// models.ValidationErrors. On creating the id must be left to the backend
+func decodeTodoJson(body []byte, todo *models.Todo, creating bool) error {
+	err := validateJson("todo.json", body)
+	var violations models.ValidationErrors
+	if err != nil && errors.As(err, &violations) == false {
+		return err
+	}
+	if creating {
+		var given struct {
+			Id interface{} `json:"id"`
+		}
+		if json.Unmarshal(body, &given) == nil && given.Id != nil {
+			violations = append(models.ValidationErrors{{Field: "id", Message: "must not be set, ids are assigned by the backend"}}, violations...)
+		}
+	}
+	if err != nil {
+		// the schema violations are reported without decoding, the values may not fit into a Todo
+		return violations
 	}
-	err := json.NewDecoder(request.Body).Decode(todo)
+	err = json.Unmarshal(body, todo)
 	if err != nil {
 		return err
 	}
+	clearMaintainedFields(todo)
+	var invalid models.ValidationErrors
+	if errors.As(todo.Validate(), &invalid) {
+		violations = append(violations, invalid...)
+	}
+	if len(violations) > 0 {
+		return violations
+	}
 	return nil
 }
 
+// clearMaintainedFields clears the fields the backend maintains, the values given by clients are ignored
+func clearMaintainedFields(todo *models.Todo) {
+	// The issue reference, the auto-assigned tags, the number, the position, the habit start and days, the
+	// waiting start, the last nudge, the creation and update times, the checklist, the owner and the deletion
+	// time are maintained by the backend only
+	todo.ExternalRef = ""
+	todo.AutoTags = nil
+	todo.Number = 0
+	todo.Position = 0
+	todo.HabitSince = ""
+	todo.HabitDays = nil
+	todo.WaitingSince = nil
+	todo.NudgedAt = nil
+	todo.CreatedAt = nil
+	todo.UpdatedAt = nil
+	todo.Items = nil
+	todo.Owner = ""
+	todo.DeletedAt = nil
+}
+
 // TodoPut Handler for a todo put by id action
-func TodoPut(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+func TodoPut(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
 	// Get todo id from url parameters
 	id := params.ByName("id")
-	_, ok := models.TodoStore()[id]
+	_, ok := models.FindTodo(id)
 	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	describedBy(writer, "todo.json")
 	if ok == false {
-		handleTodoIdNotFound(writer)
-		return
+		return handleTodoIdNotFound(writer)
 	}
 
 	var todoReceived models.Todo
-	err := decodeTodo(request, &todoReceived)
+	err := decodeTodo(request, &todoReceived, false)
 	if err != nil {
-		handleTodoNotProperlyTransmitted(writer)
-		return
+		return handleInvalidBody(writer, err)
+	}
+
+	todoReceived.Id = id
+	todoReceived, err = plugins.BeforeWrite(plugins.ActionUpdate, todoReceived)
+	if err != nil {
+		return handleWriteHookError(writer, err)
 	}
 
 	todoUpdated, ok := models.UpdateTodo(id, todoReceived)
 
 	if ok == false {
-		handleTodoNotProperlyTransmittedGeneral(writer, "Update data model failed")
-		return
-	}
-
-	response := models.JsonExtendedResponse{Data: todoUpdated}
-	writer.WriteHeader(http.StatusOK)
-	err = json.NewEncoder(writer).Encode(response)
-	if err != nil {
-		panic(err)
+		return handleTodoNotProperlyTransmittedGeneral(writer, "Update data model failed")
 	}
+	syncTodo(todoUpdated)
+	plugins.Emit(plugins.TodoUpdated, todoUpdated)
 
+	response := models.JsonExtendedResponse{Meta: models.RevisionMeta{Revision: models.Revision()}, Data: todoUpdated}
 	err = models.UpdateDataInFile()
 	if err != nil {
-		panic(err)
+		return err
 	}
+
+	writer.Header().Set("ETag", todoEtag(todoUpdated))
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
 }
 
-func handleTodoNotProperlyTransmittedGeneral(writer http.ResponseWriter, title string) {
+func handleTodoNotProperlyTransmittedGeneral(writer http.ResponseWriter, title string) error {
 	// todo was not properly transmitted
 	writer.WriteHeader(http.StatusBadRequest)
-	response := models.JsonErrorResponse{Error: models.ApiError{Status: 400, Title: title}}
-	err := json.NewEncoder(writer).Encode(response)
-	if err != nil {
-		panic(err)
-	}
+	response := models.JsonErrorResponse{Error: models.ApiError{Status: 400, Code: models.CodeInvalidRequest, Title: title}}
+	return json.NewEncoder(writer).Encode(response)
 }
 
 // TodoDelete Handler for a todo delete by id action
-func TodoDelete(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+func TodoDelete(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) error {
 	// Get todo id from url parameters
 	id := params.ByName("id")
-	_, ok := models.TodoStore()[id]
+	todo, ok := models.FindTodo(id)
 	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	if ok == false {
-		handleTodoIdNotFound(writer)
-		return
+		return handleTodoIdNotFound(writer)
 	}
 
 	models.RemoveTodo(id)
-
-	writer.WriteHeader(http.StatusOK)
+	syncRemovedTodos(todo)
+	plugins.Emit(plugins.TodoDeleted, todo)
 
 	err := models.UpdateDataInFile()
 	if err != nil {
-		panic(err)
+		return err
 	}
+
+	writer.WriteHeader(http.StatusOK)
+	return nil
 }
 
-// DeleteAllTodos Handler for deleting all todo's
-func DeleteAllTodos(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+// removeAllTodos removes all todos after writing them to a snapshot, it returns the snapshot and the removed todos
+func removeAllTodos() (models.Snapshot, []models.Todo, error) {
+	snapshot, err := models.TakeSnapshot()
+	if err != nil {
+		return models.Snapshot{}, nil, err
+	}
+
+	var todos []models.Todo
+	for _, todo := range models.AllTodos() {
+		todos = append(todos, todo)
+	}
+
 	models.DeleteAllTodos()
-	err := models.UpdateDataInFile()
+	syncRemovedTodos(todos...)
+	for _, todo := range todos {
+		plugins.Emit(plugins.TodoDeleted, todo)
+	}
+	return snapshot, todos, nil
+}
 
+// DeleteAllTodos Handler for deleting all todo's, they are saved to a snapshot first that can be restored
+// DELETE /todos
+func DeleteAllTodos(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) error {
+	snapshot, _, err := removeAllTodos()
 	if err != nil {
-		panic(err)
+		return err
+	}
+	err = models.UpdateDataInFile()
+	if err != nil {
+		return err
 	}
 
+	response := models.JsonExtendedResponse{Data: snapshot}
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
 }
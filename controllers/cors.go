@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"todo-rest-backend/models"
+)
+
+// CorsConfig are the cross-origin requests browsers may send to the API. Origins are like
+// https://todo.example.org, "*" allows every origin.
+type CorsConfig struct {
+	Origins []string
+	Methods []string
+	Headers []string
+	MaxAge  time.Duration
+}
+
+// corsConfig is nil unless cross-origin requests are allowed
+var corsConfig *CorsConfig
+
+var defaultCorsMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+var defaultCorsHeaders = []string{"Content-Type", "Authorization", "X-Api-Key", "If-None-Match", "If-Match", requestIdHeader, denylistOverrideHeader}
+
+// corsExposedHeaders are the response headers the scripts of other origins may read
+var corsExposedHeaders = []string{"ETag", "Location", requestIdHeader, "Deprecation", "Sunset", "Link"}
+
+// configureCors allows cross-origin requests from the comma separated origins of TODO_CORS_ORIGINS.
+// TODO_CORS_METHODS and TODO_CORS_HEADERS replace the allowed methods and request headers,
+// TODO_CORS_MAX_AGE (default 10m) is how long browsers may cache a preflight.
+func configureCors() error {
+	corsConfig = nil
+	origins := commaSeparated(os.Getenv("TODO_CORS_ORIGINS"))
+	if len(origins) == 0 {
+		return nil
+	}
+	for _, origin := range origins {
+		if origin != "*" && strings.Contains(origin, "://") == false {
+			return errors.New("TODO_CORS_ORIGINS must be origins like https://todo.example.org or *")
+		}
+	}
+
+	config := CorsConfig{Origins: origins, Methods: defaultCorsMethods, Headers: defaultCorsHeaders, MaxAge: 10 * time.Minute}
+	if methods := commaSeparated(os.Getenv("TODO_CORS_METHODS")); len(methods) > 0 {
+		config.Methods = nil
+		for _, method := range methods {
+			config.Methods = append(config.Methods, strings.ToUpper(method))
+		}
+	}
+	if headers := commaSeparated(os.Getenv("TODO_CORS_HEADERS")); len(headers) > 0 {
+		config.Headers = headers
+	}
+	if maxAge := os.Getenv("TODO_CORS_MAX_AGE"); maxAge != "" {
+		var err error
+		config.MaxAge, err = time.ParseDuration(maxAge)
+		if err != nil || config.MaxAge < 0 {
+			return errors.New("TODO_CORS_MAX_AGE must be a duration like 10m")
+		}
+	}
+
+	log.Printf("Cross-origin requests allowed: %+v", config)
+	corsConfig = &config
+	return nil
+}
+
+// commaSeparated splits a list like "a, b" into its trimmed, non-empty values
+func commaSeparated(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// allowsOrigin reports whether requests from the origin are allowed
+func (c *CorsConfig) allowsOrigin(origin string) bool {
+	for _, allowed := range c.Origins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsPreflight reports whether the method and all headers of a preflight request are allowed
+func (c *CorsConfig) allowsPreflight(method string, headers string) bool {
+	allowed := false
+	for _, m := range c.Methods {
+		allowed = allowed || m == method
+	}
+	if allowed == false {
+		return false
+	}
+	for _, header := range commaSeparated(headers) {
+		allowed = false
+		for _, h := range c.Headers {
+			allowed = allowed || strings.EqualFold(h, header)
+		}
+		if allowed == false {
+			return false
+		}
+	}
+	return true
+}
+
+// cors adds the CORS headers to the responses for allowed origins and answers their preflight requests
+// itself, so that they are handled the same for every route and router. Requests of other origins are
+// served without CORS headers, the browser then hides the response from the script.
+func cors(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		config := corsConfig
+		origin := request.Header.Get("Origin")
+		if config == nil || origin == "" {
+			handler.ServeHTTP(writer, request)
+			return
+		}
+
+		writer.Header().Add("Vary", "Origin")
+		preflight := request.Method == http.MethodOptions && request.Header.Get("Access-Control-Request-Method") != ""
+		if preflight {
+			writer.Header().Add("Vary", "Access-Control-Request-Method")
+			writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		}
+		if config.allowsOrigin(origin) == false {
+			if preflight {
+				logFailure(request, writeError(writer, http.StatusForbidden, models.CodeForbidden, "Origin Not Allowed"))
+				return
+			}
+			handler.ServeHTTP(writer, request)
+			return
+		}
+
+		writer.Header().Set("Access-Control-Allow-Origin", origin)
+		if preflight == false {
+			writer.Header().Set("Access-Control-Expose-Headers", strings.Join(corsExposedHeaders, ", "))
+			handler.ServeHTTP(writer, request)
+			return
+		}
+
+		method := request.Header.Get("Access-Control-Request-Method")
+		if config.allowsPreflight(method, request.Header.Get("Access-Control-Request-Headers")) == false {
+			logFailure(request, writeError(writer, http.StatusForbidden, models.CodeForbidden, "Method Or Headers Not Allowed"))
+			return
+		}
+		writer.Header().Set("Access-Control-Allow-Methods", strings.Join(config.Methods, ", "))
+		writer.Header().Set("Access-Control-Allow-Headers", strings.Join(config.Headers, ", "))
+		writer.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge.Seconds())))
+		writer.WriteHeader(http.StatusNoContent)
+	})
+}
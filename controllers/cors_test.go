@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCors_AnswersPreflightRequests(t *testing.T) {
+	// Arrange
+	//
+	defer func() { corsConfig = nil }()
+	corsConfig = &CorsConfig{Origins: []string{"https://todo.example.org"}, Methods: defaultCorsMethods, Headers: defaultCorsHeaders, MaxAge: time.Minute}
+	handler := cors(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	preflight := func(origin, method, headers string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodOptions, "/todos/1", nil)
+		request.Header.Set("Origin", origin)
+		request.Header.Set("Access-Control-Request-Method", method)
+		request.Header.Set("Access-Control-Request-Headers", headers)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// Act
+	//
+	allowed := preflight("https://todo.example.org", http.MethodPut, "content-type, x-request-id")
+	otherOrigin := preflight("https://evil.example.org", http.MethodPut, "")
+	otherHeader := preflight("https://todo.example.org", http.MethodPut, "X-Custom")
+
+	// Assert
+	//
+	if allowed.Code != http.StatusNoContent || allowed.Header().Get("Access-Control-Allow-Origin") != "https://todo.example.org" ||
+		allowed.Header().Get("Access-Control-Max-Age") != "60" {
+		t.Error("Fehler", allowed.Code, allowed.Header())
+	}
+	if otherOrigin.Code != http.StatusForbidden || otherOrigin.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Error("Fehler", otherOrigin.Code, otherOrigin.Header())
+	}
+	if otherHeader.Code != http.StatusForbidden {
+		t.Error("Fehler", otherHeader.Code)
+	}
+}
+
+func TestCors_AddsHeadersForAllowedOrigins(t *testing.T) {
+	// Arrange
+	//
+	defer func() { corsConfig = nil }()
+	corsConfig = &CorsConfig{Origins: []string{"*"}, Methods: defaultCorsMethods, Headers: defaultCorsHeaders}
+	handler := cors(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	request := httptest.NewRequest(http.MethodGet, "/todos", nil)
+	request.Header.Set("Origin", "http://localhost:3000")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	//
+	handler.ServeHTTP(recorder, request)
+
+	// Assert
+	//
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Access-Control-Allow-Origin") != "http://localhost:3000" ||
+		recorder.Header().Get("Access-Control-Expose-Headers") == "" || recorder.Header().Get("Vary") != "Origin" {
+		t.Error("Fehler", recorder.Code, recorder.Header())
+	}
+}
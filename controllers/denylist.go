@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"todo-rest-backend/models"
+	"todo-rest-backend/plugins"
+)
+
+// denylistOverrideHeader set to "true" lets an admin store a todo the denylist would reject, see SetAdmins
+const denylistOverrideHeader = "X-Denylist-Override"
+
+// denylist holds the rules of the file named by TODO_DENYLIST_FILE
+var denylist = &plugins.Denylist{}
+
+// DenylistGet Handler for the denylist action, it lists the rules keeping sensitive content out of the todos
+// GET /admin/denylist
+func DenylistGet(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+	ruleList := denylist.Rules
+	if ruleList == nil {
+		ruleList = []plugins.DenylistRule{}
+	}
+	response := models.JsonExtendedResponse{Data: ruleList}
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
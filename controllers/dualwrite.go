@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"todo-rest-backend/models"
+)
+
+// DualWriteGet Handler for the consistency report of the dual-write mode, it compares the old store with the new one
+// GET /admin/dual-write
+func DualWriteGet(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	report, err := models.CheckDualWrite()
+	if errors.Is(err, models.ErrNoDualWrite) {
+		return writeError(writer, http.StatusNotFound, models.CodeNotFound, "Dual Write Not Enabled")
+	}
+	if err != nil {
+		return err
+	}
+
+	response := models.JsonExtendedResponse{Data: report}
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
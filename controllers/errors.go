@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"todo-rest-backend/models"
+)
+
+// Handle is a handler of the todo API. An error it returns is logged and answered with
+// 500 Internal Server Error, like a panic. Handlers write their responses for expected
+// failures like invalid input themselves and return nil.
+type Handle func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error
+
+// internalError logs the failure of a request and answers with 500 unless the response was already started,
+// then the client gets the response as far as it was written
+func internalError(writer *statusWriter, request *http.Request, failure interface{}) {
+	logger.LogAttrs(request.Context(), slog.LevelError, "request failed",
+		slog.String("method", request.Method),
+		slog.String("path", request.URL.Path),
+		slog.String("request_id", writer.Header().Get(requestIdHeader)),
+		slog.String("error", fmt.Sprint(failure)),
+	)
+	if writer.status != 0 {
+		return
+	}
+	logFailure(request, writeError(writer, http.StatusInternalServerError, models.CodeInternalError, "Internal Server Error"))
+}
+
+// recoverPanic turns a panicking handler into a 500 response, it must be deferred.
+// http.ErrAbortHandler still aborts the response.
+func recoverPanic(writer *statusWriter, request *http.Request) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	if recovered == http.ErrAbortHandler {
+		panic(recovered)
+	}
+	logger.Error("handler panicked", slog.String("stack", string(debug.Stack())))
+	internalError(writer, request, recovered)
+}
+
+// logFailure logs the error of writing a response outside of a Handle, e.g. in a middleware
+func logFailure(request *http.Request, err error) {
+	if err == nil {
+		return
+	}
+	path := ""
+	if request != nil {
+		path = request.URL.Path
+	}
+	logger.Error("cannot write the response", slog.String("path", path), slog.String("error", err.Error()))
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"todo-rest-backend/models"
+	"todo-rest-backend/schemas"
+)
+
+func TestRegisterRoute_FailuresAreInternalServerErrors(t *testing.T) {
+	handles := map[string]Handle{
+		"/error": func(http.ResponseWriter, *http.Request, httprouter.Params) error {
+			return errors.New("disk full")
+		},
+		"/panic": func(http.ResponseWriter, *http.Request, httprouter.Params) error {
+			panic("disk full")
+		},
+	}
+	for path, handle := range handles {
+		// Arrange
+		//
+		var output bytes.Buffer
+		defer SetLogger(logger)
+		SetLogger(slog.New(slog.NewJSONHandler(&output, nil)))
+		router := httprouter.New()
+		registerRoute(HttpRouter{Router: router}, route{http.MethodGet, path, handle})
+		recorder := httptest.NewRecorder()
+
+		// Act
+		//
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+		// Assert
+		//
+		if recorder.Code != http.StatusInternalServerError || strings.Contains(recorder.Body.String(), "Internal Server Error") == false {
+			t.Errorf("%s: got %d %s", path, recorder.Code, recorder.Body.String())
+		}
+		if strings.Contains(output.String(), "disk full") == false {
+			t.Errorf("%s: the failure was not logged: %s", path, output.String())
+		}
+	}
+}
+
+func TestRegisterRoute_StartedResponseIsKept(t *testing.T) {
+	// Arrange
+	//
+	defer SetLogger(logger)
+	SetLogger(slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil)))
+	router := httprouter.New()
+	registerRoute(HttpRouter{Router: router}, route{http.MethodGet, "/started", func(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+		writer.WriteHeader(http.StatusOK)
+		return errors.New("connection reset")
+	}})
+	recorder := httptest.NewRecorder()
+
+	// Act
+	//
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/started", nil))
+
+	// Assert
+	//
+	if recorder.Code != http.StatusOK || recorder.Body.Len() != 0 {
+		t.Error("Fehler", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestWriteError_CodesAreThoseOfTheSchema(t *testing.T) {
+	// Arrange
+	//
+	content, _ := schemas.Get("error.json")
+	var schema struct {
+		Properties struct {
+			Error struct {
+				Properties struct {
+					Code struct {
+						Enum []models.ErrorCode `json:"enum"`
+					} `json:"code"`
+				} `json:"properties"`
+			} `json:"error"`
+		} `json:"properties"`
+	}
+	json.Unmarshal(content, &schema)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	//
+	writeError(recorder, http.StatusConflict, models.CodeStateConflict, "Pomodoro Already Running")
+
+	// Assert
+	//
+	if reflect.DeepEqual(schema.Properties.Error.Properties.Code.Enum, models.ErrorCodes) == false {
+		t.Error("Fehler", schema.Properties.Error.Properties.Code.Enum)
+	}
+	if err := schemas.Validate("error.json", recorder.Body.Bytes()); err != nil ||
+		strings.Contains(recorder.Body.String(), `"code":"STATE_CONFLICT"`) == false {
+		t.Error("Fehler", err, recorder.Body.String())
+	}
+}
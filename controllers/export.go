@@ -0,0 +1,247 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"io"
+	"net/http"
+	"time"
+	"todo-rest-backend/models"
+	"todo-rest-backend/plugins"
+)
+
+// Import modes
+const (
+	ImportMerge   = "merge"
+	ImportReplace = "replace"
+)
+
+// ImportMeta describes an import, Snapshot keeps the todos a replacing import removed
+type ImportMeta struct {
+	Revision uint64           `json:"revision"`
+	Created  int              `json:"created"`
+	Updated  int              `json:"updated"`
+	Removed  int              `json:"removed"`
+	Snapshot *models.Snapshot `json:"snapshot,omitempty"`
+}
+
+// TodosExport Handler for the todos export action, the todos are streamed as a file to download
+// GET /todos/export?format=todotxt|org|json|csv
+func TodosExport(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) error {
+	var todos []models.Todo
+	for _, todo := range models.AllTodos() {
+		todos = append(todos, todo)
+	}
+	todos = sortTodosAfterIdAscending(todos)
+
+	switch request.URL.Query().Get("format") {
+	case "todotxt":
+		writeAttachmentHeaders(writer, "text/plain; charset=UTF-8", "todo.txt")
+		return models.WriteTodoTxt(writer, todos)
+	case "org":
+		writeAttachmentHeaders(writer, "text/plain; charset=UTF-8", "todos.org")
+		return models.WriteOrg(writer, todos)
+	case "json":
+		writeAttachmentHeaders(writer, "application/json; charset=UTF-8", "todos.json")
+		return writeJsonExport(writer, todos)
+	case "csv":
+		writeAttachmentHeaders(writer, "text/csv; charset=UTF-8", "todos.csv")
+		return models.WriteCsvExport(writer, todos)
+	}
+	return handleTodoNotProperlyTransmittedGeneral(writer, "Unsupported Export Format")
+}
+
+func writeAttachmentHeaders(writer http.ResponseWriter, contentType string, fileName string) {
+	writer.Header().Set("Content-Type", contentType)
+	writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	writer.WriteHeader(http.StatusOK)
+}
+
+// writeJsonExport writes the todos as JSON array, one todo per line
+func writeJsonExport(writer io.Writer, todos []models.Todo) error {
+	separator := "[\n"
+	for _, todo := range todos {
+		encoded, err := json.Marshal(todo)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(writer, separator+string(encoded))
+		if err != nil {
+			return err
+		}
+		separator = ",\n"
+	}
+	if separator == "[\n" {
+		_, err := io.WriteString(writer, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(writer, "\n]\n")
+	return err
+}
+
+// readJsonImport reads an array of todos written by writeJsonExport, the violations are named with the index
+// of the todo. The ids, the creation times and the checklists of the export are kept.
+func readJsonImport(reader io.Reader) ([]models.Todo, models.ValidationErrors, error) {
+	var elements []json.RawMessage
+	err := json.NewDecoder(reader).Decode(&elements)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	todos := make([]models.Todo, len(elements))
+	var violations models.ValidationErrors
+	for i, element := range elements {
+		err = decodeTodoJson(element, &todos[i], false)
+		var invalid models.ValidationErrors
+		if err != nil && errors.As(err, &invalid) == false {
+			return nil, nil, err
+		}
+		violations = append(violations, indexedViolations(i, invalid)...)
+		var kept struct {
+			CreatedAt *time.Time             `json:"created_at"`
+			Items     []models.ChecklistItem `json:"items"`
+		}
+		if json.Unmarshal(element, &kept) == nil {
+			todos[i].CreatedAt, todos[i].Items = kept.CreatedAt, kept.Items
+		}
+	}
+	return todos, violations, nil
+}
+
+// readCsvImport reads todos written by models.WriteCsvExport, the violations are named with the index of the
+// todo. The ids, the creation times and the checklists of the export are kept.
+func readCsvImport(reader io.Reader) ([]models.Todo, models.ValidationErrors, error) {
+	todos, err := models.ReadCsvExport(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var violations models.ValidationErrors
+	for i := range todos {
+		createdAt, items := todos[i].CreatedAt, todos[i].Items
+		clearMaintainedFields(&todos[i])
+		todos[i].CreatedAt, todos[i].Items = createdAt, items
+		var invalid models.ValidationErrors
+		if errors.As(todos[i].Validate(), &invalid) {
+			violations = append(violations, indexedViolations(i, invalid)...)
+		}
+	}
+	return todos, violations, nil
+}
+
+// readOrgImport reads todos written by models.WriteOrg, the violations are named with the index of the todo.
+// The ids of the export are kept.
+func readOrgImport(reader io.Reader) ([]models.Todo, models.ValidationErrors, error) {
+	todos, err := models.ReadOrg(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var violations models.ValidationErrors
+	for i := range todos {
+		var invalid models.ValidationErrors
+		if errors.As(todos[i].Validate(), &invalid) {
+			violations = append(violations, indexedViolations(i, invalid)...)
+		}
+	}
+	return todos, violations, nil
+}
+
+// TodosImport Handler for the todos import action. The todos are added with new ids, ids of removed todos are
+// not reused. With mode=merge (default) the todos of an export whose id exists are updated instead.
+// mode=replace removes all todos first, they are kept in a snapshot like on DELETE /todos.
+// Nothing is changed if one of the todos is invalid or rejected.
+// POST /todos/import?format=todotxt|org|json|csv&mode=merge|replace
+func TodosImport(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	if request.Body == nil {
+		return handleTodoNotProperlyTransmitted(writer)
+	}
+	mode := request.URL.Query().Get("mode")
+	if mode == "" {
+		mode = ImportMerge
+	}
+	if mode != ImportMerge && mode != ImportReplace {
+		return handleTodoNotProperlyTransmittedGeneral(writer, "Unsupported Import Mode")
+	}
+
+	var todos []models.Todo
+	var violations models.ValidationErrors
+	var err error
+	switch request.URL.Query().Get("format") {
+	case "todotxt":
+		todos, err = models.ReadTodoTxt(request.Body)
+	case "org":
+		todos, violations, err = readOrgImport(request.Body)
+	case "json":
+		todos, violations, err = readJsonImport(request.Body)
+	case "csv":
+		todos, violations, err = readCsvImport(request.Body)
+	default:
+		return handleTodoNotProperlyTransmittedGeneral(writer, "Unsupported Import Format")
+	}
+	if err != nil {
+		return handleTodoNotProperlyTransmitted(writer)
+	}
+	if strictCoercion {
+		for i, todo := range todos {
+			if todo.Id != "" && isId(todo.Id) == false {
+				violations = append(violations, indexedViolations(i, models.ValidationErrors{{Field: "id", Message: "must be an id like 42"}})...)
+			}
+		}
+	}
+	if len(violations) > 0 {
+		return handleInvalidBody(writer, violations)
+	}
+
+	// All todos pass the write hooks before the first one is changed
+	updating := make([]bool, len(todos))
+	for i := range todos {
+		action := plugins.ActionCreate
+		if _, ok := models.FindTodo(todos[i].Id); ok && mode == ImportMerge {
+			action = plugins.ActionUpdate
+			updating[i] = true
+		}
+		todos[i], err = plugins.BeforeWrite(action, todos[i])
+		if err != nil {
+			return handleWriteHookError(writer, err)
+		}
+	}
+
+	meta := ImportMeta{}
+	if mode == ImportReplace {
+		snapshot, removed, err := removeAllTodos()
+		if err != nil {
+			return err
+		}
+		meta.Snapshot, meta.Removed = &snapshot, len(removed)
+	}
+	todosImported := []models.Todo{}
+	for i, todo := range todos {
+		if updating[i] {
+			todoUpdated, _ := models.UpdateTodo(todo.Id, todo)
+			syncTodo(todoUpdated)
+			plugins.Emit(plugins.TodoUpdated, todoUpdated)
+			todosImported = append(todosImported, todoUpdated)
+			meta.Updated++
+			continue
+		}
+		todoAdded := models.ImportTodo(todo)
+		syncTodo(todoAdded)
+		plugins.Emit(plugins.TodoCreated, todoAdded)
+		todosImported = append(todosImported, todoAdded)
+		meta.Created++
+	}
+
+	meta.Revision = models.Revision()
+	response := models.JsonDataResponse{Meta: meta, Data: todosImported}
+	err = models.UpdateDataInFile()
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(writer).Encode(response)
+}
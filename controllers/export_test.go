@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"todo-rest-backend/models"
+)
+
+func TestRegisterRoutes_ImportsExportedTodos(t *testing.T) {
+	// Arrange
+	//
+	// replacing the todos takes a snapshot, it is written to a temporary data directory
+	models.SetDataDir(t.TempDir())
+	defer models.SetDataDir(".")
+	kept := models.AddTodo(models.Todo{Title: "Renew the passport", Tags: []string{"errands"}})
+	kept, _ = models.AddItem(kept.Id, models.ChecklistItem{Title: "Take photos"})
+	renamed := models.AddTodo(models.Todo{Title: "Call the plumber"})
+	csvExport := serveRoute(t, http.MethodGet, "/todos/export?format=csv", "")
+	jsonExport := serveRoute(t, http.MethodGet, "/todos/export?format=json", "")
+	models.UpdateTodo(renamed.Id, models.Todo{Title: "Call the electrician", Tags: []string{}})
+
+	// Act
+	//
+	var merged, replaced models.JsonExtendedResponse
+	merge := serveRoute(t, http.MethodPost, "/todos/import?format=csv", csvExport.Body.String())
+	json.NewDecoder(merge.Body).Decode(&merged)
+	mergedTodo, _ := models.FindTodo(renamed.Id)
+	replace := serveRoute(t, http.MethodPost, "/todos/import?format=json&mode=replace", jsonExport.Body.String())
+	json.NewDecoder(replace.Body).Decode(&replaced)
+	invalid := serveRoute(t, http.MethodPost, "/todos/import?format=json", `[{"title": "Fine"}, {"title": ""}]`)
+	unsupported := serveRoute(t, http.MethodPost, "/todos/import?format=csv&mode=append", csvExport.Body.String())
+
+	// Assert
+	//
+	if csvExport.Header().Get("Content-Disposition") != `attachment; filename="todos.csv"` ||
+		strings.HasPrefix(csvExport.Body.String(), "id,title,") == false {
+		t.Error("Fehler", csvExport.Header(), csvExport.Body.String())
+	}
+	mergedMeta, _ := merged.Meta.(map[string]interface{})
+	if merge.Code != http.StatusCreated || mergedMeta["created"] != 0.0 || mergedMeta["updated"] == 0.0 ||
+		mergedTodo.Title != "Call the plumber" {
+		t.Error("Fehler", merge.Code, merged.Meta, mergedTodo)
+	}
+	replacedMeta, _ := replaced.Meta.(map[string]interface{})
+	var restored models.Todo
+	for _, todo := range models.AllTodos() {
+		if todo.Title == kept.Title {
+			restored = todo
+		}
+	}
+	if replace.Code != http.StatusCreated || replacedMeta["removed"] == 0.0 || replacedMeta["snapshot"] == nil ||
+		len(models.AllTodos()) != int(replacedMeta["created"].(float64)) {
+		t.Error("Fehler", replace.Code, replaced.Meta)
+	}
+	if restored.Title != kept.Title || len(restored.Items) != 1 || restored.CreatedAt.Equal(*kept.CreatedAt) == false {
+		t.Error("Fehler", restored)
+	}
+	if invalid.Code != http.StatusUnprocessableEntity || strings.Contains(invalid.Body.String(), `"1.title"`) == false {
+		t.Error("Fehler", invalid.Code, invalid.Body.String())
+	}
+	if unsupported.Code != http.StatusBadRequest {
+		t.Error("Fehler", unsupported.Code)
+	}
+}
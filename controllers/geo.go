@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"todo-rest-backend/models"
+)
+
+// defaultRadius is the radius in meters of near queries without radius
+const defaultRadius = 1000.0
+
+// nearQuery selects the todos with coordinates within radius meters of a point
+type nearQuery struct {
+	latitude  float64
+	longitude float64
+	radius    float64
+}
+
+// parseNearQuery reads near=lat,lon and radius in meters
+func parseNearQuery(near string, radius string) (nearQuery, error) {
+	parts := strings.Split(near, ",")
+	if len(parts) != 2 {
+		return nearQuery{}, errors.New("near must be lat,lon")
+	}
+
+	latitude, latErr := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	longitude, lonErr := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	location := &models.Location{Latitude: &latitude, Longitude: &longitude}
+	if latErr != nil || lonErr != nil || location.Validate() != nil {
+		return nearQuery{}, errors.New("near must be lat,lon")
+	}
+
+	query := nearQuery{latitude: latitude, longitude: longitude, radius: defaultRadius}
+	if radius != "" {
+		var err error
+		query.radius, err = strconv.ParseFloat(radius, 64)
+		if err != nil || query.radius <= 0 {
+			return nearQuery{}, errors.New("radius must be a positive number of meters")
+		}
+	}
+	return query, nil
+}
+
+// filter returns the todos within the radius, todos without coordinates are left out
+func (q nearQuery) filter(todos []models.Todo) []models.Todo {
+	var near []models.Todo
+	for _, todo := range todos {
+		if todo.Location.HasCoordinates() && todo.Location.DistanceTo(q.latitude, q.longitude) <= q.radius {
+			near = append(near, todo)
+		}
+	}
+	return near
+}
+
+// sortTodosAfterDistance sorts the todos by their distance to the point of the query, the nearest first
+func (q nearQuery) sortTodosAfterDistance(todos []models.Todo) []models.Todo {
+	sort.SliceStable(todos, func(i, j int) bool {
+		return todos[i].Location.DistanceTo(q.latitude, q.longitude) < todos[j].Location.DistanceTo(q.latitude, q.longitude)
+	})
+	return todos
+}
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"todo-rest-backend/models"
+)
+
+// GoalWithProgress is a goal with the progress of its linked todos
+type GoalWithProgress struct {
+	models.Goal
+	Progress models.GoalProgress `json:"progress"`
+}
+
+func withProgress(goal models.Goal) GoalWithProgress {
+	return GoalWithProgress{Goal: goal, Progress: models.ProgressOf(goal.Id)}
+}
+
+// decodeGoal does decoding of the json request body into a Goal
+func decodeGoal(request *http.Request, goal *models.Goal) error {
+	body, err := validatedBody(request, "goal.json")
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(body, goal)
+	if err != nil {
+		return err
+	}
+	return goal.Validate()
+}
+
+func handleGoalIdNotFound(writer http.ResponseWriter) error {
+	return writeError(writer, http.StatusNotFound, models.CodeNotFound, "Goal Not Found")
+}
+
+// GoalsGet Handler for the goals get action
+// GET /goals
+func GoalsGet(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+	goals := []GoalWithProgress{}
+	for _, goal := range models.Goals() {
+		goals = append(goals, withProgress(goal))
+	}
+
+	response := models.JsonExtendedResponse{Data: goals}
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	describedBy(writer, "goal.json")
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// GoalGetById Handler for a goal get by id action
+// GET /goals/:id
+func GoalGetById(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	describedBy(writer, "goal.json")
+	goal, ok := models.FindGoal(params.ByName("id"))
+	if ok == false {
+		return handleGoalIdNotFound(writer)
+	}
+
+	response := models.JsonExtendedResponse{Data: withProgress(goal)}
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// GoalTodosGet Handler for the todos linked to a goal action
+// GET /goals/:id/todos
+func GoalTodosGet(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	id := params.ByName("id")
+	if _, ok := models.FindGoal(id); ok == false {
+		return handleGoalIdNotFound(writer)
+	}
+
+	response := models.JsonDataResponse{
+		Meta: &models.RevisionMeta{Revision: models.Revision()},
+		Data: sortTodosAfterIdAscending(models.GoalTodos(id)),
+	}
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// GoalPost Handler for the goals post action
+// POST /goals
+func GoalPost(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	describedBy(writer, "goal.json")
+	var goal models.Goal
+	err := decodeGoal(request, &goal)
+	if err != nil {
+		return handleInvalidBody(writer, err)
+	}
+
+	response := models.JsonExtendedResponse{Data: withProgress(models.AddGoal(goal))}
+	err = models.UpdateDataInFile()
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// GoalPut Handler for a goal put by id action
+// PUT /goals/:id
+func GoalPut(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	describedBy(writer, "goal.json")
+	id := params.ByName("id")
+	if _, ok := models.FindGoal(id); ok == false {
+		return handleGoalIdNotFound(writer)
+	}
+
+	var goal models.Goal
+	err := decodeGoal(request, &goal)
+	if err != nil {
+		return handleInvalidBody(writer, err)
+	}
+	goal, _ = models.UpdateGoal(id, goal)
+
+	response := models.JsonExtendedResponse{Data: withProgress(goal)}
+	err = models.UpdateDataInFile()
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// GoalDelete Handler for a goal delete by id action, the linked todos are kept and unlinked
+// DELETE /goals/:id
+func GoalDelete(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	if models.RemoveGoal(params.ByName("id")) == false {
+		return handleGoalIdNotFound(writer)
+	}
+
+	err := models.UpdateDataInFile()
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusOK)
+	return nil
+}
@@ -0,0 +1,277 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"todo-rest-backend/models"
+	"todo-rest-backend/plugins"
+)
+
+// goldenDir holds the shapes of the responses, TODO_UPDATE_GOLDEN=true rewrites them
+const goldenDir = "testdata/golden"
+
+// notSnapshotted are the operations without a JSON envelope to snapshot
+var notSnapshotted = map[string]string{
+	"GET /":              "plain text",
+	"GET /openapi.json":  "checked by TestOpenApiGet_DescribesEveryRoute",
+	"GET /docs":          "HTML page",
+	"GET /metrics":       "Prometheus text format",
+	"GET /schemas/:name": "the files in schemas/",
+}
+
+// shapeOf reduces a decoded JSON value to its shape: objects keep their fields with the shapes of their values,
+// arrays the merged shape of their elements and other values become the name of their type
+func shapeOf(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		shape := make(map[string]interface{}, len(value))
+		for name, field := range value {
+			shape[name] = shapeOf(field)
+		}
+		return shape
+	case []interface{}:
+		if len(value) == 0 {
+			return []interface{}{}
+		}
+		element := shapeOf(value[0])
+		for _, other := range value[1:] {
+			element = mergeShapes(element, shapeOf(other))
+		}
+		return []interface{}{element}
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
+
+// mergeShapes merges the shapes of two elements of an array: objects have the fields of both, differing types
+// are joined like "number|string". Null next to an object or array keeps the shape of the object or array.
+func mergeShapes(a interface{}, b interface{}) interface{} {
+	if reflect.DeepEqual(a, b) {
+		return a
+	}
+	objectA, okA := a.(map[string]interface{})
+	objectB, okB := b.(map[string]interface{})
+	if okA && okB {
+		merged := make(map[string]interface{}, len(objectA))
+		for name, field := range objectA {
+			merged[name] = field
+		}
+		for name, field := range objectB {
+			if existing, ok := merged[name]; ok {
+				field = mergeShapes(existing, field)
+			}
+			merged[name] = field
+		}
+		return merged
+	}
+	arrayA, okA := a.([]interface{})
+	arrayB, okB := b.([]interface{})
+	if okA && okB {
+		if len(arrayA) == 0 {
+			return arrayB
+		}
+		if len(arrayB) == 0 {
+			return arrayA
+		}
+		return []interface{}{mergeShapes(arrayA[0], arrayB[0])}
+	}
+	if a == "null" {
+		return b
+	}
+	if b == "null" {
+		return a
+	}
+	types := strings.Split(fmt.Sprint(a)+"|"+fmt.Sprint(b), "|")
+	sort.Strings(types)
+	return strings.Join(types, "|")
+}
+
+// goldenRun serves the requests of the golden test and compares the shapes of the responses with the golden files
+type goldenRun struct {
+	t      *testing.T
+	router *httprouter.Router
+	// covered are the operations with a snapshot
+	covered map[string]bool
+}
+
+// check serves the request and compares the status and the shape of the response body with the golden file
+// of the name, the decoded body is returned
+func (g *goldenRun) check(name string, method string, path string, body string, header ...string) map[string]interface{} {
+	g.t.Helper()
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	for i := 0; i+1 < len(header); i += 2 {
+		request.Header.Set(header[i], header[i+1])
+	}
+	recorder := httptest.NewRecorder()
+	g.router.ServeHTTP(recorder, request)
+	if operation, ok := findApiOperation(method, request.URL.Path); ok {
+		g.covered[operation.method+" "+operation.path] = true
+	}
+
+	var decoded interface{}
+	if recorder.Body.Len() > 0 {
+		err := json.Unmarshal(recorder.Body.Bytes(), &decoded)
+		if err != nil {
+			g.t.Errorf("%s: the response is no JSON: %s", name, recorder.Body.String())
+			return nil
+		}
+	}
+	snapshot, _ := json.MarshalIndent(map[string]interface{}{"status": recorder.Code, "body": shapeOf(decoded)}, "", "  ")
+	snapshot = append(snapshot, '\n')
+
+	file := filepath.Join(goldenDir, name+".json")
+	if os.Getenv("TODO_UPDATE_GOLDEN") == "true" {
+		err := os.WriteFile(file, snapshot, 0644)
+		if err != nil {
+			g.t.Fatal(err)
+		}
+	} else if golden, err := os.ReadFile(file); err != nil {
+		g.t.Errorf("%s: no golden file, run with TODO_UPDATE_GOLDEN=true to create it", name)
+	} else if bytes.Equal(golden, snapshot) == false {
+		g.t.Errorf("%s: the response changed its shape, run with TODO_UPDATE_GOLDEN=true if that is intended\n"+
+			"got:\n%s\nwant:\n%s", name, snapshot, golden)
+	}
+
+	object, _ := decoded.(map[string]interface{})
+	return object
+}
+
+// dataId returns the id of the data of a response
+func dataId(response map[string]interface{}) string {
+	data, _ := response["data"].(map[string]interface{})
+	id, _ := data["id"].(string)
+	return id
+}
+
+// TestGolden snapshots the shape of the response of every operation in testdata/golden. Clients depend on the
+// fields and types of the meta and data envelope, a change fails the test until the golden files are updated
+// with TODO_UPDATE_GOLDEN=true go test ./controllers -run Golden
+func TestGolden(t *testing.T) {
+	// Arrange
+	//
+	defer models.SetRepository(models.NewMemoryRepository())
+	defer models.SetDataDir(".")
+	defer func(integrations *plugins.Integrations) { listIntegrations = integrations }(listIntegrations)
+	defer func() { simpleApiKey, retentionPolicy = "", nil }()
+	models.SetDataDir(t.TempDir())
+	// the configuration of the server, like the outbox of the lists, is part of some responses
+	err := Configure(models.NewMemoryRepository(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// only one focus session runs at a time
+	if running, ok := models.RunningPomodoro(); ok {
+		models.StopPomodoro(running.TodoId)
+	}
+	invalidateResponseCache()
+	simpleApiKey = "secret"
+	router := httprouter.New()
+	RegisterRoutes(HttpRouter{Router: router})
+	g := &goldenRun{t: t, router: router, covered: map[string]bool{}}
+
+	// Act and Assert
+	//
+	g.check("readyz-get", http.MethodGet, readinessPath, "")
+
+	todo := dataId(g.check("todos-post", http.MethodPost, "/todos",
+		`{"title": "Buy milk", "description": "Oat milk", "tags": ["shopping"], "list": "home"}`))
+	g.check("todos-post-suggest", http.MethodPost, "/todos?suggest=true", `{"title": "Call the bank", "list": "home"}`)
+	habit := dataId(g.check("todos-post-habit", http.MethodPost, "/todos", `{"title": "Stretch", "habit": true}`))
+	g.check("todos-get", http.MethodGet, "/todos", "")
+	g.check("todos-get-group-by", http.MethodGet, "/todos?group_by=tag", "")
+	g.check("todos-get-paged", http.MethodGet, "/todos?page=1&per_page=1&unknown=1", "")
+	g.check("todo-get", http.MethodGet, "/todos/"+todo, "")
+	g.check("todo-get-not-found", http.MethodGet, "/todos/unknown", "")
+	g.check("todo-put", http.MethodPut, "/todos/"+todo,
+		`{"title": "Buy milk", "description": "Oat milk", "tags": ["shopping"], "list": "home"}`, "If-Match", "*")
+	g.check("todo-put-invalid", http.MethodPut, "/todos/"+todo, `{"title": ""}`, "If-Match", "*")
+	g.check("todos-poll-get", http.MethodGet, "/todos/poll?timeout=10ms", "")
+	g.check("todos-export-get", http.MethodGet, "/todos/export?format=json", "")
+	g.check("todos-import-post", http.MethodPost, "/todos/import?format=json", `[{"title": "Water the plants"}]`)
+	g.check("todos-bulk-post", http.MethodPost, "/todos/bulk", `[{"op": "create", "todo": {"title": "Fax the form"}}]`)
+	g.check("todos-autocomplete-get", http.MethodGet, "/todos/autocomplete?q=bu", "")
+	g.check("todos-similar-get", http.MethodGet, "/todos/similar?title=Buy%20milk", "")
+	g.check("todos-revision-get", http.MethodGet, "/todos/revision", "")
+	g.check("todo-move-column-post", http.MethodPost, "/todos/"+todo+"/move-column", `{"column": "in_progress", "position": 1}`,
+		"If-Match", "*")
+	g.check("todo-pomodoro-post", http.MethodPost, "/todos/"+todo+"/pomodoro", `{"action": "start"}`)
+	g.check("todo-pomodoro-get", http.MethodGet, "/todos/"+todo+"/pomodoro", "")
+	g.check("todo-suggestions-get", http.MethodGet, "/todos/"+todo+"/suggestions", "")
+	g.check("todo-pomodoro-post-stop", http.MethodPost, "/todos/"+todo+"/pomodoro", `{"action": "stop"}`)
+	withItem := g.check("todo-items-post", http.MethodPost, "/todos/"+todo+"/items", `{"title": "Check the date"}`)
+	item := fmt.Sprint(withItem["data"].(map[string]interface{})["items"].([]interface{})[0].(map[string]interface{})["id"])
+	g.check("todo-item-put", http.MethodPut, "/todos/"+todo+"/items/"+item, `{"title": "Check the date", "done": true}`)
+	g.check("todo-item-delete", http.MethodDelete, "/todos/"+todo+"/items/"+item, "")
+	g.check("batch-post", http.MethodPost, "/batch",
+		`{"operations": [{"op": "create", "todo": {"title": "Book a table"}}]}`)
+	g.check("tags-get", http.MethodGet, "/tags", "")
+	g.check("settings-get", http.MethodGet, "/settings", "")
+	g.check("settings-put", http.MethodPut, "/settings", `{"default_list": "inbox"}`)
+	g.check("sync-status-get", http.MethodGet, "/sync/status", "")
+	g.check("sync-webhook-post", http.MethodPost, "/sync/webhook", `{}`)
+	g.check("rules-get", http.MethodGet, "/rules", "")
+	g.check("rules-test-post", http.MethodPost, "/rules/test", `{"title": "Pay the rent"}`)
+	g.check("retention-get", http.MethodGet, "/admin/retention", "")
+	retentionPolicy = &RetentionPolicy{Days: 30, Mode: RetentionArchive}
+	g.check("retention-run-post", http.MethodPost, "/admin/retention/run?dry_run=true", "")
+	g.check("dual-write-get", http.MethodGet, "/admin/dual-write", "")
+	g.check("shards-get", http.MethodGet, "/admin/shards", "")
+	g.check("shard-reload-post", http.MethodPost, "/admin/shards/bob/reload", "")
+	g.check("shard-reset-post", http.MethodPost, "/admin/shards/bob/reset", "")
+	g.check("denylist-get", http.MethodGet, "/admin/denylist", "")
+	g.check("outbox-get", http.MethodGet, "/admin/outbox", "")
+	g.check("dlq-get", http.MethodGet, "/admin/dlq", "")
+	g.check("dlq-redeliver-post", http.MethodPost, "/admin/dlq/unknown/redeliver", "")
+	g.check("list-todos-get", http.MethodGet, "/lists/home/todos", "")
+	g.check("list-order-put", http.MethodPut, "/lists/home/order", `["unknown"]`)
+	g.check("list-board-get", http.MethodGet, "/lists/home/board", "")
+	g.check("list-integrations-put", http.MethodPut, "/lists/home/integrations", `{}`)
+	g.check("list-integrations-get", http.MethodGet, "/lists/home/integrations", "")
+	g.check("list-todo-get", http.MethodGet, "/lists/home/todos/1", "")
+	goal := dataId(g.check("goals-post", http.MethodPost, "/goals", `{"title": "Run a marathon"}`))
+	g.check("goals-get", http.MethodGet, "/goals", "")
+	g.check("goal-get", http.MethodGet, "/goals/"+goal, "")
+	g.check("goal-put", http.MethodPut, "/goals/"+goal, `{"title": "Run a half marathon"}`)
+	g.check("goal-todos-get", http.MethodGet, "/goals/"+goal+"/todos", "")
+	g.check("goal-delete", http.MethodDelete, "/goals/"+goal, "")
+	g.check("habit-done-post", http.MethodPost, "/habits/"+habit+"/done", "")
+	g.check("habit-calendar-get", http.MethodGet, "/habits/"+habit+"/calendar", "")
+	g.check("habit-done-delete", http.MethodDelete, "/habits/"+habit+"/done", "")
+	g.check("views-waiting-get", http.MethodGet, "/views/waiting", "")
+	g.check("views-stale-get", http.MethodGet, "/views/stale", "")
+	g.check("reports-capacity-get", http.MethodGet, "/reports/capacity", "")
+	g.check("reports-focus-get", http.MethodGet, "/reports/focus", "")
+	g.check("simple-next-get", http.MethodGet, "/simple/next", "", "Authorization", "Bearer secret")
+	simple := g.check("simple-add-post", http.MethodPost, "/simple/add?title=Pack", "", "Authorization", "Bearer secret")
+	g.check("simple-done-post", http.MethodPost, fmt.Sprintf("/simple/done/%v", simple["id"]), "",
+		"Authorization", "Bearer secret")
+	g.check("todo-delete", http.MethodDelete, "/todos/"+todo, "", "If-Match", "*")
+	g.check("trash-get", http.MethodGet, "/todos/trash", "")
+	g.check("todo-restore-post", http.MethodPost, "/todos/"+todo+"/restore", "")
+	g.check("trash-delete", http.MethodDelete, "/todos/trash", "")
+	snapshot := dataId(g.check("todos-delete", http.MethodDelete, "/todos", ""))
+	g.check("restore-post", http.MethodPost, "/admin/restore/"+snapshot, "")
+
+	for _, operation := range apiOperations {
+		key := operation.method + " " + operation.path
+		if _, skipped := notSnapshotted[key]; g.covered[key] == false && skipped == false {
+			t.Error("Fehler, no snapshot of", key)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"errors"
+	"sort"
+	"todo-rest-backend/models"
+)
+
+// TodoGroup is a group of todos with the same value of the grouping field, todos keep their order
+type TodoGroup struct {
+	// Key is the value of the group, the todos without value are in the group with the empty key
+	Key   string        `json:"key"`
+	Count int           `json:"count"`
+	Todos []models.Todo `json:"todos"`
+}
+
+// GroupsMeta is the meta information of grouped todos
+type GroupsMeta struct {
+	GroupBy  string `json:"group_by"`
+	Groups   int    `json:"groups"`
+	Total    int    `json:"total"`
+	Revision uint64 `json:"revision"`
+}
+
+// dueBuckets are the groups of the due dates relative to today, in their order
+var dueBuckets = []string{"overdue", "today", "this_week", "later", "none"}
+
+// dueBucketOf returns the due bucket of the todo. Todos due before now are overdue, this_week ends with the
+// Sunday of the current week.
+func dueBucketOf(todo models.Todo) string {
+	day := todo.DueDay()
+	today := models.Today()
+	switch {
+	case day == "":
+		return "none"
+	case todo.Overdue() || day < today:
+		return "overdue"
+	case day == today:
+		return "today"
+	}
+	now := models.Now().Local()
+	daysToSunday := (7 - int(now.Weekday())) % 7
+	if day <= now.AddDate(0, 0, daysToSunday).Format(models.DateFormat) {
+		return "this_week"
+	}
+	return "later"
+}
+
+// groupTodos groups the todos by tag, status, list, due_bucket or assignee, the owner of the todo.
+// A todo with several tags is in the group of each tag. Status groups are in the order of the board
+// columns and due buckets in the order of dueBuckets, the other groups are sorted by key with the group
+// without key last.
+func groupTodos(todos []models.Todo, groupBy string) ([]TodoGroup, error) {
+	var keysOf func(todo models.Todo) []string
+	var keys []string
+	switch groupBy {
+	case "tag":
+		keysOf = func(todo models.Todo) []string {
+			if len(todo.Tags) == 0 {
+				return []string{""}
+			}
+			return todo.Tags
+		}
+	case "status":
+		keysOf = func(todo models.Todo) []string { return []string{todo.Status} }
+		keys = models.BoardColumns()
+	case "list":
+		keysOf = func(todo models.Todo) []string { return []string{todo.List} }
+	case "due_bucket":
+		keysOf = func(todo models.Todo) []string { return []string{dueBucketOf(todo)} }
+		keys = append([]string{}, dueBuckets...)
+	case "assignee":
+		keysOf = func(todo models.Todo) []string { return []string{models.OwnerOf(todo)} }
+	default:
+		return nil, errors.New("unsupported group_by " + groupBy)
+	}
+
+	groups := make(map[string]*TodoGroup)
+	for _, key := range keys {
+		groups[key] = &TodoGroup{Key: key, Todos: []models.Todo{}}
+	}
+	for _, todo := range todos {
+		for _, key := range keysOf(todo) {
+			group, ok := groups[key]
+			if ok == false {
+				group = &TodoGroup{Key: key}
+				groups[key] = group
+				keys = append(keys, key)
+			}
+			group.Todos = append(group.Todos, todo)
+			group.Count++
+		}
+	}
+
+	if groupBy != "status" && groupBy != "due_bucket" {
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i] == "" || keys[j] == "" {
+				return keys[j] == ""
+			}
+			return keys[i] < keys[j]
+		})
+	}
+	result := []TodoGroup{}
+	for _, key := range keys {
+		result = append(result, *groups[key])
+	}
+	return result, nil
+}
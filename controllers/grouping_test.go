@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+	"todo-rest-backend/clock"
+	"todo-rest-backend/models"
+)
+
+func TestGroupTodos_ByTag(t *testing.T) {
+	// Arrange
+	//
+	todos := []models.Todo{
+		{Id: "0", Tags: []string{"work", "urgent"}},
+		{Id: "1", Tags: []string{}},
+		{Id: "2", Tags: []string{"work"}},
+	}
+
+	// Act
+	//
+	groups, err := groupTodos(todos, "tag")
+
+	// Assert
+	//
+	if err != nil || len(groups) != 3 {
+		t.Fatal("Fehler", groups, err)
+	}
+	if groups[0].Key != "urgent" || groups[1].Key != "work" || groups[1].Count != 2 || groups[2].Key != "" {
+		t.Error("Fehler", groups)
+	}
+}
+
+func TestGroupTodos_ByDueBucket(t *testing.T) {
+	// Arrange
+	//
+	defer models.SetClock(clock.NewSystem())
+	// a Wednesday
+	models.SetClock(clock.NewFake(time.Date(2024, 5, 8, 12, 0, 0, 0, time.Local)))
+	todos := []models.Todo{
+		{Id: "0", DueDate: "2024-05-20"},
+		{Id: "1", DueDate: "2024-05-07"},
+		{Id: "2"},
+		{Id: "3", DueDate: "2024-05-12"},
+		{Id: "4", DueDate: "2024-05-08"},
+		{Id: "5", DueDate: "2024-05-13"},
+	}
+
+	// Act
+	//
+	groups, err := groupTodos(todos, "due_bucket")
+
+	// Assert
+	//
+	if err != nil || len(groups) != 5 {
+		t.Fatal("Fehler", groups, err)
+	}
+	expected := []struct {
+		key string
+		ids []string
+	}{{"overdue", []string{"1"}}, {"today", []string{"4"}}, {"this_week", []string{"3"}}, {"later", []string{"0", "5"}}, {"none", []string{"2"}}}
+	for i, group := range groups {
+		if group.Key != expected[i].key || group.Count != len(expected[i].ids) || group.Todos[0].Id != expected[i].ids[0] {
+			t.Error("Fehler", group)
+		}
+	}
+}
+
+func TestGroupTodos_ByAssignee(t *testing.T) {
+	// Arrange
+	//
+	todos := []models.Todo{{Id: "0", Owner: "bob"}, {Id: "1", Owner: "alice"}, {Id: "2", Owner: "bob"}}
+
+	// Act
+	//
+	groups, err := groupTodos(todos, "assignee")
+
+	// Assert
+	//
+	if err != nil || len(groups) != 2 || groups[0].Key != "alice" || groups[1].Key != "bob" || groups[1].Count != 2 {
+		t.Error("Fehler", groups, err)
+	}
+}
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"todo-rest-backend/models"
+)
+
+// findHabit returns the habit of the id parameter, other todos are no habits
+func findHabit(params httprouter.Params) (models.Todo, bool) {
+	todo, ok := models.FindTodo(params.ByName("id"))
+	if ok == false || todo.Habit == false {
+		return models.Todo{}, false
+	}
+	return todo, true
+}
+
+func handleHabitIdNotFound(writer http.ResponseWriter) error {
+	return writeError(writer, http.StatusNotFound, models.CodeNotFound, "Habit Not Found")
+}
+
+// writeHabitCalendar answers with the calendar of the month of the date
+func writeHabitCalendar(writer http.ResponseWriter, habit models.Todo, month string) error {
+	calendar, err := habit.CalendarOf(month)
+	if err != nil {
+		return handleTodoNotProperlyTransmittedGeneral(writer, "Month Must Have The Form 2006-01")
+	}
+
+	response := models.JsonExtendedResponse{Data: calendar}
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// HabitCalendarGet Handler for the month grid of a habit action, the month defaults to the current month
+// GET /habits/:id/calendar?month=2006-01
+func HabitCalendarGet(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	habit, ok := findHabit(params)
+	if ok == false {
+		return handleHabitIdNotFound(writer)
+	}
+
+	month := request.URL.Query().Get("month")
+	if month == "" {
+		month = models.Today()[:7]
+	}
+	return writeHabitCalendar(writer, habit, month)
+}
+
+// HabitDonePost Handler for recording a habit as done on a day, the day defaults to today.
+// The calendar of the month of the day is returned.
+// POST /habits/:id/done?date=2006-01-02
+func HabitDonePost(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+	return recordHabitDay(writer, request, params, true)
+}
+
+// HabitDoneDelete Handler for removing the record of a habit done on a day, the day defaults to today
+// DELETE /habits/:id/done?date=2006-01-02
+func HabitDoneDelete(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+	return recordHabitDay(writer, request, params, false)
+}
+
+func recordHabitDay(writer http.ResponseWriter, request *http.Request, params httprouter.Params, done bool) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	_, ok := findHabit(params)
+	if ok == false {
+		return handleHabitIdNotFound(writer)
+	}
+
+	date := request.URL.Query().Get("date")
+	if date == "" {
+		date = models.Today()
+	}
+	habit, err := models.RecordHabitDay(params.ByName("id"), date, done)
+	if errors.Is(err, models.ErrInvalidHabitDay) {
+		return handleTodoNotProperlyTransmittedGeneral(writer, "Date Must Be Between The Start Of The Habit And Today")
+	}
+	if err != nil {
+		return err
+	}
+
+	err = models.UpdateDataInFile()
+	if err != nil {
+		return err
+	}
+	return writeHabitCalendar(writer, habit, date[:7])
+}
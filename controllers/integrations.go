@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"todo-rest-backend/models"
+	"todo-rest-backend/plugins"
+)
+
+// integrationsFileName keeps the integrations of the lists, below the data directory
+const integrationsFileName = "integrations.json"
+
+// listsOutboxFileName keeps the events for the webhooks of the lists until they accepted them, below the
+// data directory
+const listsOutboxFileName = "outbox-lists.json"
+
+// listIntegrations are the webhooks and notifiers of the lists, they are loaded by configurePlugins
+var listIntegrations = &plugins.Integrations{}
+
+// withoutSecrets returns the integrations for responses, the secrets of the webhooks are write-only
+func withoutSecrets(integrations plugins.ListIntegrations) plugins.ListIntegrations {
+	webhooks := make([]plugins.ListWebhook, len(integrations.Webhooks))
+	for i, webhook := range integrations.Webhooks {
+		webhook.Secret = ""
+		webhooks[i] = webhook
+	}
+	integrations.Webhooks = webhooks
+	return integrations
+}
+
+// ListIntegrationsGet Handler for the list integrations action, the webhooks and notifiers of the list
+// GET /lists/:id/integrations
+func ListIntegrationsGet(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) error {
+	integrations := listIntegrations.Get(models.Owner(), params.ByName("id"))
+	response := models.JsonExtendedResponse{Data: withoutSecrets(integrations)}
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	describedBy(writer, "integrations.json")
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// ListIntegrationsPut Handler for the list integrations change action, the body replaces the webhooks and
+// notifiers of the list
+// PUT /lists/:id/integrations
+func ListIntegrationsPut(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	describedBy(writer, "integrations.json")
+	var integrations plugins.ListIntegrations
+	body, err := validatedBody(request, "integrations.json")
+	if err == nil {
+		err = json.Unmarshal(body, &integrations)
+	}
+	if err == nil {
+		err = integrations.Validate()
+	}
+	if err != nil {
+		return handleInvalidBody(writer, err)
+	}
+
+	list := params.ByName("id")
+	err = listIntegrations.Set(models.Owner(), list, integrations)
+	if err != nil {
+		return err
+	}
+
+	response := models.JsonExtendedResponse{Data: withoutSecrets(listIntegrations.Get(models.Owner(), list))}
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
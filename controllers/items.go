@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"todo-rest-backend/models"
+	"todo-rest-backend/plugins"
+)
+
+// decodeItem does decoding of the json request body into a ChecklistItem
+func decodeItem(request *http.Request, item *models.ChecklistItem) error {
+	body, err := validatedBody(request, "item.json")
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, item)
+}
+
+// writeItemChange answers with the todo after a change of its checklist
+func writeItemChange(writer http.ResponseWriter, status int, todo models.Todo, err error) error {
+	if errors.Is(err, models.ErrTodoNotFound) {
+		return handleTodoIdNotFound(writer)
+	}
+	if errors.Is(err, models.ErrItemNotFound) {
+		return writeError(writer, http.StatusNotFound, models.CodeNotFound, "Checklist Item Not Found")
+	}
+	if err != nil {
+		return handleTodoNotProperlyTransmittedGeneral(writer, "Checklist Item Needs A Title")
+	}
+
+	syncTodo(todo)
+	plugins.Emit(plugins.TodoUpdated, todo)
+
+	response := models.JsonExtendedResponse{Meta: models.RevisionMeta{Revision: models.Revision()}, Data: todo}
+	err = models.UpdateDataInFile()
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(status)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// TodoItemPost Handler for adding a checklist item to a todo, the todo with the new item last is returned
+// POST /todos/:id/items
+func TodoItemPost(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	describedBy(writer, "todo.json")
+	var item models.ChecklistItem
+	err := decodeItem(request, &item)
+	if err != nil {
+		return handleInvalidBody(writer, err)
+	}
+
+	todo, err := models.AddItem(params.ByName("id"), item)
+	return writeItemChange(writer, http.StatusCreated, todo, err)
+}
+
+// TodoItemPut Handler for changing a checklist item of a todo, the todo is terminated once all items are done
+// PUT /todos/:id/items/:itemId
+func TodoItemPut(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	describedBy(writer, "todo.json")
+	var item models.ChecklistItem
+	err := decodeItem(request, &item)
+	if err != nil {
+		return handleInvalidBody(writer, err)
+	}
+
+	item.Id = params.ByName("itemId")
+	todo, err := models.UpdateItem(params.ByName("id"), item)
+	return writeItemChange(writer, http.StatusOK, todo, err)
+}
+
+// TodoItemDelete Handler for removing a checklist item of a todo
+// DELETE /todos/:id/items/:itemId
+func TodoItemDelete(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	describedBy(writer, "todo.json")
+	todo, err := models.RemoveItem(params.ByName("id"), params.ByName("itemId"))
+	return writeItemChange(writer, http.StatusOK, todo, err)
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+	"todo-rest-backend/models"
+	"todo-rest-backend/plugins"
+)
+
+// ListTodosGet Handler for the todos of a list action, the todos are returned in the order of the list
+// GET /lists/:id/todos
+func ListTodosGet(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) error {
+	response := models.JsonDataResponse{
+		Meta: &models.RevisionMeta{Revision: models.Revision()},
+		Data: models.ListTodos(params.ByName("id")),
+	}
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// ListTodoGetByNumber Handler for a todo get by its number in a list action
+// GET /lists/:id/todos/:number
+func ListTodoGetByNumber(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	number, err := strconv.Atoi(params.ByName("number"))
+	if err != nil {
+		return handleTodoIdNotFound(writer)
+	}
+
+	todo, ok := models.FindTodoByNumber(params.ByName("id"), number)
+	if ok == false {
+		return handleTodoIdNotFound(writer)
+	}
+
+	response := models.JsonExtendedResponse{Data: todo}
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// ListOrderPut Handler for the reorder action, the body is the array of the ids of all todos of the list
+// in their new order
+// PUT /lists/:id/order
+func ListOrderPut(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	var ids []string
+	if request.Body == nil || json.NewDecoder(request.Body).Decode(&ids) != nil {
+		return handleTodoNotProperlyTransmitted(writer)
+	}
+
+	list := params.ByName("id")
+	positions := make(map[string]int)
+	for _, todo := range models.ListTodos(list) {
+		positions[todo.Id] = todo.Position
+	}
+	todos, err := models.ReorderList(list, ids)
+	if err != nil {
+		return writeError(writer, http.StatusUnprocessableEntity, models.CodeValidationFailed, "Order Must Contain Every Todo Of The List Once")
+	}
+	for _, todo := range todos {
+		if todo.Position != positions[todo.Id] {
+			plugins.Emit(plugins.TodoUpdated, todo)
+		}
+	}
+
+	response := models.JsonDataResponse{Data: todos}
+	err = models.UpdateDataInFile()
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
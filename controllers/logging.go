@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+)
+
+// requestIdHeader carries the id of a request, a client's id is kept so that its logs can be correlated
+const requestIdHeader = "X-Request-Id"
+
+// maxRequestIdLength limits the ids taken from clients
+const maxRequestIdLength = 128
+
+// logger writes the log lines of the requests as JSON, replaced by SetLogger
+var logger = slog.New(slog.NewJSONHandler(os.Stderr, nil))
+
+// SetLogger replaces the logger of the requests, e.g. by one writing to a buffer in tests
+func SetLogger(l *slog.Logger) {
+	logger = l
+}
+
+// newRequestId returns the id of the request, the X-Request-Id of the client or a new random one
+func newRequestId(request *http.Request) string {
+	id := request.Header.Get(requestIdHeader)
+	if id != "" && len(id) <= maxRequestIdLength && printable(id) {
+		return id
+	}
+	random := make([]byte, 8)
+	rand.Read(random)
+	return hex.EncodeToString(random)
+}
+
+func printable(value string) bool {
+	for _, c := range value {
+		if c < ' ' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// requestLogging logs a line with method, path, status, latency and id of every request.
+// The id is returned in the X-Request-Id header.
+func requestLogging(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		start := time.Now()
+		id := newRequestId(request)
+		writer.Header().Set(requestIdHeader, id)
+		status := &statusWriter{ResponseWriter: writer}
+
+		handler.ServeHTTP(status, request)
+
+		if status.status == 0 {
+			status.status = http.StatusOK
+		}
+		logger.LogAttrs(request.Context(), slog.LevelInfo, "request",
+			slog.String("method", request.Method),
+			slog.String("path", request.URL.Path),
+			slog.Int("status", status.status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("request_id", id),
+		)
+	})
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestLogging(t *testing.T) {
+	// Arrange
+	//
+	var output bytes.Buffer
+	defer SetLogger(logger)
+	SetLogger(slog.New(slog.NewJSONHandler(&output, nil)))
+	handler := requestLogging(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusNotFound)
+	}))
+	request := httptest.NewRequest(http.MethodGet, "/todos/7", nil)
+	request.Header.Set("X-Request-Id", "abc-1")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	//
+	handler.ServeHTTP(recorder, request)
+
+	// Assert
+	//
+	var line struct {
+		Method    string  `json:"method"`
+		Path      string  `json:"path"`
+		Status    int     `json:"status"`
+		LatencyMs float64 `json:"latency_ms"`
+		RequestId string  `json:"request_id"`
+	}
+	err := json.Unmarshal(output.Bytes(), &line)
+	if err != nil {
+		t.Fatal("Fehler", err, output.String())
+	}
+	if line.Method != "GET" || line.Path != "/todos/7" || line.Status != 404 || line.RequestId != "abc-1" {
+		t.Error("Fehler", output.String())
+	}
+	if recorder.Header().Get("X-Request-Id") != "abc-1" {
+		t.Error("Fehler", recorder.Header())
+	}
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+	"todo-rest-backend/models"
+)
+
+// metricsPath is the route of the metrics, it is served without holding the store
+const metricsPath = "/metrics"
+
+// metricsRegistry holds the metrics of the backend, a registry of its own keeps them apart from the default
+// registry of embedders
+var metricsRegistry = prometheus.NewRegistry()
+
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "todo_http_requests_total",
+		Help: "Requests by method, route and status of the response.",
+	}, []string{"method", "route", "status"})
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "todo_http_request_duration_seconds",
+		Help:    "Latency of the requests by method and route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+	statusHistogram = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "todo_http_response_status",
+		Help:    "Statuses of the responses, the buckets are the status classes.",
+		Buckets: []float64{199, 299, 399, 499, 599},
+	})
+	persistenceWriteDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "todo_persistence_write_duration_seconds",
+		Help:    "Duration of the writes of the store to the storage by result.",
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+	}, []string{"result"})
+	// todoCount is the number of todos of the last scrape that found the store free
+	todoCount float64
+)
+
+func init() {
+	metricsRegistry.MustRegister(requestsTotal, requestDuration, statusHistogram, persistenceWriteDuration,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "todo_todos",
+			Help: "Number of todos in the store.",
+		}, countTodos),
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	models.ObserveSaves(observeSave)
+}
+
+// countTodos counts the todos if no request holds the store exclusively, otherwise the previous count is
+// reported instead of waiting for the store
+func countTodos() float64 {
+	if storeMutex.TryRLock() {
+		todoCount = float64(len(models.AllTodos()))
+		storeMutex.RUnlock()
+	}
+	return todoCount
+}
+
+func observeSave(duration time.Duration, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	persistenceWriteDuration.WithLabelValues(result).Observe(duration.Seconds())
+}
+
+// observeRequest counts the request to the route and its latency, it is deferred with the start of the request
+func observeRequest(route route, writer *statusWriter, start time.Time) {
+	status := writer.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	requestsTotal.WithLabelValues(route.method, route.path, strconv.Itoa(status)).Inc()
+	requestDuration.WithLabelValues(route.method, route.path).Observe(time.Since(start).Seconds())
+	statusHistogram.Observe(float64(status))
+}
+
+// metricsHandler serves the metrics in the Prometheus text format
+var metricsHandler = promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{})
+
+// MetricsGet Handler for the metrics action, the requests, latencies, todo count and persistence writes for Prometheus
+// GET /metrics
+func MetricsGet(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) error {
+	metricsHandler.ServeHTTP(writer, request)
+	return nil
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsGet_CountsTheRequests(t *testing.T) {
+	// Arrange
+	//
+	router := httprouter.New()
+	RegisterRoutes(HttpRouter{Router: router})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/todos", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/todos/missing", nil))
+	observeSave(20*time.Millisecond, nil)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	//
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// Assert
+	//
+	body := recorder.Body.String()
+	for _, expected := range []string{
+		`todo_http_requests_total{method="GET",route="/todos",status="200"}`,
+		`todo_http_requests_total{method="GET",route="/todos/:id",status="404"}`,
+		`todo_http_request_duration_seconds_bucket{method="GET",route="/todos",le="0.005"}`,
+		`todo_http_response_status_bucket{le="499"}`,
+		"todo_todos ",
+		`todo_persistence_write_duration_seconds_count{result="ok"}`,
+		"go_goroutines ",
+	} {
+		if recorder.Code != http.StatusOK || strings.Contains(body, expected) == false {
+			t.Error("Fehler", recorder.Code, expected)
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"todo-rest-backend/models"
+	"todo-rest-backend/schemas"
+)
+
+// apiOperation describes an action of the API in the OpenAPI document
+type apiOperation struct {
+	method string
+	// path is written like the routes, with parameters as :name
+	path    string
+	summary string
+	// query are the names of the query parameters
+	query []string
+	// booleans are the query parameters taking true or false
+	booleans []string
+	// body is the schema of the JSON request body, e.g. "todo.json", empty for actions without one
+	body string
+	// status is the status of a successful response, 200 if it is 0
+	status int
+	// data is the schema of the data of the response, list for a list of them; empty for other data
+	data string
+	list bool
+	// deprecations phase out the operation or its query parameters
+	deprecations []deprecation
+}
+
+// apiOperations are the actions described by the OpenAPI document, a test checks that they are the routes
+var apiOperations = []apiOperation{
+	{method: http.MethodGet, path: "/", summary: "Welcome text"},
+	{method: http.MethodGet, path: readinessPath, summary: "Readiness and loading progress"},
+	{method: http.MethodGet, path: "/openapi.json", summary: "This OpenAPI document"},
+	{method: http.MethodGet, path: "/docs", summary: "Swagger UI for this document, if enabled"},
+	{method: http.MethodGet, path: metricsPath, summary: "Metrics in the Prometheus text format"},
+	{method: http.MethodGet, path: "/todos", summary: "List the todos",
+		query: []string{"sort", "order", "page", "per_page", "near", "radius", "group_by", "terminated", "title_contains",
+			"description_contains", "tag", "overdue", "due_before", "updated_since"},
+		booleans: []string{"terminated", "overdue"}, data: "todo.json", list: true},
+	{method: http.MethodPost, path: "/todos", summary: "Create a todo", query: []string{"suggest"}, booleans: []string{"suggest"},
+		body: "todo.json", status: http.StatusCreated, data: "todo.json"},
+	{method: http.MethodDelete, path: "/todos", summary: "Delete all todos, they are kept in a snapshot"},
+	{method: http.MethodGet, path: "/todos/:id", summary: "Get a todo", data: "todo.json"},
+	{method: http.MethodPut, path: "/todos/:id", summary: "Update a todo", body: "todo.json", data: "todo.json"},
+	{method: http.MethodDelete, path: "/todos/:id", summary: "Delete a todo, it is moved to the trash"},
+	{method: http.MethodGet, path: "/todos/poll", summary: "Wait for the next change", query: []string{"since", "timeout"}},
+	{method: http.MethodGet, path: "/todos/export", summary: "Download the todos", query: []string{"format"}},
+	{method: http.MethodPost, path: "/todos/import", summary: "Upload todos", query: []string{"format", "mode"},
+		status: http.StatusCreated, data: "todo.json", list: true},
+	{method: http.MethodPost, path: "/todos/bulk", summary: "Create several todos at once", body: "bulk.json",
+		status: http.StatusCreated, data: "todo.json", list: true},
+	{method: http.MethodGet, path: "/todos/autocomplete", summary: "Suggest titles and tags", query: []string{"q", "limit"}},
+	{method: http.MethodGet, path: "/todos/similar", summary: "Find todos with a similar title",
+		query: []string{"title", "threshold"}, data: "todo.json", list: true},
+	{method: http.MethodGet, path: "/todos/revision", summary: "The revision of the store"},
+	{method: http.MethodGet, path: "/todos/trash", summary: "List the deleted todos", data: "todo.json", list: true},
+	{method: http.MethodDelete, path: "/todos/trash", summary: "Purge the trash"},
+	{method: http.MethodPost, path: "/todos/:id/restore", summary: "Take a todo out of the trash", data: "todo.json"},
+	{method: http.MethodPost, path: "/todos/:id/move-column", summary: "Move a todo on the board", data: "todo.json"},
+	{method: http.MethodGet, path: "/todos/:id/pomodoro", summary: "The focus sessions of a todo"},
+	{method: http.MethodGet, path: "/todos/:id/suggestions", summary: "A due date and priority from similar todos"},
+	{method: http.MethodPost, path: "/todos/:id/pomodoro", summary: "Start or stop a focus session"},
+	{method: http.MethodPost, path: "/todos/:id/items", summary: "Add a checklist item", body: "item.json",
+		status: http.StatusCreated, data: "todo.json"},
+	{method: http.MethodPut, path: "/todos/:id/items/:itemId", summary: "Change a checklist item", body: "item.json",
+		data: "todo.json"},
+	{method: http.MethodDelete, path: "/todos/:id/items/:itemId", summary: "Remove a checklist item", data: "todo.json"},
+	{method: http.MethodPost, path: "/batch", summary: "Apply operations all or none", body: "batch.json"},
+	{method: http.MethodGet, path: "/schemas/:name", summary: "A JSON Schema of the bodies"},
+	{method: http.MethodGet, path: "/tags", summary: "The tags with their numbers of todos"},
+	{method: http.MethodGet, path: "/settings", summary: "The defaults of new todos", data: "settings.json"},
+	{method: http.MethodPut, path: "/settings", summary: "Change the defaults of new todos", body: "settings.json",
+		data: "settings.json"},
+	{method: http.MethodGet, path: "/sync/status", summary: "The status of the issue sync"},
+	{method: http.MethodPost, path: "/sync/webhook", summary: "Issue events of GitHub or Jira"},
+	{method: http.MethodGet, path: "/rules", summary: "List the rules"},
+	{method: http.MethodPost, path: "/rules/test", summary: "Evaluate the rules for a todo", query: []string{"action"},
+		body: "todo.json"},
+	{method: http.MethodGet, path: "/admin/retention", summary: "The retention policy and its last run"},
+	{method: http.MethodPost, path: "/admin/retention/run", summary: "Apply the retention policy", query: []string{"dry_run"},
+		booleans: []string{"dry_run"}},
+	{method: http.MethodGet, path: "/admin/dual-write", summary: "Compare the stores of the dual-write mode"},
+	{method: http.MethodGet, path: "/admin/denylist", summary: "The rules for sensitive content"},
+	{method: http.MethodGet, path: "/admin/outbox", summary: "The events not delivered yet"},
+	{method: http.MethodGet, path: "/admin/dlq", summary: "The events given up"},
+	{method: http.MethodPost, path: "/admin/dlq/:id/redeliver", summary: "Deliver a dead letter again",
+		status: http.StatusAccepted},
+	{method: http.MethodPost, path: "/admin/restore/:snapshot", summary: "Restore the todos of a snapshot",
+		data: "todo.json", list: true},
+	{method: http.MethodGet, path: "/admin/shards", summary: "The shards of the sharded storage"},
+	{method: http.MethodPost, path: "/admin/shards/:owner/reload", summary: "Read a shard again"},
+	{method: http.MethodPost, path: "/admin/shards/:owner/reset", summary: "Start a failed shard anew"},
+	{method: http.MethodGet, path: "/lists/:id/todos", summary: "The todos of a list in their order", data: "todo.json",
+		list: true},
+	{method: http.MethodPut, path: "/lists/:id/order", summary: "Reorder the todos of a list", data: "todo.json", list: true},
+	{method: http.MethodGet, path: "/lists/:id/board", summary: "The board of a list"},
+	{method: http.MethodGet, path: "/lists/:id/integrations", summary: "The webhooks and notifiers of a list",
+		data: "integrations.json"},
+	{method: http.MethodPut, path: "/lists/:id/integrations", summary: "Change the webhooks and notifiers of a list",
+		body: "integrations.json", data: "integrations.json"},
+	{method: http.MethodGet, path: "/lists/:id/todos/:number", summary: "Get a todo by its number in a list",
+		data: "todo.json"},
+	{method: http.MethodGet, path: "/goals", summary: "List the goals", data: "goal.json", list: true},
+	{method: http.MethodPost, path: "/goals", summary: "Create a goal", body: "goal.json", status: http.StatusCreated,
+		data: "goal.json"},
+	{method: http.MethodGet, path: "/goals/:id", summary: "Get a goal", data: "goal.json"},
+	{method: http.MethodPut, path: "/goals/:id", summary: "Update a goal", body: "goal.json", data: "goal.json"},
+	{method: http.MethodDelete, path: "/goals/:id", summary: "Delete a goal, its todos are kept"},
+	{method: http.MethodGet, path: "/goals/:id/todos", summary: "The todos of a goal", data: "todo.json", list: true},
+	{method: http.MethodGet, path: "/habits/:id/calendar", summary: "The month grid of a habit", query: []string{"month"}},
+	{method: http.MethodPost, path: "/habits/:id/done", summary: "Record a habit done", query: []string{"date"}},
+	{method: http.MethodDelete, path: "/habits/:id/done", summary: "Remove the record of a habit done",
+		query: []string{"date"}},
+	{method: http.MethodGet, path: "/views/waiting", summary: "The todos waiting on someone", data: "todo.json", list: true},
+	{method: http.MethodGet, path: "/views/stale", summary: "The open todos created long ago", query: []string{"days"},
+		data: "todo.json", list: true},
+	{method: http.MethodGet, path: "/reports/capacity", summary: "The capacity report of a day", query: []string{"date"}},
+	{method: http.MethodGet, path: "/reports/focus", summary: "The focus time of a day", query: []string{"date"}},
+	{method: http.MethodGet, path: "/simple/next", summary: "The next todo for voice assistants"},
+	{method: http.MethodPost, path: "/simple/add", summary: "Add a todo for voice assistants", query: []string{"title"},
+		status: http.StatusCreated},
+	{method: http.MethodPost, path: "/simple/done/:id", summary: "Terminate a todo for voice assistants"},
+}
+
+// swaggerUi enables the Swagger UI at /docs, set by TODO_SWAGGER_UI
+var swaggerUi bool
+
+func configureApiDocs() {
+	swaggerUi = os.Getenv("TODO_SWAGGER_UI") == "true"
+}
+
+// OpenApiGet Handler for the OpenAPI document action, it describes the routes with their bodies and responses
+// GET /openapi.json
+func OpenApiGet(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(openApiDocument())
+}
+
+// ApiDocsGet Handler for the Swagger UI action, the UI is loaded from unpkg.com and shows /openapi.json
+// GET /docs
+func ApiDocsGet(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+	if swaggerUi == false {
+		return writeError(writer, http.StatusNotFound, models.CodeRouteNotFound, "Route Not Found")
+	}
+	writer.Header().Set("Content-Type", "text/html; charset=UTF-8")
+	writer.WriteHeader(http.StatusOK)
+	_, err := writer.Write([]byte(swaggerUiPage))
+	return err
+}
+
+const swaggerUiPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Todo REST API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>SwaggerUIBundle({url: "openapi.json", dom_id: "#swagger-ui"});</script>
+</body>
+</html>
+`
+
+// openApiDocument builds the OpenAPI 3.1 document of the operations, the schemas of the bodies are the
+// JSON Schemas of the schemas package
+func openApiDocument() map[string]interface{} {
+	paths := map[string]map[string]interface{}{}
+	for _, operation := range apiOperations {
+		path := openApiPath(operation.path)
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(operation.method)] = openApiOperation(operation)
+	}
+
+	components := map[string]interface{}{}
+	for _, name := range schemas.Names() {
+		components[schemaComponent(name)] = componentSchema(name)
+	}
+	return map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":   "Todo REST API",
+			"version": "1",
+			"description": "Successful responses wrap their data as {\"meta\": ..., \"data\": ...}, " +
+				"errors are {\"error\": {\"status\": ..., \"code\": ..., \"title\": ...}}.",
+		},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": components},
+	}
+}
+
+// openApiMeta is the meta information of the responses, its fields depend on the operation except the warnings
+var openApiMeta = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"warnings": map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"code":    map[string]interface{}{"type": "string", "enum": []string{WarningDeprecated, WarningUnknownParameter}},
+					"message": map[string]interface{}{"type": "string"},
+				},
+			},
+		},
+	},
+}
+
+func openApiOperation(operation apiOperation) map[string]interface{} {
+	var parameters []interface{}
+	for _, segment := range strings.Split(operation.path, "/") {
+		if strings.HasPrefix(segment, ":") {
+			parameters = append(parameters, map[string]interface{}{"name": segment[1:], "in": "path", "required": true,
+				"schema": map[string]interface{}{"type": "string"}})
+		}
+	}
+	deprecated := map[string]bool{}
+	for _, deprecation := range operation.deprecations {
+		deprecated[deprecation.param] = true
+	}
+	boolean := map[string]bool{}
+	for _, name := range operation.booleans {
+		boolean[name] = true
+	}
+	for _, name := range operation.query {
+		parameter := map[string]interface{}{"name": name, "in": "query", "schema": map[string]interface{}{"type": "string"}}
+		if boolean[name] {
+			parameter["schema"] = map[string]interface{}{"type": "boolean"}
+		}
+		if deprecated[name] {
+			parameter["deprecated"] = true
+		}
+		parameters = append(parameters, parameter)
+	}
+
+	data := map[string]interface{}{}
+	if operation.data != "" {
+		data = componentRef(operation.data)
+		if operation.list {
+			data = map[string]interface{}{"type": "array", "items": data}
+		}
+	}
+	status := operation.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	envelope := map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"meta": openApiMeta, "data": data},
+	}
+	result := map[string]interface{}{
+		"summary": operation.summary,
+		"responses": map[string]interface{}{
+			strconv.Itoa(status): map[string]interface{}{
+				"description": http.StatusText(status),
+				"content":     map[string]interface{}{"application/json": map[string]interface{}{"schema": envelope}},
+			},
+			"default": map[string]interface{}{
+				"description": "Error",
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": componentRef("error.json")},
+				},
+			},
+		},
+	}
+	if len(parameters) > 0 {
+		result["parameters"] = parameters
+	}
+	if deprecated[""] {
+		result["deprecated"] = true
+	}
+	if operation.body != "" {
+		result["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  map[string]interface{}{"application/json": map[string]interface{}{"schema": componentRef(operation.body)}},
+		}
+	}
+	return result
+}
+
+// openApiPath writes the parameters of a route path as {name}
+func openApiPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// schemaComponent names the component of a schema file, e.g. "Todo" for "todo.json"
+func schemaComponent(name string) string {
+	name = strings.TrimSuffix(name, ".json")
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func componentRef(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + schemaComponent(name)}
+}
+
+// componentSchema returns the schema of the file without $schema and $id, its references to other files
+// point to their components
+func componentSchema(name string) interface{} {
+	content, _ := schemas.Get(name)
+	var schema map[string]interface{}
+	json.Unmarshal(content, &schema)
+	delete(schema, "$schema")
+	delete(schema, "$id")
+	return rewriteRefs(schema)
+}
+
+func rewriteRefs(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			if ref, ok := child.(string); ok && key == "$ref" {
+				value[key] = componentRef(ref)["$ref"]
+				continue
+			}
+			value[key] = rewriteRefs(child)
+		}
+	case []interface{}:
+		for i, child := range value {
+			value[i] = rewriteRefs(child)
+		}
+	}
+	return value
+}
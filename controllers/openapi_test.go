@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// servedBy tells whether the route serves the path, directly or as one of its sub routes like /todos/poll of /todos/:id
+func servedBy(method string, path string, routeMethod string, routePath string) bool {
+	if method != routeMethod {
+		return false
+	}
+	if path == routePath {
+		return true
+	}
+	parent, segment, found := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return found && strings.HasPrefix(segment, ":") == false && strings.Contains(segment, "/") == false &&
+		routePath == "/"+parent+"/:id"
+}
+
+func TestOpenApiGet_DescribesEveryRoute(t *testing.T) {
+	// Arrange
+	//
+	recorder := httptest.NewRecorder()
+	var document struct {
+		OpenApi    string                            `json:"openapi"`
+		Paths      map[string]map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+
+	// Act
+	//
+	OpenApiGet(recorder, httptest.NewRequest(http.MethodGet, "/openapi.json", nil), nil)
+	errDecoded := json.NewDecoder(recorder.Body).Decode(&document)
+
+	// Assert
+	//
+	for _, route := range routes() {
+		documented := false
+		for _, operation := range apiOperations {
+			documented = documented || servedBy(operation.method, operation.path, route.method, route.path)
+		}
+		if documented == false {
+			t.Error("Fehler, not documented:", route.method, route.path)
+		}
+	}
+	for _, operation := range apiOperations {
+		served := false
+		for _, route := range routes() {
+			served = served || servedBy(operation.method, operation.path, route.method, route.path)
+		}
+		if served == false {
+			t.Error("Fehler, no route:", operation.method, operation.path)
+		}
+	}
+	if errDecoded != nil || document.OpenApi != "3.1.0" || document.Paths["/todos/{id}"]["put"] == nil {
+		t.Error("Fehler", errDecoded, document.OpenApi)
+	}
+	if strings.Contains(string(document.Components.Schemas["Todo"]), `"$ref":"#/components/schemas/Item"`) == false {
+		t.Error("Fehler", string(document.Components.Schemas["Todo"]))
+	}
+}
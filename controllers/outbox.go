@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"os"
+	"strconv"
+	"todo-rest-backend/models"
+	"todo-rest-backend/plugins"
+)
+
+// webhookOutboxFileName keeps the events for the webhook until it accepted them, below the data directory
+const webhookOutboxFileName = "outbox-webhook.json"
+
+// defaultWebhookMaxAttempts gives up an event after about an hour of retries
+const defaultWebhookMaxAttempts = 12
+
+// webhookMaxAttempts reads how often an event is posted to the webhook before it is given up from
+// TODO_WEBHOOK_MAX_ATTEMPTS, 0 retries until the webhook accepts it
+func webhookMaxAttempts() (int, error) {
+	value := os.Getenv("TODO_WEBHOOK_MAX_ATTEMPTS")
+	if value == "" {
+		return defaultWebhookMaxAttempts, nil
+	}
+	attempts, err := strconv.Atoi(value)
+	if err != nil || attempts < 0 {
+		return 0, errors.New("TODO_WEBHOOK_MAX_ATTEMPTS must be a number of attempts like 12")
+	}
+	return attempts, nil
+}
+
+// OutboxGet Handler for the delivery status of the outboxes, it lists the events not delivered yet
+// GET /admin/outbox
+func OutboxGet(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+	response := models.JsonExtendedResponse{Data: plugins.Outboxes()}
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// DeadLettersGet Handler for the dead letters, the events the outboxes gave up after their last attempt
+// GET /admin/dlq
+func DeadLettersGet(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+	response := models.JsonExtendedResponse{Data: plugins.DeadLetters()}
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// DeadLetterRedeliverPost Handler for the redeliver action, the dead letter is delivered again by its outbox
+// POST /admin/dlq/:id/redeliver
+func DeadLetterRedeliverPost(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	letter, err := plugins.Redeliver(params.ByName("id"))
+	if errors.Is(err, plugins.ErrUnknownDeadLetter) {
+		return writeError(writer, http.StatusNotFound, models.CodeNotFound, "Dead Letter Not Found")
+	}
+	if err != nil {
+		return err
+	}
+
+	response := models.JsonExtendedResponse{Data: letter}
+	writer.WriteHeader(http.StatusAccepted)
+	return json.NewEncoder(writer).Encode(response)
+}
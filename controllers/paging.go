@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"todo-rest-backend/models"
+)
+
+// defaultPerPage is the page size of requests giving a page without per_page
+const defaultPerPage = 50
+
+// maxPerPage limits the page size
+const maxPerPage = 500
+
+// ListMeta is the meta information of the todo list, the pagination fields are set for paginated requests
+type ListMeta struct {
+	Revision uint64 `json:"revision"`
+	Total    int    `json:"total"`
+	Page     int    `json:"page,omitempty"`
+	PerPage  int    `json:"per_page,omitempty"`
+	Pages    int    `json:"pages,omitempty"`
+	// Next and Prev are the links to the neighbouring pages, omitted on the last and the first page
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// pageQuery is the requested page, a zero page returns all todos
+type pageQuery struct {
+	page    int
+	perPage int
+}
+
+// parsePageQuery reads page and per_page, giving either of them paginates the list
+func parsePageQuery(query url.Values) (pageQuery, error) {
+	if query.Get("page") == "" && query.Get("per_page") == "" {
+		return pageQuery{}, nil
+	}
+
+	p := pageQuery{page: 1, perPage: defaultPerPage}
+	var err error
+	if query.Get("page") != "" {
+		p.page, err = strconv.Atoi(query.Get("page"))
+		if err != nil || p.page < 1 {
+			return pageQuery{}, errors.New("page must be a positive number")
+		}
+	}
+	if query.Get("per_page") != "" {
+		p.perPage, err = strconv.Atoi(query.Get("per_page"))
+		if err != nil || p.perPage < 1 || p.perPage > maxPerPage {
+			return pageQuery{}, errors.New("per_page must be between 1 and 500")
+		}
+	}
+	return p, nil
+}
+
+// apply returns the todos of the page and the meta information with the links to the neighbouring pages
+func (p pageQuery) apply(request *http.Request, todos []models.Todo) ([]models.Todo, ListMeta) {
+	meta := ListMeta{Revision: models.Revision(), Total: len(todos)}
+	if p.page == 0 {
+		return todos, meta
+	}
+
+	meta.Page, meta.PerPage = p.page, p.perPage
+	meta.Pages = (len(todos) + p.perPage - 1) / p.perPage
+	if p.page < meta.Pages {
+		meta.Next = pageLink(request, p.page+1)
+	}
+	if p.page > 1 {
+		meta.Prev = pageLink(request, min(p.page-1, max(meta.Pages, 1)))
+	}
+
+	start := min((p.page-1)*p.perPage, len(todos))
+	end := min(start+p.perPage, len(todos))
+	return todos[start:end], meta
+}
+
+// pageLink is the path and query of the request with another page
+func pageLink(request *http.Request, page int) string {
+	query := request.URL.Query()
+	query.Set("page", strconv.Itoa(page))
+	return request.URL.Path + "?" + query.Encode()
+}
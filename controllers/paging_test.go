@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-rest-backend/models"
+)
+
+func TestPageQuery_Apply(t *testing.T) {
+	// Arrange
+	//
+	todos := []models.Todo{{Id: "0"}, {Id: "1"}, {Id: "2"}, {Id: "3"}, {Id: "4"}}
+	request := httptest.NewRequest(http.MethodGet, "/todos?page=2&per_page=2&sort=title", nil)
+	page, err := parsePageQuery(request.URL.Query())
+	if err != nil {
+		t.Fatal("Fehler", err)
+	}
+
+	// Act
+	//
+	got, meta := page.apply(request, todos)
+
+	// Assert
+	//
+	if len(got) != 2 || got[0].Id != "2" || got[1].Id != "3" {
+		t.Error("Fehler", got)
+	}
+	if meta.Total != 5 || meta.Pages != 3 {
+		t.Error("Fehler", meta)
+	}
+	if meta.Next != "/todos?page=3&per_page=2&sort=title" || meta.Prev != "/todos?page=1&per_page=2&sort=title" {
+		t.Error("Fehler", meta.Next, meta.Prev)
+	}
+}
+
+func TestParsePageQuery_RejectsInvalidPages(t *testing.T) {
+	for _, query := range []string{"page=0", "page=x", "per_page=0", "per_page=501"} {
+		// Act
+		//
+		_, err := parsePageQuery(httptest.NewRequest(http.MethodGet, "/todos?"+query, nil).URL.Query())
+
+		// Assert
+		//
+		if err == nil {
+			t.Error("Fehler", query)
+		}
+	}
+}
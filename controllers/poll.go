@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"todo-rest-backend/models"
+)
+
+// defaultPollTimeout is how long a poll waits for a change without timeout parameter
+const defaultPollTimeout = 30 * time.Second
+
+// maxPollTimeout limits the wait of a poll, proxies tend to cut off longer requests
+const maxPollTimeout = 2 * time.Minute
+
+// PollResult is the answer of a poll, Changed tells whether the revision passed since before the timeout
+type PollResult struct {
+	Revision uint64 `json:"revision"`
+	Changed  bool   `json:"changed"`
+}
+
+// pollsStopped is closed on shutdown, the waiting polls are answered then
+var pollsStopped = make(chan struct{})
+var pollsMutex sync.Mutex
+
+// stopPolls answers the waiting polls, so that the shutdown does not wait for their timeouts
+func stopPolls() {
+	pollsMutex.Lock()
+	defer pollsMutex.Unlock()
+	close(pollsStopped)
+	pollsStopped = make(chan struct{})
+}
+
+// TodosPollGet Handler for the poll action, it answers as soon as the revision of the store passed since or
+// when the timeout elapsed, the store is not held while waiting. Without since it waits for the next change.
+// GET /todos/poll?since=12&timeout=30s
+func TodosPollGet(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) error {
+	query := request.URL.Query()
+	since := models.Revision()
+	if value := query.Get("since"); value != "" {
+		var err error
+		since, err = strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return writeError(writer, http.StatusBadRequest, models.CodeInvalidRequest, "Invalid Since")
+		}
+	}
+	timeout := defaultPollTimeout
+	if value := query.Get("timeout"); value != "" {
+		var err error
+		timeout, err = time.ParseDuration(value)
+		if err != nil || timeout < 0 || timeout > maxPollTimeout {
+			return writeError(writer, http.StatusBadRequest, models.CodeInvalidRequest, "Invalid Timeout")
+		}
+	}
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	pollsMutex.Lock()
+	stopped := pollsStopped
+	pollsMutex.Unlock()
+	for waiting := true; waiting && models.Revision() <= since; {
+		change := models.RevisionChanged()
+		withoutStore(request, func() {
+			select {
+			case <-change:
+			case <-deadline.C:
+				waiting = false
+			case <-stopped:
+				waiting = false
+			case <-request.Context().Done():
+				waiting = false
+			}
+		})
+	}
+
+	revision := models.Revision()
+	response := models.JsonExtendedResponse{Data: PollResult{Revision: revision, Changed: revision > since}}
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-rest-backend/models"
+)
+
+func TestTodosPollGet_AnswersOnChange(t *testing.T) {
+	// Arrange
+	//
+	router := httprouter.New()
+	RegisterRoutes(HttpRouter{Router: router})
+	since := models.Revision()
+	polled := make(chan *httptest.ResponseRecorder)
+	go func() {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/todos/poll?since=%d&timeout=10s", since), nil))
+		polled <- recorder
+	}()
+
+	// Act
+	//
+	// the change is only possible while the poll does not hold the store
+	created := httptest.NewRecorder()
+	router.ServeHTTP(created, httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(`{"title": "Sort the mail"}`)))
+	recorder := <-polled
+
+	// Assert
+	//
+	var response struct {
+		Data PollResult `json:"data"`
+	}
+	json.NewDecoder(recorder.Body).Decode(&response)
+	if created.Code != http.StatusCreated || response.Data.Changed == false || response.Data.Revision <= since {
+		t.Error("Fehler", created.Code, recorder.Code, response.Data)
+	}
+}
+
+func TestTodosPollGet_AnswersOnTimeout(t *testing.T) {
+	// Arrange
+	//
+	router := httprouter.New()
+	RegisterRoutes(HttpRouter{Router: router})
+	recorder := httptest.NewRecorder()
+
+	// Act
+	//
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/todos/poll?timeout=10ms", nil))
+
+	// Assert
+	//
+	var response struct {
+		Data PollResult `json:"data"`
+	}
+	json.NewDecoder(recorder.Body).Decode(&response)
+	if recorder.Code != http.StatusOK || response.Data.Changed || response.Data.Revision != models.Revision() {
+		t.Error("Fehler", recorder.Code, response.Data)
+	}
+	if recorder.Header().Get("Cache-Control") != "no-store" {
+		t.Error("Fehler", recorder.Header())
+	}
+}
@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"time"
+	"todo-rest-backend/models"
+)
+
+// PomodoroAction is the body of the pomodoro action, Action is "start" or "stop"
+type PomodoroAction struct {
+	Action string `json:"action"`
+}
+
+// PomodoroSummary counts the focus sessions of a todo
+type PomodoroSummary struct {
+	TodoId            string                   `json:"todo_id"`
+	Sessions          int                      `json:"sessions"`
+	CompletedSessions int                      `json:"completed_sessions"`
+	FocusMinutes      int                      `json:"focus_minutes"`
+	Running           *models.PomodoroSession  `json:"running,omitempty"`
+	History           []models.PomodoroSession `json:"history,omitempty"`
+}
+
+// FocusReport sums the focus time of a day, per todo in the order of the todo ids
+type FocusReport struct {
+	Date              string            `json:"date"`
+	Sessions          int               `json:"sessions"`
+	CompletedSessions int               `json:"completed_sessions"`
+	FocusMinutes      int               `json:"focus_minutes"`
+	Todos             []PomodoroSummary `json:"todos"`
+}
+
+// summarizePomodoros counts the sessions and adds up their focus time
+func summarizePomodoros(todoId string, sessions []models.PomodoroSession) PomodoroSummary {
+	summary := PomodoroSummary{TodoId: todoId, History: sessions}
+	var focusTime time.Duration
+	for _, session := range sessions {
+		summary.Sessions++
+		if session.Completed {
+			summary.CompletedSessions++
+		}
+		if session.EndedAt == nil {
+			running := session
+			summary.Running = &running
+		}
+		focusTime += session.FocusTime()
+	}
+	summary.FocusMinutes = int(focusTime.Minutes())
+	return summary
+}
+
+// focusReportOf groups the sessions started on the date by todo, sessions of deleted todos have an empty todo id
+func focusReportOf(date string) FocusReport {
+	report := FocusReport{Date: date, Todos: []PomodoroSummary{}}
+	sessionsByTodo := make(map[string][]models.PomodoroSession)
+	var todos []models.Todo
+	var focusTime time.Duration
+	for _, session := range models.PomodoroSessionsOn(date) {
+		if _, ok := sessionsByTodo[session.TodoId]; ok == false {
+			todos = append(todos, models.Todo{Id: session.TodoId})
+		}
+		sessionsByTodo[session.TodoId] = append(sessionsByTodo[session.TodoId], session)
+
+		report.Sessions++
+		if session.Completed {
+			report.CompletedSessions++
+		}
+		focusTime += session.FocusTime()
+	}
+	report.FocusMinutes = int(focusTime.Minutes())
+
+	for _, todo := range sortTodosAfterIdAscending(todos) {
+		summary := summarizePomodoros(todo.Id, sessionsByTodo[todo.Id])
+		summary.History = nil
+		report.Todos = append(report.Todos, summary)
+	}
+	return report
+}
+
+// TodoPomodoroGet Handler for the focus sessions of a todo action
+// GET /todos/:id/pomodoro
+func TodoPomodoroGet(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	id := params.ByName("id")
+	if _, ok := models.FindTodo(id); ok == false {
+		return handleTodoIdNotFound(writer)
+	}
+
+	response := models.JsonExtendedResponse{Data: summarizePomodoros(id, models.PomodoroSessionsOf(id))}
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// TodoPomodoroPost Handler for starting and stopping a focus session on a todo, the body is
+// {"action": "start"} or {"action": "stop"}. A session ends by itself after 25 minutes.
+// POST /todos/:id/pomodoro
+func TodoPomodoroPost(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	id := params.ByName("id")
+	if _, ok := models.FindTodo(id); ok == false {
+		return handleTodoIdNotFound(writer)
+	}
+
+	var action PomodoroAction
+	if request.Body == nil || json.NewDecoder(request.Body).Decode(&action) != nil {
+		return handleTodoNotProperlyTransmitted(writer)
+	}
+
+	var session models.PomodoroSession
+	var err error
+	switch action.Action {
+	case "start":
+		session, err = models.StartPomodoro(id)
+	case "stop":
+		session, err = models.StopPomodoro(id)
+	default:
+		return handleTodoNotProperlyTransmittedGeneral(writer, "Action Must Be Start Or Stop")
+	}
+	switch {
+	case errors.Is(err, models.ErrPomodoroRunning):
+		return writeError(writer, http.StatusConflict, models.CodeStateConflict, "Pomodoro Already Running")
+	case errors.Is(err, models.ErrNoPomodoroRunning):
+		return writeError(writer, http.StatusConflict, models.CodeStateConflict, "No Pomodoro Running")
+	case errors.Is(err, models.ErrPomodoroTerminated):
+		return writeError(writer, http.StatusConflict, models.CodeStateConflict, "Todo Already Terminated")
+	case err != nil:
+		return err
+	}
+
+	response := models.JsonExtendedResponse{Data: session}
+	err = models.UpdateDataInFile()
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// FocusReportGet Handler for the daily focus time report action, the date defaults to today
+// GET /reports/focus?date=2006-01-02
+func FocusReportGet(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	date := request.URL.Query().Get("date")
+	if date == "" {
+		date = models.Today()
+	}
+	if _, err := time.Parse(models.DateFormat, date); err != nil {
+		return handleTodoNotProperlyTransmittedGeneral(writer, "Date Must Have The Form 2006-01-02")
+	}
+
+	response := models.JsonExtendedResponse{Data: focusReportOf(date)}
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"os"
+	"todo-rest-backend/models"
+)
+
+// readinessPath is answered while the todos are loading, other requests are answered with 503 until then
+const readinessPath = "/readyz"
+
+// initializeStore loads the todos. With TODO_BACKGROUND_LOADING=true the server starts right away and loads
+// them in the background, the store mutex is held until they are loaded so the background jobs wait for them.
+func initializeStore() {
+	if os.Getenv("TODO_BACKGROUND_LOADING") != "true" {
+		models.Initialize()
+		return
+	}
+
+	models.BeginWarmup()
+	storeMutex.Lock()
+	go func() {
+		defer storeMutex.Unlock()
+		models.Initialize()
+		invalidateResponseCache()
+	}()
+}
+
+// rejectWhileLoading answers with 503 Service Unavailable until the todos are loaded
+func rejectWhileLoading(writer http.ResponseWriter, request *http.Request) bool {
+	if models.Ready() {
+		return false
+	}
+	writer.Header().Set("Retry-After", "1")
+	logFailure(request, writeError(writer, http.StatusServiceUnavailable, models.CodeUnavailable, "Todos Are Loading"))
+	return true
+}
+
+// ReadyzGet Handler for the readiness action, 200 OK once the todos are loaded and 503 with the progress before
+// GET /readyz
+func ReadyzGet(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+	status := models.Warmup()
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	if status.Phase == models.WarmupReady {
+		writer.WriteHeader(http.StatusOK)
+	} else {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}
+	return json.NewEncoder(writer).Encode(models.JsonExtendedResponse{Data: status})
+}
@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-rest-backend/models"
+)
+
+func TestRoutes_UnavailableWhileLoading(t *testing.T) {
+	// Arrange
+	//
+	router := httprouter.New()
+	RegisterRoutes(HttpRouter{Router: router})
+	models.BeginWarmup()
+	loading, loadingReadiness := httptest.NewRecorder(), httptest.NewRecorder()
+
+	// Act
+	//
+	router.ServeHTTP(loading, httptest.NewRequest(http.MethodGet, "/todos", nil))
+	router.ServeHTTP(loadingReadiness, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	models.Initialize()
+	loaded, loadedReadiness := httptest.NewRecorder(), httptest.NewRecorder()
+	router.ServeHTTP(loaded, httptest.NewRequest(http.MethodGet, "/todos", nil))
+	router.ServeHTTP(loadedReadiness, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	// Assert
+	//
+	if loading.Code != http.StatusServiceUnavailable || loadingReadiness.Code != http.StatusServiceUnavailable {
+		t.Error("Fehler", loading.Code, loadingReadiness.Code)
+	}
+	if loaded.Code != http.StatusOK || loadedReadiness.Code != http.StatusOK {
+		t.Error("Fehler", loaded.Code, loadedReadiness.Code)
+	}
+}
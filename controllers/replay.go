@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"todo-rest-backend/clock"
+	"todo-rest-backend/models"
+)
+
+// RecordedRequest is a request written to the record file, one JSON object per line
+type RecordedRequest struct {
+	Time   time.Time   `json:"time"`
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+	// Status is the status the request was answered with, replays compare it
+	Status int `json:"status"`
+}
+
+// redacted replaces the values of credentials in recorded headers
+const redacted = "[redacted]"
+
+var recordFile *os.File
+var recordMutex sync.Mutex
+
+// configureRecording appends the requests to the file named by TODO_RECORD_FILE, see Replay
+func configureRecording() error {
+	name := os.Getenv("TODO_RECORD_FILE")
+	if name == "" {
+		return nil
+	}
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("cannot open the record file: %w", err)
+	}
+	recordFile = file
+	return nil
+}
+
+// sensitiveHeader tells whether the header carries credentials, they are not recorded
+func sensitiveHeader(name string) bool {
+	name = strings.ToLower(name)
+	switch name {
+	case "authorization", "proxy-authorization", "cookie", "x-api-key":
+		return true
+	}
+	return strings.Contains(name, "token") || strings.Contains(name, "secret") || strings.Contains(name, "signature")
+}
+
+// statusWriter remembers the status of a response
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// responseStatus returns the status of the response of a route written so far, 0 if it was not started
+func responseStatus(writer http.ResponseWriter) int {
+	switch writer := writer.(type) {
+	case *statusWriter:
+		return writer.status
+	case *warningWriter:
+		return writer.status
+	}
+	return 0
+}
+
+// recording writes the requests with their status to the record file, credentials are redacted
+func recording(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if recordFile == nil || request.URL.Path == readinessPath {
+			handler.ServeHTTP(writer, request)
+			return
+		}
+
+		record := RecordedRequest{Time: models.Now().UTC(), Method: request.Method, Path: request.URL.RequestURI(),
+			Header: http.Header{}}
+		for name, values := range request.Header {
+			if sensitiveHeader(name) {
+				values = []string{redacted}
+			}
+			record.Header[name] = values
+		}
+		if request.Body != nil {
+			body, err := readBody(request)
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				logFailure(request, writeError(writer, http.StatusRequestEntityTooLarge, models.CodeInvalidBody, "Body Too Large"))
+				return
+			}
+			if err != nil {
+				logFailure(request, writeError(writer, http.StatusBadRequest, models.CodeInvalidBody, "Invalid Body"))
+				return
+			}
+			// the bodies hold the todos, they are encrypted like the descriptions in the data files
+			if len(body) > 0 {
+				record.Body = models.Seal(string(body), record.Method+" "+record.Path)
+			}
+			request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		status := &statusWriter{ResponseWriter: writer}
+		defer func() {
+			record.Status = status.status
+			line, _ := json.Marshal(record)
+			recordMutex.Lock()
+			defer recordMutex.Unlock()
+			_, err := recordFile.Write(append(line, '\n'))
+			if err != nil {
+				log.Println("Cannot record the request:", err)
+			}
+		}()
+		handler.ServeHTTP(status, request)
+	})
+}
+
+// Replay re-executes the recorded requests against a fresh in-memory store, with the store clock following
+// the times of the recording. Every request is reported to output with its status, requests answered with
+// another status than recorded are counted as mismatches.
+// Replay is meant for a process without todos, like the -replay command, so that the ids are assigned as recorded.
+func Replay(input io.Reader, output io.Writer) (mismatches int, err error) {
+	err = Configure(models.NewMemoryRepository(), false)
+	if err != nil {
+		return 0, err
+	}
+	// replayed requests are not recorded again
+	if recordFile != nil {
+		recordFile.Close()
+		recordFile = nil
+	}
+	handler, err := newHandler(os.Getenv("TODO_ROUTER"))
+	if err != nil {
+		return 0, err
+	}
+	handler = normalizedPaths(handler)
+
+	var fake *clock.Fake
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(nil, 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var record RecordedRequest
+		err = json.Unmarshal(scanner.Bytes(), &record)
+		if err != nil {
+			return mismatches, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if fake == nil {
+			fake = clock.NewFake(record.Time)
+			models.SetClock(fake)
+		}
+		if passed := record.Time.Sub(fake.Now()); passed > 0 {
+			fake.Advance(passed)
+		}
+
+		body, err := models.Open(record.Body, record.Method+" "+record.Path)
+		if err != nil {
+			return mismatches, fmt.Errorf("line %d: %w", line, err)
+		}
+		request := httptest.NewRequest(record.Method, record.Path, strings.NewReader(body))
+		for name, values := range record.Header {
+			request.Header[name] = values
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		result := "ok"
+		if recorder.Code != record.Status {
+			result = fmt.Sprintf("MISMATCH, recorded %d: %s", record.Status, strings.TrimSpace(recorder.Body.String()))
+			mismatches++
+		}
+		fmt.Fprintf(output, "%d %s %s %d %s\n", line, record.Method, record.Path, recorder.Code, result)
+	}
+	return mismatches, scanner.Err()
+}
+
+// ReplayFile replays the requests recorded in the file, see Replay
+func ReplayFile(name string, output io.Writer) (mismatches int, err error) {
+	file, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, fmt.Errorf("record file %s does not exist", name)
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	return Replay(file, output)
+}
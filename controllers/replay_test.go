@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"todo-rest-backend/clock"
+	"todo-rest-backend/models"
+)
+
+func TestReplay_ReproducesRecordedRequests(t *testing.T) {
+	// Arrange
+	//
+	models.SetRepository(models.NewMemoryRepository())
+	defer models.SetRepository(models.NewMemoryRepository())
+	defer models.SetClock(clock.NewSystem())
+	name := filepath.Join(t.TempDir(), "requests.jsonl")
+	t.Setenv("TODO_RECORD_FILE", name)
+	err := configureRecording()
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler, _ := newHandler("")
+	handler = recording(handler)
+	for _, request := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(`{"title": "Buy milk"}`)),
+		httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(`{"title": 42}`)),
+		httptest.NewRequest(http.MethodGet, "/todos?sort=title", nil),
+		httptest.NewRequest(http.MethodDelete, "/todos/999999", nil),
+	} {
+		request.Header.Set("X-Api-Key", "secret")
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+	}
+	recordFile.Close()
+	recordFile = nil
+	t.Setenv("TODO_RECORD_FILE", "")
+	content, _ := os.ReadFile(name)
+
+	// Act
+	//
+	var output bytes.Buffer
+	mismatches, err := Replay(bytes.NewReader(content), &output)
+
+	// Assert
+	//
+	if err != nil || mismatches != 0 || strings.Count(output.String(), " ok\n") != 4 {
+		t.Error("Fehler", err, mismatches, output.String())
+	}
+	if strings.Contains(string(content), "secret") {
+		t.Error("Fehler: the api key was recorded")
+	}
+}
+
+func TestReplay_DecryptsTheRecordedBodies(t *testing.T) {
+	// Arrange
+	//
+	models.SetRepository(models.NewMemoryRepository())
+	defer models.SetRepository(models.NewMemoryRepository())
+	defer models.SetClock(clock.NewSystem())
+	defer models.SetEncryptionKeys("", nil)
+	models.SetEncryptionKeys("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=", nil)
+	name := filepath.Join(t.TempDir(), "requests.jsonl")
+	t.Setenv("TODO_RECORD_FILE", name)
+	err := configureRecording()
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler, _ := newHandler("")
+	recording(handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/todos",
+		strings.NewReader(`{"title": "Call the doctor", "description": "Results of the blood test"}`)))
+	recordFile.Close()
+	recordFile = nil
+	t.Setenv("TODO_RECORD_FILE", "")
+	content, _ := os.ReadFile(name)
+
+	// Act
+	//
+	var output bytes.Buffer
+	mismatches, err := Replay(bytes.NewReader(content), &output)
+
+	// Assert
+	//
+	if strings.Contains(string(content), "blood test") || strings.Contains(string(content), "doctor") {
+		t.Error("Fehler, the body is not encrypted:", string(content))
+	}
+	if err != nil || mismatches != 0 || strings.Count(output.String(), " ok\n") != 1 {
+		t.Error("Fehler", err, mismatches, output.String())
+	}
+}
+
+func TestRecording_RejectsBodiesLargerThanTheLimit(t *testing.T) {
+	// Arrange
+	//
+	name := filepath.Join(t.TempDir(), "requests.jsonl")
+	t.Setenv("TODO_RECORD_FILE", name)
+	err := configureRecording()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		recordFile.Close()
+		recordFile = nil
+	}()
+	handled := false
+	handler := recording(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { handled = true }))
+	recorder := httptest.NewRecorder()
+	body := `{"title": "` + strings.Repeat("x", maxBodySize) + `"}`
+
+	// Act
+	//
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(body)))
+	content, _ := os.ReadFile(name)
+
+	// Assert
+	//
+	if recorder.Code != http.StatusRequestEntityTooLarge || handled || len(content) != 0 {
+		t.Error("Fehler", recorder.Code, handled, len(content))
+	}
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+	"todo-rest-backend/models"
+)
+
+// defaultDailyCapacity is the working time of a day in minutes without configuration
+const defaultDailyCapacity = 480
+
+var dailyCapacity = defaultDailyCapacity
+
+// CapacityReport compares the estimated effort of the open todos due on a day with the daily capacity
+type CapacityReport struct {
+	Date            string `json:"date"`
+	CapacityMinutes int    `json:"capacity_minutes"`
+	PlannedMinutes  int    `json:"planned_minutes"`
+	// RemainingMinutes is negative if the day is overcommitted
+	RemainingMinutes int  `json:"remaining_minutes"`
+	Overcommitted    bool `json:"overcommitted"`
+	// TodoIds are the open todos due on the day, UnestimatedIds those of them without estimate
+	TodoIds        []string `json:"todo_ids"`
+	UnestimatedIds []string `json:"unestimated_ids"`
+}
+
+// configureReports reads the daily capacity in minutes from TODO_DAILY_CAPACITY_MINUTES
+func configureReports() error {
+	value := os.Getenv("TODO_DAILY_CAPACITY_MINUTES")
+	if value == "" {
+		return nil
+	}
+
+	capacity, err := strconv.Atoi(value)
+	if err != nil || capacity < 0 {
+		return errors.New("TODO_DAILY_CAPACITY_MINUTES must be a number of minutes")
+	}
+	dailyCapacity = capacity
+	return nil
+}
+
+// capacityOf sums the estimates of the open todos due on the date
+func capacityOf(date string) CapacityReport {
+	report := CapacityReport{Date: date, CapacityMinutes: dailyCapacity, TodoIds: []string{}, UnestimatedIds: []string{}}
+
+	var due []models.Todo
+	for _, todo := range models.AllTodos() {
+		if todo.Terminated == false && todo.DueDay() == date {
+			due = append(due, todo)
+		}
+	}
+	for _, todo := range sortTodosAfterIdAscending(due) {
+		report.TodoIds = append(report.TodoIds, todo.Id)
+		if todo.EstimateMinutes == 0 {
+			report.UnestimatedIds = append(report.UnestimatedIds, todo.Id)
+		}
+		report.PlannedMinutes += todo.EstimateMinutes
+	}
+
+	report.RemainingMinutes = report.CapacityMinutes - report.PlannedMinutes
+	report.Overcommitted = report.RemainingMinutes < 0
+	return report
+}
+
+// CapacityReportGet Handler for the capacity report action, the date defaults to today
+// GET /reports/capacity?date=2006-01-02
+func CapacityReportGet(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	date := request.URL.Query().Get("date")
+	if date == "" {
+		date = models.Today()
+	}
+	if _, err := time.Parse(models.DateFormat, date); err != nil {
+		return handleTodoNotProperlyTransmittedGeneral(writer, "Date Must Have The Form 2006-01-02")
+	}
+
+	response := models.JsonExtendedResponse{Data: capacityOf(date)}
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"testing"
+	"todo-rest-backend/models"
+)
+
+func TestCapacityOf_Overcommitted(t *testing.T) {
+	// Arrange
+	//
+	models.DeleteAllTodos()
+	defer models.DeleteAllTodos()
+	models.AddTodo(models.Todo{Title: "Report", DueDate: "2024-05-06", EstimateMinutes: 300})
+	models.AddTodo(models.Todo{Title: "Review", DueDate: "2024-05-06", EstimateMinutes: 240})
+	models.AddTodo(models.Todo{Title: "Call", DueDate: "2024-05-06"})
+	models.AddTodo(models.Todo{Title: "Done", DueDate: "2024-05-06", EstimateMinutes: 60, Terminated: true})
+	models.AddTodo(models.Todo{Title: "Later", DueDate: "2024-05-07", EstimateMinutes: 60})
+
+	// Act
+	//
+	report := capacityOf("2024-05-06")
+
+	// Assert
+	//
+	if report.PlannedMinutes != 540 || report.RemainingMinutes != dailyCapacity-540 || report.Overcommitted == false {
+		t.Error("Fehler", report)
+	}
+	if len(report.TodoIds) != 3 || len(report.UnestimatedIds) != 1 {
+		t.Error("Fehler", report)
+	}
+}
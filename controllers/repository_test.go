@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-rest-backend/models"
+)
+
+// fakeRepository records the todos added by the handlers
+type fakeRepository struct {
+	*models.MemoryRepository
+	added []models.Todo
+}
+
+func (r *fakeRepository) Add(todo models.Todo) error {
+	r.added = append(r.added, todo)
+	return r.MemoryRepository.Add(todo)
+}
+
+func TestTodoPost_UsesRepository(t *testing.T) {
+	// Arrange
+	//
+	repository := &fakeRepository{MemoryRepository: models.NewMemoryRepository()}
+	models.SetRepository(repository)
+	defer models.SetRepository(models.NewMemoryRepository())
+	request := httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(`{"title": "Buy milk"}`))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	//
+	TodoPost(recorder, request, nil)
+
+	// Assert
+	//
+	if recorder.Code != http.StatusCreated || len(repository.added) != 1 || repository.added[0].Title != "Buy milk" {
+		t.Fatal("Fehler", recorder.Code, repository.added)
+	}
+	if _, ok := models.FindTodo(repository.added[0].Id); ok == false {
+		t.Error("Fehler", repository.List())
+	}
+}
@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+	"todo-rest-backend/jobs"
+	"todo-rest-backend/models"
+	"todo-rest-backend/plugins"
+)
+
+// Retention modes
+const (
+	RetentionArchive = "archive"
+	RetentionPurge   = "purge"
+)
+
+// RetentionPolicy removes todos completed more than Days days ago
+type RetentionPolicy struct {
+	Days int `json:"days"`
+	// Mode is "archive" to append the removed todos to the archive file or "purge" to drop them
+	Mode string `json:"mode"`
+	// DryRun only reports the todos the scheduled runs would remove
+	DryRun   bool          `json:"dry_run"`
+	Interval time.Duration `json:"-"`
+}
+
+// RetentionReport describes a single run of the retention policy
+type RetentionReport struct {
+	RunAt   time.Time `json:"run_at"`
+	DryRun  bool      `json:"dry_run"`
+	TodoIds []string  `json:"todo_ids"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// RetentionStatus is returned by the retention status action
+type RetentionStatus struct {
+	Enabled       bool             `json:"enabled"`
+	Policy        *RetentionPolicy `json:"policy,omitempty"`
+	Interval      string           `json:"interval,omitempty"`
+	NextRunAt     *time.Time       `json:"next_run_at,omitempty"`
+	Runs          int              `json:"runs"`
+	TodosArchived int              `json:"todos_archived"`
+	TodosPurged   int              `json:"todos_purged"`
+	LastRun       *RetentionReport `json:"last_run,omitempty"`
+}
+
+var retentionPolicy *RetentionPolicy
+var retentionJob *jobs.Job
+var retentionStatus RetentionStatus
+
+// configureRetention starts the retention job if TODO_RETENTION_DAYS is set.
+// TODO_RETENTION_MODE (archive or purge), TODO_RETENTION_INTERVAL (default 1h)
+// and TODO_RETENTION_DRY_RUN complete the policy.
+func configureRetention() error {
+	daysValue := os.Getenv("TODO_RETENTION_DAYS")
+	if daysValue == "" {
+		return nil
+	}
+
+	days, err := strconv.Atoi(daysValue)
+	if err != nil || days < 0 {
+		return errors.New("TODO_RETENTION_DAYS must be a number of days")
+	}
+
+	policy := &RetentionPolicy{Days: days, Mode: os.Getenv("TODO_RETENTION_MODE"), Interval: time.Hour}
+	if policy.Mode == "" {
+		policy.Mode = RetentionArchive
+	}
+	if policy.Mode != RetentionArchive && policy.Mode != RetentionPurge {
+		return errors.New("TODO_RETENTION_MODE must be archive or purge")
+	}
+	if interval := os.Getenv("TODO_RETENTION_INTERVAL"); interval != "" {
+		policy.Interval, err = time.ParseDuration(interval)
+		if err != nil || policy.Interval <= 0 {
+			return errors.New("TODO_RETENTION_INTERVAL must be a positive duration like 30m")
+		}
+	}
+	policy.DryRun = models.ToBool(os.Getenv("TODO_RETENTION_DRY_RUN"))
+
+	retentionPolicy = policy
+	retentionJob = jobs.Start("retention", policy.Interval, func() {
+		storeMutex.Lock()
+		defer storeMutex.Unlock()
+		applyRetention(policy.DryRun)
+		invalidateResponseCache()
+	})
+	return nil
+}
+
+// applyRetention removes the todos completed before the retention period, the store mutex must be held
+func applyRetention(dryRun bool) RetentionReport {
+	report := RetentionReport{RunAt: models.Now(), DryRun: dryRun, TodoIds: []string{}}
+	cutoff := report.RunAt.AddDate(0, 0, -retentionPolicy.Days)
+	isStale := func(todo models.Todo) bool {
+		return todo.DeletedAt == nil && todo.Terminated && todo.CompletedAt != nil && todo.CompletedAt.Before(cutoff)
+	}
+
+	var stale []models.Todo
+	for _, todo := range models.AllTodos() {
+		if isStale(todo) {
+			stale = append(stale, todo)
+		}
+	}
+	for _, todo := range sortTodosAfterIdAscending(stale) {
+		report.TodoIds = append(report.TodoIds, todo.Id)
+	}
+
+	retentionStatus.Runs++
+	retentionStatus.LastRun = &report
+	if dryRun || len(stale) == 0 {
+		return report
+	}
+
+	// The todos stay in the store if they cannot be archived
+	if retentionPolicy.Mode == RetentionArchive {
+		err := models.ArchiveTodos(stale)
+		if err != nil {
+			log.Println("Retention failed:", err)
+			report.Error = err.Error()
+			return report
+		}
+	}
+
+	removed := models.RemoveTodos(isStale)
+	syncRemovedTodos(removed...)
+	for _, todo := range removed {
+		plugins.Emit(plugins.TodoDeleted, todo)
+	}
+	if retentionPolicy.Mode == RetentionArchive {
+		retentionStatus.TodosArchived += len(removed)
+	} else {
+		retentionStatus.TodosPurged += len(removed)
+	}
+
+	err := models.UpdateDataInFile()
+	if err != nil {
+		log.Println("Retention failed:", err)
+		report.Error = err.Error()
+	}
+	return report
+}
+
+// RetentionGet Handler for the retention status action
+// GET /admin/retention
+func RetentionGet(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+	status := retentionStatus
+	status.Enabled = retentionPolicy != nil
+	status.Policy = retentionPolicy
+	if retentionPolicy != nil {
+		status.Interval = retentionPolicy.Interval.String()
+		nextRunAt := retentionJob.NextRun()
+		status.NextRunAt = &nextRunAt
+	}
+
+	response := models.JsonExtendedResponse{Data: status}
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// RetentionRunPost Handler for triggering the retention policy, dry_run=true only reports the affected todos
+// POST /admin/retention/run?dry_run=true
+func RetentionRunPost(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	if retentionPolicy == nil {
+		return handleTodoNotProperlyTransmittedGeneral(writer, "Retention Policy Not Configured")
+	}
+
+	report := applyRetention(models.ToBool(request.URL.Query().Get("dry_run")))
+
+	response := models.JsonExtendedResponse{Data: report}
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-rest-backend/models"
+)
+
+func TestTodosRevisionGet_IncreasesWithChanges(t *testing.T) {
+	// Arrange
+	//
+	var before, after struct {
+		Data models.RevisionMeta `json:"data"`
+	}
+	recorder := httptest.NewRecorder()
+	TodosRevisionGet(recorder, httptest.NewRequest(http.MethodGet, "/todos/revision", nil), nil)
+	json.NewDecoder(recorder.Body).Decode(&before)
+
+	// Act
+	//
+	TodoPost(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(`{"title": "Buy milk"}`)), nil)
+	recorder = httptest.NewRecorder()
+	TodosRevisionGet(recorder, httptest.NewRequest(http.MethodGet, "/todos/revision", nil), nil)
+	json.NewDecoder(recorder.Body).Decode(&after)
+
+	// Assert
+	//
+	if after.Data.Revision != before.Data.Revision+1 {
+		t.Error("Fehler", before.Data.Revision, after.Data.Revision)
+	}
+}
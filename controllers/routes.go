@@ -0,0 +1,242 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strings"
+	"time"
+	"todo-rest-backend/models"
+)
+
+// Router is a router the todo API can be mounted on.
+// Adapters for other routers (chi, gorilla/mux, http.ServeMux) implement it in a few lines,
+// HttpRouter is the adapter for httprouter used by Run.
+type Router interface {
+	// Handle registers the handler for the method and the path, path parameters are written as :name
+	Handle(method string, path string, handler http.Handler)
+	// Param returns the value of a path parameter of a request dispatched by the router
+	Param(request *http.Request, name string) string
+}
+
+// HttpRouter adapts an httprouter.Router
+type HttpRouter struct {
+	Router *httprouter.Router
+}
+
+// Handle registers the handler with the router
+func (r HttpRouter) Handle(method string, path string, handler http.Handler) {
+	r.Router.Handler(method, path, handler)
+}
+
+// Param reads the path parameter httprouter stored in the request context
+func (r HttpRouter) Param(request *http.Request, name string) string {
+	return httprouter.ParamsFromContext(request.Context()).ByName(name)
+}
+
+// ServeMux adapts the method and wildcard patterns of the http.ServeMux of the standard library
+type ServeMux struct {
+	Mux *http.ServeMux
+}
+
+// Handle registers the handler for a "METHOD /path/{name}" pattern
+func (m ServeMux) Handle(method string, path string, handler http.Handler) {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	pattern := strings.Join(segments, "/")
+	// "/" matches every path in a ServeMux pattern, the index only matches itself
+	if pattern == "/" {
+		pattern = "/{$}"
+	}
+	m.Mux.Handle(method+" "+pattern, handler)
+}
+
+// Param returns the wildcard value matched by the ServeMux
+func (m ServeMux) Param(request *http.Request, name string) string {
+	return request.PathValue(name)
+}
+
+// route is an action of the todo API
+type route struct {
+	method string
+	path   string
+	handle Handle
+}
+
+// routes lists the actions of the todo API, it is called after the caching is configured
+func routes() []route {
+	return []route{
+		{http.MethodGet, "/", Index},
+		{http.MethodGet, readinessPath, noStore(ReadyzGet)},
+		{http.MethodGet, "/openapi.json", OpenApiGet},
+		{http.MethodGet, "/docs", ApiDocsGet},
+		{http.MethodGet, metricsPath, noStore(MetricsGet)},
+		{http.MethodGet, "/todos", cacheable("/todos", TodosGet, true)},
+		// polls wait for changes and exports are streamed, their responses are neither cached nor shared
+		{http.MethodGet, "/todos/:id", withSubRoutes(subRoutes{"poll": noStore(TodosPollGet), "export": noStore(TodosExport)},
+			cacheable("/todos/:id", withSubRoutes(subRoutes{
+				"autocomplete": TodosAutocomplete,
+				"similar":      TodosSimilar,
+				"revision":     TodosRevisionGet,
+				"trash":        TrashGet,
+			}, TodoGetById), false))},
+		{http.MethodPost, "/todos", mutation(TodoPost)},
+		{http.MethodPost, "/todos/:id", mutation(withSubRoutes(subRoutes{"import": TodosImport, "bulk": TodosBulkPost}, nil))},
+		{http.MethodPut, "/todos/:id", mutation(ifMatch(TodoPut))},
+		{http.MethodPost, "/todos/:id/move-column", mutation(ifMatch(TodoMoveColumnPost))},
+		{http.MethodPost, "/todos/:id/restore", mutation(TodoRestorePost)},
+		{http.MethodGet, "/todos/:id/pomodoro", noStore(TodoPomodoroGet)},
+		{http.MethodGet, "/todos/:id/suggestions", noStore(TodoSuggestionsGet)},
+		{http.MethodPost, "/todos/:id/pomodoro", mutation(TodoPomodoroPost)},
+		{http.MethodPost, "/todos/:id/items", mutation(TodoItemPost)},
+		{http.MethodPut, "/todos/:id/items/:itemId", mutation(TodoItemPut)},
+		{http.MethodDelete, "/todos/:id/items/:itemId", mutation(TodoItemDelete)},
+		{http.MethodDelete, "/todos/:id", mutation(withSubRoutes(subRoutes{"trash": TrashDelete}, ifMatch(TodoDelete)))},
+		{http.MethodDelete, "/todos", mutation(DeleteAllTodos)},
+		{http.MethodPost, "/batch", mutation(BatchPost)},
+		{http.MethodGet, "/schemas/:name", cacheable("/schemas/:name", SchemaGet, false)},
+		{http.MethodGet, "/tags", cacheable("/tags", TagsGet, true)},
+		{http.MethodGet, "/settings", cacheable("/settings", SettingsGet, false)},
+		{http.MethodPut, "/settings", mutation(SettingsPut)},
+		{http.MethodGet, "/sync/status", noStore(SyncStatusGet)},
+		{http.MethodPost, "/sync/webhook", mutation(replayProtected(SyncWebhookPost, false))},
+		{http.MethodGet, "/rules", cacheable("/rules", RulesGet, false)},
+		{http.MethodPost, "/rules/test", noStore(RulesTestPost)},
+		{http.MethodGet, "/admin/retention", noStore(RetentionGet)},
+		{http.MethodPost, "/admin/retention/run", mutation(RetentionRunPost)},
+		{http.MethodGet, "/admin/dual-write", noStore(DualWriteGet)},
+		{http.MethodGet, "/admin/denylist", noStore(DenylistGet)},
+		{http.MethodGet, "/admin/outbox", noStore(OutboxGet)},
+		{http.MethodGet, "/admin/dlq", noStore(DeadLettersGet)},
+		{http.MethodPost, "/admin/dlq/:id/redeliver", noStore(DeadLetterRedeliverPost)},
+		{http.MethodPost, "/admin/restore/:snapshot", mutation(RestorePost)},
+		{http.MethodGet, "/admin/shards", noStore(ShardsGet)},
+		{http.MethodPost, "/admin/shards/:owner/reload", mutation(ShardReloadPost)},
+		{http.MethodPost, "/admin/shards/:owner/reset", mutation(ShardResetPost)},
+		{http.MethodGet, "/lists/:id/todos", cacheable("/lists/:id/todos", ListTodosGet, true)},
+		{http.MethodPut, "/lists/:id/order", mutation(ListOrderPut)},
+		{http.MethodGet, "/lists/:id/board", cacheable("/lists/:id/board", ListBoardGet, true)},
+		{http.MethodGet, "/lists/:id/integrations", noStore(ListIntegrationsGet)},
+		{http.MethodPut, "/lists/:id/integrations", mutation(ListIntegrationsPut)},
+		{http.MethodGet, "/lists/:id/todos/:number", cacheable("/lists/:id/todos/:number", ListTodoGetByNumber, false)},
+		{http.MethodGet, "/goals", cacheable("/goals", GoalsGet, true)},
+		{http.MethodGet, "/goals/:id", cacheable("/goals/:id", GoalGetById, false)},
+		{http.MethodGet, "/goals/:id/todos", cacheable("/goals/:id/todos", GoalTodosGet, true)},
+		{http.MethodPost, "/goals", mutation(GoalPost)},
+		{http.MethodPut, "/goals/:id", mutation(GoalPut)},
+		{http.MethodDelete, "/goals/:id", mutation(GoalDelete)},
+		{http.MethodGet, "/habits/:id/calendar", noStore(HabitCalendarGet)},
+		{http.MethodPost, "/habits/:id/done", mutation(HabitDonePost)},
+		{http.MethodDelete, "/habits/:id/done", mutation(HabitDoneDelete)},
+		{http.MethodGet, "/views/waiting", noStore(WaitingViewGet)},
+		{http.MethodGet, "/views/stale", noStore(StaleViewGet)},
+		{http.MethodGet, "/reports/capacity", cacheable("/reports/capacity", CapacityReportGet, true)},
+		{http.MethodGet, "/reports/focus", noStore(FocusReportGet)},
+		{http.MethodGet, "/simple/next", noStore(simpleApi(replayProtected(SimpleNextGet, true)))},
+		{http.MethodPost, "/simple/add", mutation(simpleApi(replayProtected(SimpleAddPost, true)))},
+		{http.MethodPost, "/simple/done/:id", mutation(simpleApi(replayProtected(SimpleDonePost, true)))},
+	}
+}
+
+// RegisterRoutes mounts the todo API on the router.
+// A route conflicting with a route of the embedder is returned as error instead of the router's panic,
+// the routes registered before the conflict stay registered.
+func RegisterRoutes(r Router) error {
+	for _, route := range routes() {
+		err := registerRoute(r, route)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func registerRoute(r Router, route route) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("cannot register %s %s: %v", route.method, route.path, recovered)
+		}
+	}()
+
+	var names []string
+	for _, segment := range strings.Split(route.path, "/") {
+		if strings.HasPrefix(segment, ":") {
+			names = append(names, segment[1:])
+		}
+	}
+
+	r.Handle(route.method, route.path, http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		// failures and panics of the handler are answered with 500 if the response was not started yet
+		writer := &statusWriter{ResponseWriter: responseWriter}
+		defer observeRequest(route, writer, time.Now())
+		defer recoverPanic(writer, request)
+
+		params := httprouter.Params{}
+		for _, name := range names {
+			params = append(params, httprouter.Param{Key: name, Value: r.Param(request, name)})
+		}
+
+		// the readiness is reported without waiting for the store
+		if route.path == readinessPath {
+			err := route.handle(writer, request, params)
+			if err != nil {
+				internalError(writer, request, err)
+			}
+			return
+		}
+		user := ""
+		if needsAuthentication(route) {
+			var ok bool
+			user, ok = authenticate(request)
+			if ok == false {
+				rejectUnauthenticated(writer, request)
+				return
+			}
+		}
+		if adminRoute(route) && isAdmin(user) == false {
+			logFailure(request, writeError(writer, http.StatusForbidden, models.CodeForbidden, "Admin Route Forbidden"))
+			return
+		}
+		if rejectNonConforming(writer, request) {
+			return
+		}
+		override := request.Header.Get(denylistOverrideHeader) == "true"
+		if override && isAdmin(user) == false {
+			logFailure(request, writeError(writer, http.StatusForbidden, models.CodeForbidden, "Denylist Override Forbidden"))
+			return
+		}
+		// the metrics are collected without waiting for the store, also while the todos are loading
+		if route.path == metricsPath {
+			err := route.handle(writer, request, params)
+			if err != nil {
+				internalError(writer, request, err)
+			}
+			return
+		}
+		if rejectWhileLoading(writer, request) {
+			return
+		}
+
+		// reading requests are handled concurrently, changing requests one at a time. With users all requests
+		// are handled one at a time, as the store functions act for the user of the request.
+		hold := storeHold{shared: route.method == http.MethodGet && len(users) == 0, user: user, override: override}
+		hold.acquire()
+		defer hold.release()
+		request = request.WithContext(context.WithValue(request.Context(), storeHoldKey{}, hold))
+		warned, request := withWarnings(writer, request)
+		checkParameters(warned, request)
+		err := route.handle(warned, request, params)
+		if err == nil {
+			err = warned.finish()
+		}
+		if err != nil {
+			internalError(writer, request, err)
+		}
+	}))
+	return nil
+}
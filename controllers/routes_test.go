@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegisterRoutes_DuplicateRouteIsError(t *testing.T) {
+	// Arrange
+	//
+	router := httprouter.New()
+	router.GET("/rules", func(http.ResponseWriter, *http.Request, httprouter.Params) {})
+
+	// Act
+	//
+	err := RegisterRoutes(HttpRouter{Router: router})
+
+	// Assert
+	//
+	if err == nil {
+		t.Error("registering an existing route did not fail")
+	}
+}
+
+func TestRegisterRoutes_PassesPathParams(t *testing.T) {
+	// Arrange
+	//
+	router := httprouter.New()
+	RegisterRoutes(HttpRouter{Router: router})
+	recorder := httptest.NewRecorder()
+
+	// Act
+	//
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/todos/999", nil))
+
+	// Assert
+	//
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("got status %d, want 404 for an unknown id", recorder.Code)
+	}
+}
+
+func TestRegisterRoutes_ServeMuxMatchesHttpRouter(t *testing.T) {
+	// Arrange
+	//
+	router := httprouter.New()
+	RegisterRoutes(HttpRouter{Router: router})
+	mux := http.NewServeMux()
+	err := RegisterRoutes(ServeMux{Mux: mux})
+	requests := []struct{ method, path string }{
+		{http.MethodGet, "/"},
+		{http.MethodGet, "/unknown"},
+		{http.MethodGet, "/todos/999"},
+		{http.MethodGet, "/rules"},
+		{http.MethodPost, "/todos/999"},
+	}
+
+	for _, r := range requests {
+		// Act
+		//
+		fromRouter, fromMux := httptest.NewRecorder(), httptest.NewRecorder()
+		router.ServeHTTP(fromRouter, httptest.NewRequest(r.method, r.path, nil))
+		mux.ServeHTTP(fromMux, httptest.NewRequest(r.method, r.path, nil))
+
+		// Assert
+		//
+		if err != nil || fromRouter.Code != fromMux.Code {
+			t.Errorf("%s %s: httprouter answered %d, ServeMux %d (%v)", r.method, r.path, fromRouter.Code, fromMux.Code, err)
+		}
+	}
+}
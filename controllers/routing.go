@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"todo-rest-backend/models"
+)
+
+// Handling of paths differing from the canonical path of a route only by a trailing slash, letter case or dot segments
+const (
+	// PathsRedirect redirects to the canonical path, 301 for GET and HEAD and 308 for other methods
+	PathsRedirect = "redirect"
+	// PathsRewrite serves the canonical path without redirect
+	PathsRewrite = "rewrite"
+	// PathsReject answers with 404 Not Found
+	PathsReject = "reject"
+)
+
+var nonCanonicalPaths = PathsRedirect
+
+// configureRouting reads the handling of non-canonical paths from TODO_NON_CANONICAL_PATHS
+func configureRouting() error {
+	mode := os.Getenv("TODO_NON_CANONICAL_PATHS")
+	switch mode {
+	case "":
+	case PathsRedirect, PathsRewrite, PathsReject:
+		nonCanonicalPaths = mode
+	default:
+		return errors.New("TODO_NON_CANONICAL_PATHS must be redirect, rewrite or reject")
+	}
+	return nil
+}
+
+// canonicalPath is the cleaned path without trailing slash and with the static segments of the matching route
+// in lower case. The path parameters keep their case, list names are free text.
+func canonicalPath(requestPath string) string {
+	segments := strings.Split(path.Clean("/"+requestPath), "/")
+	for _, route := range routes() {
+		pattern := strings.Split(route.path, "/")
+		if matchesIgnoringCase(pattern, segments) {
+			for i, segment := range pattern {
+				if strings.HasPrefix(segment, ":") == false {
+					segments[i] = segment
+				}
+			}
+			break
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// matchesIgnoringCase tells whether the segments of a path match the segments of a route pattern,
+// the static segments are compared ignoring their case
+func matchesIgnoringCase(pattern []string, segments []string) bool {
+	if len(pattern) != len(segments) {
+		return false
+	}
+	for i, segment := range pattern {
+		if strings.HasPrefix(segment, ":") == false && strings.EqualFold(segment, segments[i]) == false {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizedPaths handles non-canonical paths the same way for every router.
+// Percent-encoded paths are decoded before routing, an encoded slash is rejected as it cannot be told apart
+// from a path separator by all routers.
+func normalizedPaths(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if strings.Contains(strings.ToLower(request.URL.RawPath), "%2f") {
+			logFailure(request, writeError(writer, http.StatusBadRequest, models.CodeInvalidRequest, "Invalid Path"))
+			return
+		}
+
+		canonical := canonicalPath(request.URL.Path)
+		if canonical != request.URL.Path {
+			switch nonCanonicalPaths {
+			case PathsRedirect:
+				location := canonical
+				if request.URL.RawQuery != "" {
+					location += "?" + request.URL.RawQuery
+				}
+				status := http.StatusPermanentRedirect
+				if request.Method == http.MethodGet || request.Method == http.MethodHead {
+					status = http.StatusMovedPermanently
+				}
+				writer.Header().Set("Location", location)
+				logFailure(request, writeError(writer, status, models.CodeMoved, http.StatusText(status)))
+				return
+			case PathsReject:
+				logFailure(request, writeError(writer, http.StatusNotFound, models.CodeNotFound, "Record Not Found"))
+				return
+			}
+		}
+
+		// the routers see the decoded path only
+		request.URL.Path = canonical
+		request.URL.RawPath = ""
+		handler.ServeHTTP(writer, request)
+	})
+}
+
+// newHandler creates the router named by TODO_ROUTER ("httprouter" by default or "servemux") with all routes.
+// Both routers answer unknown paths and methods in the JSON error format.
+func newHandler(routerName string) (http.Handler, error) {
+	switch routerName {
+	case "", "httprouter":
+		router := httprouter.New()
+		// non-canonical paths are handled by normalizedPaths for all routers
+		router.RedirectTrailingSlash = false
+		router.RedirectFixedPath = false
+		// httprouter sets the Allow header before calling MethodNotAllowed
+		router.NotFound = http.HandlerFunc(routeNotFound)
+		router.MethodNotAllowed = http.HandlerFunc(methodNotAllowed)
+		return router, RegisterRoutes(HttpRouter{Router: router})
+	case "servemux":
+		mux := http.NewServeMux()
+		return jsonRouterErrors(mux), RegisterRoutes(ServeMux{Mux: mux})
+	}
+	return nil, errors.New("TODO_ROUTER must be httprouter or servemux")
+}
+
+// routeNotFound answers requests for unknown paths
+func routeNotFound(writer http.ResponseWriter, request *http.Request) {
+	logFailure(request, writeError(writer, http.StatusNotFound, models.CodeRouteNotFound, "Route Not Found"))
+}
+
+// methodNotAllowed answers requests with a method the path does not support, the router sets the Allow header
+func methodNotAllowed(writer http.ResponseWriter, request *http.Request) {
+	logFailure(request, writeError(writer, http.StatusMethodNotAllowed, models.CodeMethodNotAllowed, "Method Not Allowed"))
+}
+
+// allowedMethods lists the methods of the routes with the path for the Allow header, except the requested method
+func allowedMethods(path string, except string) string {
+	var methods []string
+	for _, route := range routes() {
+		if route.path == path && route.method != except {
+			methods = append(methods, route.method)
+		}
+	}
+	return strings.Join(methods, ", ")
+}
+
+// routerErrorWriter replaces the plain text 404 and 405 responses of a router by the JSON error format
+type routerErrorWriter struct {
+	http.ResponseWriter
+	replaced bool
+}
+
+func (w *routerErrorWriter) WriteHeader(status int) {
+	plainText := strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain")
+	if plainText && (status == http.StatusNotFound || status == http.StatusMethodNotAllowed) {
+		w.replaced = true
+		w.Header().Del("X-Content-Type-Options")
+		if status == http.StatusNotFound {
+			logFailure(nil, writeError(w.ResponseWriter, http.StatusNotFound, models.CodeRouteNotFound, "Route Not Found"))
+		} else {
+			logFailure(nil, writeError(w.ResponseWriter, http.StatusMethodNotAllowed, models.CodeMethodNotAllowed, "Method Not Allowed"))
+		}
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *routerErrorWriter) Write(data []byte) (int, error) {
+	if w.replaced {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// jsonRouterErrors wraps a router without handlers for unknown paths and methods, like http.ServeMux
+func jsonRouterErrors(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		handler.ServeHTTP(&routerErrorWriter{ResponseWriter: writer}, request)
+	})
+}
+
+// writeError answers with the JSON error format of the API
+func writeError(writer http.ResponseWriter, status int, code models.ErrorCode, title string) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	writer.WriteHeader(status)
+	response := models.JsonErrorResponse{Error: models.ApiError{Status: int16(status), Code: code, Title: title}}
+	return json.NewEncoder(writer).Encode(response)
+}
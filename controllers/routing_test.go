@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"todo-rest-backend/models"
+)
+
+func TestNormalizedPaths_RedirectsToCanonicalPath(t *testing.T) {
+	// Arrange
+	//
+	handler := normalizedPaths(http.NotFoundHandler())
+	recorder := httptest.NewRecorder()
+
+	// Act
+	//
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/Todos/?format=org", nil))
+
+	// Assert
+	//
+	if recorder.Code != http.StatusMovedPermanently || recorder.Header().Get("Location") != "/todos?format=org" {
+		t.Errorf("got %d to %q", recorder.Code, recorder.Header().Get("Location"))
+	}
+	if recorder.Header().Get("Content-Type") != "application/json; charset=UTF-8" {
+		t.Error("redirect is not answered with JSON")
+	}
+}
+
+func TestNormalizedPaths_DecodesIds(t *testing.T) {
+	// Arrange
+	//
+	var routedPath string
+	handler := normalizedPaths(http.HandlerFunc(func(_ http.ResponseWriter, request *http.Request) {
+		routedPath = request.URL.EscapedPath()
+	}))
+
+	// Act
+	//
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/todos/%31", nil))
+	slash := httptest.NewRecorder()
+	handler.ServeHTTP(slash, httptest.NewRequest(http.MethodGet, "/todos/1%2F2", nil))
+
+	// Assert
+	//
+	if routedPath != "/todos/1" {
+		t.Errorf("routed %q, want /todos/1", routedPath)
+	}
+	if slash.Code != http.StatusBadRequest {
+		t.Errorf("encoded slash answered %d, want 400", slash.Code)
+	}
+}
+
+func TestNewHandler_JsonErrorsForUnknownRoutes(t *testing.T) {
+	for _, routerName := range []string{"httprouter", "servemux"} {
+		// Arrange
+		//
+		handler, err := newHandler(routerName)
+		notFound, notAllowed := httptest.NewRecorder(), httptest.NewRecorder()
+
+		// Act
+		//
+		handler.ServeHTTP(notFound, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+		handler.ServeHTTP(notAllowed, httptest.NewRequest(http.MethodPatch, "/todos", nil))
+
+		// Assert
+		//
+		if err != nil || notFound.Code != http.StatusNotFound || notAllowed.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s: got %d and %d (%v)", routerName, notFound.Code, notAllowed.Code, err)
+		}
+		if notFound.Header().Get("Content-Type") != "application/json; charset=UTF-8" ||
+			notAllowed.Header().Get("Content-Type") != "application/json; charset=UTF-8" {
+			t.Errorf("%s: errors are not JSON", routerName)
+		}
+		if notAllowed.Header().Get("Allow") == "" {
+			t.Errorf("%s: Allow header is missing", routerName)
+		}
+	}
+}
+
+func TestNormalizedPaths_KeepsTheCaseOfListNames(t *testing.T) {
+	// Arrange
+	//
+	todo := models.AddTodo(models.Todo{Title: "Prepare the slides", List: "Work"})
+	defer models.RemoveTodo(todo.Id)
+
+	// Act
+	//
+	list := serveRoute(t, http.MethodGet, "/lists/Work/todos", "")
+	numbered := serveRoute(t, http.MethodGet, "/lists/Work/todos/"+strconv.Itoa(todo.Number), "")
+	redirected := serveRoute(t, http.MethodGet, "/Lists/Work/Todos", "")
+
+	// Assert
+	//
+	if list.Code != http.StatusOK || strings.Contains(list.Body.String(), "Prepare the slides") == false {
+		t.Error("Fehler", list.Code, list.Body.String())
+	}
+	if numbered.Code != http.StatusOK || strings.Contains(numbered.Body.String(), "Prepare the slides") == false {
+		t.Error("Fehler", numbered.Code, numbered.Body.String())
+	}
+	if redirected.Code != http.StatusMovedPermanently || redirected.Header().Get("Location") != "/lists/Work/todos" {
+		t.Error("Fehler", redirected.Code, redirected.Header())
+	}
+}
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"todo-rest-backend/models"
+	"todo-rest-backend/plugins"
+	"todo-rest-backend/rules"
+)
+
+// ruleEngine holds the rules of the file named by TODO_RULES_FILE
+var ruleEngine = &rules.Engine{}
+
+// RuleTestResult is returned by the rules test action
+type RuleTestResult struct {
+	Valid      bool              `json:"valid"`
+	Todo       models.Todo       `json:"todo"`
+	Violations []rules.Violation `json:"violations"`
+}
+
+// RulesGet Handler for the rules list action
+// GET /rules
+func RulesGet(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+	ruleList := ruleEngine.Rules
+	if ruleList == nil {
+		ruleList = []rules.Rule{}
+	}
+	response := models.JsonExtendedResponse{Data: ruleList}
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// RulesTestPost Handler for the rules test action, it evaluates the rules for a todo without storing it
+// POST /rules/test?action=create|update
+func RulesTestPost(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	var todo models.Todo
+	err := decodeTodo(request, &todo, false)
+	if err != nil {
+		return handleInvalidBody(writer, err)
+	}
+
+	action := request.URL.Query().Get("action")
+	if action == "" {
+		action = plugins.ActionCreate
+	}
+
+	result := RuleTestResult{}
+	result.Todo, result.Violations = ruleEngine.Evaluate(action, todo)
+	result.Valid = len(result.Violations) == 0
+
+	response := models.JsonExtendedResponse{Data: result}
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+	"todo-rest-backend/jobs"
+	"todo-rest-backend/models"
+)
+
+// configureSaving starts the save worker if TODO_SAVE_INTERVAL is set. The handlers change the store in memory
+// and answer without writing the data file, the worker writes the changes of each interval at once, so the
+// latency of changes does not grow with the number of todos. The changes of the last interval are lost if the
+// process is killed, on shutdown they are saved after the requests in flight.
+func configureSaving() error {
+	value := os.Getenv("TODO_SAVE_INTERVAL")
+	if value == "" {
+		models.DeferSaving(false)
+		return nil
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		return errors.New("TODO_SAVE_INTERVAL must be a positive duration like 500ms")
+	}
+
+	models.DeferSaving(true)
+	jobs.Start("save", interval, saveChanges)
+	return nil
+}
+
+// saveChanges writes the changes of the store, the store is only held while they are copied.
+// A failed save is retried with the next run.
+func saveChanges() {
+	storeMutex.Lock()
+	pending, ok := models.TakePendingSave()
+	storeMutex.Unlock()
+	if ok == false {
+		return
+	}
+
+	err := pending.Write()
+	if err != nil {
+		log.Println("Cannot save the todos, retrying:", err)
+		return
+	}
+	storeMutex.Lock()
+	pending.MarkSaved()
+	storeMutex.Unlock()
+}
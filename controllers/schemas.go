@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"io"
+	"net/http"
+	"strings"
+	"todo-rest-backend/models"
+	"todo-rest-backend/schemas"
+)
+
+// describedBy links the response to the JSON Schema of the resources in its data
+func describedBy(writer http.ResponseWriter, schema string) {
+	writer.Header().Add("Link", "</schemas/"+schema+">; rel=\"describedby\"")
+}
+
+// validatedBody reads the request body and checks it against the JSON Schema
+func validatedBody(request *http.Request, schema string) ([]byte, error) {
+	body, err := readBody(request)
+	if err != nil {
+		return nil, err
+	}
+	return body, validateJson(schema, body)
+}
+
+// maxBodySize is the size of the largest request body that is read, imports of a few thousand todos fit
+const maxBodySize = 10 << 20
+
+// readBody reads the whole body of the request, bodies larger than maxBodySize are invalid
+func readBody(request *http.Request) ([]byte, error) {
+	if request.Body == nil {
+		return nil, errors.New("invalid body")
+	}
+	return io.ReadAll(http.MaxBytesReader(nil, request.Body, maxBodySize))
+}
+
+// validateJson validates the body against the schema, the violations are returned as models.ValidationErrors
+func validateJson(schema string, body []byte) error {
+	err := schemas.Validate(schema, body)
+	var violations schemas.ValidationErrors
+	if errors.As(err, &violations) {
+		return fieldErrors(violations)
+	}
+	return err
+}
+
+// fieldErrors names the fields of the schema violations the way they are named in the body, e.g. "location.latitude"
+func fieldErrors(violations schemas.ValidationErrors) models.ValidationErrors {
+	var fields models.ValidationErrors
+	for _, violation := range violations {
+		field := strings.ReplaceAll(strings.TrimPrefix(violation.Path, "/"), "/", ".")
+		fields = append(fields, models.FieldError{Field: field, Message: violation.Message})
+	}
+	return fields
+}
+
+// SchemaGet Handler for the schema get action, the schemas describe the request and response bodies
+// GET /schemas/:name
+func SchemaGet(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) error {
+	content, ok := schemas.Get(params.ByName("name"))
+	if ok == false {
+		return writeError(writer, http.StatusNotFound, models.CodeNotFound, "Schema Not Found")
+	}
+
+	writer.Header().Set("Content-Type", "application/schema+json")
+	writer.WriteHeader(http.StatusOK)
+	_, err := writer.Write(content)
+	return err
+}
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// serveRoute answers a request with all routes like the server does, the headers are given as name and value
+// pairs and headers with an empty value are left out. Faults of the chaos configuration are injected, paths
+// are normalized.
+func serveRoute(t *testing.T, method string, path string, body string, headers ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	router := httprouter.New()
+	err := RegisterRoutes(HttpRouter{Router: router})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	request := httptest.NewRequest(method, path, reader)
+	for i := 0; i+1 < len(headers); i += 2 {
+		if headers[i+1] != "" {
+			request.Header.Set(headers[i], headers[i+1])
+		}
+	}
+	recorder := httptest.NewRecorder()
+	chaos(normalizedPaths(router)).ServeHTTP(recorder, request)
+	return recorder
+}
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"os"
+	"strings"
+	"todo-rest-backend/models"
+)
+
+// configureDefaults reads the defaults of new todos from TODO_DEFAULT_LIST, TODO_DEFAULT_TAGS,
+// a comma separated list of tags, and TODO_DEFAULT_PRIORITY. Settings saved with PUT /settings replace them.
+func configureDefaults() error {
+	defaults := models.Settings{
+		DefaultList:     os.Getenv("TODO_DEFAULT_LIST"),
+		DefaultPriority: os.Getenv("TODO_DEFAULT_PRIORITY"),
+	}
+	for _, tag := range strings.Split(os.Getenv("TODO_DEFAULT_TAGS"), ",") {
+		if strings.TrimSpace(tag) != "" {
+			defaults.DefaultTags = append(defaults.DefaultTags, tag)
+		}
+	}
+	return models.SetSettings(defaults)
+}
+
+// SettingsGet Handler for the settings get action
+// GET /settings
+func SettingsGet(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+	response := models.JsonExtendedResponse{Data: models.CurrentSettings()}
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	describedBy(writer, "settings.json")
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// SettingsPut Handler for the settings put action, the defaults apply to the todos created from then on
+// PUT /settings
+func SettingsPut(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	describedBy(writer, "settings.json")
+	var settings models.Settings
+	body, err := validatedBody(request, "settings.json")
+	if err == nil {
+		err = json.Unmarshal(body, &settings)
+	}
+	if err != nil {
+		return handleInvalidBody(writer, err)
+	}
+	err = models.SetSettings(settings)
+	if errors.Is(err, models.ErrInvalidPriority) {
+		return handleTodoNotProperlyTransmittedGeneral(writer, "Invalid Default Priority")
+	}
+	if err != nil {
+		return handleTodoNotProperlyTransmittedGeneral(writer, "Invalid Default Tags")
+	}
+
+	err = models.PersistSettings()
+	if err != nil {
+		return err
+	}
+
+	response := models.JsonExtendedResponse{Data: models.CurrentSettings()}
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"os"
+	"todo-rest-backend/models"
+)
+
+// unownedShard names the shard of the todos without owner in the paths
+const unownedShard = "-"
+
+// ShardsGet Handler for the shards of the sharded storage, a shard that failed to load has an error
+// GET /admin/shards
+func ShardsGet(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	shards, err := models.Shards()
+	if errors.Is(err, models.ErrNotSharded) {
+		return writeError(writer, http.StatusNotFound, models.CodeNotFound, "Storage Not Sharded")
+	}
+	if err != nil {
+		return err
+	}
+
+	response := models.JsonExtendedResponse{Data: shards}
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// ShardReloadPost Handler for reloading a shard, like after its file was restored from a backup. The todos of
+// the owner are replaced with the ones of the shard, "-" names the shard of the todos without owner.
+// POST /admin/shards/:owner/reload
+func ShardReloadPost(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) error {
+	return changeShard(writer, params, models.ReloadShard)
+}
+
+// ShardResetPost Handler for resetting a shard that failed to load, its file is kept with the suffix .corrupt
+// and the todos the owner has in the store are saved to a new file
+// POST /admin/shards/:owner/reset
+func ShardResetPost(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) error {
+	return changeShard(writer, params, models.ResetShard)
+}
+
+// changeShard applies the change to the shard of the owner of the path and saves the store
+func changeShard(writer http.ResponseWriter, params httprouter.Params, change func(owner string) (models.ShardInfo, error)) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	owner := params.ByName("owner")
+	if owner == unownedShard {
+		owner = ""
+	}
+
+	shard, err := change(owner)
+	switch {
+	case errors.Is(err, models.ErrNotSharded):
+		return writeError(writer, http.StatusNotFound, models.CodeNotFound, "Storage Not Sharded")
+	case errors.Is(err, os.ErrNotExist):
+		return writeError(writer, http.StatusNotFound, models.CodeNotFound, "Shard Not Found")
+	case errors.Is(err, models.ErrShardHealthy):
+		return writeError(writer, http.StatusConflict, models.CodeStateConflict, "Shard Not Failed")
+	case errors.Is(err, models.ErrShardConflict):
+		return writeError(writer, http.StatusConflict, models.CodeStateConflict, "Shard Ids Of Other Owners")
+	case errors.Is(err, models.ErrCorruptData):
+		return writeError(writer, http.StatusConflict, models.CodeStateConflict, "Shard Corrupted")
+	case err != nil:
+		return err
+	}
+
+	err = models.UpdateDataInFile()
+	if err != nil {
+		return err
+	}
+
+	response := models.JsonExtendedResponse{Data: shard}
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"todo-rest-backend/models"
+)
+
+func TestShardsGet_ReportsTheFailedShard(t *testing.T) {
+	// Arrange
+	//
+	dir := t.TempDir()
+	sharded := &models.ShardedStorage{CsvStorage: models.CsvStorage{FileName: filepath.Join(dir, models.FileName)},
+		Dir: filepath.Join(dir, models.ShardsDirName)}
+	os.Mkdir(sharded.Dir, 0700)
+	os.WriteFile(filepath.Join(sharded.Dir, "bob.csv"), []byte("2,Trunc"), 0600)
+	defer models.SetRepository(models.NewMemoryRepository())
+	defer models.SetStorage(models.CsvStorage{FileName: models.FileName})
+	defer models.DisableFilePersistence()
+	models.SetRepository(models.NewMemoryRepository())
+	models.SetStorage(sharded)
+	models.EnableFilePersistence()
+	models.Initialize()
+
+	// Act
+	//
+	shards := serveRoute(t, http.MethodGet, "/admin/shards", "")
+	reset := serveRoute(t, http.MethodPost, "/admin/shards/bob/reset", "")
+	resetAgain := serveRoute(t, http.MethodPost, "/admin/shards/bob/reset", "")
+	var response struct {
+		Data []models.ShardInfo `json:"data"`
+	}
+	json.Unmarshal(shards.Body.Bytes(), &response)
+
+	// Assert
+	//
+	if shards.Code != http.StatusOK || len(response.Data) != 2 || response.Data[1].Owner != "bob" || response.Data[1].Error == "" {
+		t.Error("Fehler", shards.Code, shards.Body.String())
+	}
+	if reset.Code != http.StatusOK || resetAgain.Code != http.StatusConflict {
+		t.Error("Fehler", reset.Code, resetAgain.Code, resetAgain.Body.String())
+	}
+}
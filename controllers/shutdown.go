@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"time"
+	"todo-rest-backend/jobs"
+	"todo-rest-backend/models"
+)
+
+// shutdownTimeout is how long the requests in flight are waited for on shutdown, set by TODO_SHUTDOWN_TIMEOUT
+var shutdownTimeout = 10 * time.Second
+
+// configureShutdown reads the shutdown timeout from TODO_SHUTDOWN_TIMEOUT
+func configureShutdown() error {
+	value := os.Getenv("TODO_SHUTDOWN_TIMEOUT")
+	if value == "" {
+		return nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return errors.New("TODO_SHUTDOWN_TIMEOUT must be a positive duration like 30s")
+	}
+	shutdownTimeout = timeout
+	return nil
+}
+
+// serve answers the requests of the listener until the context is done. Then it stops accepting
+// connections, waits up to the shutdown timeout for the requests in flight and the background jobs,
+// saves the todos and closes the repository.
+func serve(ctx context.Context, server *http.Server, listener net.Listener, repository models.Repository) error {
+	failed := make(chan error, 1)
+	go func() {
+		failed <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-failed:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutting down, waiting for the requests in flight")
+	stopPolls()
+	timeout, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err := server.Shutdown(timeout)
+	if err != nil {
+		log.Println("Requests in flight were aborted:", err)
+	}
+	jobs.StopAll()
+	// the todos changed since the last run of the sync job are pushed now
+	pushQueuedTodos()
+
+	// the save worker stopped with the jobs, the changes since its last run are saved now
+	storeMutex.Lock()
+	err = models.SaveData()
+	storeMutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	recordMutex.Lock()
+	if recordFile != nil {
+		recordFile.Close()
+		recordFile = nil
+	}
+	recordMutex.Unlock()
+
+	if closer, ok := repository.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
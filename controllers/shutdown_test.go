@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+	"todo-rest-backend/models"
+)
+
+func TestServe_DrainsRequestsInFlight(t *testing.T) {
+	// Arrange
+	//
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	server := &http.Server{Handler: http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		writer.WriteHeader(http.StatusOK)
+	})}
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error)
+	go func() {
+		stopped <- serve(ctx, server, listener, models.NewMemoryRepository())
+	}()
+	responses := make(chan int)
+	go func() {
+		response, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			responses <- 0
+			return
+		}
+		response.Body.Close()
+		responses <- response.StatusCode
+	}()
+	<-started
+
+	// Act
+	//
+	cancel()
+
+	// Assert
+	//
+	if status := <-responses; status != http.StatusOK {
+		t.Error("Fehler", status)
+	}
+	if err := <-stopped; err != nil {
+		t.Error("Fehler", err)
+	}
+}
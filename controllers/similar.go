@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"todo-rest-backend/models"
+	"todo-rest-backend/search"
+)
+
+// defaultSimilarityThreshold is the similarity from which todos are reported as likely duplicates
+const defaultSimilarityThreshold = 0.5
+
+const maxSimilarTodos = 10
+
+// SimilarTodo is a likely duplicate with the similarity of its title between 0 and 1
+type SimilarTodo struct {
+	Todo       models.Todo `json:"todo"`
+	Similarity float64     `json:"similarity"`
+}
+
+// TodosSimilar Handler for the duplicate search action, it returns the todos with a title similar to title,
+// the most similar first
+// GET /todos/similar?title=Buy%20milk&threshold=0.5
+func TodosSimilar(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	query := request.URL.Query()
+	title := strings.TrimSpace(query.Get("title"))
+	if title == "" {
+		return handleTodoNotProperlyTransmittedGeneral(writer, "Missing Title")
+	}
+
+	threshold := defaultSimilarityThreshold
+	if query.Get("threshold") != "" {
+		var err error
+		threshold, err = strconv.ParseFloat(query.Get("threshold"), 64)
+		if err != nil || threshold <= 0 || threshold > 1 {
+			return handleTodoNotProperlyTransmittedGeneral(writer, "Invalid Threshold")
+		}
+	}
+
+	similar := []SimilarTodo{}
+	for _, todo := range models.AllTodos() {
+		similarity := search.Similarity(title, todo.Title)
+		if similarity >= threshold {
+			similar = append(similar, SimilarTodo{Todo: todo, Similarity: math.Round(similarity*100) / 100})
+		}
+	}
+	sort.Slice(similar, func(i, j int) bool {
+		if similar[i].Similarity != similar[j].Similarity {
+			return similar[i].Similarity > similar[j].Similarity
+		}
+		leftValueAsInt, _ := strconv.Atoi(similar[i].Todo.Id)
+		rightValueAsInt, _ := strconv.Atoi(similar[j].Todo.Id)
+		return leftValueAsInt < rightValueAsInt
+	})
+	if len(similar) > maxSimilarTodos {
+		similar = similar[:maxSimilarTodos]
+	}
+
+	response := models.JsonExtendedResponse{Data: similar}
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
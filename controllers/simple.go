@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"os"
+	"strings"
+	"todo-rest-backend/models"
+	"todo-rest-backend/plugins"
+)
+
+// SimpleTodo is the compact todo of the simple API, Speech is a sentence a voice assistant can read out
+type SimpleTodo struct {
+	Id     string `json:"id,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Speech string `json:"speech"`
+}
+
+// simpleApiKey is the API key of the simple API, it is disabled without key
+var simpleApiKey string
+
+// configureSimpleApi reads the API key of the simple API from TODO_SIMPLE_API_KEY
+func configureSimpleApi() {
+	simpleApiKey = os.Getenv("TODO_SIMPLE_API_KEY")
+}
+
+// simpleApi only accepts requests with the API key in the X-Api-Key header or as bearer token, or with the
+// token of a user
+func simpleApi(handle Handle) Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		// with users the request was authenticated with the token of a user, see needsAuthentication
+		if len(users) > 0 {
+			return handle(writer, request, params)
+		}
+		apiKey := simpleApiKey
+		if apiKey == "" {
+			return writeError(writer, http.StatusNotFound, models.CodeRouteNotFound, "Route Not Found")
+		}
+
+		given := request.Header.Get("X-Api-Key")
+		if given == "" {
+			given = strings.TrimPrefix(request.Header.Get("Authorization"), "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(given), []byte(apiKey)) != 1 {
+			return writeError(writer, http.StatusUnauthorized, models.CodeUnauthorized, "Invalid API Key")
+		}
+		return handle(writer, request, params)
+	}
+}
+
+func writeSimpleTodo(writer http.ResponseWriter, status int, todo SimpleTodo) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	writer.WriteHeader(status)
+	return json.NewEncoder(writer).Encode(todo)
+}
+
+// SimpleNextGet Handler for the next todo action of the simple API, the open todo with the lowest id
+// GET /simple/next
+func SimpleNextGet(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+	var todos []models.Todo
+	for _, todo := range models.AllTodos() {
+		if todo.Terminated == false {
+			todos = append(todos, todo)
+		}
+	}
+	if len(todos) == 0 {
+		return writeSimpleTodo(writer, http.StatusOK, SimpleTodo{Speech: "You have no open todos."})
+	}
+
+	next := sortTodosAfterIdAscending(todos)[0]
+	return writeSimpleTodo(writer, http.StatusOK, SimpleTodo{Id: next.Id, Title: next.Title, Speech: "Your next todo is " + next.Title + "."})
+}
+
+// SimpleAddPost Handler for the add action of the simple API
+// POST /simple/add?title=Buy%20milk
+func SimpleAddPost(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) error {
+	title := strings.TrimSpace(request.URL.Query().Get("title"))
+	if title == "" {
+		return writeError(writer, http.StatusBadRequest, models.CodeInvalidRequest, "Missing Title")
+	}
+
+	todo, err := plugins.BeforeWrite(plugins.ActionCreate, models.ApplyDefaults(models.Todo{Title: title}))
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		return handleWriteHookError(writer, err)
+	}
+
+	todoAdded := models.AddTodo(todo)
+	syncTodo(todoAdded)
+	plugins.Emit(plugins.TodoCreated, todoAdded)
+	err = models.UpdateDataInFile()
+	if err != nil {
+		return err
+	}
+	return writeSimpleTodo(writer, http.StatusCreated, SimpleTodo{Id: todoAdded.Id, Title: todoAdded.Title, Speech: "Added " + todoAdded.Title + "."})
+}
+
+// SimpleDonePost Handler for the done action of the simple API, it terminates the todo
+// POST /simple/done/:id
+func SimpleDonePost(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) error {
+	id := params.ByName("id")
+	todo, ok := models.FindTodo(id)
+	if ok == false {
+		writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		return handleTodoIdNotFound(writer)
+	}
+
+	todo.Terminated = true
+	todo, err := plugins.BeforeWrite(plugins.ActionUpdate, todo)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		return handleWriteHookError(writer, err)
+	}
+
+	todoUpdated, _ := models.UpdateTodo(id, todo)
+	syncTodo(todoUpdated)
+	plugins.Emit(plugins.TodoUpdated, todoUpdated)
+	err = models.UpdateDataInFile()
+	if err != nil {
+		return err
+	}
+	return writeSimpleTodo(writer, http.StatusOK, SimpleTodo{Id: todoUpdated.Id, Title: todoUpdated.Title, Speech: "Marked " + todoUpdated.Title + " as done."})
+}
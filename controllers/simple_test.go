@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSimpleApi_RequiresApiKey(t *testing.T) {
+	// Arrange
+	//
+	defer func() { simpleApiKey = "" }()
+	simpleApiKey = "secret"
+	handle := simpleApi(func(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+		writer.WriteHeader(http.StatusOK)
+		return nil
+	})
+	withKey := httptest.NewRequest(http.MethodGet, "/simple/next", nil)
+	withKey.Header.Set("Authorization", "Bearer secret")
+	withoutKey := httptest.NewRequest(http.MethodGet, "/simple/next", nil)
+	accepted, refused := httptest.NewRecorder(), httptest.NewRecorder()
+
+	// Act
+	//
+	handle(accepted, withKey, nil)
+	handle(refused, withoutKey, nil)
+
+	// Assert
+	//
+	if accepted.Code != http.StatusOK || refused.Code != http.StatusUnauthorized {
+		t.Errorf("got %d with key and %d without", accepted.Code, refused.Code)
+	}
+}
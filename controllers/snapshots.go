@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"log"
+	"net/http"
+	"os"
+	"time"
+	"todo-rest-backend/models"
+	"todo-rest-backend/plugins"
+)
+
+// configureSnapshots reads how long the snapshots taken before deleting all todos can be restored from
+// TODO_RESTORE_WINDOW, default 24h
+func configureSnapshots() error {
+	window := 24 * time.Hour
+	if value := os.Getenv("TODO_RESTORE_WINDOW"); value != "" {
+		var err error
+		window, err = time.ParseDuration(value)
+		if err != nil || window <= 0 {
+			return errors.New("TODO_RESTORE_WINDOW must be a positive duration like 24h")
+		}
+	}
+	models.SetSnapshotWindow(window)
+	return nil
+}
+
+// RestorePost Handler for the restore action, it adds the todos of a snapshot taken by deleting all todos back.
+// The todos are restored all or none, like the operations of a batch.
+// POST /admin/restore/:snapshot
+func RestorePost(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	transaction := models.BeginTransaction()
+	defer func() {
+		// a failing store panics, the todos restored before are removed again
+		if recovered := recover(); recovered != nil {
+			err := transaction.Rollback()
+			if err != nil {
+				log.Println("Cannot roll back the restore:", err)
+			}
+			panic(recovered)
+		}
+	}()
+	snapshot, restored, err := models.RestoreSnapshot(params.ByName("snapshot"))
+	if errors.Is(err, models.ErrUnknownSnapshot) {
+		return writeError(writer, http.StatusNotFound, models.CodeNotFound, "Snapshot Not Found")
+	}
+	if errors.Is(err, models.ErrSnapshotExpired) {
+		return writeError(writer, http.StatusGone, models.CodeSnapshotExpired, "Snapshot Expired")
+	}
+	if err != nil {
+		rollbackErr := transaction.Rollback()
+		if rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+
+	// the todos in the trash of the snapshot stay unknown to the issue sync and the plugins
+	for _, todo := range restored {
+		if todo.DeletedAt == nil {
+			syncTodo(todo)
+			plugins.Emit(plugins.TodoCreated, todo)
+		}
+	}
+
+	err = models.UpdateDataInFile()
+	if err != nil {
+		return err
+	}
+
+	response := models.JsonExtendedResponse{Data: snapshot}
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+	"todo-rest-backend/models"
+	"todo-rest-backend/plugins"
+)
+
+// eventsOf passes the events of a todo to a channel
+type eventsOf struct {
+	id     string
+	events chan plugins.Event
+}
+
+func (s eventsOf) HandleEvent(event plugins.Event) error {
+	if event.Todo.Id == s.id {
+		s.events <- event
+	}
+	return nil
+}
+
+func TestRestorePost_EmitsEventsOfTheRestoredTodos(t *testing.T) {
+	// Arrange
+	//
+	defer models.SetRepository(models.NewMemoryRepository())
+	defer models.SetDataDir(".")
+	models.SetRepository(models.NewMemoryRepository())
+	models.SetDataDir(t.TempDir())
+	todo := models.AddTodo(models.Todo{Title: "Book the flights"})
+	snapshot, err := models.TakeSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	models.DeleteAllTodos()
+	sink := eventsOf{id: todo.Id, events: make(chan plugins.Event, 1)}
+	plugins.RegisterEventSink(sink)
+
+	// Act
+	//
+	recorder := serveRoute(t, http.MethodPost, "/admin/restore/"+snapshot.Id, "")
+	var event plugins.Event
+	select {
+	case event = <-sink.events:
+	case <-time.After(time.Second):
+	}
+
+	// Assert
+	//
+	if recorder.Code != http.StatusOK {
+		t.Error("Fehler", recorder.Code, recorder.Body.String())
+	}
+	if event.Type != plugins.TodoCreated || event.Todo.Title != "Book the flights" {
+		t.Error("Fehler", event)
+	}
+}
@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"todo-rest-backend/models"
+)
+
+// soakGoroutineTolerance is the number of goroutines the server may have more after the load than before,
+// e.g. started once by the first request
+const soakGoroutineTolerance = 5
+
+// soakHeapTolerance is the growth of the heap after the warm-up that is not considered a leak
+const soakHeapTolerance = 4 << 20
+
+// TestSoak runs the server under sustained mixed load and fails if goroutines or the heap keep growing.
+// It only runs with TODO_SOAK_DURATION set, e.g. TODO_SOAK_DURATION=10m go test ./controllers -run Soak -timeout 0.
+// TODO_SOAK_WORKERS sets the number of concurrent clients, default 8.
+func TestSoak(t *testing.T) {
+	duration, err := time.ParseDuration(os.Getenv("TODO_SOAK_DURATION"))
+	if err != nil {
+		t.Skip("TODO_SOAK_DURATION is not set")
+	}
+	workers := 8
+	if value := os.Getenv("TODO_SOAK_WORKERS"); value != "" {
+		workers, err = strconv.Atoi(value)
+		if err != nil || workers <= 0 {
+			t.Fatal("TODO_SOAK_WORKERS must be a positive number")
+		}
+	}
+
+	// Arrange
+	//
+	err = Configure(models.NewMemoryRepository(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer models.SetRepository(models.NewMemoryRepository())
+	handler, err := newHandler("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(normalizedPaths(handler))
+	defer server.Close()
+	client := &http.Client{Transport: &http.Transport{MaxIdleConnsPerHost: workers}}
+	goroutinesBefore := runtime.NumGoroutine()
+
+	// Act
+	//
+	stop := make(chan struct{})
+	var wait sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wait.Add(1)
+		go func(worker int) {
+			defer wait.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				err := soakRound(client, server.URL, fmt.Sprintf("%d-%d", worker, i))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(worker)
+	}
+
+	warmUp := duration / 10
+	time.Sleep(warmUp)
+	heapAfterWarmUp := soakHeap()
+	t.Logf("after warm-up: %d goroutines, heap %d KiB", runtime.NumGoroutine(), heapAfterWarmUp>>10)
+	deadline := time.Now().Add(duration - warmUp)
+	for time.Now().Before(deadline) {
+		time.Sleep(min(time.Minute, time.Until(deadline)))
+		t.Logf("%d goroutines, heap %d KiB", runtime.NumGoroutine(), soakHeap()>>10)
+	}
+	close(stop)
+	wait.Wait()
+	client.CloseIdleConnections()
+
+	// Assert
+	//
+	goroutinesAfter := runtime.NumGoroutine()
+	for settle := time.Now().Add(5 * time.Second); goroutinesAfter > goroutinesBefore+soakGoroutineTolerance && time.Now().Before(settle); {
+		time.Sleep(100 * time.Millisecond)
+		goroutinesAfter = runtime.NumGoroutine()
+	}
+	if goroutinesAfter > goroutinesBefore+soakGoroutineTolerance {
+		var dump strings.Builder
+		pprof.Lookup("goroutine").WriteTo(&dump, 1)
+		t.Errorf("goroutines leaked: %d before the load, %d after\n%s", goroutinesBefore, goroutinesAfter, dump.String())
+	}
+	heapAfter := soakHeap()
+	if heapAfter > heapAfterWarmUp+max(heapAfterWarmUp/2, soakHeapTolerance) {
+		t.Errorf("heap grew from %d KiB after the warm-up to %d KiB", heapAfterWarmUp>>10, heapAfter>>10)
+	}
+}
+
+// soakRound creates, reads, lists, changes and deletes a todo, the store keeps its size over the rounds
+func soakRound(client *http.Client, url string, name string) error {
+	var created struct {
+		Data models.Todo `json:"data"`
+	}
+	body := fmt.Sprintf(`{"title": "Soak %s", "tags": ["soak"], "priority": "high"}`, name)
+	err := soakRequest(client, http.MethodPost, url+"/todos", body, &created)
+	if err != nil {
+		return err
+	}
+	path := url + "/todos/" + created.Data.Id
+	for _, step := range []struct{ method, url, body string }{
+		{http.MethodGet, path, ""},
+		{http.MethodGet, url + "/todos?tag=soak&sort=priority&page=1", ""},
+		{http.MethodGet, url + "/tags", ""},
+		{http.MethodPost, path + "/items", `{"title": "Step"}`},
+		{http.MethodPut, path, `{"title": "Soaked", "terminated": true}`},
+		{http.MethodGet, url + "/todos/revision", ""},
+		{http.MethodDelete, path, ""},
+	} {
+		err = soakRequest(client, step.method, step.url, step.body, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func soakRequest(client *http.Client, method string, url string, body string, data interface{}) error {
+	request, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	// every worker changes todos of its own, the changes apply to their current version
+	if method == http.MethodPut || method == http.MethodDelete {
+		request.Header.Set("If-Match", "*")
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%s %s: status %d", method, url, response.StatusCode)
+	}
+	if data == nil {
+		// the connection is only reused after the body was read
+		_, err = io.Copy(io.Discard, response.Body)
+		return err
+	}
+	return json.NewDecoder(response.Body).Decode(data)
+}
+
+// soakHeap returns the bytes of the live heap objects after a garbage collection
+func soakHeap() uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"fmt"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+	"todo-rest-backend/models"
+)
+
+// defaultLocale collates the titles of requests without a supported Accept-Language, TODO_LOCALE overrides it
+var defaultLocale = language.English
+
+var collationMatcher = newCollationMatcher()
+
+// newCollationMatcher matches the languages with a collation, the default locale first.
+// Alternative collations like the German phone book order are left out, "de" selects the standard German order.
+func newCollationMatcher() language.Matcher {
+	tags := []language.Tag{defaultLocale}
+	for _, tag := range collate.Supported() {
+		if len(tag.Extensions()) == 0 {
+			tags = append(tags, tag)
+		}
+	}
+	return language.NewMatcher(tags)
+}
+
+// configureSorting reads the locale used for requests without Accept-Language from TODO_LOCALE, e.g. "de"
+func configureSorting() error {
+	locale := os.Getenv("TODO_LOCALE")
+	if locale == "" {
+		return nil
+	}
+
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return fmt.Errorf("invalid TODO_LOCALE %q: %w", locale, err)
+	}
+	defaultLocale = tag
+	collationMatcher = newCollationMatcher()
+	return nil
+}
+
+// titleCollator compares titles in the language the client accepts, e.g. "Äpfel" before "Birnen" for German
+func titleCollator(request *http.Request) *collate.Collator {
+	tag, _ := language.MatchStrings(collationMatcher, request.Header.Get("Accept-Language"))
+	return collate.New(tag, collate.IgnoreCase)
+}
+
+// sortTodosAfterCreation sorts the todos by their creation, todos created before the creation time was
+// recorded come first and keep their order
+func sortTodosAfterCreation(todos []models.Todo) []models.Todo {
+	return sortTodosAfterTime(todos, func(todo models.Todo) *time.Time { return todo.CreatedAt })
+}
+
+// sortTodosAfterUpdate sorts the todos by their last change, todos not changed since the update time was
+// recorded come first and keep their order
+func sortTodosAfterUpdate(todos []models.Todo) []models.Todo {
+	return sortTodosAfterTime(todos, func(todo models.Todo) *time.Time { return todo.UpdatedAt })
+}
+
+// sortTodosAfterTime sorts the todos by one of their times, todos without the time come first
+func sortTodosAfterTime(todos []models.Todo, timeOf func(todo models.Todo) *time.Time) []models.Todo {
+	sort.SliceStable(todos, func(i, j int) bool {
+		left, right := timeOf(todos[i]), timeOf(todos[j])
+		if left == nil || right == nil {
+			return left == nil && right != nil
+		}
+		return left.Before(*right)
+	})
+
+	return todos
+}
+
+// sortTodosAfterPriority sorts the todos by their priority, the highest first and todos without priority last
+func sortTodosAfterPriority(todos []models.Todo) []models.Todo {
+	sort.SliceStable(todos, func(i, j int) bool {
+		return models.PriorityRank(todos[i].Priority) > models.PriorityRank(todos[j].Priority)
+	})
+
+	return todos
+}
+
+// reverseTodos reverses the order of the todos for descending sorts
+func reverseTodos(todos []models.Todo) []models.Todo {
+	for i, j := 0, len(todos)-1; i < j; i, j = i+1, j-1 {
+		todos[i], todos[j] = todos[j], todos[i]
+	}
+	return todos
+}
+
+// sortTodosAfterTitle sorts the todos by title with the collator, todos with equal titles keep their order
+func sortTodosAfterTitle(todos []models.Todo, collator *collate.Collator) []models.Todo {
+	sort.SliceStable(todos, func(i, j int) bool {
+		return collator.CompareString(todos[i].Title, todos[j].Title) < 0
+	})
+
+	return todos
+}
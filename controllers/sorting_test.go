@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-rest-backend/models"
+)
+
+func TestSortTodosAfterTitle_UsesAcceptLanguage(t *testing.T) {
+	for language, want := range map[string]string{"de-CH,de;q=0.9": "Ärger", "sv": "Zebra"} {
+		// Arrange
+		//
+		todos := []models.Todo{{Id: "0", Title: "Zebra"}, {Id: "1", Title: "Ärger"}, {Id: "2", Title: "apfel"}}
+		request := httptest.NewRequest(http.MethodGet, "/todos?sort=title", nil)
+		request.Header.Set("Accept-Language", language)
+
+		// Act
+		//
+		got := sortTodosAfterTitle(todos, titleCollator(request))
+
+		// Assert
+		//
+		if got[0].Title != "apfel" || got[1].Title != want {
+			t.Errorf("%s: got %v", language, got)
+		}
+	}
+}
+
+func TestSortTodosAfterPriority(t *testing.T) {
+	// Arrange
+	//
+	todos := []models.Todo{
+		{Id: "0", Priority: models.PriorityLow},
+		{Id: "1"},
+		{Id: "2", Priority: models.PriorityHigh},
+		{Id: "3", Priority: models.PriorityMedium},
+		{Id: "4", Priority: models.PriorityHigh},
+	}
+
+	// Act
+	//
+	got := sortTodosAfterPriority(todos)
+
+	// Assert
+	//
+	var ids string
+	for _, todo := range got {
+		ids += todo.Id
+	}
+	if ids != "24301" {
+		t.Error("Fehler", ids)
+	}
+}
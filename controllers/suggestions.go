@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+	"todo-rest-backend/models"
+	"todo-rest-backend/search"
+)
+
+// TodoSuggestions are the due date and priority proposed for a todo, the client applies them with PUT /todos/:id.
+// They are left out if no similar todo was completed yet.
+type TodoSuggestions struct {
+	DueDate  string `json:"due_date,omitempty"`
+	Priority string `json:"priority,omitempty"`
+	// Basis is the number of similar completed todos the suggestions are based on
+	Basis int `json:"basis"`
+	// CompletionDays is the median of the days the similar todos took from their creation to their completion
+	CompletionDays float64 `json:"completion_days"`
+}
+
+// similarCompleted tells whether the completed todo is similar to the todo: its title is similar or it has a
+// tag of the todo
+func similarCompleted(todo models.Todo, completed models.Todo) bool {
+	if search.Similarity(todo.Title, completed.Title) >= defaultSimilarityThreshold {
+		return true
+	}
+	for _, tag := range todo.Tags {
+		if completed.HasTag(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestFor proposes a due date and a priority from the completion times of the similar todos of the history.
+// The todo is due the median completion time after its creation, but not before today. Todos similar ones
+// were done within a day get high priority, within a week medium and low otherwise.
+func suggestFor(todo models.Todo, history []models.Todo, now time.Time) TodoSuggestions {
+	var durations []time.Duration
+	for _, completed := range history {
+		if completed.Id == todo.Id || completed.CreatedAt == nil || completed.CompletedAt == nil {
+			continue
+		}
+		if similarCompleted(todo, completed) {
+			durations = append(durations, completed.CompletedAt.Sub(*completed.CreatedAt))
+		}
+	}
+	if len(durations) == 0 {
+		return TodoSuggestions{}
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	median := durations[len(durations)/2]
+	if len(durations)%2 == 0 {
+		median = (durations[len(durations)/2-1] + durations[len(durations)/2]) / 2
+	}
+	days := median.Hours() / 24
+	suggestions := TodoSuggestions{Basis: len(durations), CompletionDays: math.Round(days*10) / 10}
+
+	start := now
+	if todo.CreatedAt != nil && todo.CreatedAt.After(now) == false {
+		start = *todo.CreatedAt
+	}
+	due := start.In(now.Location()).AddDate(0, 0, int(math.Ceil(days)))
+	if due.Before(now) {
+		due = now
+	}
+	suggestions.DueDate = due.Format(models.DateFormat)
+
+	switch {
+	case days <= 1:
+		suggestions.Priority = models.PriorityHigh
+	case days <= 7:
+		suggestions.Priority = models.PriorityMedium
+	default:
+		suggestions.Priority = models.PriorityLow
+	}
+	return suggestions
+}
+
+// TodoSuggestionsGet Handler for the suggestions of a todo action, it proposes a due date and a priority from the
+// completion times of similar todos
+// GET /todos/:id/suggestions
+func TodoSuggestionsGet(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	todo, ok := models.FindTodo(params.ByName("id"))
+	if ok == false {
+		return handleTodoIdNotFound(writer)
+	}
+
+	response := models.JsonExtendedResponse{Data: suggestFor(todo, models.AllTodos(), models.Now())}
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
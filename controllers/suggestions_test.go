@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+	"todo-rest-backend/models"
+)
+
+// completedAfter returns a completed todo created on the first of May that took the days
+func completedAfter(id string, title string, days int) models.Todo {
+	createdAt := time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local)
+	completedAt := createdAt.AddDate(0, 0, days)
+	return models.Todo{Id: id, Title: title, Terminated: true, CreatedAt: &createdAt, CompletedAt: &completedAt}
+}
+
+func TestSuggestFor_ProposesTheMedianCompletionTime(t *testing.T) {
+	// Arrange
+	//
+	now := time.Date(2024, 5, 8, 12, 0, 0, 0, time.Local)
+	todo := models.Todo{Id: "9", Title: "Renew the passport", CreatedAt: &now}
+	history := []models.Todo{
+		completedAfter("0", "Renew the passports", 2),
+		completedAfter("1", "Renew the passport", 4),
+		completedAfter("2", "Renew passport", 20),
+		completedAfter("3", "Water the plants", 1),
+		todo,
+	}
+
+	// Act
+	//
+	suggestions := suggestFor(todo, history, now)
+
+	// Assert
+	//
+	if suggestions.Basis != 3 || suggestions.CompletionDays != 4 {
+		t.Error("Fehler", suggestions)
+	}
+	if suggestions.DueDate != "2024-05-12" || suggestions.Priority != models.PriorityMedium {
+		t.Error("Fehler", suggestions)
+	}
+}
+
+func TestSuggestFor_WithoutSimilarTodos(t *testing.T) {
+	// Arrange
+	//
+	now := time.Date(2024, 5, 8, 12, 0, 0, 0, time.Local)
+	todo := models.Todo{Id: "9", Title: "Renew the passport", CreatedAt: &now}
+	history := []models.Todo{completedAfter("3", "Water the plants", 1), {Id: "4", Title: "Renew the passport"}}
+
+	// Act
+	//
+	suggestions := suggestFor(todo, history, now)
+
+	// Assert
+	//
+	if suggestions != (TodoSuggestions{}) {
+		t.Error("Fehler", suggestions)
+	}
+}
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"log"
+	"net/http"
+	"os"
+	"time"
+	"todo-rest-backend/issuesync"
+	"todo-rest-backend/jobs"
+	"todo-rest-backend/models"
+	"todo-rest-backend/plugins"
+)
+
+// queuedSync is a todo waiting to be mirrored to the issue tracker, pushed todos are read from the store when
+// they are pushed and removed todos close their issue
+type queuedSync struct {
+	todo    models.Todo
+	removed bool
+}
+
+// syncQueue are the todos the handlers changed since the last run of the sync job, guarded by the store mutex
+var syncQueue []queuedSync
+var issueSyncJob *jobs.Job
+
+// configureIssueSync configures the issue trackers from the TODO_SYNC_* variables and starts the job pushing
+// the changed todos to them, every TODO_SYNC_INTERVAL (default 1s). The trackers are called without holding
+// the store, so that a slow tracker does not delay the requests.
+func configureIssueSync() error {
+	if issueSyncJob != nil {
+		issueSyncJob.Stop()
+		issueSyncJob = nil
+	}
+	syncQueue = nil
+	err := issuesync.ConfigureFromEnv()
+	if err != nil || issuesync.Enabled() == false {
+		return err
+	}
+
+	interval := time.Second
+	if value := os.Getenv("TODO_SYNC_INTERVAL"); value != "" {
+		interval, err = time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return errors.New("TODO_SYNC_INTERVAL must be a positive duration like 500ms")
+		}
+	}
+	issueSyncJob = jobs.Start("issue-sync", interval, pushQueuedTodos)
+	return nil
+}
+
+// SyncStatusGet Handler for the issue sync status action
+// GET /sync/status
+func SyncStatusGet(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+	response := models.JsonExtendedResponse{Data: issuesync.Status()}
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// SyncWebhookPost Handler for the issue events sent by GitHub or Jira
+// POST /sync/webhook
+func SyncWebhookPost(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	if request.Body == nil {
+		return handleTodoNotProperlyTransmitted(writer)
+	}
+
+	issue, err := issuesync.ReceiveWebhook(request)
+	if err != nil {
+		return handleTodoNotProperlyTransmittedGeneral(writer, "Invalid Webhook Event")
+	}
+
+	todo, changed, err := issuesync.ResolveRemote(issue)
+	if err == issuesync.ErrUnknownIssue {
+		// Issues created in the tracker itself are not imported
+		writer.WriteHeader(http.StatusAccepted)
+		return nil
+	}
+	if err == issuesync.ErrUnpushedChanges {
+		// the local changes win, they are pushed again
+		syncTodo(todo)
+	}
+
+	if changed {
+		todo, _ = models.UpdateTodo(todo.Id, todo)
+		plugins.Emit(plugins.TodoUpdated, todo)
+		err = models.UpdateDataInFile()
+		if err != nil {
+			return err
+		}
+	}
+
+	response := models.JsonExtendedResponse{Data: todo}
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// syncTodo queues a created or updated todo to be mirrored to the issue tracker, the sync job stores the
+// reference of a created issue. The store mutex must be held.
+func syncTodo(todo models.Todo) {
+	if issuesync.Enabled() {
+		syncQueue = append(syncQueue, queuedSync{todo: todo})
+	}
+}
+
+// syncRemovedTodos queues the removed todos to close their issues, the store mutex must be held
+func syncRemovedTodos(todos ...models.Todo) {
+	if issuesync.Enabled() == false {
+		return
+	}
+	for _, todo := range todos {
+		syncQueue = append(syncQueue, queuedSync{todo: todo, removed: true})
+	}
+}
+
+// pushQueuedTodos mirrors the queued todos in the order they changed, a todo changed several times is pushed
+// once in its current state. The store is only held to take the queue and to store the issue references.
+func pushQueuedTodos() {
+	storeMutex.Lock()
+	queue := syncQueue
+	syncQueue = nil
+	var pending []queuedSync
+	for i, queued := range queue {
+		if changedLater(queue[i+1:], queued.todo.Id) {
+			continue
+		}
+		if queued.removed == false {
+			var ok bool
+			queued.todo, ok = models.FindTodo(queued.todo.Id)
+			if ok == false {
+				continue
+			}
+		}
+		pending = append(pending, queued)
+	}
+	storeMutex.Unlock()
+
+	var created []models.Todo
+	for _, queued := range pending {
+		if queued.removed {
+			issuesync.RemoveTodo(queued.todo)
+			continue
+		}
+		synced := issuesync.PushTodo(queued.todo)
+		if synced.ExternalRef != queued.todo.ExternalRef {
+			created = append(created, synced)
+		}
+	}
+	if len(created) == 0 {
+		return
+	}
+
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+	for _, todo := range created {
+		_, ok := models.SetExternalRef(todo.Id, todo.ExternalRef)
+		if ok == false {
+			// removed while its issue was created, the issue is closed with the next run
+			syncQueue = append(syncQueue, queuedSync{todo: todo, removed: true})
+		}
+	}
+	err := models.UpdateDataInFile()
+	if err != nil {
+		log.Println("Cannot store the references of the issues:", err)
+	}
+	invalidateResponseCache()
+}
+
+// changedLater tells whether the todo is queued again
+func changedLater(queue []queuedSync, id string) bool {
+	for _, queued := range queue {
+		if queued.todo.Id == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"todo-rest-backend/issuesync"
+	"todo-rest-backend/models"
+)
+
+// countingTracker counts the issues created in the tracker
+type countingTracker struct {
+	created int
+}
+
+func (c *countingTracker) Name() string { return "counting" }
+
+func (c *countingTracker) Create(todo models.Todo) (string, error) {
+	c.created++
+	return "counting:" + todo.Id, nil
+}
+
+func (c *countingTracker) Update(string, models.Todo) error { return nil }
+
+func (c *countingTracker) Close(string) error { return nil }
+
+func (c *countingTracker) ParseWebhook(*http.Request) (issuesync.RemoteIssue, error) {
+	return issuesync.RemoteIssue{}, nil
+}
+
+func TestSyncTodo_PushesAfterTheRequest(t *testing.T) {
+	// Arrange
+	//
+	defer issuesync.Configure(nil, "")
+	tracker := &countingTracker{}
+	issuesync.Configure(tracker, issuesync.ConflictLocalWins)
+	var created struct {
+		Data models.Todo `json:"data"`
+	}
+	json.NewDecoder(serveRoute(t, http.MethodPost, "/todos", `{"title": "Renew the passport"}`).Body).Decode(&created)
+	defer models.RemoveTodo(created.Data.Id)
+	serveRoute(t, http.MethodPut, "/todos/"+created.Data.Id, `{"title": "Renew the passport soon"}`, "If-Match", "*")
+	createdInRequest := tracker.created
+
+	// Act
+	//
+	pushQueuedTodos()
+	pushed, _ := models.FindTodo(created.Data.Id)
+
+	// Assert
+	//
+	if createdInRequest != 0 || tracker.created != 1 {
+		t.Error("Fehler", createdInRequest, tracker.created)
+	}
+	if pushed.ExternalRef != "counting:"+created.Data.Id || pushed.Title != "Renew the passport soon" {
+		t.Error("Fehler", pushed)
+	}
+}
@@ -0,0 +1,20 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"todo-rest-backend/models"
+)
+
+// TagsGet Handler for the tags get action, the tags of all todos with the number of todos tagged with them
+// GET /tags
+func TagsGet(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+	response := models.JsonExtendedResponse{
+		Meta: models.RevisionMeta{Revision: models.Revision()},
+		Data: models.TagCounts(models.AllTodos()),
+	}
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+	"todo-rest-backend/jobs"
+	"todo-rest-backend/models"
+	"todo-rest-backend/plugins"
+)
+
+// PurgeResult is returned by the purge action
+type PurgeResult struct {
+	Purged int `json:"purged"`
+}
+
+// TrashMeta is the retention of the trash, omitted while the trash is kept until it is purged by hand
+type TrashMeta struct {
+	RetentionDays int `json:"retention_days"`
+	// NextPurgeAt is the next run of the purge, it removes the todos deleted more than RetentionDays days ago
+	NextPurgeAt time.Time `json:"next_purge_at"`
+}
+
+var trashRetentionDays int
+var trashPurgeJob *jobs.Job
+
+// configureTrashRetention starts the purge of the trash if TODO_TRASH_RETENTION_DAYS is set, todos deleted
+// more than that many days ago are removed for good. TODO_TRASH_PURGE_INTERVAL (default 1h) sets how often
+// the trash is purged.
+func configureTrashRetention() error {
+	daysValue := os.Getenv("TODO_TRASH_RETENTION_DAYS")
+	if daysValue == "" {
+		return nil
+	}
+
+	days, err := strconv.Atoi(daysValue)
+	if err != nil || days < 0 {
+		return errors.New("TODO_TRASH_RETENTION_DAYS must be a number of days")
+	}
+	interval := time.Hour
+	if value := os.Getenv("TODO_TRASH_PURGE_INTERVAL"); value != "" {
+		interval, err = time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return errors.New("TODO_TRASH_PURGE_INTERVAL must be a positive duration like 30m")
+		}
+	}
+
+	trashRetentionDays = days
+	trashPurgeJob = jobs.Start("trash-purge", interval, func() {
+		storeMutex.Lock()
+		defer storeMutex.Unlock()
+		purgeExpiredTrash()
+	})
+	return nil
+}
+
+// purgeExpiredTrash removes the todos deleted before the retention period, the store mutex must be held
+func purgeExpiredTrash() []models.Todo {
+	purged := models.PurgeTrash(models.Now().AddDate(0, 0, -trashRetentionDays))
+	if len(purged) == 0 {
+		return purged
+	}
+	err := models.UpdateDataInFile()
+	if err != nil {
+		log.Println("Cannot store the purged trash:", err)
+	}
+	invalidateResponseCache()
+	return purged
+}
+
+// TrashGet Handler for the trash action, the deleted todos that can be restored, the last deleted first.
+// With a retention the meta tells when the todos deleted before the retention period are purged next.
+// GET /todos/trash
+func TrashGet(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+	todos := sortTodosAfterIdAscending(models.TrashedTodos())
+	sort.SliceStable(todos, func(i, j int) bool {
+		return todos[i].DeletedAt.After(*todos[j].DeletedAt)
+	})
+
+	response := models.JsonDataResponse{Data: todos}
+	if trashPurgeJob != nil {
+		response.Meta = TrashMeta{RetentionDays: trashRetentionDays, NextPurgeAt: trashPurgeJob.NextRun()}
+	}
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// TrashDelete Handler for the purge action, the todos in the trash are removed for good
+// DELETE /todos/trash
+func TrashDelete(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+	purged := models.PurgeTrash(time.Time{})
+	err := models.UpdateDataInFile()
+	if err != nil {
+		return err
+	}
+
+	response := models.JsonExtendedResponse{Data: PurgeResult{Purged: len(purged)}}
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+// TodoRestorePost Handler for the restore action, the todo is taken out of the trash
+// POST /todos/:id/restore
+func TodoRestorePost(writer http.ResponseWriter, _ *http.Request, params httprouter.Params) error {
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	todo, ok := models.RestoreTodo(params.ByName("id"))
+	if ok == false {
+		return handleTodoIdNotFound(writer)
+	}
+	// the issue closed on the deletion is opened again
+	syncTodo(todo)
+	plugins.Emit(plugins.TodoCreated, todo)
+
+	err := models.UpdateDataInFile()
+	if err != nil {
+		return err
+	}
+
+	response := models.JsonExtendedResponse{Data: todo}
+	writer.Header().Set("ETag", todoEtag(todo))
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+	"todo-rest-backend/clock"
+	"todo-rest-backend/models"
+)
+
+func TestRegisterRoutes_MovesDeletedTodosToTheTrash(t *testing.T) {
+	// Arrange
+	//
+	// the todos are deleted after the ones other tests left in the trash
+	fake := clock.NewFake(time.Now().Add(time.Hour))
+	defer models.SetClock(clock.NewSystem())
+	models.SetClock(fake)
+	restored := models.AddTodo(models.Todo{Title: "Water the plants"})
+	purged := models.AddTodo(models.Todo{Title: "Fax the form"})
+
+	// Act
+	//
+	serveRoute(t, http.MethodDelete, "/todos/"+restored.Id, "", "If-Match", "*")
+	fake.Advance(time.Minute)
+	serveRoute(t, http.MethodDelete, "/todos/"+purged.Id, "", "If-Match", "*")
+	deleted := serveRoute(t, http.MethodGet, "/todos/"+restored.Id, "")
+	var trash models.JsonDataResponse
+	json.NewDecoder(serveRoute(t, http.MethodGet, "/todos/trash", "").Body).Decode(&trash)
+	restore := serveRoute(t, http.MethodPost, "/todos/"+restored.Id+"/restore", "")
+	restoredAgain := serveRoute(t, http.MethodPost, "/todos/"+restored.Id+"/restore", "")
+	purge := serveRoute(t, http.MethodDelete, "/todos/trash", "")
+	_, purgedFound := models.TodoStore()[purged.Id]
+	found, restoredFound := models.FindTodo(restored.Id)
+
+	// Assert
+	//
+	if deleted.Code != http.StatusNotFound || len(trash.Data) < 2 || trash.Data[0].Id != purged.Id ||
+		trash.Data[0].DeletedAt == nil || trash.Data[1].Id != restored.Id {
+		t.Error("Fehler", deleted.Code, trash.Data)
+	}
+	if restore.Code != http.StatusOK || restoredAgain.Code != http.StatusNotFound {
+		t.Error("Fehler", restore.Code, restoredAgain.Code)
+	}
+	if purge.Code != http.StatusOK || purgedFound || restoredFound == false || found.DeletedAt != nil {
+		t.Error("Fehler", purge.Code, purgedFound, found)
+	}
+}
+
+func TestConfigureTrashRetention_PurgesExpiredTodos(t *testing.T) {
+	// Arrange
+	//
+	t.Setenv("TODO_TRASH_RETENTION_DAYS", "7")
+	// the todos deleted in the future are kept away from the trash of the other tests
+	defer models.SetRepository(models.NewMemoryRepository())
+	models.SetRepository(models.NewMemoryRepository())
+	fake := clock.NewFake(time.Now().Add(2 * time.Hour))
+	defer models.SetClock(clock.NewSystem())
+	models.SetClock(fake)
+	expired := models.AddTodo(models.Todo{Title: "Cancel the newspaper"})
+	kept := models.AddTodo(models.Todo{Title: "Return the library books"})
+	models.RemoveTodo(expired.Id)
+	fake.Advance(10 * 24 * time.Hour)
+	models.RemoveTodo(kept.Id)
+
+	// Act
+	//
+	err := configureTrashRetention()
+	defer func() {
+		trashPurgeJob.Stop()
+		trashPurgeJob, trashRetentionDays = nil, 0
+	}()
+	storeMutex.Lock()
+	purged := purgeExpiredTrash()
+	storeMutex.Unlock()
+	recorder := serveRoute(t, http.MethodGet, "/todos/trash", "")
+	var trash struct {
+		Meta TrashMeta     `json:"meta"`
+		Data []models.Todo `json:"data"`
+	}
+	json.NewDecoder(recorder.Body).Decode(&trash)
+	_, expiredFound := models.TodoStore()[expired.Id]
+	_, keptFound := models.TodoStore()[kept.Id]
+
+	// Assert
+	//
+	if err != nil || len(purged) == 0 || expiredFound || keptFound == false {
+		t.Error("Fehler", err, purged, expiredFound, keptFound)
+	}
+	if trash.Meta.RetentionDays != 7 || trash.Meta.NextPurgeAt.IsZero() || len(trash.Data) == 0 {
+		t.Error("Fehler", trash.Meta, trash.Data)
+	}
+}
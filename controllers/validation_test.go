@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-rest-backend/models"
+)
+
+func TestTodoPost_ListsFieldViolations(t *testing.T) {
+	// Arrange
+	//
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(`{"id": "7", "title": ""}`))
+
+	// Act
+	//
+	TodoPost(recorder, request, nil)
+
+	// Assert
+	//
+	var response models.JsonErrorResponse
+	json.NewDecoder(recorder.Body).Decode(&response)
+	if recorder.Code != http.StatusUnprocessableEntity || len(response.Error.Details) != 2 {
+		t.Fatal("Fehler", recorder.Code, response)
+	}
+	if response.Error.Details[0].Field != "id" || response.Error.Details[1].Field != "title" {
+		t.Error("Fehler", response.Error.Details)
+	}
+}
+
+func TestTodoPost_CreatesArraysAllOrNone(t *testing.T) {
+	// Arrange
+	//
+	before := len(models.AllTodos())
+	invalid := httptest.NewRecorder()
+	created := httptest.NewRecorder()
+
+	// Act
+	//
+	TodoPost(invalid, httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(`[{"title": "Pack"}, {"title": ""}]`)), nil)
+	TodoPost(created, httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(` [{"title": "Pack"}, {"title": "Travel"}]`)), nil)
+
+	// Assert
+	//
+	var violations models.JsonErrorResponse
+	json.NewDecoder(invalid.Body).Decode(&violations)
+	if invalid.Code != http.StatusUnprocessableEntity || len(violations.Error.Details) != 1 || violations.Error.Details[0].Field != "1.title" {
+		t.Error("Fehler", invalid.Code, violations)
+	}
+	var response struct {
+		Data []models.Todo `json:"data"`
+	}
+	json.NewDecoder(created.Body).Decode(&response)
+	if created.Code != http.StatusCreated || len(response.Data) != 2 || response.Data[1].Title != "Travel" {
+		t.Fatal("Fehler", created.Code, response)
+	}
+	if response.Data[0].Id == "" || response.Data[0].Number+1 != response.Data[1].Number || len(models.AllTodos()) != before+2 {
+		t.Error("Fehler", response.Data, len(models.AllTodos()))
+	}
+}
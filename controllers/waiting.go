@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+	"todo-rest-backend/jobs"
+	"todo-rest-backend/models"
+	"todo-rest-backend/plugins"
+)
+
+// nudgeDays is the number of days after which waiting todos are nudged, 0 disables the nudges
+var nudgeDays int
+
+// WaitingMeta is the meta information of the waiting view
+type WaitingMeta struct {
+	NudgeAfterDays int `json:"nudge_after_days,omitempty"`
+}
+
+// configureWaiting starts the nudge reminders if TODO_WAITING_NUDGE_DAYS is set.
+// The registered notifiers are asked to remind of todos waiting that many days, TODO_WAITING_NUDGE_INTERVAL
+// (default 1h) sets how often the waiting todos are checked.
+func configureWaiting() error {
+	daysValue := os.Getenv("TODO_WAITING_NUDGE_DAYS")
+	if daysValue == "" {
+		return nil
+	}
+
+	days, err := strconv.Atoi(daysValue)
+	if err != nil || days <= 0 {
+		return errors.New("TODO_WAITING_NUDGE_DAYS must be a positive number of days")
+	}
+	interval := time.Hour
+	if value := os.Getenv("TODO_WAITING_NUDGE_INTERVAL"); value != "" {
+		interval, err = time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return errors.New("TODO_WAITING_NUDGE_INTERVAL must be a positive duration like 30m")
+		}
+	}
+
+	nudgeDays = days
+	jobs.Start("waiting-nudge", interval, sendNudges)
+	return nil
+}
+
+// sendNudges notifies of the todos waiting too long, the notifiers are called without holding the store
+func sendNudges() {
+	storeMutex.Lock()
+	nudges := models.DueNudges(nudgeDays)
+	if len(nudges) > 0 {
+		err := models.UpdateDataInFile()
+		if err != nil {
+			log.Println("Cannot store the nudges:", err)
+		}
+		invalidateResponseCache()
+	}
+	storeMutex.Unlock()
+
+	for _, todo := range nudges {
+		plugins.Notify(plugins.Notification{
+			Subject: fmt.Sprintf("Waiting on %s for %d days", todo.WaitingOn, todo.WaitingDays()),
+			Message: fmt.Sprintf("%q is waiting on %s since %s, time for a nudge.",
+				todo.Title, todo.WaitingOn, todo.WaitingSince.Format(models.DateFormat)),
+			Todo: todo,
+		})
+	}
+}
+
+// WaitingViewGet Handler for the waiting view action, the open todos waiting on someone, the longest waiting first
+// GET /views/waiting
+func WaitingViewGet(writer http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
+	response := models.JsonExtendedResponse{Meta: WaitingMeta{NudgeAfterDays: nudgeDays}, Data: models.WaitingTodos()}
+	writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	writer.WriteHeader(http.StatusOK)
+	return json.NewEncoder(writer).Encode(response)
+}
@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Warning is a problem of a request that did not keep it from succeeding, like a deprecated or unknown query
+// parameter. The warnings of a request are added to the meta information of its JSON response.
+type Warning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Codes of the warnings
+const (
+	WarningDeprecated       = "DEPRECATED"
+	WarningUnknownParameter = "UNKNOWN_PARAMETER"
+)
+
+// deprecation phases out an operation or one of its query parameters. Requests using it are answered as
+// before with a Deprecation header, a Sunset header once the date of the removal is decided, a Link to the
+// replacement and a warning.
+type deprecation struct {
+	// param is the deprecated query parameter, empty if the operation is deprecated
+	param string
+	// since is when it was deprecated
+	since time.Time
+	// sunset is when it stops working, zero until that is decided
+	sunset time.Time
+	// link points to the documentation of the replacement
+	link    string
+	message string
+}
+
+// warningsKey is the context key of the warnings of a request
+type warningsKey struct{}
+
+// addWarning adds a warning to the meta information of the response to the request
+func addWarning(request *http.Request, code string, message string) {
+	if warnings, ok := request.Context().Value(warningsKey{}).(*[]Warning); ok {
+		*warnings = append(*warnings, Warning{Code: code, Message: message})
+	}
+}
+
+// checkParameters warns of query parameters the operation of the request does not have and signals the
+// deprecations of the operation the request uses
+func checkParameters(writer http.ResponseWriter, request *http.Request) {
+	operation, ok := findApiOperation(request.Method, request.URL.Path)
+	if ok == false {
+		return
+	}
+	query := request.URL.Query()
+	for name := range query {
+		known := false
+		for _, param := range operation.query {
+			known = known || param == name
+		}
+		if known == false {
+			addWarning(request, WarningUnknownParameter, fmt.Sprintf("the query parameter %s is ignored", name))
+		}
+	}
+	for _, deprecated := range operation.deprecations {
+		if deprecated.param == "" || query.Has(deprecated.param) {
+			signalDeprecation(writer, request, deprecated)
+		}
+	}
+}
+
+func signalDeprecation(writer http.ResponseWriter, request *http.Request, deprecated deprecation) {
+	writer.Header().Set("Deprecation", fmt.Sprintf("@%d", deprecated.since.Unix()))
+	if deprecated.sunset.IsZero() == false {
+		writer.Header().Set("Sunset", deprecated.sunset.UTC().Format(http.TimeFormat))
+	}
+	if deprecated.link != "" {
+		writer.Header().Add("Link", "<"+deprecated.link+">; rel=\"deprecation\"")
+	}
+	addWarning(request, WarningDeprecated, deprecated.message)
+}
+
+// findApiOperation returns the documented operation of the method and the path, literal segments like
+// /todos/poll take precedence over parameters like /todos/:id
+func findApiOperation(method string, path string) (apiOperation, bool) {
+	segments := strings.Split(path, "/")
+	var found apiOperation
+	foundParams := -1
+	for _, operation := range apiOperations {
+		if operation.method != method {
+			continue
+		}
+		operationSegments := strings.Split(operation.path, "/")
+		if len(operationSegments) != len(segments) {
+			continue
+		}
+		params := 0
+		for i, segment := range operationSegments {
+			if strings.HasPrefix(segment, ":") {
+				params++
+			} else if segment != segments[i] {
+				params = -1
+				break
+			}
+		}
+		if params >= 0 && (foundParams < 0 || params < foundParams) {
+			found, foundParams = operation, params
+		}
+	}
+	return found, foundParams >= 0
+}
+
+// withWarnings collects the warnings of the request, the returned writer adds them to the meta information of
+// a JSON response. finish must be called when the handler returned.
+func withWarnings(writer http.ResponseWriter, request *http.Request) (*warningWriter, *http.Request) {
+	warned := &warningWriter{ResponseWriter: writer, warnings: &[]Warning{}}
+	return warned, request.WithContext(context.WithValue(request.Context(), warningsKey{}, warned.warnings))
+}
+
+// warningWriter passes the response through unless the request has warnings when the response starts, then it
+// buffers the response to add them to its meta information
+type warningWriter struct {
+	http.ResponseWriter
+	warnings  *[]Warning
+	status    int
+	buffering bool
+	body      bytes.Buffer
+}
+
+func (w *warningWriter) WriteHeader(status int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = status
+	w.buffering = len(*w.warnings) > 0
+	if w.buffering == false {
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (w *warningWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.buffering {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// finish writes a buffered response with the warnings in the meta information of a JSON object
+func (w *warningWriter) finish() error {
+	if w.buffering == false {
+		return nil
+	}
+	body := w.body.Bytes()
+	if strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		if merged, err := mergeWarnings(body, *w.warnings); err == nil {
+			body = merged
+		}
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	_, err := w.ResponseWriter.Write(body)
+	return err
+}
+
+// mergeWarnings adds the warnings to the meta field of the JSON object, keeping the order of its fields
+func mergeWarnings(body []byte, warnings []Warning) ([]byte, error) {
+	encodedWarnings, err := json.Marshal(warnings)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	token, err := decoder.Token()
+	if err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("the response is no JSON object")
+	}
+
+	var merged bytes.Buffer
+	merged.WriteString("{")
+	hasMeta := false
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		err = decoder.Decode(&value)
+		if err != nil {
+			return nil, err
+		}
+		if merged.Len() > 1 {
+			merged.WriteString(",")
+		}
+		name, _ := json.Marshal(key)
+		merged.Write(name)
+		merged.WriteString(":")
+		if key == "meta" {
+			hasMeta = true
+			value = metaWithWarnings(value, encodedWarnings)
+		}
+		merged.Write(value)
+	}
+	if hasMeta == false {
+		if merged.Len() > 1 {
+			merged.WriteString(",")
+		}
+		merged.WriteString(`"meta":`)
+		merged.Write(metaWithWarnings(nil, encodedWarnings))
+	}
+	merged.WriteString("}\n")
+	return merged.Bytes(), nil
+}
+
+// metaWithWarnings adds the warnings to the meta object, null becomes an object with the warnings only
+func metaWithWarnings(meta json.RawMessage, warnings []byte) json.RawMessage {
+	meta = bytes.TrimSpace(meta)
+	if len(meta) < 2 || meta[0] != '{' {
+		return json.RawMessage(`{"warnings":` + string(warnings) + `}`)
+	}
+	fields := bytes.TrimSpace(meta[1 : len(meta)-1])
+	separator := ","
+	if len(fields) == 0 {
+		separator = ""
+	}
+	return json.RawMessage("{" + string(fields) + separator + `"warnings":` + string(warnings) + "}")
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRegisterRoutes_AddsWarningsToTheMeta(t *testing.T) {
+	// Arrange
+	//
+	defer func(operations []apiOperation) { apiOperations = operations }(apiOperations)
+	apiOperations = append([]apiOperation{}, apiOperations...)
+	for i, operation := range apiOperations {
+		if operation.method == http.MethodGet && operation.path == "/todos" {
+			apiOperations[i].deprecations = []deprecation{{param: "terminated", since: time.Unix(1700000000, 0),
+				sunset: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), link: "https://example.com/status-filter",
+				message: "terminated is replaced by status"}}
+		}
+	}
+	router := httprouter.New()
+	RegisterRoutes(HttpRouter{Router: router})
+	warned, plain := httptest.NewRecorder(), httptest.NewRecorder()
+	var body struct {
+		Meta struct {
+			Revision uint64    `json:"revision"`
+			Warnings []Warning `json:"warnings"`
+		} `json:"meta"`
+	}
+
+	// Act
+	//
+	router.ServeHTTP(warned, httptest.NewRequest(http.MethodGet, "/todos?terminated=false&colour=red", nil))
+	router.ServeHTTP(plain, httptest.NewRequest(http.MethodGet, "/todos?page=1", nil))
+	errDecoded := json.NewDecoder(warned.Body).Decode(&body)
+
+	// Assert
+	//
+	if warned.Code != http.StatusOK || errDecoded != nil || len(body.Meta.Warnings) != 2 {
+		t.Error("Fehler", warned.Code, errDecoded, body)
+	}
+	if warned.Header().Get("Deprecation") != "@1700000000" || warned.Header().Get("Sunset") != "Tue, 01 Jan 2030 00:00:00 GMT" ||
+		warned.Header().Values("Link")[0] != `<https://example.com/status-filter>; rel="deprecation"` {
+		t.Error("Fehler", warned.Header())
+	}
+	if plain.Header().Get("Deprecation") != "" || json.Valid(plain.Body.Bytes()) == false ||
+		len(plain.Body.String()) == 0 || containsWarnings(plain.Body.Bytes()) {
+		t.Error("Fehler", plain.Header(), plain.Body.String())
+	}
+}
+
+func containsWarnings(body []byte) bool {
+	var response struct {
+		Meta struct {
+			Warnings []Warning `json:"warnings"`
+		} `json:"meta"`
+	}
+	json.Unmarshal(body, &response)
+	return len(response.Meta.Warnings) > 0
+}
+
+func TestMergeWarnings_KeepsTheFields(t *testing.T) {
+	// Arrange
+	//
+	warnings := []Warning{{Code: WarningUnknownParameter, Message: "the query parameter x is ignored"}}
+
+	// Act
+	//
+	withMeta, errWithMeta := mergeWarnings([]byte(`{"meta":{"revision":3},"data":[]}`), warnings)
+	withoutMeta, errWithoutMeta := mergeWarnings([]byte(`{"error":{"status":404}}`), warnings)
+	_, errNoObject := mergeWarnings([]byte(`[1, 2]`), warnings)
+
+	// Assert
+	//
+	expected := `{"meta":{"revision":3,"warnings":[{"code":"UNKNOWN_PARAMETER","message":"the query parameter x is ignored"}]},"data":[]}` + "\n"
+	if errWithMeta != nil || string(withMeta) != expected {
+		t.Error("Fehler", errWithMeta, string(withMeta))
+	}
+	if errWithoutMeta != nil || string(withoutMeta) != `{"error":{"status":404},"meta":{"warnings":[{"code":"UNKNOWN_PARAMETER","message":"the query parameter x is ignored"}]}}`+"\n" {
+		t.Error("Fehler", errWithoutMeta, string(withoutMeta))
+	}
+	if errNoObject == nil {
+		t.Error("Fehler")
+	}
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+	"todo-rest-backend/models"
+	"todo-rest-backend/weather"
+)
+
+const defaultOpenMeteoUrl = "https://api.open-meteo.com/v1/forecast"
+
+// forecastDays is how many days ahead the forecasts reach
+const forecastDays = 16
+
+// weatherProvider annotates the todos tagged with weatherTag if set
+var weatherProvider *weather.CachedProvider
+var weatherTag = "outdoor"
+
+// weatherLookup is a forecast a handler missed in the cache, it is fetched in the background
+type weatherLookup struct {
+	latitude  float64
+	longitude float64
+	day       time.Time
+}
+
+// weatherLookups is read by the goroutine fetching the forecasts, lookups are dropped if it is behind
+var weatherLookups chan weatherLookup
+
+// WeatherMeta is the meta information of an outdoor todo with a location
+type WeatherMeta struct {
+	Weather weather.Advisory `json:"weather"`
+}
+
+// configureWeather enables the weather advisories with TODO_WEATHER_PROVIDER=open-meteo.
+// TODO_WEATHER_URL replaces the API URL, TODO_WEATHER_TAG the tag of outdoor todos (default "outdoor").
+func configureWeather() error {
+	if weatherLookups != nil {
+		close(weatherLookups)
+		weatherLookups = nil
+	}
+	weatherProvider = nil
+
+	switch os.Getenv("TODO_WEATHER_PROVIDER") {
+	case "":
+		return nil
+	case "open-meteo":
+		apiUrl := os.Getenv("TODO_WEATHER_URL")
+		if apiUrl == "" {
+			apiUrl = defaultOpenMeteoUrl
+		}
+		startWeather(weather.NewOpenMeteo(apiUrl))
+	default:
+		return errors.New("TODO_WEATHER_PROVIDER must be open-meteo")
+	}
+
+	if tag := os.Getenv("TODO_WEATHER_TAG"); tag != "" {
+		weatherTag = tag
+	}
+	return nil
+}
+
+// startWeather caches the forecasts of the provider and starts the goroutine fetching the missed ones
+func startWeather(provider weather.Provider) {
+	cached := weather.NewCachedProvider(provider)
+	lookups := make(chan weatherLookup, 64)
+	go func() {
+		for lookup := range lookups {
+			_, err := cached.Forecast(lookup.latitude, lookup.longitude, lookup.day)
+			if err != nil {
+				log.Println("Weather forecast failed:", err)
+			}
+		}
+	}()
+	weatherProvider, weatherLookups = cached, lookups
+}
+
+// weatherMeta returns the advisory of the due day for outdoor todos with coordinates and nil for all other
+// todos. Only cached forecasts are returned, a missed one is fetched in the background so that the request
+// does not wait for the provider. Todos without due day or due beyond the forecasts get no advisory.
+func weatherMeta(todo models.Todo) interface{} {
+	if weatherProvider == nil || todo.HasTag(weatherTag) == false || todo.Location.HasCoordinates() == false {
+		return nil
+	}
+	if todo.DueDate == "" {
+		return nil
+	}
+	at, _, err := models.ParseDue(todo.DueDate)
+	if err != nil {
+		return nil
+	}
+	now := models.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	day := at.In(now.Location())
+	if day.Before(today) || day.After(today.AddDate(0, 0, forecastDays)) {
+		return nil
+	}
+
+	latitude, longitude := *todo.Location.Latitude, *todo.Location.Longitude
+	advisory, ok := weatherProvider.Cached(latitude, longitude, day)
+	if ok == false {
+		select {
+		case weatherLookups <- weatherLookup{latitude: latitude, longitude: longitude, day: day}:
+		default:
+		}
+		return nil
+	}
+	return WeatherMeta{Weather: advisory}
+}
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+	"todo-rest-backend/models"
+	"todo-rest-backend/weather"
+)
+
+// dayProvider forecasts rain and sends the days it is asked for
+type dayProvider struct {
+	days chan time.Time
+}
+
+func (p dayProvider) Forecast(_ float64, _ float64, day time.Time) (weather.Advisory, error) {
+	p.days <- day
+	return weather.Advisory{Date: day.Format(models.DateFormat), PrecipitationProbability: 80}, nil
+}
+
+func TestWeatherMeta_ForecastsTheDueDayInTheBackground(t *testing.T) {
+	// Arrange
+	//
+	provider := dayProvider{days: make(chan time.Time, 1)}
+	startWeather(provider)
+	defer configureWeather()
+	latitude, longitude := 47.3769, 8.5417
+	due := models.Now().AddDate(0, 0, 2).Format(models.DateFormat)
+	todo := models.Todo{Title: "Hike", Tags: []string{"outdoor"}, DueDate: due, Location: &models.Location{Latitude: &latitude, Longitude: &longitude}}
+
+	// Act
+	//
+	first := weatherMeta(todo)
+	var asked time.Time
+	select {
+	case asked = <-provider.days:
+	case <-time.After(time.Second):
+		t.Fatal("Fehler", "no forecast requested")
+	}
+	var second interface{}
+	for i := 0; i < 100 && second == nil; i++ {
+		second = weatherMeta(todo)
+		time.Sleep(time.Millisecond)
+	}
+
+	// Assert
+	//
+	if first != nil || asked.Format(models.DateFormat) != due {
+		t.Error("Fehler", first, asked)
+	}
+	if meta, ok := second.(WeatherMeta); ok == false || meta.Weather.Date != due {
+		t.Error("Fehler", second)
+	}
+}
+
+func TestWeatherMeta_SkipsTodosWithoutDueDate(t *testing.T) {
+	// Arrange
+	//
+	provider := dayProvider{days: make(chan time.Time, 1)}
+	startWeather(provider)
+	defer configureWeather()
+	latitude, longitude := 47.3769, 8.5417
+	todo := models.Todo{Title: "Hike", Tags: []string{"outdoor"}, Location: &models.Location{Latitude: &latitude, Longitude: &longitude}}
+
+	// Act
+	//
+	meta := weatherMeta(todo)
+	time.Sleep(10 * time.Millisecond)
+
+	// Assert
+	//
+	if meta != nil || len(provider.days) != 0 {
+		t.Error("Fehler", meta, len(provider.days))
+	}
+}
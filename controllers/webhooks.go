@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"io"
+	"net/http"
+	"os"
+	"time"
+	"todo-rest-backend/models"
+	"todo-rest-backend/webhooks"
+)
+
+// inboundSecret is the secret the requests of the simple API must be signed with, see webhooks.Sign.
+// The requests are not signed without.
+var inboundSecret string
+
+// inboundTolerance is how far the timestamp of a signed request may be off
+var inboundTolerance = webhooks.DefaultTolerance
+
+// inboundReplays remembers the nonces of the signed requests and the delivery ids of the issue trackers
+var inboundReplays = webhooks.NewReplayGuard(2 * webhooks.DefaultTolerance)
+
+// deliveryHeaders carry the ids of the webhook deliveries of GitHub and Jira
+var deliveryHeaders = []string{"X-GitHub-Delivery", "X-Atlassian-Webhook-Identifier"}
+
+// configureInboundWebhooks reads the secret of the signed requests from TODO_INBOUND_WEBHOOK_SECRET and
+// their tolerance from TODO_WEBHOOK_TOLERANCE, default 5m
+func configureInboundWebhooks() error {
+	inboundSecret = os.Getenv("TODO_INBOUND_WEBHOOK_SECRET")
+	inboundTolerance = webhooks.DefaultTolerance
+	if value := os.Getenv("TODO_WEBHOOK_TOLERANCE"); value != "" {
+		var err error
+		inboundTolerance, err = time.ParseDuration(value)
+		if err != nil || inboundTolerance <= 0 {
+			return errors.New("TODO_WEBHOOK_TOLERANCE must be a positive duration like 5m")
+		}
+	}
+	inboundReplays = webhooks.NewReplayGuard(2 * inboundTolerance)
+	return nil
+}
+
+// replayProtected rejects replayed requests of the inbound integrations with 409 Conflict. Requests of the
+// trackers are recognized by their delivery id. With signed and a secret configured the requests must carry
+// a valid signature in the webhooks.SignatureHeader, its timestamp must be within the tolerance and its nonce
+// must not have been seen before. The ids of requests failing with a server error are forgotten, so
+// that they can be retried.
+func replayProtected(handle Handle, signed bool) Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) error {
+		nonce := ""
+		for _, header := range deliveryHeaders {
+			if id := request.Header.Get(header); id != "" {
+				nonce = header + ":" + id
+			}
+		}
+
+		if signed && inboundSecret != "" {
+			var body []byte
+			if request.Body != nil {
+				var err error
+				body, err = readBody(request)
+				if err != nil {
+					return err
+				}
+				request.Body = io.NopCloser(bytes.NewReader(body))
+			}
+			signature, err := webhooks.Verify(inboundSecret, request.Header.Get(webhooks.SignatureHeader), body,
+				time.Now(), inboundTolerance)
+			if errors.Is(err, webhooks.ErrStaleSignature) {
+				return writeError(writer, http.StatusUnauthorized, models.CodeInvalidSignature, "Stale Signature")
+			}
+			if err != nil {
+				return writeError(writer, http.StatusUnauthorized, models.CodeInvalidSignature, "Invalid Signature")
+			}
+			nonce = "nonce:" + signature.Nonce
+		}
+
+		if nonce == "" {
+			return handle(writer, request, params)
+		}
+		if inboundReplays.Seen(nonce, time.Now()) {
+			return writeError(writer, http.StatusConflict, models.CodeReplayedRequest, "Replayed Request")
+		}
+		err := handle(writer, request, params)
+		if err != nil || responseStatus(writer) >= http.StatusInternalServerError {
+			inboundReplays.Forget(nonce)
+		}
+		return err
+	}
+}
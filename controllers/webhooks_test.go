@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+	"todo-rest-backend/models"
+	"todo-rest-backend/webhooks"
+)
+
+func TestReplayProtected_RejectsReplayedRequests(t *testing.T) {
+	// Arrange
+	//
+	defer configureInboundWebhooks()
+	defer func() { simpleApiKey = "" }()
+	inboundSecret, simpleApiKey = "s3cret", "key"
+	before := len(models.AllTodos())
+	serve := func(signature string) int {
+		return serveRoute(t, http.MethodPost, "/simple/add?title=Buy%20milk", "",
+			"X-Api-Key", "key", webhooks.SignatureHeader, signature).Code
+	}
+	signature := webhooks.Sign("s3cret", []byte{}, time.Now(), webhooks.NewNonce())
+
+	// Act
+	//
+	first := serve(signature)
+	replayed := serve(signature)
+	stale := serve(webhooks.Sign("s3cret", []byte{}, time.Now().Add(-time.Hour), webhooks.NewNonce()))
+	forged := serve(webhooks.Sign("guess", []byte{}, time.Now(), webhooks.NewNonce()))
+
+	// Assert
+	//
+	added := len(models.AllTodos()) - before
+	for _, todo := range models.AllTodos() {
+		if todo.Title == "Buy milk" {
+			models.RemoveTodo(todo.Id)
+		}
+	}
+	if first != http.StatusCreated || replayed != http.StatusConflict || added != 1 {
+		t.Error("Fehler", first, replayed, added)
+	}
+	if stale != http.StatusUnauthorized || forged != http.StatusUnauthorized {
+		t.Error("Fehler", stale, forged)
+	}
+}
+
+func TestReplayProtected_RejectsRedeliveredTrackerEvents(t *testing.T) {
+	// Arrange
+	//
+	defer configureInboundWebhooks()
+	// without tracker the events are rejected as invalid, a redelivery is rejected before
+	serve := func(delivery string) int {
+		return serveRoute(t, http.MethodPost, "/sync/webhook", "{}", "X-GitHub-Delivery", delivery).Code
+	}
+
+	// Act
+	//
+	first := serve("72d3162e-cc78-11e3-81ab-4c9367dc0958")
+	redelivered := serve("72d3162e-cc78-11e3-81ab-4c9367dc0958")
+	other := serve("8a1c0f9e-cc78-11e3-81ab-4c9367dc0958")
+
+	// Assert
+	//
+	if first != http.StatusBadRequest || redelivered != http.StatusConflict || other != http.StatusBadRequest {
+		t.Error("Fehler", first, redelivered, other)
+	}
+}
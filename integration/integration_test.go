@@ -0,0 +1,337 @@
+//go:build integration
+
+// Package integration runs the backend binary end to end: every test starts the server on a random port with a
+// data directory of its own, restarts it and checks what the todos survived.
+//
+//	go test -tags integration ./integration
+package integration
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// binary is the backend built by TestMain
+var binary string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "todo-integration")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	binary = filepath.Join(dir, "todo-rest-backend")
+	build := exec.Command("go", "build", "-o", binary, "todo-rest-backend")
+	build.Stdout, build.Stderr = os.Stderr, os.Stderr
+	err = build.Run()
+	if err != nil {
+		os.RemoveAll(dir)
+		fmt.Fprintln(os.Stderr, "cannot build the backend:", err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// server is a running backend process
+type server struct {
+	t       *testing.T
+	command *exec.Cmd
+	url     string
+	// log collects the output of the process, it is shown when a test fails
+	log    bytes.Buffer
+	logMu  sync.Mutex
+	exited chan error
+}
+
+// environment is the environment of the tests without the configuration of the backend, so that the backend
+// runs with its defaults and the settings of the test only
+func environment(settings []string) []string {
+	var env []string
+	for _, variable := range os.Environ() {
+		if strings.HasPrefix(variable, "TODO_") == false && strings.HasPrefix(variable, "DATABASE_URL=") == false {
+			env = append(env, variable)
+		}
+	}
+	return append(env, settings...)
+}
+
+// start runs the backend with the data directory and the settings like "TODO_STORAGE=json", it is stopped when
+// the test ends
+func start(t *testing.T, dataDir string, settings ...string) *server {
+	t.Helper()
+	s := &server{t: t, exited: make(chan error, 1)}
+	s.command = exec.Command(binary, "-addr", "127.0.0.1:0", "-data-dir", dataDir)
+	s.command.Env = environment(settings)
+	stderr, err := s.command.StderrPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.command.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.stop)
+
+	// the address is logged once the backend listens
+	address := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			s.logMu.Lock()
+			s.log.WriteString(scanner.Text() + "\n")
+			s.logMu.Unlock()
+			var line struct {
+				Msg     string `json:"msg"`
+				Address string `json:"address"`
+			}
+			if json.Unmarshal(scanner.Bytes(), &line) == nil && line.Msg == "backend running" {
+				address <- line.Address
+			}
+		}
+		s.exited <- s.command.Wait()
+	}()
+	select {
+	case listening := <-address:
+		s.url = "http://" + listening
+	case err := <-s.exited:
+		t.Fatalf("the backend exited: %v\n%s", err, s.output())
+	case <-time.After(10 * time.Second):
+		t.Fatalf("the backend did not start\n%s", s.output())
+	}
+	s.waitUntilReady()
+	return s
+}
+
+func (s *server) output() string {
+	s.logMu.Lock()
+	defer s.logMu.Unlock()
+	return s.log.String()
+}
+
+// waitUntilReady waits until the todos are loaded
+func (s *server) waitUntilReady() {
+	s.t.Helper()
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); {
+		response, err := http.Get(s.url + "/readyz")
+		if err == nil {
+			response.Body.Close()
+			if response.StatusCode == http.StatusOK {
+				return
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	s.t.Fatalf("the backend did not get ready\n%s", s.output())
+}
+
+// stop shuts the backend down like a deployment does with SIGTERM, it saves the todos before it exits
+func (s *server) stop() {
+	if s.command.ProcessState != nil || s.command.Process == nil {
+		return
+	}
+	s.command.Process.Signal(syscall.SIGTERM)
+	select {
+	case err := <-s.exited:
+		if err != nil {
+			s.t.Errorf("the backend did not stop cleanly: %v\n%s", err, s.output())
+		}
+	case <-time.After(15 * time.Second):
+		s.command.Process.Kill()
+		<-s.exited
+		s.t.Errorf("the backend did not stop in time\n%s", s.output())
+	}
+}
+
+// envelope is a response of the API
+type envelope struct {
+	Meta  json.RawMessage `json:"meta"`
+	Data  json.RawMessage `json:"data"`
+	Error *struct {
+		Code  string `json:"code"`
+		Title string `json:"title"`
+	} `json:"error"`
+}
+
+// todo has the fields of a todo the workflows check
+type todo struct {
+	Id          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Terminated  bool     `json:"terminated"`
+	Tags        []string `json:"tags"`
+	Items       []struct {
+		Title string `json:"title"`
+		Done  bool   `json:"done"`
+	} `json:"items"`
+}
+
+// call sends the request and decodes the data of the response into data, the status is returned
+func (s *server) call(method string, path string, body string, data interface{}) int {
+	s.t.Helper()
+	request, err := http.NewRequest(method, s.url+path, strings.NewReader(body))
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	// the workflows change one todo at a time, every change applies to its current version
+	if method == http.MethodPut || method == http.MethodDelete {
+		request.Header.Set("If-Match", "*")
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		s.t.Fatalf("%s %s: %v\n%s", method, path, err, s.output())
+	}
+	defer response.Body.Close()
+	content, _ := io.ReadAll(response.Body)
+	var answer envelope
+	if len(content) > 0 && json.Unmarshal(content, &answer) != nil {
+		s.t.Fatalf("%s %s: the response is no JSON: %s", method, path, content)
+	}
+	if data != nil && answer.Data != nil {
+		err = json.Unmarshal(answer.Data, data)
+		if err != nil {
+			s.t.Fatalf("%s %s: %v: %s", method, path, err, content)
+		}
+	}
+	return response.StatusCode
+}
+
+// configurations are the stores the workflows run against
+var configurations = []struct {
+	name     string
+	settings []string
+}{
+	{"csv", nil},
+	{"csv.gz", []string{"TODO_STORAGE=csv.gz"}},
+	{"json", []string{"TODO_STORAGE=json"}},
+	{"sqlite", []string{"TODO_REPOSITORY=sqlite"}},
+	{"save interval", []string{"TODO_SAVE_INTERVAL=1h"}},
+}
+
+func TestWorkflow_CreateUpdateRestart(t *testing.T) {
+	for _, configuration := range configurations {
+		t.Run(configuration.name, func(t *testing.T) {
+			// Arrange
+			//
+			dataDir := t.TempDir()
+			first := start(t, dataDir, configuration.settings...)
+			var created, updated, checked todo
+			first.call(http.MethodPost, "/todos", `{"title": "Buy milk", "tags": ["shopping"]}`, &created)
+			first.call(http.MethodPut, "/todos/"+created.Id,
+				`{"title": "Buy oat milk", "description": "2 litres", "tags": ["shopping"]}`, &updated)
+			first.call(http.MethodPost, "/todos/"+created.Id+"/items", `{"title": "Check the price"}`, &checked)
+			var revision struct {
+				Revision uint64 `json:"revision"`
+			}
+			first.call(http.MethodGet, "/todos/revision", "", &revision)
+
+			// Act
+			//
+			first.stop()
+			second := start(t, dataDir, configuration.settings...)
+			var loaded todo
+			status := second.call(http.MethodGet, "/todos/"+created.Id, "", &loaded)
+			var all []todo
+			second.call(http.MethodGet, "/todos", "", &all)
+			var loadedRevision struct {
+				Revision uint64 `json:"revision"`
+			}
+			second.call(http.MethodGet, "/todos/revision", "", &loadedRevision)
+			var next todo
+			second.call(http.MethodPost, "/todos", `{"title": "Call the bank"}`, &next)
+
+			// Assert
+			//
+			if status != http.StatusOK || loaded.Title != "Buy oat milk" || loaded.Description != "2 litres" ||
+				len(loaded.Tags) != 1 || len(loaded.Items) != 1 || loaded.Items[0].Title != "Check the price" {
+				t.Error("Fehler", status, loaded)
+			}
+			if len(all) != 1 {
+				t.Error("Fehler", all)
+			}
+			if loadedRevision.Revision < revision.Revision {
+				t.Error("Fehler", loadedRevision.Revision, revision.Revision)
+			}
+			if next.Id == "" || next.Id == created.Id {
+				t.Error("Fehler, the id was reused:", next.Id)
+			}
+		})
+	}
+}
+
+func TestWorkflow_TrashSurvivesRestart(t *testing.T) {
+	// Arrange
+	//
+	dataDir := t.TempDir()
+	first := start(t, dataDir)
+	var kept, deleted todo
+	first.call(http.MethodPost, "/todos", `{"title": "Water the plants"}`, &kept)
+	first.call(http.MethodPost, "/todos", `{"title": "Fax the form"}`, &deleted)
+	first.call(http.MethodDelete, "/todos/"+deleted.Id, "", nil)
+
+	// Act
+	//
+	first.stop()
+	second := start(t, dataDir)
+	var trash []todo
+	second.call(http.MethodGet, "/todos/trash", "", &trash)
+	statusDeleted := second.call(http.MethodGet, "/todos/"+deleted.Id, "", nil)
+	statusRestored := second.call(http.MethodPost, "/todos/"+deleted.Id+"/restore", "", nil)
+	second.stop()
+	third := start(t, dataDir)
+	var all []todo
+	third.call(http.MethodGet, "/todos", "", &all)
+
+	// Assert
+	//
+	if len(trash) != 1 || trash[0].Id != deleted.Id {
+		t.Error("Fehler", trash)
+	}
+	if statusDeleted != http.StatusNotFound || statusRestored != http.StatusOK {
+		t.Error("Fehler", statusDeleted, statusRestored)
+	}
+	if len(all) != 2 {
+		t.Error("Fehler", all)
+	}
+}
+
+func TestWorkflow_ImportSurvivesRestart(t *testing.T) {
+	// Arrange
+	//
+	dataDir := t.TempDir()
+	first := start(t, dataDir)
+	var imported []todo
+	status := first.call(http.MethodPost, "/todos/import?format=json",
+		`[{"title": "Book a table", "tags": ["family"]}, {"title": "Pay the rent", "terminated": true}]`, &imported)
+
+	// Act
+	//
+	first.stop()
+	second := start(t, dataDir)
+	var open []todo
+	second.call(http.MethodGet, "/todos?terminated=false", "", &open)
+	var family []todo
+	second.call(http.MethodGet, "/todos?tag=family", "", &family)
+
+	// Assert
+	//
+	if status != http.StatusCreated || len(imported) != 2 {
+		t.Error("Fehler", status, imported)
+	}
+	if len(open) != 1 || open[0].Title != "Book a table" || len(family) != 1 {
+		t.Error("Fehler", open, family)
+	}
+}
@@ -0,0 +1,167 @@
+package issuesync
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"todo-rest-backend/models"
+)
+
+// httpClient is shared by the connectors, the calls happen inside the request handlers
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// GitHubConnector mirrors todos to the issues of a GitHub repository
+type GitHubConnector struct {
+	// ApiUrl is https://api.github.com or the API of a GitHub Enterprise server
+	ApiUrl string
+	// Repository in the form owner/repo
+	Repository string
+	Token      string
+	// WebhookSecret verifies the X-Hub-Signature-256 header of inbound webhooks if set
+	WebhookSecret string
+}
+
+type gitHubIssue struct {
+	Number int    `json:"number,omitempty"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	State  string `json:"state,omitempty"`
+}
+
+// Name returns "github"
+func (c *GitHubConnector) Name() string {
+	return "github"
+}
+
+// Create opens an issue for the todo
+func (c *GitHubConnector) Create(todo models.Todo) (string, error) {
+	var created gitHubIssue
+	err := c.call(http.MethodPost, "/issues", gitHubIssue{Title: todo.Title, Body: todo.Description}, &created)
+	if err != nil {
+		return "", err
+	}
+
+	ref := c.ref(created.Number)
+	if todo.Terminated {
+		// issues are always created open
+		err = c.Update(ref, todo)
+	}
+	return ref, err
+}
+
+// Update sets title, body and state of the issue
+func (c *GitHubConnector) Update(ref string, todo models.Todo) error {
+	number, err := c.number(ref)
+	if err != nil {
+		return err
+	}
+
+	issue := gitHubIssue{Title: todo.Title, Body: todo.Description, State: "open"}
+	if todo.Terminated {
+		issue.State = "closed"
+	}
+	return c.call(http.MethodPatch, "/issues/"+strconv.Itoa(number), issue, nil)
+}
+
+// Close closes the issue
+func (c *GitHubConnector) Close(ref string) error {
+	number, err := c.number(ref)
+	if err != nil {
+		return err
+	}
+	return c.call(http.MethodPatch, "/issues/"+strconv.Itoa(number), map[string]string{"state": "closed"}, nil)
+}
+
+// ParseWebhook reads an "issues" webhook event
+func (c *GitHubConnector) ParseWebhook(request *http.Request) (RemoteIssue, error) {
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		return RemoteIssue{}, err
+	}
+
+	if c.WebhookSecret != "" {
+		mac := hmac.New(sha256.New, []byte(c.WebhookSecret))
+		mac.Write(body)
+		expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		if hmac.Equal([]byte(expected), []byte(request.Header.Get("X-Hub-Signature-256"))) == false {
+			return RemoteIssue{}, errors.New("invalid webhook signature")
+		}
+	}
+
+	var event struct {
+		Issue      gitHubIssue `json:"issue"`
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+	}
+	err = json.Unmarshal(body, &event)
+	if err != nil {
+		return RemoteIssue{}, err
+	}
+	if event.Issue.Number == 0 || strings.EqualFold(event.Repository.FullName, c.Repository) == false {
+		return RemoteIssue{}, errors.New("not an issue event of " + c.Repository)
+	}
+
+	return RemoteIssue{
+		Ref:         c.ref(event.Issue.Number),
+		Title:       event.Issue.Title,
+		Description: event.Issue.Body,
+		Closed:      event.Issue.State == "closed",
+	}, nil
+}
+
+func (c *GitHubConnector) ref(number int) string {
+	return fmt.Sprintf("github:%s#%d", c.Repository, number)
+}
+
+func (c *GitHubConnector) number(ref string) (int, error) {
+	prefix := "github:" + c.Repository + "#"
+	if strings.HasPrefix(ref, prefix) == false {
+		return 0, errors.New("reference " + ref + " does not belong to " + c.Repository)
+	}
+	return strconv.Atoi(strings.TrimPrefix(ref, prefix))
+}
+
+func (c *GitHubConnector) call(method string, path string, payload interface{}, result interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(method, strings.TrimRight(c.ApiUrl, "/")+"/repos/"+c.Repository+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/vnd.github+json")
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+c.Token)
+
+	return doJson(request, result)
+}
+
+// doJson sends the request and decodes the JSON response into result if it is not nil
+func doJson(request *http.Request, result interface{}) error {
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("%s %s: %s %s", request.Method, request.URL.Path, response.Status, strings.TrimSpace(string(message)))
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
@@ -0,0 +1,182 @@
+package issuesync
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"todo-rest-backend/models"
+)
+
+// JiraConnector mirrors todos to the tickets of a Jira project using the REST API v2
+type JiraConnector struct {
+	BaseUrl string
+	// Project is the key of the Jira project, e.g. "TODO"
+	Project string
+	User    string
+	Token   string
+	// IssueType of the created tickets, e.g. "Task"
+	IssueType string
+	// Ids of the workflow transitions used to close and reopen tickets
+	DoneTransition   string
+	ReopenTransition string
+	// WebhookSecret must be passed as "secret" query parameter by inbound webhooks if set
+	WebhookSecret string
+}
+
+type jiraFields struct {
+	Project     *jiraKey    `json:"project,omitempty"`
+	IssueType   *jiraName   `json:"issuetype,omitempty"`
+	Summary     string      `json:"summary"`
+	Description string      `json:"description"`
+	Status      *jiraStatus `json:"status,omitempty"`
+}
+
+type jiraKey struct {
+	Key string `json:"key"`
+}
+
+type jiraName struct {
+	Name string `json:"name"`
+}
+
+type jiraStatus struct {
+	StatusCategory jiraKey `json:"statusCategory"`
+}
+
+type jiraIssue struct {
+	Key    string     `json:"key,omitempty"`
+	Fields jiraFields `json:"fields"`
+}
+
+// Name returns "jira"
+func (c *JiraConnector) Name() string {
+	return "jira"
+}
+
+// Create creates a ticket for the todo
+func (c *JiraConnector) Create(todo models.Todo) (string, error) {
+	issue := jiraIssue{Fields: jiraFields{
+		Project:     &jiraKey{Key: c.Project},
+		IssueType:   &jiraName{Name: c.IssueType},
+		Summary:     todo.Title,
+		Description: todo.Description,
+	}}
+
+	var created jiraIssue
+	err := c.call(http.MethodPost, "/rest/api/2/issue", issue, &created)
+	if err != nil {
+		return "", err
+	}
+
+	ref := "jira:" + created.Key
+	if todo.Terminated {
+		err = c.transition(created.Key, c.DoneTransition)
+	}
+	return ref, err
+}
+
+// Update sets summary and description of the ticket and moves it to done or back
+func (c *JiraConnector) Update(ref string, todo models.Todo) error {
+	key, err := c.key(ref)
+	if err != nil {
+		return err
+	}
+
+	fields := jiraFields{Summary: todo.Title, Description: todo.Description}
+	err = c.call(http.MethodPut, "/rest/api/2/issue/"+key, jiraIssue{Fields: fields}, nil)
+	if err != nil {
+		return err
+	}
+
+	// The current status is needed to avoid invalid transitions
+	var current jiraIssue
+	err = c.call(http.MethodGet, "/rest/api/2/issue/"+key+"?fields=status", nil, &current)
+	if err != nil {
+		return err
+	}
+	done := current.Fields.Status != nil && current.Fields.Status.StatusCategory.Key == "done"
+	if todo.Terminated && done == false {
+		return c.transition(key, c.DoneTransition)
+	}
+	if todo.Terminated == false && done {
+		return c.transition(key, c.ReopenTransition)
+	}
+	return nil
+}
+
+// Close moves the ticket to done
+func (c *JiraConnector) Close(ref string) error {
+	key, err := c.key(ref)
+	if err != nil {
+		return err
+	}
+	return c.transition(key, c.DoneTransition)
+}
+
+// ParseWebhook reads a "jira:issue_updated" webhook event
+func (c *JiraConnector) ParseWebhook(request *http.Request) (RemoteIssue, error) {
+	if c.WebhookSecret != "" {
+		secret := request.URL.Query().Get("secret")
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(c.WebhookSecret)) != 1 {
+			return RemoteIssue{}, errors.New("invalid webhook secret")
+		}
+	}
+
+	var event struct {
+		Issue jiraIssue `json:"issue"`
+	}
+	err := json.NewDecoder(request.Body).Decode(&event)
+	if err != nil {
+		return RemoteIssue{}, err
+	}
+	if event.Issue.Key == "" || strings.HasPrefix(event.Issue.Key, c.Project+"-") == false {
+		return RemoteIssue{}, errors.New("not an issue event of project " + c.Project)
+	}
+
+	fields := event.Issue.Fields
+	return RemoteIssue{
+		Ref:         "jira:" + event.Issue.Key,
+		Title:       fields.Summary,
+		Description: fields.Description,
+		Closed:      fields.Status != nil && fields.Status.StatusCategory.Key == "done",
+	}, nil
+}
+
+func (c *JiraConnector) key(ref string) (string, error) {
+	if strings.HasPrefix(ref, "jira:"+c.Project+"-") == false {
+		return "", errors.New("reference " + ref + " does not belong to project " + c.Project)
+	}
+	return strings.TrimPrefix(ref, "jira:"), nil
+}
+
+func (c *JiraConnector) transition(key string, id string) error {
+	if id == "" {
+		return errors.New("no transition configured to change the status of " + key)
+	}
+	payload := map[string]interface{}{"transition": map[string]string{"id": id}}
+	return c.call(http.MethodPost, "/rest/api/2/issue/"+key+"/transitions", payload, nil)
+}
+
+func (c *JiraConnector) call(method string, path string, payload interface{}, result interface{}) error {
+	var body []byte
+	if payload != nil {
+		var err error
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+	}
+
+	request, err := http.NewRequest(method, strings.TrimRight(c.BaseUrl, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Content-Type", "application/json")
+	request.SetBasicAuth(c.User, c.Token)
+
+	return doJson(request, result)
+}
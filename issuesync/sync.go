@@ -0,0 +1,411 @@
+// Package issuesync mirrors todos to issues of an external tracker (GitHub or Jira)
+package issuesync
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"todo-rest-backend/models"
+)
+
+// Connector is implemented by the supported issue trackers
+type Connector interface {
+	// Name returns the name of the tracker, e.g. "github"
+	Name() string
+	// Create creates an issue for the todo and returns its reference
+	Create(todo models.Todo) (string, error)
+	// Update updates title, description and open/closed state of the issue
+	Update(ref string, todo models.Todo) error
+	// Close closes the issue of a removed todo
+	Close(ref string) error
+	// ParseWebhook reads the issue sent by the tracker to the inbound webhook endpoint
+	ParseWebhook(request *http.Request) (RemoteIssue, error)
+}
+
+// RemoteIssue is the state of an issue as reported by the tracker
+type RemoteIssue struct {
+	Ref         string
+	Title       string
+	Description string
+	Closed      bool
+}
+
+// Conflict rules deciding which side wins when both the todo and the issue changed
+const (
+	// ConflictLocalWins keeps unsynced local changes and pushes them again
+	ConflictLocalWins = "local"
+	// ConflictRemoteWins always applies the changes of the tracker
+	ConflictRemoteWins = "remote"
+)
+
+// Sync states reported by the status endpoint
+const (
+	StateSynced  = "synced"
+	StateError   = "error"
+	StateRemoved = "removed"
+)
+
+// TodoSyncStatus is the sync state of a single todo
+type TodoSyncStatus struct {
+	TodoId       string     `json:"todo_id"`
+	Ref          string     `json:"ref,omitempty"`
+	State        string     `json:"state"`
+	LastSyncedAt *time.Time `json:"last_synced_at,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	// hash of the todo content at the last successful push or pull
+	syncedHash string
+}
+
+// StatusReport is returned by the sync status endpoint
+type StatusReport struct {
+	Enabled      bool   `json:"enabled"`
+	Provider     string `json:"provider,omitempty"`
+	ConflictRule string `json:"conflict_rule,omitempty"`
+	// Lists maps the lists with a tracker of their own to its provider, "none" for lists left out of the sync
+	Lists map[string]string `json:"lists,omitempty"`
+	Todos []TodoSyncStatus  `json:"todos"`
+}
+
+var ErrUnknownIssue = errors.New("no todo is linked to the issue")
+
+// ErrUnpushedChanges is returned by ResolveRemote for todos with local changes winning over the tracker,
+// the caller pushes the todo again
+var ErrUnpushedChanges = errors.New("the todo has local changes that were not pushed yet")
+
+var connector Connector
+var conflictRule = ConflictLocalWins
+
+// listConnectors are the trackers of the lists configured with ConfigureList, keyed by the list of the todos.
+// A nil connector leaves the list out of the sync, the other lists use the connector of Configure.
+var listConnectors = make(map[string]Connector)
+
+// syncStates holds the sync state per issue reference.
+// Todos whose issue could not be created yet are keyed by "todo:" and their id.
+var syncStates = make(map[string]*TodoSyncStatus)
+
+// statesMutex guards the sync states, the trackers are called without holding it
+var statesMutex sync.Mutex
+
+// Configure enables the sync with the given connector, a nil connector disables it.
+// The trackers of the lists are removed.
+func Configure(c Connector, rule string) {
+	connector = c
+	if rule == ConflictRemoteWins {
+		conflictRule = ConflictRemoteWins
+	} else {
+		conflictRule = ConflictLocalWins
+	}
+	listConnectors = make(map[string]Connector)
+	statesMutex.Lock()
+	syncStates = make(map[string]*TodoSyncStatus)
+	statesMutex.Unlock()
+}
+
+// ConfigureList mirrors the todos of the list with a tracker of their own, a nil connector leaves the list out
+// of the sync
+func ConfigureList(list string, c Connector) {
+	listConnectors[list] = c
+}
+
+// ConfigureFromEnv configures the sync from the TODO_SYNC_* environment variables.
+// Without TODO_SYNC_PROVIDER the sync stays disabled. TODO_SYNC_LISTS_FILE names a JSON file with the
+// trackers of the lists, see configureListsFromFile.
+func ConfigureFromEnv() error {
+	c, err := newConnector(os.Getenv)
+	if err != nil {
+		return err
+	}
+	Configure(c, os.Getenv("TODO_SYNC_CONFLICT"))
+
+	fileName := os.Getenv("TODO_SYNC_LISTS_FILE")
+	if fileName == "" {
+		return nil
+	}
+	return configureListsFromFile(fileName)
+}
+
+// configureListsFromFile reads the trackers of the lists from a JSON object with the settings per list, like
+// {"work": {"provider": "jira", "jira_project": "WORK"}, "private": {"provider": "none"}}. The settings are
+// the TODO_SYNC_* variables in lower case without prefix, missing ones are taken from the variables.
+func configureListsFromFile(fileName string) error {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+	var lists map[string]map[string]string
+	err = json.Unmarshal(content, &lists)
+	if err != nil {
+		return errors.New("TODO_SYNC_LISTS_FILE must map the lists to their settings: " + err.Error())
+	}
+
+	for list, settings := range lists {
+		setting := func(key string) string {
+			value, ok := settings[strings.ToLower(strings.TrimPrefix(key, "TODO_SYNC_"))]
+			if ok == false {
+				return os.Getenv(key)
+			}
+			return value
+		}
+		if setting("TODO_SYNC_PROVIDER") == "none" {
+			ConfigureList(list, nil)
+			continue
+		}
+		c, err := newConnector(setting)
+		if err != nil {
+			return errors.New("list " + list + ": " + err.Error())
+		}
+		ConfigureList(list, c)
+	}
+	return nil
+}
+
+// newConnector creates the connector named by the TODO_SYNC_PROVIDER setting, nil without provider
+func newConnector(setting func(key string) string) (Connector, error) {
+	withDefault := func(key string, defaultValue string) string {
+		value := setting(key)
+		if value == "" {
+			return defaultValue
+		}
+		return value
+	}
+
+	switch setting("TODO_SYNC_PROVIDER") {
+	case "":
+		return nil, nil
+	case "github":
+		return &GitHubConnector{
+			ApiUrl:        withDefault("TODO_SYNC_GITHUB_API", "https://api.github.com"),
+			Repository:    setting("TODO_SYNC_GITHUB_REPO"),
+			Token:         setting("TODO_SYNC_GITHUB_TOKEN"),
+			WebhookSecret: setting("TODO_SYNC_WEBHOOK_SECRET"),
+		}, nil
+	case "jira":
+		return &JiraConnector{
+			BaseUrl:          setting("TODO_SYNC_JIRA_URL"),
+			Project:          setting("TODO_SYNC_JIRA_PROJECT"),
+			User:             setting("TODO_SYNC_JIRA_USER"),
+			Token:            setting("TODO_SYNC_JIRA_TOKEN"),
+			IssueType:        withDefault("TODO_SYNC_JIRA_ISSUE_TYPE", "Task"),
+			DoneTransition:   setting("TODO_SYNC_JIRA_DONE_TRANSITION"),
+			ReopenTransition: setting("TODO_SYNC_JIRA_REOPEN_TRANSITION"),
+			WebhookSecret:    setting("TODO_SYNC_WEBHOOK_SECRET"),
+		}, nil
+	}
+	return nil, errors.New("unknown sync provider " + setting("TODO_SYNC_PROVIDER"))
+}
+
+// Enabled tells whether a connector is configured, for all todos or for a list
+func Enabled() bool {
+	if connector != nil {
+		return true
+	}
+	for _, c := range listConnectors {
+		if c != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// connectorOf returns the tracker of the list of the todo, nil if the todo is not mirrored
+func connectorOf(todo models.Todo) Connector {
+	if c, ok := listConnectors[todo.List]; ok {
+		return c
+	}
+	return connector
+}
+
+// PushTodo creates or updates the issue of the todo and returns the todo with its external reference.
+// Failures are recorded in the sync status and do not fail the caller, the push is retried on the next change.
+func PushTodo(todo models.Todo) models.Todo {
+	c := connectorOf(todo)
+	if c == nil {
+		return todo
+	}
+
+	if todo.ExternalRef == "" {
+		ref, err := c.Create(todo)
+		statesMutex.Lock()
+		defer statesMutex.Unlock()
+		if err != nil {
+			syncStates["todo:"+todo.Id] = &TodoSyncStatus{TodoId: todo.Id, State: StateError, LastError: err.Error()}
+			return todo
+		}
+		delete(syncStates, "todo:"+todo.Id)
+		todo.ExternalRef = ref
+		markSynced(todo)
+		return todo
+	}
+
+	err := c.Update(todo.ExternalRef, todo)
+	statesMutex.Lock()
+	defer statesMutex.Unlock()
+	if err != nil {
+		state := stateOf(todo)
+		state.State = StateError
+		state.LastError = err.Error()
+		return todo
+	}
+	markSynced(todo)
+	return todo
+}
+
+// RemoveTodo closes the issue of a removed todo
+func RemoveTodo(todo models.Todo) {
+	c := connectorOf(todo)
+	if c == nil {
+		return
+	}
+	if todo.ExternalRef == "" {
+		statesMutex.Lock()
+		delete(syncStates, "todo:"+todo.Id)
+		statesMutex.Unlock()
+		return
+	}
+
+	err := c.Close(todo.ExternalRef)
+	statesMutex.Lock()
+	defer statesMutex.Unlock()
+	state := stateOf(todo)
+	if err != nil {
+		state.State = StateError
+		state.LastError = err.Error()
+		return
+	}
+	now := models.Now()
+	state.State = StateRemoved
+	state.LastError = ""
+	state.LastSyncedAt = &now
+}
+
+// ReceiveWebhook parses an inbound webhook request of the configured trackers, the trackers of the lists
+// are tried after the tracker of all todos
+func ReceiveWebhook(request *http.Request) (RemoteIssue, error) {
+	connectors := []Connector{connector}
+	lists := make([]string, 0, len(listConnectors))
+	for list := range listConnectors {
+		lists = append(lists, list)
+	}
+	sort.Strings(lists)
+	for _, list := range lists {
+		connectors = append(connectors, listConnectors[list])
+	}
+
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		return RemoteIssue{}, err
+	}
+	err = errors.New("issue sync is disabled")
+	for _, c := range connectors {
+		if c == nil {
+			continue
+		}
+		request.Body = io.NopCloser(bytes.NewReader(body))
+		var issue RemoteIssue
+		issue, err = c.ParseWebhook(request)
+		if err == nil {
+			return issue, nil
+		}
+	}
+	return RemoteIssue{}, err
+}
+
+// ResolveRemote merges an issue change into its linked todo according to the conflict rule.
+// It returns the todo to store and whether it differs from the stored one. Local changes that never reached
+// the tracker win with the local conflict rule, then the local todo is returned with ErrUnpushedChanges.
+func ResolveRemote(issue RemoteIssue) (models.Todo, bool, error) {
+	local, ok := models.FindTodoByExternalRef(issue.Ref)
+	if ok == false {
+		return models.Todo{}, false, ErrUnknownIssue
+	}
+
+	statesMutex.Lock()
+	defer statesMutex.Unlock()
+	state := stateOf(local)
+	if conflictRule == ConflictLocalWins && state.syncedHash != "" && state.syncedHash != hashTodo(local) {
+		return local, false, ErrUnpushedChanges
+	}
+
+	remote := local
+	remote.Title = issue.Title
+	remote.Description = issue.Description
+	remote.Terminated = issue.Closed
+	markSynced(remote)
+
+	changed := remote.Title != local.Title || remote.Description != local.Description || remote.Terminated != local.Terminated
+	return remote, changed, nil
+}
+
+// Status returns the sync state of all mirrored todos ordered by todo id
+func Status() StatusReport {
+	report := StatusReport{Enabled: Enabled(), Todos: []TodoSyncStatus{}}
+	if report.Enabled == false {
+		return report
+	}
+
+	if connector != nil {
+		report.Provider = connector.Name()
+	}
+	report.ConflictRule = conflictRule
+	for list, c := range listConnectors {
+		if report.Lists == nil {
+			report.Lists = make(map[string]string)
+		}
+		report.Lists[list] = "none"
+		if c != nil {
+			report.Lists[list] = c.Name()
+		}
+	}
+	statesMutex.Lock()
+	defer statesMutex.Unlock()
+	for _, state := range syncStates {
+		report.Todos = append(report.Todos, *state)
+	}
+	sort.Slice(report.Todos, func(i, j int) bool {
+		leftValueAsInt, _ := strconv.Atoi(report.Todos[i].TodoId)
+		rightValueAsInt, _ := strconv.Atoi(report.Todos[j].TodoId)
+		return leftValueAsInt < rightValueAsInt
+	})
+	return report
+}
+
+// stateOf returns the sync state of the todo, the caller holds statesMutex
+func stateOf(todo models.Todo) *TodoSyncStatus {
+	state, ok := syncStates[todo.ExternalRef]
+	if ok == false {
+		state = &TodoSyncStatus{Ref: todo.ExternalRef}
+		syncStates[todo.ExternalRef] = state
+	}
+	state.TodoId = todo.Id
+	return state
+}
+
+// markSynced records the successful push or pull of the todo, the caller holds statesMutex
+func markSynced(todo models.Todo) {
+	now := models.Now()
+	state := stateOf(todo)
+	state.State = StateSynced
+	state.LastError = ""
+	state.LastSyncedAt = &now
+	state.syncedHash = hashTodo(todo)
+}
+
+// hashTodo hashes the fields mirrored to the tracker
+func hashTodo(todo models.Todo) string {
+	closed := "open"
+	if todo.Terminated {
+		closed = "closed"
+	}
+	sum := sha256.Sum256([]byte(todo.Title + "\x00" + todo.Description + "\x00" + closed))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,140 @@
+package issuesync
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-rest-backend/models"
+)
+
+type fakeConnector struct {
+	updates int
+	fail    bool
+}
+
+func (c *fakeConnector) Name() string { return "fake" }
+
+func (c *fakeConnector) Create(todo models.Todo) (string, error) {
+	if c.fail {
+		return "", errors.New("unreachable")
+	}
+	return "fake:" + todo.Id, nil
+}
+
+func (c *fakeConnector) Update(ref string, todo models.Todo) error {
+	if c.fail {
+		return errors.New("unreachable")
+	}
+	c.updates++
+	return nil
+}
+
+func (c *fakeConnector) Close(ref string) error { return nil }
+
+func (c *fakeConnector) ParseWebhook(request *http.Request) (RemoteIssue, error) {
+	return RemoteIssue{}, nil
+}
+
+func TestResolveRemote_LocalWins(t *testing.T) {
+	// Arrange
+	//
+	connector := &fakeConnector{}
+	Configure(connector, ConflictLocalWins)
+	models.DeleteAllTodos()
+	todo := PushTodo(models.AddTodo(models.Todo{Title: "Local"}))
+	models.SetExternalRef(todo.Id, todo.ExternalRef)
+
+	// a local change that could not be pushed
+	connector.fail = true
+	todo.Title = "Local changed"
+	models.UpdateTodo(todo.Id, todo)
+	PushTodo(todo)
+	connector.fail = false
+
+	// Act
+	//
+	got, changed, err := ResolveRemote(RemoteIssue{Ref: todo.ExternalRef, Title: "Remote"})
+
+	// Assert
+	//
+	if err != ErrUnpushedChanges || changed || got.Title != "Local changed" {
+		t.Errorf("got %+v changed=%v err=%v, want the local todo", got, changed, err)
+	}
+	if connector.updates != 0 {
+		t.Errorf("got %d updates, want the caller to push the local todo again", connector.updates)
+	}
+}
+
+func TestResolveRemote_RemoteWins(t *testing.T) {
+	// Arrange
+	//
+	Configure(&fakeConnector{}, ConflictRemoteWins)
+	models.DeleteAllTodos()
+	todo := PushTodo(models.AddTodo(models.Todo{Title: "Local"}))
+	models.SetExternalRef(todo.Id, todo.ExternalRef)
+
+	// Act
+	//
+	got, changed, err := ResolveRemote(RemoteIssue{Ref: todo.ExternalRef, Title: "Remote", Closed: true})
+
+	// Assert
+	//
+	if err != nil || changed == false || got.Title != "Remote" || got.Terminated == false {
+		t.Errorf("got %+v changed=%v err=%v, want the remote state", got, changed, err)
+	}
+}
+
+func TestPushTodo_UsesTheTrackerOfTheList(t *testing.T) {
+	// Arrange
+	//
+	defer Configure(nil, "")
+	all, work := &fakeConnector{}, &fakeConnector{}
+	Configure(all, ConflictLocalWins)
+	ConfigureList("work", work)
+	ConfigureList("private", nil)
+	models.DeleteAllTodos()
+	inbox := models.AddTodo(models.Todo{Title: "Buy milk"})
+	ofWork := models.AddTodo(models.Todo{Title: "Prepare the slides", List: "work"})
+	private := models.AddTodo(models.Todo{Title: "Call mum", List: "private"})
+
+	// Act
+	//
+	inbox = PushTodo(PushTodo(inbox))
+	ofWork = PushTodo(PushTodo(ofWork))
+	private = PushTodo(PushTodo(private))
+
+	// Assert
+	//
+	if all.updates != 1 || work.updates != 1 || inbox.ExternalRef == "" || ofWork.ExternalRef == "" {
+		t.Errorf("got %d and %d updates for %+v and %+v", all.updates, work.updates, inbox, ofWork)
+	}
+	if private.ExternalRef != "" || Status().Lists["private"] != "none" || Status().Lists["work"] != "fake" {
+		t.Errorf("got %+v and status %+v, want the private list left out", private, Status())
+	}
+}
+
+func TestGitHubConnector_Create(t *testing.T) {
+	// Arrange
+	//
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost || request.URL.Path != "/repos/owner/repo/issues" {
+			t.Errorf("unexpected request %s %s", request.Method, request.URL.Path)
+		}
+		writer.WriteHeader(http.StatusCreated)
+		json.NewEncoder(writer).Encode(map[string]interface{}{"number": 12})
+	}))
+	defer server.Close()
+	connector := &GitHubConnector{ApiUrl: server.URL, Repository: "owner/repo"}
+
+	// Act
+	//
+	ref, err := connector.Create(models.Todo{Title: "Issue"})
+
+	// Assert
+	//
+	if err != nil || ref != "github:owner/repo#12" {
+		t.Errorf("got %q, %v", ref, err)
+	}
+}
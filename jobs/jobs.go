@@ -0,0 +1,142 @@
+// Package jobs runs the recurring background jobs of the backend.
+//
+// Jobs are scheduled on the monotonic clock, jumps of the wall clock (NTP corrections, manual changes)
+// neither delay nor repeat a run. Times reported to users are derived from the monotonic schedule
+// so that they follow the corrected wall clock. Daylight saving time does not affect the schedule,
+// intervals are durations and not times of day.
+package jobs
+
+import (
+	"log"
+	"sync"
+	"time"
+	"todo-rest-backend/clock"
+)
+
+// skewTolerance is the difference between wall clock and monotonic clock that is logged as clock jump
+const skewTolerance = time.Second
+
+// clk schedules the jobs, it is replaced in tests to simulate the passing of time and jumps of the wall clock
+var clk clock.Clock = clock.NewSystem()
+
+// SetClock replaces the clock of jobs started afterwards
+func SetClock(c clock.Clock) {
+	clk = c
+}
+
+// Job runs a function in a fixed interval until it is stopped
+type Job struct {
+	Name     string
+	Interval time.Duration
+
+	clock clock.Clock
+	run   func()
+	stop  chan struct{}
+	// done is closed when the loop ended
+	done     chan struct{}
+	stopOnce sync.Once
+	mutex    sync.Mutex
+	// due is the monotonic time of the next execution
+	due time.Duration
+	// lastWall and lastMonotonic are the clock readings of the last skew check
+	lastWall      time.Time
+	lastMonotonic time.Duration
+}
+
+// running are the started jobs that were not stopped yet
+var running = map[*Job]bool{}
+var runningMutex sync.Mutex
+
+// Start runs the function every interval, the first run happens after one interval
+func Start(name string, interval time.Duration, run func()) *Job {
+	job := &Job{Name: name, Interval: interval, clock: clk, run: run, stop: make(chan struct{}), done: make(chan struct{})}
+	job.lastWall, job.lastMonotonic = job.clock.Now(), job.clock.Elapsed()
+	job.due = job.lastMonotonic + interval
+	runningMutex.Lock()
+	running[job] = true
+	runningMutex.Unlock()
+	go job.loop()
+	return job
+}
+
+// Stop ends the job, a running execution is completed
+func (j *Job) Stop() {
+	j.stopOnce.Do(func() {
+		runningMutex.Lock()
+		delete(running, j)
+		runningMutex.Unlock()
+		close(j.stop)
+	})
+}
+
+// StopAll ends all jobs and waits for their running executions, it is called on shutdown
+func StopAll() {
+	runningMutex.Lock()
+	var jobs []*Job
+	for job := range running {
+		jobs = append(jobs, job)
+	}
+	runningMutex.Unlock()
+
+	for _, job := range jobs {
+		job.Stop()
+		<-job.done
+	}
+}
+
+// NextRun returns the wall clock time of the next execution
+func (j *Job) NextRun() time.Time {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	wall, monotonic := j.checkSkew()
+	return wall.Add(j.due - monotonic)
+}
+
+// checkSkew logs jumps of the wall clock since the last check and returns the current clock readings,
+// the mutex must be held
+func (j *Job) checkSkew() (time.Time, time.Duration) {
+	wall, monotonic := j.clock.Now(), j.clock.Elapsed()
+	skew := wall.Sub(j.lastWall) - (monotonic - j.lastMonotonic)
+	if skew > skewTolerance || skew < -skewTolerance {
+		log.Printf("Job %s: wall clock jumped by %v, next run at %s", j.Name, skew,
+			wall.Add(j.due-monotonic).Format(time.RFC3339))
+	}
+	j.lastWall, j.lastMonotonic = wall, monotonic
+	return wall, monotonic
+}
+
+func (j *Job) scheduleNextRun() {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	_, monotonic := j.checkSkew()
+	j.due = monotonic + j.Interval
+}
+
+func (j *Job) loop() {
+	// tickers run on the monotonic clock of the system, a fake clock only changes the reported times
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+	defer close(j.done)
+
+	for {
+		select {
+		case <-j.stop:
+			return
+		case <-ticker.C:
+			j.execute()
+			j.scheduleNextRun()
+		}
+	}
+}
+
+// execute keeps a panicking run from ending the job
+func (j *Job) execute() {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("Job %s failed: %v", j.Name, recovered)
+		}
+	}()
+	j.run()
+}
@@ -0,0 +1,80 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+	"todo-rest-backend/clock"
+)
+
+func TestStart(t *testing.T) {
+	// Arrange
+	//
+	runs := make(chan struct{}, 10)
+
+	// Act
+	//
+	job := Start("test", 10*time.Millisecond, func() {
+		runs <- struct{}{}
+	})
+	defer job.Stop()
+
+	// Assert
+	//
+	for i := 0; i < 2; i++ {
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			t.Fatal("job did not run")
+		}
+	}
+}
+
+func TestJob_NextRunFollowsWallClockJump(t *testing.T) {
+	// Arrange
+	//
+	fake := clock.NewFake(time.Date(2024, 3, 31, 1, 30, 0, 0, time.UTC))
+	defer SetClock(clk)
+	SetClock(fake)
+
+	job := Start("test", time.Hour, func() {})
+	defer job.Stop()
+
+	// Act
+	//
+	fake.Advance(10 * time.Minute)
+	fake.Jump(-2 * time.Hour)
+	got := job.NextRun()
+
+	// Assert
+	//
+	want := fake.Now().Add(50 * time.Minute)
+	if got.Equal(want) == false {
+		t.Errorf("next run %v, want %v", got, want)
+	}
+}
+
+func TestStopAll_WaitsForRunningExecution(t *testing.T) {
+	// Arrange
+	//
+	started := make(chan struct{})
+	finished := false
+	Start("test", time.Millisecond, func() {
+		select {
+		case started <- struct{}{}:
+			time.Sleep(20 * time.Millisecond)
+			finished = true
+		default:
+		}
+	})
+	<-started
+
+	// Act
+	//
+	StopAll()
+
+	// Assert
+	//
+	if finished == false {
+		t.Error("Fehler")
+	}
+}
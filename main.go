@@ -1,7 +1,167 @@
 package main
 
-import "todo-rest-backend/controllers"
+import (
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"todo-rest-backend/controllers"
+	"todo-rest-backend/models"
+)
 
 func main() {
-	controllers.Run(true)
+	// the keys are taken from the environment only, arguments are visible to all users of the machine
+	err := models.SetEncryptionKeys(os.Getenv("TODO_ENCRYPTION_KEY"), previousEncryptionKeys())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate-store" {
+		migrateStore(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "reencrypt" {
+		reencrypt(os.Args[2:])
+		return
+	}
+
+	dataDir := flag.String("data-dir", os.Getenv("TODO_DATA_DIR"),
+		"directory of the data files, defaults to the platform data directory")
+	fileMode := flag.String("file-mode", os.Getenv("TODO_FILE_MODE"),
+		"octal permission of created data files, defaults to 0600")
+	repositoryKind := flag.String("repository", os.Getenv("TODO_REPOSITORY"),
+		"where the todos are kept: memory (saved to data.csv), sqlite or postgres, "+
+			"defaults to postgres if DATABASE_URL is set and to memory otherwise")
+	sqliteFile := flag.String("sqlite-file", os.Getenv("TODO_SQLITE_FILE"),
+		"SQLite database of the sqlite repository, relative to the data directory, defaults to todos.db")
+	dualWrite := flag.String("dual-write", os.Getenv("TODO_DUAL_WRITE"),
+		"migrates the memory repository without downtime: sqlite or postgres, the todos are read from data.csv "+
+			"and written to both, see GET /admin/dual-write")
+	address := flag.String("addr", os.Getenv("TODO_ADDR"),
+		"listen address like 127.0.0.1:9000 or :9000, defaults to "+controllers.BackendHostUrl)
+	apiKey := flag.String("api-key", os.Getenv("TODO_API_KEY"),
+		"token the requests changing todos must present as bearer token, the API is open without")
+	authenticateReads := flag.Bool("authenticate-reads", os.Getenv("TODO_AUTHENTICATE_READS") == "true",
+		"requires the token of -api-key for reading requests as well")
+	usersFile := flag.String("users-file", os.Getenv("TODO_USERS_FILE"),
+		"file with a line \"name token\" per user, every request must present the token of a user and sees only "+
+			"the todos of the user")
+	defaultOwner := flag.String("default-owner", os.Getenv("TODO_DEFAULT_OWNER"),
+		"user owning the todos stored before -users-file was set")
+	adminUsers := flag.String("admins", os.Getenv("TODO_ADMINS"),
+		"comma separated users of -users-file allowed to call the /admin routes and to override the denylist of "+
+			"TODO_DENYLIST_FILE")
+	replayFile := flag.String("replay", "",
+		"re-executes the requests recorded with TODO_RECORD_FILE against a fresh in-memory store and exits")
+	flag.Parse()
+
+	if *replayFile != "" {
+		mismatches, err := controllers.ReplayFile(*replayFile, os.Stdout)
+		if err != nil {
+			log.Fatal("Cannot replay the requests: ", err)
+		}
+		if mismatches > 0 {
+			log.Fatalf("%d requests were answered with another status than recorded", mismatches)
+		}
+		return
+	}
+
+	err = models.SetFileMode(*fileMode)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = models.SetDataDir(*dataDir)
+	if err != nil {
+		log.Fatal("Cannot create the data directory: ", err)
+	}
+	models.WarnAboutPermissions()
+
+	if *authenticateReads && *apiKey == "" {
+		log.Fatal("-authenticate-reads needs the token of -api-key")
+	}
+	controllers.SetAuthentication(*apiKey, *authenticateReads)
+	if *usersFile != "" {
+		tokens, err := controllers.ReadUsersFile(*usersFile)
+		if err != nil {
+			log.Fatal("Cannot read the users: ", err)
+		}
+		controllers.SetUsers(tokens)
+	}
+	if *adminUsers != "" {
+		if *usersFile == "" {
+			log.Fatal("-admins names users of -users-file")
+		}
+		controllers.SetAdmins(strings.Split(*adminUsers, ","))
+	}
+	models.SetDefaultOwner(*defaultOwner)
+
+	if *address == "" {
+		*address = controllers.BackendHostUrl
+	}
+	if *repositoryKind == "" && *dualWrite == "" && os.Getenv("DATABASE_URL") != "" {
+		*repositoryKind = "postgres"
+	}
+	if *dualWrite != "" && *repositoryKind != "" && *repositoryKind != "memory" {
+		log.Fatal("The dual-write mode migrates the memory repository, it cannot be used with ", *repositoryKind)
+	}
+	switch *repositoryKind {
+	case "", "memory":
+		if *dualWrite != "" {
+			target, err := openStore(*dualWrite, *sqliteFile)
+			if err != nil {
+				log.Fatal("Cannot open the new store of the dual-write mode: ", err)
+			}
+			repository, ok := target.(models.Repository)
+			if ok == false {
+				log.Fatal("The new store of the dual-write mode must be sqlite or postgres")
+			}
+			controllers.Run(models.NewDualWriteRepository(models.NewMemoryRepository(), repository), true, *address)
+			return
+		}
+		controllers.Run(models.NewMemoryRepository(), true, *address)
+	case "sqlite":
+		repository, err := openSqliteRepository(*sqliteFile)
+		if err != nil {
+			log.Fatal("Cannot open the SQLite database: ", err)
+		}
+		controllers.Run(repository, false, *address)
+	case "postgres":
+		repository, err := openPostgresRepository()
+		if err != nil {
+			log.Fatal("Cannot open the PostgreSQL database: ", err)
+		}
+		controllers.Run(repository, false, *address)
+	default:
+		log.Fatal("Unknown repository ", *repositoryKind, ", use memory, sqlite or postgres")
+	}
+}
+
+// openSqliteRepository opens the SQLite database, a relative path is relative to the data directory
+func openSqliteRepository(path string) (*models.SqlRepository, error) {
+	if path == "" {
+		path = "todos.db"
+	}
+	if filepath.IsAbs(path) == false {
+		path = models.DataPath(path)
+	}
+	return models.OpenSqliteRepository(path)
+}
+
+// openPostgresRepository opens the PostgreSQL database of DATABASE_URL
+func openPostgresRepository() (*models.SqlRepository, error) {
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		return nil, errors.New("DATABASE_URL must be set for the postgres repository")
+	}
+	maxConnections := 10
+	if value := os.Getenv("TODO_DATABASE_MAX_CONNECTIONS"); value != "" {
+		var err error
+		maxConnections, err = strconv.Atoi(value)
+		if err != nil || maxConnections <= 0 {
+			return nil, errors.New("TODO_DATABASE_MAX_CONNECTIONS must be a positive number")
+		}
+	}
+	return models.OpenPostgresRepository(url, maxConnections)
 }
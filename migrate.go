@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"todo-rest-backend/models"
+	"todo-rest-backend/plugins"
+)
+
+// migrateStore copies all data from one store to another and verifies the copy,
+// e.g. todo-rest-backend migrate-store --from csv --to sqlite
+func migrateStore(args []string) {
+	flags := flag.NewFlagSet("migrate-store", flag.ExitOnError)
+	from := flags.String("from", "", "source store: sqlite, postgres or a storage like csv or csv.gz")
+	to := flags.String("to", "", "target store: sqlite, postgres or a storage like csv or csv.gz")
+	dataDir := flags.String("data-dir", os.Getenv("TODO_DATA_DIR"),
+		"directory of the data files, defaults to the platform data directory")
+	sqliteFile := flags.String("sqlite-file", os.Getenv("TODO_SQLITE_FILE"),
+		"SQLite database, relative to the data directory, defaults to todos.db")
+	overwrite := flags.Bool("overwrite", false, "replace the data of a target already holding todos")
+	flags.Parse(args)
+
+	if *from == "" || *to == "" {
+		log.Fatal("migrate-store needs --from and --to")
+	}
+	if *from == *to {
+		log.Fatal("The source and the target of the migration are the same store")
+	}
+	err := models.SetDataDir(*dataDir)
+	if err != nil {
+		log.Fatal("Cannot create the data directory: ", err)
+	}
+
+	source, err := openStore(*from, *sqliteFile)
+	if err != nil {
+		log.Fatal("Cannot open the source: ", err)
+	}
+	defer closeStore(source)
+	target, err := openStore(*to, *sqliteFile)
+	if err != nil {
+		log.Fatal("Cannot open the target: ", err)
+	}
+	defer closeStore(target)
+
+	report, err := models.MigrateStore(source, target, *overwrite)
+	if err != nil {
+		log.Fatal("Migration failed: ", err)
+	}
+	fmt.Printf("Migrated %d todos, %d list sequences, %d pomodoro sessions and %d goals from %s to %s\n",
+		report.Counts.Todos, report.Counts.Lists, report.Counts.PomodoroSessions, report.Counts.Goals, *from, *to)
+	fmt.Println("Checksum:", report.Checksum)
+}
+
+// reencrypt encrypts the descriptions of a store with the current key of TODO_ENCRYPTION_KEY, reading them
+// with the keys of TODO_ENCRYPTION_PREVIOUS_KEYS, e.g. todo-rest-backend reencrypt --store sqlite
+func reencrypt(args []string) {
+	flags := flag.NewFlagSet("reencrypt", flag.ExitOnError)
+	storeName := flags.String("store", os.Getenv("TODO_STORAGE"),
+		"store to re-encrypt: sqlite, postgres or a storage like csv or json, defaults to csv")
+	dataDir := flags.String("data-dir", os.Getenv("TODO_DATA_DIR"),
+		"directory of the data files, defaults to the platform data directory")
+	sqliteFile := flags.String("sqlite-file", os.Getenv("TODO_SQLITE_FILE"),
+		"SQLite database, relative to the data directory, defaults to todos.db")
+	flags.Parse(args)
+
+	if *storeName == "" {
+		*storeName = "csv"
+	}
+	err := models.SetDataDir(*dataDir)
+	if err != nil {
+		log.Fatal("Cannot create the data directory: ", err)
+	}
+	store, err := openStore(*storeName, *sqliteFile)
+	if err != nil {
+		log.Fatal("Cannot open the store: ", err)
+	}
+	defer closeStore(store)
+
+	encrypted, err := models.ReencryptStore(store)
+	if err != nil {
+		log.Fatal("Re-encryption failed: ", err)
+	}
+	fmt.Printf("Encrypted %d descriptions of %s with the current key\n", encrypted, *storeName)
+}
+
+// previousEncryptionKeys returns the comma separated keys of TODO_ENCRYPTION_PREVIOUS_KEYS
+func previousEncryptionKeys() []string {
+	var keys []string
+	for _, key := range strings.Split(os.Getenv("TODO_ENCRYPTION_PREVIOUS_KEYS"), ",") {
+		if strings.TrimSpace(key) != "" {
+			keys = append(keys, strings.TrimSpace(key))
+		}
+	}
+	return keys
+}
+
+// openStore opens the repository sqlite or postgres or the registered storage with the name
+func openStore(name string, sqliteFile string) (interface{}, error) {
+	switch name {
+	case "sqlite":
+		return openSqliteRepository(sqliteFile)
+	case "postgres":
+		return openPostgresRepository()
+	}
+	return plugins.NewStorage(name)
+}
+
+func closeStore(store interface{}) {
+	if closer, ok := store.(io.Closer); ok {
+		closer.Close()
+	}
+}
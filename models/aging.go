@@ -0,0 +1,82 @@
+package models
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// Staleness buckets of open todos by their age in days
+const (
+	AgeFresh = "fresh"
+	AgeAging = "aging"
+	AgeStale = "stale"
+)
+
+// Lower bounds of the staleness buckets in days
+const (
+	agingAfterDays = 7
+	staleAfterDays = 30
+)
+
+// AgeDays returns the number of full days since the todo was created and false for todos
+// without creation time
+func (t Todo) AgeDays() (int, bool) {
+	if t.CreatedAt == nil {
+		return 0, false
+	}
+	return int(Now().Sub(*t.CreatedAt).Hours() / 24), true
+}
+
+// Staleness returns the bucket of an open todo: fresh for less than a week, aging for less than 30 days
+// and stale after that. Terminated todos and todos without creation time have none.
+func (t Todo) Staleness() string {
+	days, ok := t.AgeDays()
+	switch {
+	case t.Terminated || ok == false:
+		return ""
+	case days >= staleAfterDays:
+		return AgeStale
+	case days >= agingAfterDays:
+		return AgeAging
+	}
+	return AgeFresh
+}
+
+// MarshalJSON adds the age in days and the staleness bucket to open todos
+// and the days waiting to todos waiting on someone
+func (t Todo) MarshalJSON() ([]byte, error) {
+	// todoFields has the fields of Todo without its methods, so that it is encoded as usual
+	type todoFields Todo
+	aged := struct {
+		todoFields
+		AgeDays     *int   `json:"age_days,omitempty"`
+		Staleness   string `json:"staleness,omitempty"`
+		WaitingDays *int   `json:"waiting_days,omitempty"`
+	}{todoFields: todoFields(t), Staleness: t.Staleness()}
+	if days, ok := t.AgeDays(); ok && t.Terminated == false {
+		aged.AgeDays = &days
+	}
+	if t.WaitingSince != nil {
+		waitingDays := t.WaitingDays()
+		aged.WaitingDays = &waitingDays
+	}
+	return json.Marshal(aged)
+}
+
+// StaleTodos returns the open todos created at least days ago, the oldest first
+func StaleTodos(days int) []Todo {
+	todos := []Todo{}
+	for _, todo := range listTodos() {
+		age, ok := todo.AgeDays()
+		if todo.Terminated == false && ok && age >= days {
+			todos = append(todos, todo)
+		}
+	}
+	sort.Slice(todos, func(i, j int) bool {
+		if todos[i].CreatedAt.Equal(*todos[j].CreatedAt) {
+			return todos[i].Number < todos[j].Number
+		}
+		return todos[i].CreatedAt.Before(*todos[j].CreatedAt)
+	})
+	return todos
+}
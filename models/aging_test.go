@@ -0,0 +1,38 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"todo-rest-backend/clock"
+)
+
+func TestStaleTodos(t *testing.T) {
+	// Arrange
+	//
+	DeleteAllTodos()
+	defer DeleteAllTodos()
+	defer SetClock(clk)
+	fake := clock.NewFake(time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC))
+	SetClock(fake)
+	old := AddTodo(Todo{Title: "Clean the attic"})
+	AddTodo(Todo{Title: "Call the bank", Terminated: true})
+	fake.Advance(25 * 24 * time.Hour)
+	AddTodo(Todo{Title: "Buy milk"})
+	fake.Advance(10 * 24 * time.Hour)
+
+	// Act
+	//
+	stale := StaleTodos(30)
+	encoded, _ := json.Marshal(stale[0])
+
+	// Assert
+	//
+	if len(stale) != 1 || stale[0].Id != old.Id || stale[0].Staleness() != AgeStale {
+		t.Fatal("Fehler", stale)
+	}
+	if strings.Contains(string(encoded), `"age_days":35,"staleness":"stale"`) == false {
+		t.Error("Fehler", string(encoded))
+	}
+}
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Default board columns, a todo's status is one of the columns
+const (
+	StatusTodo       = "todo"
+	StatusInProgress = "in_progress"
+	StatusDone       = "done"
+)
+
+// boardColumns are the statuses of todos from left to right, todos in the last column are terminated
+var boardColumns = []string{StatusTodo, StatusInProgress, StatusDone}
+
+// ErrUnknownColumn is returned for a status that is not a board column
+var ErrUnknownColumn = errors.New("unknown board column")
+
+// SetBoardColumns replaces the board columns, the last column holds the terminated todos
+func SetBoardColumns(columns []string) error {
+	if len(columns) < 2 {
+		return errors.New("a board needs at least two columns")
+	}
+	seen := make(map[string]bool)
+	for _, column := range columns {
+		if column == "" || seen[column] {
+			return fmt.Errorf("invalid board column %q", column)
+		}
+		seen[column] = true
+	}
+	boardColumns = columns
+	return nil
+}
+
+// BoardColumns returns the board columns from left to right
+func BoardColumns() []string {
+	return append([]string{}, boardColumns...)
+}
+
+// IsBoardColumn tells whether the status is a board column
+func IsBoardColumn(status string) bool {
+	for _, column := range boardColumns {
+		if column == status {
+			return true
+		}
+	}
+	return false
+}
+
+// DoneColumn returns the last board column, the status of terminated todos
+func DoneColumn() string {
+	return boardColumns[len(boardColumns)-1]
+}
+
+// statusOf returns the column of a todo without a valid status, e.g. stored before todos had a status
+func statusOf(terminated bool) string {
+	if terminated {
+		return DoneColumn()
+	}
+	return boardColumns[0]
+}
+
+// reconcileStatus keeps status and terminated flag consistent. The status follows the terminated flag
+// if only the flag changed, e.g. by clients unaware of the board, the flag follows the status otherwise.
+func reconcileStatus(todo Todo, previous *Todo) Todo {
+	if previous == nil {
+		if IsBoardColumn(todo.Status) == false {
+			todo.Status = statusOf(todo.Terminated)
+		}
+	} else if todo.Status == "" || todo.Status == previous.Status || IsBoardColumn(todo.Status) == false {
+		todo.Status = previous.Status
+		if IsBoardColumn(todo.Status) == false || todo.Terminated != previous.Terminated {
+			todo.Status = statusOf(todo.Terminated)
+		}
+	}
+	todo.Terminated = todo.Status == DoneColumn()
+	return todo
+}
+
+// initializeStatuses puts loaded todos without valid status into the first or the last column.
+// The terminated flag wins over a status it contradicts, e.g. after the columns were changed.
+func initializeStatuses() {
+	for _, todo := range listTodos() {
+		if IsBoardColumn(todo.Status) == false || todo.Terminated != (todo.Status == DoneColumn()) {
+			todo.Status = statusOf(todo.Terminated)
+			storeTodo(todo)
+		}
+	}
+}
+
+// MoveTodoToColumn sets the status of the todo and moves it to the position in the column, 1 is the top.
+// A position after the last todo of the column moves the todo to the end of the column.
+func MoveTodoToColumn(id string, column string, position int) (Todo, error) {
+	todo, ok := getTodo(id)
+	if ok == false {
+		return Todo{}, ErrTodoNotFound
+	}
+	if IsBoardColumn(column) == false {
+		return Todo{}, ErrUnknownColumn
+	}
+
+	// The position in the column is translated into a position in the list
+	var order []string
+	var columnTodos []Todo
+	for _, current := range ListTodos(todo.List) {
+		if current.Id == id {
+			continue
+		}
+		order = append(order, current.Id)
+		if current.Status == column {
+			columnTodos = append(columnTodos, current)
+		}
+	}
+
+	insertAt := len(order)
+	if position < 1 {
+		position = 1
+	}
+	if position <= len(columnTodos) {
+		insertAt = indexOf(order, columnTodos[position-1].Id)
+	} else if len(columnTodos) > 0 {
+		insertAt = indexOf(order, columnTodos[len(columnTodos)-1].Id) + 1
+	}
+	order = append(order[:insertAt], append([]string{id}, order[insertAt:]...)...)
+
+	previous := todo
+	todo.Status = column
+	todo.Terminated = column == DoneColumn()
+	todo.CompletedAt = completionTime(todo.Terminated, previous.CompletedAt)
+	touchTodo(todo)
+	_, err := ReorderList(todo.List, order)
+	if err != nil {
+		storeTodo(previous)
+		return Todo{}, err
+	}
+	moved, _ := getTodo(id)
+	return moved, nil
+}
+
+func indexOf(ids []string, id string) int {
+	for i, current := range ids {
+		if current == id {
+			return i
+		}
+	}
+	return -1
+}
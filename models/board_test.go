@@ -0,0 +1,49 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestMoveTodoToColumn(t *testing.T) {
+	// Arrange
+	//
+	DeleteAllTodos()
+	defer DeleteAllTodos()
+	first := AddTodo(Todo{Title: "First", List: "board"})
+	second := AddTodo(Todo{Title: "Second", List: "board"})
+	third := AddTodo(Todo{Title: "Third", List: "board"})
+	MoveTodoToColumn(first.Id, StatusInProgress, 1)
+
+	// Act
+	//
+	moved, err := MoveTodoToColumn(third.Id, StatusInProgress, 1)
+	done, _ := MoveTodoToColumn(second.Id, StatusDone, 1)
+
+	// Assert
+	//
+	if err != nil || moved.Status != StatusInProgress || moved.Position > TodoStore()[first.Id].Position {
+		t.Error("Fehler", moved, err)
+	}
+	if done.Terminated == false || done.CompletedAt == nil {
+		t.Error("todo moved to the last column is not terminated", done)
+	}
+}
+
+func TestUpdateTodo_StatusFollowsTerminated(t *testing.T) {
+	// Arrange
+	//
+	DeleteAllTodos()
+	defer DeleteAllTodos()
+	todo := AddTodo(Todo{Title: "Todo", Status: StatusInProgress})
+
+	// Act
+	//
+	unchanged, _ := UpdateTodo(todo.Id, Todo{Title: "Renamed"})
+	terminated, _ := UpdateTodo(todo.Id, Todo{Title: "Renamed", Terminated: true})
+
+	// Assert
+	//
+	if unchanged.Status != StatusInProgress || terminated.Status != StatusDone {
+		t.Error("Fehler", unchanged.Status, terminated.Status)
+	}
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrCorruptData is returned for data files not matching their checksum manifest or not being valid CSV
+var ErrCorruptData = errors.New("data file is corrupted")
+
+// manifestFileName is the file holding the checksum of a data file, it has the format of sha256sum
+func manifestFileName(fileName string) string {
+	return fileName + ".sha256"
+}
+
+// backupFileName is the previous version of a data file, it is kept on every save
+func backupFileName(fileName string) string {
+	return fileName + ".bak"
+}
+
+func fileChecksum(fileName string) (string, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// writeManifest stores the checksum of the data file next to it
+func writeManifest(fileName string) error {
+	checksum, err := fileChecksum(fileName)
+	if err != nil {
+		return err
+	}
+	return replaceManifest(fileName, checksum)
+}
+
+// replaceManifest replaces the manifest of the data file atomically with the checksum
+func replaceManifest(fileName string, checksum string) error {
+	manifest := manifestFileName(fileName)
+	temporary, err := writeTemporaryFile(manifest, []byte(checksum+"  "+filepath.Base(fileName)+"\n"))
+	if err != nil {
+		return err
+	}
+	return os.Rename(temporary, manifest)
+}
+
+// verifyChecksum compares the data file with its manifest.
+// Files written before manifests existed have none and are accepted, unless the backup has a manifest: then
+// the file was written after manifests existed and lost its manifest in a torn save.
+func verifyChecksum(fileName string) error {
+	manifest, err := os.ReadFile(manifestFileName(fileName))
+	if errors.Is(err, os.ErrNotExist) {
+		_, err = os.Stat(manifestFileName(backupFileName(fileName)))
+		if err == nil {
+			return fmt.Errorf("%s: %w: the manifest is missing", fileName, ErrCorruptData)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	checksum, err := fileChecksum(fileName)
+	if err != nil {
+		return err
+	}
+	fields := strings.Fields(string(manifest))
+	if len(fields) == 0 || fields[0] != checksum {
+		return fmt.Errorf("%s: %w", fileName, ErrCorruptData)
+	}
+	return nil
+}
+
+// replaceVerifiedDataFile replaces a data file and its manifest with the content, the previous file and its
+// manifest are kept as backup. The steps are ordered so that a crash leaves either a file matching its
+// manifest, no file or a file not matching its manifest; the last two are loaded from the backup.
+func replaceVerifiedDataFile(fileName string, content []byte) error {
+	temporary, err := writeTemporaryFile(fileName, content)
+	if err != nil {
+		return err
+	}
+	err = backupDataFile(fileName)
+	if err == nil {
+		checksum := sha256.Sum256(content)
+		err = replaceManifest(fileName, hex.EncodeToString(checksum[:]))
+	}
+	if err == nil {
+		err = os.Rename(temporary, fileName)
+	}
+	if err != nil {
+		os.Remove(temporary)
+		return err
+	}
+	return syncDir(filepath.Dir(fileName))
+}
+
+// backupDataFile moves a verified data file and its manifest to the backup before it is replaced.
+// Corrupted files do not replace the last valid backup. The manifest of the old backup is removed first, a
+// backup without manifest is accepted while the data file is missing.
+func backupDataFile(fileName string) error {
+	_, err := os.Stat(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil || verifyChecksum(fileName) != nil {
+		return err
+	}
+
+	backup := backupFileName(fileName)
+	err = os.Remove(manifestFileName(backup))
+	if err != nil && errors.Is(err, os.ErrNotExist) == false {
+		return err
+	}
+	err = os.Rename(fileName, backup)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(manifestFileName(fileName), manifestFileName(backup))
+	if errors.Is(err, os.ErrNotExist) {
+		return writeManifest(backup)
+	}
+	return err
+}
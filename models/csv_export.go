@@ -0,0 +1,73 @@
+package models
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+)
+
+// ErrMissingTitleColumn is returned for CSV exports without title column
+var ErrMissingTitleColumn = errors.New("the CSV has no title column")
+
+// WriteCsvExport writes the todos as CSV with a header row naming the columns. The columns are those of the
+// data file and the database, the checklist is a JSON array and the tags are separated by commas.
+func WriteCsvExport(writer io.Writer, todos []Todo) error {
+	csvWriter := csv.NewWriter(writer)
+	err := csvWriter.Write(todoColumns)
+	if err != nil {
+		return err
+	}
+	for _, todo := range todos {
+		err = csvWriter.Write(todo.Serialize())
+		if err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// ReadCsvExport reads todos written by WriteCsvExport. The columns are recognized by the header row, they may
+// be in any order and all but the title may be missing; unknown columns are ignored.
+func ReadCsvExport(reader io.Reader) ([]Todo, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return nil, ErrMissingTitleColumn
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// positions maps the index of a column in the export to its index in the data file
+	positions := make(map[int]int)
+	for i, name := range header {
+		for j, column := range todoColumns {
+			if name == column {
+				positions[i] = j
+			}
+		}
+	}
+	if _, ok := positions[indexOf(header, "title")]; ok == false {
+		return nil, ErrMissingTitleColumn
+	}
+
+	todos := []Todo{}
+	for {
+		row, err := csvReader.Read()
+		if err == io.EOF {
+			return todos, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rec := make([]string, len(todoColumns))
+		for i, value := range row {
+			if position, ok := positions[i]; ok {
+				rec[position] = value
+			}
+		}
+		todos = append(todos, parseTodoData(rec))
+	}
+}
@@ -0,0 +1,264 @@
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileName is the CSV file in the data directory the todos are stored in
+const FileName = "data.csv"
+
+// CompressedFileName is the data file of the gzip-compressed CSV storage
+const CompressedFileName = FileName + ".gz"
+
+// ArchiveFileName is the CSV file in the data directory archived todos are appended to
+const ArchiveFileName = "archive.csv"
+
+// Storage persists the todo store
+type Storage interface {
+	// Load reads all todos keyed by their id
+	Load() (map[string]Todo, error)
+	// Save replaces the stored todos
+	Save(todos map[string]Todo) error
+}
+
+// CsvStorage stores the todos in a CSV file, one todo per row
+type CsvStorage struct {
+	FileName string
+	// Compress writes the file gzip-compressed, compressed and plain files are both read
+	Compress bool
+}
+
+// Load reads the todos from the CSV file.
+// A corrupted file is not loaded, the backup of the previous save is read instead.
+func (s CsvStorage) Load() (map[string]Todo, error) {
+	// a compressed storage without data file yet starts from the plain file
+	//
+	fileName := s.FileName
+	if s.Compress && strings.HasSuffix(fileName, ".gz") && exists(fileName) == false && exists(backupFileName(fileName)) == false {
+		fileName = strings.TrimSuffix(fileName, ".gz")
+	}
+
+	// the backup is no replacement for data encrypted with a missing key, it is older;
+	// a missing file next to a backup is a save interrupted between its renames
+	todos, err := loadCsvFile(fileName)
+	backup := backupFileName(fileName)
+	if err == nil || errors.Is(err, ErrEncryptionKey) || (errors.Is(err, os.ErrNotExist) && exists(backup) == false) {
+		return todos, err
+	}
+
+	log.Printf("Cannot load %s: %v, loading the backup %s instead", fileName, err, backup)
+	todos, backupErr := loadCsvFile(backup)
+	if backupErr != nil {
+		return nil, fmt.Errorf("%w, the backup cannot be loaded either: %v", err, backupErr)
+	}
+	return todos, nil
+}
+
+// exists tells whether the file exists
+func exists(fileName string) bool {
+	_, err := os.Stat(fileName)
+	return err == nil
+}
+
+// loadCsvFile verifies the checksum of the file and reads its todos
+func loadCsvFile(fileName string) (map[string]Todo, error) {
+	err := verifyChecksum(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	// open file
+	//
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, err := decompressed(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %v", fileName, ErrCorruptData, err)
+	}
+
+	var readTodos = make(map[string]Todo)
+
+	// read csv values using csv.Reader
+	//
+	csvReader := csv.NewReader(reader)
+	rowIndex := 0
+	for {
+		records, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w: %v", fileName, ErrCorruptData, err)
+		}
+		if len(records) < 4 {
+			return nil, fmt.Errorf("%s: %w: row %d has only %d columns", fileName, ErrCorruptData, rowIndex+1, len(records))
+		}
+
+		// Add todo to map
+		//
+		todo, err := openTodo(parseTodoData(records))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fileName, err)
+		}
+		readTodos[todo.Id] = todo
+		rowIndex = rowIndex + 1
+	}
+
+	return readTodos, nil
+}
+
+// decompressed returns a reader of the uncompressed content, files are recognized as gzip by their magic number
+func decompressed(file io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(file)
+	magic, err := buffered.Peek(2)
+	if err != nil || magic[0] != 0x1f || magic[1] != 0x8b {
+		return buffered, nil
+	}
+	return gzip.NewReader(buffered)
+}
+
+func parseTodoData(rec []string) Todo {
+	// Parse todo
+	//
+	id := rec[0]
+	title := rec[1]
+	description := rec[2]
+	terminated := ToBool(rec[3])
+	externalRef := csvField(rec, 4)
+	tags := splitList(csvField(rec, 5))
+	autoTags := splitList(csvField(rec, 6))
+	if len(autoTags) == 0 {
+		autoTags = nil
+	}
+	completedAt := parseTime(csvField(rec, 7))
+	location := parseLocation(csvField(rec, 8), csvField(rec, 9), csvField(rec, 10))
+	list := csvField(rec, 11)
+	number, _ := strconv.Atoi(csvField(rec, 12))
+	position, _ := strconv.Atoi(csvField(rec, 13))
+	status := csvField(rec, 14)
+	dueDate := csvField(rec, 15)
+	estimateMinutes, _ := strconv.Atoi(csvField(rec, 16))
+	habitSince := csvField(rec, 17)
+	habitDays := splitList(csvField(rec, 18))
+	if len(habitDays) == 0 {
+		habitDays = nil
+	}
+	goalId := csvField(rec, 19)
+	waitingOn := csvField(rec, 20)
+	waitingSince := parseTime(csvField(rec, 21))
+	nudgedAt := parseTime(csvField(rec, 22))
+	createdAt := parseTime(csvField(rec, 23))
+	priority := csvField(rec, 24)
+	items := parseItems(csvField(rec, 25))
+	owner := csvField(rec, 26)
+	deletedAt := parseTime(csvField(rec, 27))
+	updatedAt := parseTime(csvField(rec, 28))
+
+	// Create new todo based on parsed values
+	//
+	todo := Todo{Id: id, Title: title, Description: description, Terminated: terminated, ExternalRef: externalRef,
+		Tags: tags, AutoTags: autoTags, CompletedAt: completedAt, Location: location,
+		List: list, Number: number, Position: position,
+		Status: status, DueDate: dueDate, EstimateMinutes: estimateMinutes,
+		Habit: habitSince != "", HabitSince: habitSince, HabitDays: habitDays, GoalId: goalId,
+		WaitingOn: waitingOn, WaitingSince: waitingSince, NudgedAt: nudgedAt,
+		CreatedAt: createdAt, Priority: priority, Items: items, Owner: owner,
+		DeletedAt: deletedAt, UpdatedAt: updatedAt}
+	return todo
+}
+
+// parseTime parses an RFC 3339 column, empty or invalid columns are nil
+func parseTime(field string) *time.Time {
+	parsed, err := time.Parse(time.RFC3339, field)
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
+
+// splitList splits a comma separated column, an empty column is an empty list
+func splitList(field string) []string {
+	if field == "" {
+		return []string{}
+	}
+	return strings.Split(field, ",")
+}
+
+// csvField returns the column at index or an empty string for files written before the column existed
+func csvField(rec []string, index int) string {
+	if index >= len(rec) {
+		return ""
+	}
+	return rec[index]
+}
+
+// Save writes the todos to the CSV file and its checksum manifest, the previous file is kept as backup.
+// The file is written next to the data file and renamed over it, a crash never leaves a partly written file.
+func (s CsvStorage) Save(todos map[string]Todo) error {
+	var content bytes.Buffer
+	var output io.Writer = &content
+	var compressor *gzip.Writer
+	if s.Compress {
+		compressor = gzip.NewWriter(&content)
+		output = compressor
+	}
+	writer := csv.NewWriter(output)
+
+	for _, todo := range todos {
+		err := writer.Write(sealTodo(todo).Serialize())
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	err := writer.Error()
+	if err == nil && compressor != nil {
+		err = compressor.Close()
+	}
+	if err != nil {
+		return err
+	}
+
+	return replaceVerifiedDataFile(s.FileName, content.Bytes())
+}
+
+// ArchiveTodos appends the todos to the archive file
+func ArchiveTodos(todos []Todo) error {
+	file, err := openDataFile(DataPath(ArchiveFileName), os.O_WRONLY|os.O_CREATE|os.O_APPEND)
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(file)
+
+	for _, todo := range todos {
+		err = writer.Write(sealTodo(todo).Serialize())
+		if err != nil {
+			file.Close()
+			return err
+		}
+	}
+
+	writer.Flush()
+	err = writer.Error()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
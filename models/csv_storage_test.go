@@ -0,0 +1,134 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCsvStorage_CompressedRoundTrip(t *testing.T) {
+	// Arrange
+	//
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "data.csv"), []byte("0,Plain,Beschrieb,false\n"), 0600)
+	storage := CsvStorage{FileName: filepath.Join(dir, "data.csv.gz"), Compress: true}
+
+	// Act
+	//
+	plain, plainErr := storage.Load()
+	saveErr := storage.Save(map[string]Todo{"0": {Id: "0", Title: "Compressed", Tags: []string{}}})
+	compressed, compressedErr := storage.Load()
+	content, _ := os.ReadFile(storage.FileName)
+
+	// Assert
+	//
+	if plainErr != nil || plain["0"].Title != "Plain" {
+		t.Error("Fehler", plainErr)
+	}
+	if saveErr != nil || compressedErr != nil || compressed["0"].Title != "Compressed" {
+		t.Error("Fehler", saveErr, compressedErr)
+	}
+	if len(content) < 2 || content[0] != 0x1f || content[1] != 0x8b {
+		t.Error("file is not gzip-compressed")
+	}
+}
+
+func TestCsvStorage_CorruptedFileFallsBackToBackup(t *testing.T) {
+	// Arrange
+	//
+	storage := CsvStorage{FileName: filepath.Join(t.TempDir(), "data.csv")}
+	storage.Save(map[string]Todo{"0": {Id: "0", Title: "Backup", Tags: []string{}}})
+	storage.Save(map[string]Todo{"0": {Id: "0", Title: "Current", Tags: []string{}}})
+	os.WriteFile(storage.FileName, []byte("0,Trunc"), 0600)
+
+	// Act
+	//
+	got, err := storage.Load()
+
+	// Assert
+	//
+	if err != nil || got["0"].Title != "Backup" {
+		t.Error("Fehler", err)
+	}
+}
+
+func TestCsvStorage_SaveCreatesPrivateFiles(t *testing.T) {
+	// Arrange
+	//
+	storage := CsvStorage{FileName: filepath.Join(t.TempDir(), "data.csv")}
+	os.WriteFile(storage.FileName, []byte{}, 0644)
+
+	// Act
+	//
+	err := storage.Save(map[string]Todo{})
+	info, _ := os.Stat(storage.FileName)
+	manifest, _ := os.Stat(manifestFileName(storage.FileName))
+
+	// Assert
+	//
+	if err != nil || info.Mode().Perm() != 0600 || manifest.Mode().Perm() != 0600 {
+		t.Error("Fehler", err)
+	}
+}
+
+func TestCsvStorage_TornWriteFallsBackToBackup(t *testing.T) {
+	// Arrange
+	//
+	storage := CsvStorage{FileName: filepath.Join(t.TempDir(), "data.csv")}
+	storage.Save(map[string]Todo{"0": {Id: "0", Title: "Backup", Tags: []string{}}})
+	storage.Save(map[string]Todo{"0": {Id: "0", Title: "Current", Tags: []string{}}})
+	os.Rename(storage.FileName, backupFileName(storage.FileName))
+	os.Rename(manifestFileName(storage.FileName), manifestFileName(backupFileName(storage.FileName)))
+	// the save was torn after the first row of the new file, before its manifest was written
+	os.WriteFile(storage.FileName, []byte("0,Torn,,false\n"), 0600)
+
+	// Act
+	//
+	got, err := storage.Load()
+
+	// Assert
+	//
+	if err != nil || got["0"].Title != "Current" {
+		t.Error("Fehler", err, got)
+	}
+}
+
+func TestCsvStorage_InterruptedSaveLoadsBackup(t *testing.T) {
+	// Arrange
+	//
+	storage := CsvStorage{FileName: filepath.Join(t.TempDir(), "data.csv.gz"), Compress: true}
+	storage.Save(map[string]Todo{"0": {Id: "0", Title: "Backup", Tags: []string{}}})
+	storage.Save(map[string]Todo{"0": {Id: "0", Title: "Current", Tags: []string{}}})
+	os.Remove(storage.FileName)
+
+	// Act
+	//
+	got, err := storage.Load()
+	saveErr := storage.Save(map[string]Todo{"0": {Id: "0", Title: "Next", Tags: []string{}}})
+	next, nextErr := storage.Load()
+
+	// Assert
+	//
+	if err != nil || got["0"].Title != "Backup" {
+		t.Error("Fehler", err, got)
+	}
+	if saveErr != nil || nextErr != nil || next["0"].Title != "Next" {
+		t.Error("Fehler", saveErr, nextErr, next)
+	}
+}
+
+func TestCsvStorage_SaveReturnsWriteErrors(t *testing.T) {
+	// Arrange
+	//
+	storage := CsvStorage{FileName: filepath.Join(t.TempDir(), "missing", "data.csv")}
+
+	// Act
+	//
+	err := storage.Save(map[string]Todo{"0": {Id: "0", Title: "Unsaved", Tags: []string{}}})
+
+	// Assert
+	//
+	if err == nil {
+		t.Error("Fehler, the save did not fail")
+	}
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// appDirName is the directory of the backend below the platform data directory
+const appDirName = "todo-backend"
+
+// dataDir holds the data files, the working directory is used while it is empty
+var dataDir string
+
+// SetDataDir selects the directory of the data files and creates it.
+// Without override the platform data directory is used, unless the working directory
+// already contains a data file from before the data directory existed.
+func SetDataDir(override string) error {
+	dir := override
+	if dir == "" {
+		for _, fileName := range []string{FileName, CompressedFileName} {
+			_, err := os.Stat(fileName)
+			if err == nil {
+				log.Println("Using the data file in the working directory, move it to the data directory to use that")
+				dataDir = ""
+				return nil
+			}
+		}
+
+		var err error
+		dir, err = platformDataDir()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Existing directories like $HOME may hold other files, only the directories created here get the mode 0700
+	if _, err := os.Stat(dir); err == nil {
+		warnOpenDir(dir)
+	} else {
+		err = os.MkdirAll(dir, 0700)
+		if err != nil {
+			return err
+		}
+	}
+	dataDir = dir
+	return nil
+}
+
+// warnOpenDir warns about an existing data directory other users can access, it is not changed.
+func warnOpenDir(dir string) {
+	if runtime.GOOS == "windows" {
+		return
+	}
+	info, err := os.Stat(dir)
+	if err != nil || info.Mode().Perm()&0077 == 0 {
+		return
+	}
+	log.Printf("Warning: other users can access the data directory %s with mode %04o, restrict it with chmod 0700",
+		filepath.Clean(dir), info.Mode().Perm())
+}
+
+// DataDir returns the directory of the data files, an empty string is the working directory
+func DataDir() string {
+	return dataDir
+}
+
+// DataPath returns the path of a data file in the data directory
+func DataPath(fileName string) string {
+	return filepath.Join(dataDir, fileName)
+}
+
+// platformDataDir is $XDG_DATA_HOME (default ~/.local/share) on Linux and other Unix systems,
+// %APPDATA% on Windows and ~/Library/Application Support on macOS
+func platformDataDir() (string, error) {
+	switch runtime.GOOS {
+	case "windows", "darwin", "ios", "plan9":
+		base, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(base, appDirName), nil
+	}
+
+	base := os.Getenv("XDG_DATA_HOME")
+	if base == "" || filepath.IsAbs(base) == false {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", errors.New("cannot resolve the data directory, neither XDG_DATA_HOME nor HOME is set")
+		}
+		base = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(base, appDirName), nil
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetDataDir_CreatesOverride(t *testing.T) {
+	// Arrange
+	//
+	dir := filepath.Join(t.TempDir(), "nested", "data")
+	defer func() { dataDir = "" }()
+
+	// Act
+	//
+	err := SetDataDir(dir)
+	info, statErr := os.Stat(dir)
+
+	// Assert
+	//
+	if err != nil || statErr != nil || info.IsDir() == false || info.Mode().Perm() != 0700 {
+		t.Error("Fehler", err, statErr)
+	}
+	if DataPath(FileName) != filepath.Join(dir, FileName) {
+		t.Error("Fehler", DataPath(FileName))
+	}
+}
+
+func TestSetDataDir_KeepsTheModeOfExistingDirectory(t *testing.T) {
+	// Arrange
+	//
+	dir := filepath.Join(t.TempDir(), "data")
+	os.Mkdir(dir, 0755)
+	os.Chmod(dir, 0755)
+	defer func() { dataDir = "" }()
+
+	// Act
+	//
+	err := SetDataDir(dir)
+	info, _ := os.Stat(dir)
+
+	// Assert
+	//
+	if err != nil || info.Mode().Perm() != 0755 {
+		t.Error("Fehler", err, info.Mode().Perm())
+	}
+}
@@ -0,0 +1,203 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// ErrNoDualWrite is returned for consistency checks without dual-write mode
+var ErrNoDualWrite = errors.New("dual-write mode is not enabled")
+
+// DualWriteRepository is the transition mode of a migration without downtime: the todos are read from the
+// old repository and written to both. The new store gets a copy of the data when Initialize finished,
+// from then on it is written after the old one. The old store stays the source of truth, failed writes
+// to the new one are logged and show up in the consistency report.
+type DualWriteRepository struct {
+	Repository
+	// Target is the new store, typically a PersistentRepository keeping the side data as well
+	Target Repository
+
+	mutex       sync.Mutex
+	mirroring   bool
+	failures    int
+	lastFailure string
+}
+
+// NewDualWriteRepository returns a repository reading from old and writing to old and target
+func NewDualWriteRepository(old Repository, target Repository) *DualWriteRepository {
+	return &DualWriteRepository{Repository: old, Target: target}
+}
+
+// ConsistencyReport compares the data saved in the old store with the data of the new one
+type ConsistencyReport struct {
+	Consistent bool          `json:"consistent"`
+	Mirroring  bool          `json:"mirroring"`
+	Old        DatasetCounts `json:"old"`
+	New        DatasetCounts `json:"new"`
+	// the checksums of both datasets, see Dataset.Checksum
+	OldChecksum string `json:"old_checksum"`
+	NewChecksum string `json:"new_checksum"`
+	// the ids of the todos only in the old store, only in the new one and different in both
+	Missing    []string `json:"missing"`
+	Unexpected []string `json:"unexpected"`
+	Differing  []string `json:"differing"`
+	// WriteFailures counts the writes to the new store that failed since the start
+	WriteFailures int    `json:"write_failures"`
+	LastFailure   string `json:"last_failure,omitempty"`
+}
+
+func (r *DualWriteRepository) Add(todo Todo) error {
+	err := r.Repository.Add(todo)
+	if err == nil {
+		r.mirror("add "+todo.Id, func() error { return r.Target.Add(todo) })
+	}
+	return err
+}
+
+func (r *DualWriteRepository) Update(todo Todo) error {
+	err := r.Repository.Update(todo)
+	if err == nil {
+		r.mirror("update "+todo.Id, func() error { return r.Target.Update(todo) })
+	}
+	return err
+}
+
+func (r *DualWriteRepository) Delete(id string) error {
+	err := r.Repository.Delete(id)
+	if err == nil {
+		r.mirror("delete "+id, func() error { return r.Target.Delete(id) })
+	}
+	return err
+}
+
+func (r *DualWriteRepository) DeleteAll() error {
+	err := r.Repository.DeleteAll()
+	if err == nil {
+		r.mirror("delete all", r.Target.DeleteAll)
+	}
+	return err
+}
+
+// Close closes the stores implementing io.Closer
+func (r *DualWriteRepository) Close() error {
+	var errs []error
+	for _, store := range []Repository{r.Repository, r.Target} {
+		if closer, ok := store.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// mirror runs the write to the new store once it got its copy of the data
+func (r *DualWriteRepository) mirror(operation string, write func() error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.mirroring == false {
+		return
+	}
+	err := write()
+	if err != nil {
+		r.failed(fmt.Errorf("%s: %w", operation, err))
+	}
+}
+
+// failed records a failed write to the new store, the mutex must be held
+func (r *DualWriteRepository) failed(err error) {
+	log.Println("Dual write: cannot write to the new store:", err)
+	r.failures++
+	r.lastFailure = err.Error()
+}
+
+// synchronize copies the todos and the side data to the new store and starts mirroring the writes
+func (r *DualWriteRepository) synchronize(side interface{}) {
+	dataset := Dataset{Todos: make(map[string]Todo), Sequences: listSequences, PomodoroSessions: pomodoroSessions,
+		Goals: Goals(), Revision: savedRevision}
+	for _, todo := range r.Repository.List() {
+		dataset.Todos[todo.Id] = todo
+	}
+	if settingsStorage, ok := side.(SettingsStorage); ok {
+		dataset.Settings, _ = settingsStorage.LoadSettings()
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	err := WriteDataset(r.Target, dataset)
+	if err != nil {
+		r.failed(fmt.Errorf("copy: %w", err))
+		log.Println("Dual write: the writes are not mirrored until the next start")
+		return
+	}
+	r.mirroring = true
+	log.Printf("Dual write: copied %+v to the new store", dataset.Counts())
+}
+
+// mirrorSideData writes the saved side data to the new store
+func (r *DualWriteRepository) mirrorSideData(dataset Dataset) {
+	r.mirror("side data", func() error { return writeSideData(r.Target, dataset) })
+}
+
+// mirrorSettings writes the saved settings to the new store
+func (r *DualWriteRepository) mirrorSettings(settings Settings) {
+	if settingsStorage, ok := r.Target.(SettingsStorage); ok {
+		r.mirror("settings", func() error { return settingsStorage.SaveSettings(settings) })
+	}
+}
+
+// dualWrite returns the repository if the store is in dual-write mode
+func dualWrite() (*DualWriteRepository, bool) {
+	dual, ok := repository.(*DualWriteRepository)
+	return dual, ok
+}
+
+// CheckDualWrite compares the data saved in the old store with the new store of the dual-write mode
+func CheckDualWrite() (ConsistencyReport, error) {
+	dual, ok := dualWrite()
+	if ok == false {
+		return ConsistencyReport{}, ErrNoDualWrite
+	}
+	var old Dataset
+	var err error
+	if filePersistence {
+		old, err = ReadDataset(storage)
+	} else {
+		old, err = ReadDataset(dual.Repository)
+	}
+	if err != nil {
+		return ConsistencyReport{}, fmt.Errorf("cannot read the old store: %w", err)
+	}
+	target, err := ReadDataset(dual.Target)
+	if err != nil {
+		return ConsistencyReport{}, fmt.Errorf("cannot read the new store: %w", err)
+	}
+
+	dual.mutex.Lock()
+	report := ConsistencyReport{Mirroring: dual.mirroring, WriteFailures: dual.failures, LastFailure: dual.lastFailure}
+	dual.mutex.Unlock()
+	report.Old, report.New = old.Counts(), target.Counts()
+	report.OldChecksum, report.NewChecksum = old.Checksum(), target.Checksum()
+	report.Missing, report.Unexpected, report.Differing = []string{}, []string{}, []string{}
+	for id, todo := range old.Todos {
+		copied, ok := target.Todos[id]
+		if ok == false {
+			report.Missing = append(report.Missing, id)
+		} else if reflect.DeepEqual(todo.Serialize(), copied.Serialize()) == false {
+			report.Differing = append(report.Differing, id)
+		}
+	}
+	for id := range target.Todos {
+		if _, ok := old.Todos[id]; ok == false {
+			report.Unexpected = append(report.Unexpected, id)
+		}
+	}
+	sort.Strings(report.Missing)
+	sort.Strings(report.Unexpected)
+	sort.Strings(report.Differing)
+	report.Consistent = report.OldChecksum == report.NewChecksum
+	return report, nil
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDualWriteRepository_MirrorsWritesToTheNewStore(t *testing.T) {
+	// Arrange
+	//
+	dir := t.TempDir()
+	target, err := OpenSqliteRepository(filepath.Join(dir, "todos.db"))
+	if err != nil {
+		t.Fatal("Fehler", err)
+	}
+	defer target.Close()
+	old := CsvStorage{FileName: filepath.Join(dir, FileName)}
+	WriteDataset(old, Dataset{Todos: map[string]Todo{"0": {Id: "0", Title: "Buy milk", List: "inbox", Number: 1}},
+		Sequences: map[string]int{"inbox": 1}})
+	defer SetRepository(NewMemoryRepository())
+	defer SetStorage(CsvStorage{FileName: FileName})
+	defer DisableFilePersistence()
+	SetStorage(old)
+	EnableFilePersistence()
+	SetRepository(NewDualWriteRepository(NewMemoryRepository(), target))
+	listSequences = make(map[string]int)
+	Initialize()
+
+	// Act
+	//
+	AddTodo(Todo{Title: "Call Anna", List: "inbox"})
+	// the removed todo stays in the trash
+	RemoveTodo("0")
+	err = UpdateDataInFile()
+	consistent, errConsistent := CheckDualWrite()
+	target.Update(Todo{Id: "1", Title: "Changed behind the back"})
+	diverged, _ := CheckDualWrite()
+
+	// Assert
+	//
+	if err != nil || errConsistent != nil || consistent.Consistent == false || consistent.New.Todos != 2 {
+		t.Error("Fehler", consistent, err, errConsistent)
+	}
+	if diverged.Consistent || len(diverged.Differing) != 1 || diverged.Differing[0] != "1" {
+		t.Error("Fehler", diverged)
+	}
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// DateFormat is the format of due dates
+const DateFormat = "2006-01-02"
+
+// ParseDue reads a due date, either a day in the form 2006-01-02 or a time in RFC 3339.
+// A day is returned as its start in the local time zone, dayOnly tells them apart.
+func ParseDue(due string) (at time.Time, dayOnly bool, err error) {
+	at, err = time.ParseInLocation(DateFormat, due, time.Local)
+	if err == nil {
+		return at, true, nil
+	}
+	at, err = time.Parse(time.RFC3339, due)
+	if err != nil {
+		return time.Time{}, false, errors.New("due_date must have the form 2006-01-02 or 2006-01-02T15:04:05Z07:00")
+	}
+	return at, false, nil
+}
+
+// DueAt returns the due time of the todo, the start of the day for due days
+func (t Todo) DueAt() (time.Time, bool) {
+	if t.DueDate == "" {
+		return time.Time{}, false
+	}
+	at, _, err := ParseDue(t.DueDate)
+	return at, err == nil
+}
+
+// DueDay returns the local day the todo is due in the form 2006-01-02, empty without due date
+func (t Todo) DueDay() string {
+	at, ok := t.DueAt()
+	if ok == false {
+		return ""
+	}
+	return at.Local().Format(DateFormat)
+}
+
+// Overdue tells whether the open todo is past its due date, a due day is over at its end
+func (t Todo) Overdue() bool {
+	if t.Terminated || t.DueDate == "" {
+		return false
+	}
+	at, dayOnly, err := ParseDue(t.DueDate)
+	if err != nil {
+		return false
+	}
+	if dayOnly {
+		return t.DueDate < Today()
+	}
+	return Now().After(at)
+}
+
+// Today returns the current day of the store clock in the form of due dates
+func Today() string {
+	return Now().Local().Format(DateFormat)
+}
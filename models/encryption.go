@@ -0,0 +1,181 @@
+package models
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// encryptedPrefix marks encrypted fields, it is followed by the id of the key and the base64 encoded nonce and
+// ciphertext: enc:v1:<key id>:<nonce and ciphertext>
+const encryptedPrefix = "enc:v1:"
+
+// ErrEncryptionKey is returned for encrypted fields without the key they were encrypted with
+var ErrEncryptionKey = errors.New("the data is encrypted with an unknown key")
+
+// encryptionKey is an AES-256-GCM key, its id is the start of its SHA-256 hash
+type encryptionKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// encryptionKeys are the current key followed by the previous keys, the descriptions are stored in plain text
+// without keys
+var encryptionKeys []encryptionKey
+
+// SetEncryptionKeys encrypts the descriptions of the todos with the current key before they are written to
+// files or databases, handlers see them decrypted. Descriptions encrypted with the previous keys are still
+// read, they are encrypted with the current key when they are written again, see ReencryptStore.
+// The keys are 32 random bytes in base64, an empty current key disables the encryption.
+func SetEncryptionKeys(current string, previous []string) error {
+	encryptionKeys = nil
+	if current == "" {
+		if len(previous) > 0 {
+			return errors.New("previous encryption keys need a current key")
+		}
+		return nil
+	}
+	var keys []encryptionKey
+	for _, encoded := range append([]string{current}, previous...) {
+		key, err := parseEncryptionKey(encoded)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+	encryptionKeys = keys
+	return nil
+}
+
+func parseEncryptionKey(encoded string) (encryptionKey, error) {
+	secret, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(secret) != 32 {
+		return encryptionKey{}, errors.New("an encryption key must be 32 bytes in base64, e.g. from openssl rand -base64 32")
+	}
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return encryptionKey{}, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return encryptionKey{}, err
+	}
+	hash := sha256.Sum256(secret)
+	return encryptionKey{id: hex.EncodeToString(hash[:4]), aead: aead}, nil
+}
+
+// sealTodo returns the todo as it is stored, with its description encrypted with the current key.
+// The id of the todo is authenticated with the description, it cannot be moved to another todo.
+func sealTodo(todo Todo) Todo {
+	if todo.Description != "" {
+		todo.Description = Seal(todo.Description, todo.Id)
+	}
+	return todo
+}
+
+// openTodo returns the stored todo with its description decrypted, descriptions stored in plain text are kept
+func openTodo(todo Todo) (Todo, error) {
+	description, err := Open(todo.Description, todo.Id)
+	if err != nil {
+		return todo, fmt.Errorf("todo %s: %w", todo.Id, err)
+	}
+	todo.Description = description
+	return todo, nil
+}
+
+// SealTodo returns the todo with its description encrypted like in the data files, for other files keeping todos
+func SealTodo(todo Todo) Todo {
+	return sealTodo(todo)
+}
+
+// OpenTodo returns a todo sealed by SealTodo with its description decrypted
+func OpenTodo(todo Todo) (Todo, error) {
+	return openTodo(todo)
+}
+
+// EncryptionEnabled tells whether a current key is set
+func EncryptionEnabled() bool {
+	return len(encryptionKeys) > 0
+}
+
+// Seal encrypts the text with the current key, the context is authenticated with it: the sealed text can only
+// be opened with the same context. Without key the text is returned as it is.
+func Seal(text string, context string) string {
+	if len(encryptionKeys) == 0 {
+		return text
+	}
+	key := encryptionKeys[0]
+	nonce := make([]byte, key.aead.NonceSize())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		// storing the text unencrypted is no option, and the system's randomness does not fail
+		panic(err)
+	}
+	sealed := key.aead.Seal(nonce, nonce, []byte(text), []byte(context))
+	return encryptedPrefix + key.id + ":" + base64.StdEncoding.EncodeToString(sealed)
+}
+
+// Open decrypts a text sealed by Seal with the context, texts stored in plain text are returned as they are
+func Open(text string, context string) (string, error) {
+	if strings.HasPrefix(text, encryptedPrefix) == false {
+		return text, nil
+	}
+	keyId, encoded, found := strings.Cut(strings.TrimPrefix(text, encryptedPrefix), ":")
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if found == false || err != nil {
+		return text, fmt.Errorf("%w: the text is not properly encrypted", ErrCorruptData)
+	}
+	for _, key := range encryptionKeys {
+		if key.id != keyId {
+			continue
+		}
+		if len(sealed) < key.aead.NonceSize() {
+			break
+		}
+		nonceSize := key.aead.NonceSize()
+		plain, err := key.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(context))
+		if err != nil {
+			break
+		}
+		return string(plain), nil
+	}
+	return text, fmt.Errorf("%w %s", ErrEncryptionKey, keyId)
+}
+
+// ReencryptStore writes all todos of a Storage or a Repository again, encrypting their descriptions with
+// the current key; after a key rotation the previous key is not needed any more. The store is read back and
+// verified like by MigrateStore. It returns the number of encrypted descriptions.
+func ReencryptStore(store interface{}) (int, error) {
+	if len(encryptionKeys) == 0 {
+		return 0, errors.New("re-encrypting needs a current encryption key")
+	}
+	dataset, err := ReadDataset(store)
+	if err != nil {
+		return 0, fmt.Errorf("cannot read the store: %w", err)
+	}
+	err = WriteDataset(store, dataset)
+	if err != nil {
+		return 0, fmt.Errorf("cannot write the store: %w", err)
+	}
+	written, err := ReadDataset(store)
+	if err != nil {
+		return 0, fmt.Errorf("cannot read the store back: %w", err)
+	}
+	if written.Checksum() != dataset.Checksum() {
+		return 0, errors.New("the checksum of the re-encrypted store differs")
+	}
+
+	encrypted := 0
+	for _, todo := range dataset.Todos {
+		if todo.Description != "" {
+			encrypted++
+		}
+	}
+	return encrypted, nil
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncryption_RotatesTheKey(t *testing.T) {
+	// Arrange
+	//
+	oldKey := "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+	newKey := "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA="
+	defer SetEncryptionKeys("", nil)
+	SetEncryptionKeys(oldKey, nil)
+	storage := CsvStorage{FileName: filepath.Join(t.TempDir(), "data.csv")}
+	storage.Save(map[string]Todo{"0": {Id: "0", Title: "Call the bank", Description: "IBAN DE02 1203 0000 0000 2020 51",
+		Tags: []string{}}})
+	encrypted, _ := os.ReadFile(storage.FileName)
+
+	// Act
+	//
+	SetEncryptionKeys(newKey, []string{oldKey})
+	reencrypted, errReencrypted := ReencryptStore(storage)
+	SetEncryptionKeys(newKey, nil)
+	rotated, errRotated := storage.Load()
+	SetEncryptionKeys(oldKey, nil)
+	_, errUnknown := storage.Load()
+	errInvalid := SetEncryptionKeys("c2hvcnQ=", nil)
+
+	// Assert
+	//
+	if strings.Contains(string(encrypted), "IBAN") || strings.Contains(string(encrypted), encryptedPrefix) == false {
+		t.Error("Fehler", string(encrypted))
+	}
+	if errReencrypted != nil || reencrypted != 1 {
+		t.Error("Fehler", errReencrypted, reencrypted)
+	}
+	if errRotated != nil || rotated["0"].Description != "IBAN DE02 1203 0000 0000 2020 51" {
+		t.Error("Fehler", errRotated, rotated)
+	}
+	if errors.Is(errUnknown, ErrEncryptionKey) == false || errInvalid == nil {
+		t.Error("Fehler", errUnknown, errInvalid)
+	}
+}
@@ -0,0 +1,56 @@
+package models
+
+// ErrorCode identifies the kind of an error response. Unlike the title it is stable, clients compare the code
+// instead of parsing the English title. The codes are published with the schema error.json.
+type ErrorCode string
+
+const (
+	// CodeInvalidRequest is a malformed query parameter, path or header
+	CodeInvalidRequest ErrorCode = "INVALID_REQUEST"
+	// CodeInvalidBody is a request body that is no JSON or does not fit the resource
+	CodeInvalidBody ErrorCode = "INVALID_BODY"
+	// CodeValidationFailed is a request body violating the rules of its fields, the details name the fields
+	CodeValidationFailed ErrorCode = "VALIDATION_FAILED"
+	// CodeWriteRejected is a todo refused by a write hook, e.g. by the denylist
+	CodeWriteRejected ErrorCode = "WRITE_REJECTED"
+	// CodeTooManyItems is a request with more todos or operations than allowed at once
+	CodeTooManyItems ErrorCode = "TOO_MANY_ITEMS"
+	// CodeUnauthorized is a request without valid credentials
+	CodeUnauthorized ErrorCode = "UNAUTHORIZED"
+	// CodeInvalidSignature is an inbound webhook with a missing, invalid or stale signature
+	CodeInvalidSignature ErrorCode = "INVALID_SIGNATURE"
+	// CodeForbidden is a request the client is not allowed to make, e.g. from an unknown origin
+	CodeForbidden ErrorCode = "FORBIDDEN"
+	// CodeTodoNotFound is a todo id without a todo
+	CodeTodoNotFound ErrorCode = "TODO_NOT_FOUND"
+	// CodeNotFound is a missing resource other than a todo, e.g. a goal or a checklist item
+	CodeNotFound ErrorCode = "NOT_FOUND"
+	// CodeRouteNotFound is a path the API does not have
+	CodeRouteNotFound ErrorCode = "ROUTE_NOT_FOUND"
+	// CodeMethodNotAllowed is a method the path does not support
+	CodeMethodNotAllowed ErrorCode = "METHOD_NOT_ALLOWED"
+	// CodeMoved is a path that moved to the Location header
+	CodeMoved ErrorCode = "MOVED"
+	// CodeVersionConflict is an If-Match header not matching the current version of the todo
+	CodeVersionConflict ErrorCode = "VERSION_CONFLICT"
+	// CodeVersionRequired is a change without the If-Match header the server requires
+	CodeVersionRequired ErrorCode = "VERSION_REQUIRED"
+	// CodeStateConflict is an action not possible in the current state, e.g. starting a second pomodoro
+	CodeStateConflict ErrorCode = "STATE_CONFLICT"
+	// CodeReplayedRequest is an inbound webhook delivered before
+	CodeReplayedRequest ErrorCode = "REPLAYED_REQUEST"
+	// CodeSnapshotExpired is a snapshot that cannot be restored any more
+	CodeSnapshotExpired ErrorCode = "SNAPSHOT_EXPIRED"
+	// CodeNotExecuted is an operation of a batch skipped or rolled back because another operation failed
+	CodeNotExecuted ErrorCode = "NOT_EXECUTED"
+	// CodeUnavailable is a server not ready yet, the request can be retried
+	CodeUnavailable ErrorCode = "UNAVAILABLE"
+	// CodeInternalError is a failure of the server
+	CodeInternalError ErrorCode = "INTERNAL_ERROR"
+)
+
+// ErrorCodes are all codes of error responses
+var ErrorCodes = []ErrorCode{CodeInvalidRequest, CodeInvalidBody, CodeValidationFailed, CodeWriteRejected,
+	CodeTooManyItems, CodeUnauthorized, CodeInvalidSignature, CodeForbidden, CodeTodoNotFound, CodeNotFound,
+	CodeRouteNotFound, CodeMethodNotAllowed, CodeMoved, CodeVersionConflict, CodeVersionRequired, CodeStateConflict,
+	CodeReplayedRequest, CodeSnapshotExpired, CodeNotExecuted, CodeUnavailable, CodeInternalError}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// TodoFilter selects todos by their fields, empty criteria match every todo
+type TodoFilter struct {
+	// Terminated selects the terminated or the open todos
+	Terminated *bool
+	// TitleContains and DescriptionContains are matched ignoring case
+	TitleContains       string
+	DescriptionContains string
+	// Tags selects the todos tagged with all of the tags
+	Tags []string
+	// Overdue selects the open todos past their due date or the other todos
+	Overdue *bool
+	// DueBefore selects the todos due before the time, todos without due date never match
+	DueBefore *time.Time
+	// UpdatedSince selects the todos changed at or after the time, todos without update time never match
+	UpdatedSince *time.Time
+}
+
+// Matches tells whether the todo meets all criteria of the filter
+func (f TodoFilter) Matches(todo Todo) bool {
+	if f.Terminated != nil && todo.Terminated != *f.Terminated {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if todo.HasTag(tag) == false {
+			return false
+		}
+	}
+	if f.Overdue != nil && todo.Overdue() != *f.Overdue {
+		return false
+	}
+	if f.DueBefore != nil {
+		dueAt, ok := todo.DueAt()
+		if ok == false || dueAt.Before(*f.DueBefore) == false {
+			return false
+		}
+	}
+	if f.UpdatedSince != nil && (todo.UpdatedAt == nil || todo.UpdatedAt.Before(*f.UpdatedSince)) {
+		return false
+	}
+	if containsFold(todo.Title, f.TitleContains) == false {
+		return false
+	}
+	return containsFold(todo.Description, f.DescriptionContains)
+}
+
+// FilterTodos returns the todos matching the filter in their order
+func FilterTodos(todos []Todo, filter TodoFilter) []Todo {
+	var matching []Todo
+	for _, todo := range todos {
+		if filter.Matches(todo) {
+			matching = append(matching, todo)
+		}
+	}
+	return matching
+}
+
+func containsFold(text string, part string) bool {
+	return strings.Contains(strings.ToLower(text), strings.ToLower(part))
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"testing"
+	"time"
+	"todo-rest-backend/clock"
+)
+
+func TestFilterTodos(t *testing.T) {
+	// Arrange
+	//
+	terminated := true
+	todos := []Todo{
+		{Id: "0", Title: "Buy milk", Description: "Oat milk", Terminated: true},
+		{Id: "1", Title: "Buy bread", Description: "Whole grain"},
+		{Id: "2", Title: "Call Anna", Description: "About the milk", Terminated: true},
+	}
+
+	// Act
+	//
+	byTitle := FilterTodos(todos, TodoFilter{TitleContains: "BUY"})
+	byAll := FilterTodos(todos, TodoFilter{Terminated: &terminated, DescriptionContains: "milk", TitleContains: "buy"})
+	unfiltered := FilterTodos(todos, TodoFilter{})
+
+	// Assert
+	//
+	if len(byTitle) != 2 || byTitle[0].Id != "0" || byTitle[1].Id != "1" {
+		t.Error("Fehler", byTitle)
+	}
+	if len(byAll) != 1 || byAll[0].Id != "0" {
+		t.Error("Fehler", byAll)
+	}
+	if len(unfiltered) != 3 {
+		t.Error("Fehler", unfiltered)
+	}
+}
+
+func TestFilterTodos_ByDueDate(t *testing.T) {
+	// Arrange
+	//
+	defer SetClock(clk)
+	SetClock(clock.NewFake(time.Date(2024, 5, 6, 12, 0, 0, 0, time.Local)))
+	overdue := true
+	dueBefore, _, _ := ParseDue("2024-05-07")
+	todos := []Todo{
+		{Id: "0", Title: "Yesterday", DueDate: "2024-05-05"},
+		{Id: "1", Title: "Today", DueDate: "2024-05-06"},
+		{Id: "2", Title: "This morning", DueDate: time.Date(2024, 5, 6, 9, 0, 0, 0, time.Local).Format(time.RFC3339)},
+		{Id: "3", Title: "Tomorrow", DueDate: "2024-05-07"},
+		{Id: "4", Title: "Done", DueDate: "2024-05-01", Terminated: true},
+		{Id: "5", Title: "Someday"},
+	}
+
+	// Act
+	//
+	byOverdue := FilterTodos(todos, TodoFilter{Overdue: &overdue})
+	byDueBefore := FilterTodos(todos, TodoFilter{DueBefore: &dueBefore})
+
+	// Assert
+	//
+	if len(byOverdue) != 2 || byOverdue[0].Id != "0" || byOverdue[1].Id != "2" {
+		t.Error("Fehler", byOverdue)
+	}
+	if len(byDueBefore) != 4 || byDueBefore[3].Id != "4" {
+		t.Error("Fehler", byDueBefore)
+	}
+}
+
+func TestFilterTodos_ByTags(t *testing.T) {
+	// Arrange
+	//
+	todos := []Todo{
+		{Id: "0", Title: "Write report", Tags: []string{"work", "urgent"}},
+		{Id: "1", Title: "Plan sprint", Tags: []string{"work"}},
+		{Id: "2", Title: "Buy milk"},
+	}
+
+	// Act
+	//
+	byTag := FilterTodos(todos, TodoFilter{Tags: []string{"work"}})
+	byTags := FilterTodos(todos, TodoFilter{Tags: []string{"work", "urgent"}})
+
+	// Assert
+	//
+	if len(byTag) != 2 {
+		t.Error("Fehler", byTag)
+	}
+	if len(byTags) != 1 || byTags[0].Id != "0" {
+		t.Error("Fehler", byTags)
+	}
+}
@@ -0,0 +1,172 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Goal is a higher-level objective, todos are linked to it by their goal id
+type Goal struct {
+	Id          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// The day the goal should be reached in the form 2006-01-02
+	TargetDate string `json:"target_date,omitempty"`
+}
+
+// GoalProgress is computed from the completion of the linked todos
+type GoalProgress struct {
+	Todos      int `json:"todos"`
+	Terminated int `json:"terminated"`
+	// Percent of the linked todos that are terminated, 0 without linked todos
+	Percent int `json:"percent"`
+}
+
+// GoalStorage is implemented by storages persisting the goals
+type GoalStorage interface {
+	LoadGoals() ([]Goal, error)
+	SaveGoals(goals []Goal) error
+}
+
+// ErrGoalNotFound is returned for todos linked to a goal that does not exist
+var ErrGoalNotFound = errors.New("goal not found")
+
+var goalStore = make(map[string]Goal)
+
+// Validate checks the title and the target date of the goal
+func (g Goal) Validate() error {
+	if strings.TrimSpace(g.Title) == "" {
+		return errors.New("a goal needs a title")
+	}
+	if g.TargetDate != "" {
+		if _, err := time.Parse(DateFormat, g.TargetDate); err != nil {
+			return errors.New("target_date must have the form 2006-01-02")
+		}
+	}
+	return nil
+}
+
+// Goals returns the goals in the order of their ids
+func Goals() []Goal {
+	goals := []Goal{}
+	for _, goal := range goalStore {
+		goals = append(goals, goal)
+	}
+	sort.Slice(goals, func(i, j int) bool {
+		left, _ := strconv.Atoi(goals[i].Id)
+		right, _ := strconv.Atoi(goals[j].Id)
+		return left < right
+	})
+	return goals
+}
+
+// FindGoal returns the goal with the id
+func FindGoal(id string) (Goal, bool) {
+	goal, ok := goalStore[id]
+	return goal, ok
+}
+
+// AddGoal adds a goal with the id following the highest id
+func AddGoal(goal Goal) Goal {
+	highest := 0
+	for id := range goalStore {
+		if value, _ := strconv.Atoi(id); value > highest {
+			highest = value
+		}
+	}
+	goal.Id = strconv.Itoa(highest + 1)
+	goalStore[goal.Id] = goal
+	changed()
+	return goal
+}
+
+// UpdateGoal replaces the goal with the id
+func UpdateGoal(id string, goal Goal) (Goal, bool) {
+	previous, ok := goalStore[id]
+	if ok == false {
+		return Goal{}, false
+	}
+	goal.Id = id
+	if goal != previous {
+		goalStore[id] = goal
+		changed()
+	}
+	return goal, true
+}
+
+// RemoveGoal removes the goal and unlinks its todos, so that a goal added later with the same id
+// does not inherit them
+func RemoveGoal(id string) bool {
+	if _, ok := goalStore[id]; ok == false {
+		return false
+	}
+	delete(goalStore, id)
+	changed()
+	// the goals are shared by the users, the todos of all users are unlinked
+	for _, todo := range repository.List() {
+		if todo.GoalId == id {
+			todo.GoalId = ""
+			touchTodo(todo)
+		}
+	}
+	return true
+}
+
+// GoalTodos returns the todos linked to the goal
+func GoalTodos(id string) []Todo {
+	todos := []Todo{}
+	for _, todo := range listTodos() {
+		if todo.GoalId == id {
+			todos = append(todos, todo)
+		}
+	}
+	return todos
+}
+
+// ProgressOf computes the progress of the goal from its linked todos
+func ProgressOf(id string) GoalProgress {
+	progress := GoalProgress{}
+	for _, todo := range GoalTodos(id) {
+		progress.Todos++
+		if todo.Terminated {
+			progress.Terminated++
+		}
+	}
+	if progress.Todos > 0 {
+		progress.Percent = progress.Terminated * 100 / progress.Todos
+	}
+	return progress
+}
+
+// goalsFileName stores the goals of a CSV storage next to its data file
+func (s CsvStorage) goalsFileName() string {
+	return s.FileName + ".goals"
+}
+
+// LoadGoals reads the goals, a missing file has no goals
+func (s CsvStorage) LoadGoals() ([]Goal, error) {
+	var goals []Goal
+	content, err := readDataFile(s.goalsFileName())
+	if errors.Is(err, os.ErrNotExist) {
+		return goals, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(content, &goals)
+	return goals, err
+}
+
+// SaveGoals writes the goals as JSON array
+func (s CsvStorage) SaveGoals(goals []Goal) error {
+	content, err := json.Marshal(goals)
+	if err != nil {
+		return err
+	}
+	return replaceDataFile(s.goalsFileName(), content)
+}
@@ -0,0 +1,49 @@
+package models
+
+import "testing"
+
+func TestGoal_Progress(t *testing.T) {
+	// Arrange
+	//
+	DeleteAllTodos()
+	defer DeleteAllTodos()
+	goalStore = make(map[string]Goal)
+	defer func() { goalStore = make(map[string]Goal) }()
+	goal := AddGoal(Goal{Title: "Run a marathon"})
+	AddTodo(Todo{Title: "Buy shoes", GoalId: goal.Id, Terminated: true})
+	AddTodo(Todo{Title: "Run 10k", GoalId: goal.Id})
+	AddTodo(Todo{Title: "Run 20k", GoalId: goal.Id})
+	AddTodo(Todo{Title: "Unrelated"})
+
+	// Act
+	//
+	progress := ProgressOf(goal.Id)
+
+	// Assert
+	//
+	if progress.Todos != 3 || progress.Terminated != 1 || progress.Percent != 33 {
+		t.Error("Fehler", progress)
+	}
+}
+
+func TestGoal_RemoveUnlinksTodos(t *testing.T) {
+	// Arrange
+	//
+	DeleteAllTodos()
+	defer DeleteAllTodos()
+	goalStore = make(map[string]Goal)
+	defer func() { goalStore = make(map[string]Goal) }()
+	goal := AddGoal(Goal{Title: "Learn Go"})
+	AddTodo(Todo{Title: "Read the tour", GoalId: goal.Id})
+
+	// Act
+	//
+	RemoveGoal(goal.Id)
+	added := AddGoal(Goal{Title: "Learn Rust"})
+
+	// Assert
+	//
+	if added.Id != goal.Id || len(GoalTodos(added.Id)) != 0 {
+		t.Error("Fehler", added, GoalTodos(added.Id))
+	}
+}
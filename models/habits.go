@@ -0,0 +1,161 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// States of the days of a habit calendar
+const (
+	HabitDone     = "done"
+	HabitMissed   = "missed"
+	HabitOpen     = "open"
+	HabitUpcoming = "upcoming"
+	HabitInactive = "inactive"
+)
+
+// Errors of recording the days of habits
+var (
+	ErrNotAHabit       = errors.New("the todo is not a habit")
+	ErrInvalidHabitDay = errors.New("the day must be between the start of the habit and today")
+)
+
+// HabitDay is a day of a habit calendar
+type HabitDay struct {
+	Date string `json:"date"`
+	// State is done, missed, open for today if not done yet, upcoming or inactive before the habit started
+	State string `json:"state"`
+}
+
+// HabitCalendar is the month grid of a habit, each week starts on Monday.
+// Days of the weeks belonging to the previous or next month are nil.
+type HabitCalendar struct {
+	HabitId       string        `json:"habit_id"`
+	Month         string        `json:"month"`
+	CurrentStreak int           `json:"current_streak"`
+	LongestStreak int           `json:"longest_streak"`
+	Weeks         [][]*HabitDay `json:"weeks"`
+}
+
+// keepHabit keeps the start and the days of a todo staying a habit. A todo becoming a habit starts today,
+// a todo that is no longer a habit loses its days.
+func keepHabit(todo Todo, previous *Todo) Todo {
+	todo.HabitSince = ""
+	todo.HabitDays = nil
+	if todo.Habit == false {
+		return todo
+	}
+	if previous != nil && previous.Habit {
+		todo.HabitSince = previous.HabitSince
+		todo.HabitDays = previous.HabitDays
+		return todo
+	}
+	todo.HabitSince = Today()
+	return todo
+}
+
+// RecordHabitDay records the habit as done or not done on the day in the form 2006-01-02
+func RecordHabitDay(id string, date string, done bool) (Todo, error) {
+	todo, ok := getTodo(id)
+	if ok == false {
+		return Todo{}, ErrTodoNotFound
+	}
+	if todo.Habit == false {
+		return Todo{}, ErrNotAHabit
+	}
+	if _, err := time.Parse(DateFormat, date); err != nil || date < todo.HabitSince || date > Today() {
+		return Todo{}, ErrInvalidHabitDay
+	}
+
+	days := []string{}
+	for _, day := range todo.HabitDays {
+		if day != date {
+			days = append(days, day)
+		}
+	}
+	if done {
+		days = append(days, date)
+		sort.Strings(days)
+	}
+	todo.HabitDays = days
+	todo = touchTodo(todo)
+	return todo, nil
+}
+
+// Streaks returns the number of consecutive days the habit was done until today, or until yesterday
+// while today is still open, and the longest run of consecutive days
+func (t Todo) Streaks() (current int, longest int) {
+	done := make(map[string]bool)
+	for _, day := range t.HabitDays {
+		done[day] = true
+	}
+
+	day := Now().Local()
+	if done[day.Format(DateFormat)] == false {
+		day = day.AddDate(0, 0, -1)
+	}
+	for done[day.Format(DateFormat)] {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	run := 0
+	var previous time.Time
+	for i, date := range t.HabitDays {
+		parsed, _ := time.Parse(DateFormat, date)
+		if i > 0 && parsed.Equal(previous.AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+		previous = parsed
+	}
+	return current, longest
+}
+
+// CalendarOf returns the calendar of the habit for the month in the form 2006-01
+func (t Todo) CalendarOf(month string) (HabitCalendar, error) {
+	first, err := time.Parse("2006-01", month)
+	if err != nil {
+		return HabitCalendar{}, err
+	}
+
+	calendar := HabitCalendar{HabitId: t.Id, Month: month, Weeks: [][]*HabitDay{}}
+	calendar.CurrentStreak, calendar.LongestStreak = t.Streaks()
+
+	done := make(map[string]bool)
+	for _, day := range t.HabitDays {
+		done[day] = true
+	}
+	today := Today()
+
+	// the first week is padded up to the Monday before the first day of the month
+	week := make([]*HabitDay, (int(first.Weekday())+6)%7)
+	for day := first; day.Month() == first.Month(); day = day.AddDate(0, 0, 1) {
+		date := day.Format(DateFormat)
+		state := HabitMissed
+		switch {
+		case done[date]:
+			state = HabitDone
+		case date < t.HabitSince:
+			state = HabitInactive
+		case date == today:
+			state = HabitOpen
+		case date > today:
+			state = HabitUpcoming
+		}
+		week = append(week, &HabitDay{Date: date, State: state})
+		if len(week) == 7 {
+			calendar.Weeks = append(calendar.Weeks, week)
+			week = []*HabitDay{}
+		}
+	}
+	if len(week) > 0 {
+		calendar.Weeks = append(calendar.Weeks, append(week, make([]*HabitDay, 7-len(week))...))
+	}
+	return calendar, nil
+}
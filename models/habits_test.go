@@ -0,0 +1,62 @@
+package models
+
+import (
+	"testing"
+	"time"
+	"todo-rest-backend/clock"
+)
+
+func TestTodo_Streaks(t *testing.T) {
+	// Arrange
+	//
+	defer SetClock(clk)
+	SetClock(clock.NewFake(time.Date(2024, 5, 10, 12, 0, 0, 0, time.Local)))
+	habit := Todo{Habit: true, HabitSince: "2024-05-01",
+		HabitDays: []string{"2024-05-01", "2024-05-02", "2024-05-03", "2024-05-05", "2024-05-08", "2024-05-09"}}
+
+	// Act
+	//
+	current, longest := habit.Streaks()
+
+	// Assert
+	//
+	if current != 2 || longest != 3 {
+		t.Error("Fehler", current, longest)
+	}
+}
+
+func TestTodo_CalendarOf(t *testing.T) {
+	// Arrange
+	//
+	DeleteAllTodos()
+	defer DeleteAllTodos()
+	defer SetClock(clk)
+	SetClock(clock.NewFake(time.Date(2024, 5, 2, 12, 0, 0, 0, time.Local)))
+	habit := AddTodo(Todo{Title: "Stretch", Habit: true})
+	SetClock(clock.NewFake(time.Date(2024, 5, 10, 12, 0, 0, 0, time.Local)))
+	habit, err := RecordHabitDay(habit.Id, "2024-05-03", true)
+	_, errBeforeStart := RecordHabitDay(habit.Id, "2024-05-01", true)
+
+	// Act
+	//
+	calendar, _ := habit.CalendarOf("2024-05")
+
+	// Assert
+	//
+	if err != nil || errBeforeStart != ErrInvalidHabitDay {
+		t.Fatal("Fehler", err, errBeforeStart)
+	}
+	// May 2024 starts on a Wednesday and ends on a Friday
+	if len(calendar.Weeks) != 5 || calendar.Weeks[0][0] != nil || calendar.Weeks[4][5] != nil {
+		t.Fatal("Fehler", calendar.Weeks)
+	}
+	states := map[string]string{"2024-05-01": HabitInactive, "2024-05-02": HabitMissed, "2024-05-03": HabitDone,
+		"2024-05-10": HabitOpen, "2024-05-11": HabitUpcoming}
+	for _, week := range calendar.Weeks {
+		for _, day := range week {
+			if day != nil && states[day.Date] != "" && states[day.Date] != day.State {
+				t.Error("Fehler", day)
+			}
+		}
+	}
+}
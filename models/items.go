@@ -0,0 +1,118 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ChecklistItem is a subtask of a todo
+type ChecklistItem struct {
+	// Id is unique within the todo, ids of removed items are not reused while a later item exists
+	Id    string `json:"id"`
+	Title string `json:"title"`
+	Done  bool   `json:"done"`
+}
+
+// Errors of changing checklist items
+var (
+	ErrItemNotFound     = errors.New("the checklist item does not exist")
+	ErrItemTitleMissing = errors.New("a checklist item needs a title")
+)
+
+// AddItem appends the item to the checklist of the todo
+func AddItem(todoId string, item ChecklistItem) (Todo, error) {
+	todo, ok := getTodo(todoId)
+	if ok == false {
+		return Todo{}, ErrTodoNotFound
+	}
+	if strings.TrimSpace(item.Title) == "" {
+		return Todo{}, ErrItemTitleMissing
+	}
+
+	next := 1
+	for _, existing := range todo.Items {
+		if id, _ := strconv.Atoi(existing.Id); id >= next {
+			next = id + 1
+		}
+	}
+	item.Id = strconv.Itoa(next)
+	items := append(append([]ChecklistItem{}, todo.Items...), item)
+	return changeItems(todo, items), nil
+}
+
+// UpdateItem replaces the title and the done flag of the item
+func UpdateItem(todoId string, item ChecklistItem) (Todo, error) {
+	todo, ok := getTodo(todoId)
+	if ok == false {
+		return Todo{}, ErrTodoNotFound
+	}
+	if strings.TrimSpace(item.Title) == "" {
+		return Todo{}, ErrItemTitleMissing
+	}
+
+	items := append([]ChecklistItem{}, todo.Items...)
+	for i := range items {
+		if items[i].Id == item.Id {
+			items[i] = item
+			return changeItems(todo, items), nil
+		}
+	}
+	return Todo{}, ErrItemNotFound
+}
+
+// RemoveItem removes the item from the checklist of the todo
+func RemoveItem(todoId string, itemId string) (Todo, error) {
+	todo, ok := getTodo(todoId)
+	if ok == false {
+		return Todo{}, ErrTodoNotFound
+	}
+
+	var items []ChecklistItem
+	for _, item := range todo.Items {
+		if item.Id != itemId {
+			items = append(items, item)
+		}
+	}
+	if len(items) == len(todo.Items) {
+		return Todo{}, ErrItemNotFound
+	}
+	return changeItems(todo, items), nil
+}
+
+// changeItems stores the todo with the items. A todo with items is terminated once all of its items are done
+// and reopened when one of them is not; the terminated flag set with a todo update holds until the next change
+// of the items.
+func changeItems(previous Todo, items []ChecklistItem) Todo {
+	todo := previous
+	todo.Items = items
+	if len(items) > 0 {
+		todo.Terminated = true
+		for _, item := range items {
+			todo.Terminated = todo.Terminated && item.Done
+		}
+	}
+	todo = reconcileStatus(todo, &previous)
+	todo.CompletedAt = completionTime(todo.Terminated, previous.CompletedAt)
+	return touchTodo(todo)
+}
+
+// serializeItems encodes the checklist as JSON array for the storages, empty without items
+func serializeItems(items []ChecklistItem) string {
+	if len(items) == 0 {
+		return ""
+	}
+	encoded, _ := json.Marshal(items)
+	return string(encoded)
+}
+
+// parseItems decodes the checklist of serializeItems
+func parseItems(encoded string) []ChecklistItem {
+	var items []ChecklistItem
+	json.Unmarshal([]byte(encoded), &items)
+	if len(items) == 0 {
+		return nil
+	}
+	return items
+}
@@ -0,0 +1,56 @@
+package models
+
+import "testing"
+
+func TestItems_DeriveTerminated(t *testing.T) {
+	// Arrange
+	//
+	DeleteAllTodos()
+	defer DeleteAllTodos()
+	todo := AddTodo(Todo{Title: "Pack for the trip"})
+	AddItem(todo.Id, ChecklistItem{Title: "Passport"})
+	AddItem(todo.Id, ChecklistItem{Title: "Charger"})
+
+	// Act
+	//
+	halfDone, _ := UpdateItem(todo.Id, ChecklistItem{Id: "1", Title: "Passport", Done: true})
+	allDone, _ := UpdateItem(todo.Id, ChecklistItem{Id: "2", Title: "Charger", Done: true})
+	reopened, _ := AddItem(todo.Id, ChecklistItem{Title: "Tickets"})
+	removed, _ := RemoveItem(todo.Id, "3")
+	_, err := RemoveItem(todo.Id, "3")
+
+	// Assert
+	//
+	if halfDone.Terminated || allDone.Terminated == false || allDone.Status != DoneColumn() || allDone.CompletedAt == nil {
+		t.Error("Fehler", halfDone, allDone)
+	}
+	if reopened.Terminated || len(reopened.Items) != 3 || reopened.Items[2].Id != "3" {
+		t.Error("Fehler", reopened)
+	}
+	if removed.Terminated == false || len(removed.Items) != 2 {
+		t.Error("Fehler", removed)
+	}
+	if err != ErrItemNotFound {
+		t.Error("Fehler", err)
+	}
+}
+
+func TestItems_KeptByUpdateAndStorage(t *testing.T) {
+	// Arrange
+	//
+	DeleteAllTodos()
+	defer DeleteAllTodos()
+	todo := AddTodo(Todo{Title: "Pack for the trip"})
+	todo, _ = AddItem(todo.Id, ChecklistItem{Title: "Passport, \"ID\""})
+
+	// Act
+	//
+	updated, _ := UpdateTodo(todo.Id, Todo{Title: "Pack for the holidays"})
+	parsed := parseTodoData(updated.Serialize())
+
+	// Assert
+	//
+	if len(updated.Items) != 1 || len(parsed.Items) != 1 || parsed.Items[0] != todo.Items[0] {
+		t.Error("Fehler", updated.Items, parsed.Items)
+	}
+}
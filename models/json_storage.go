@@ -0,0 +1,348 @@
+package models
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JsonFileName is the data file of the JSON storage
+const JsonFileName = "data.json"
+
+// CompressedJsonFileName is the data file of the gzip-compressed JSON storage
+const CompressedJsonFileName = JsonFileName + ".gz"
+
+// jsonFormat names the content of the JSON data files, JsonFormatVersion is the version written
+const (
+	jsonFormat        = "todo-backend"
+	JsonFormatVersion = 1
+)
+
+// ErrUnsupportedVersion is returned for data files written by a newer version of the backend
+var ErrUnsupportedVersion = errors.New("the data file was written by a newer version")
+
+// DatasetStorage is implemented by storages keeping the whole dataset in one file. It is saved at once
+// instead of through the storages of the side data.
+type DatasetStorage interface {
+	Storage
+	// SaveDataset replaces the stored dataset, nil Settings keep the saved settings
+	SaveDataset(dataset Dataset) error
+}
+
+// JsonStorage stores the todos with the sequences, the pomodoro sessions, the goals, the revision and the
+// settings in a single JSON file. The file starts with its format and version; it is replaced atomically by
+// writing a temporary file renamed over it, a crash while saving leaves the previous file.
+type JsonStorage struct {
+	FileName string
+	// LegacyFileName is a CSV data file read with its side files as long as the JSON file does not exist,
+	// the data moves to the JSON file with the first save
+	LegacyFileName string
+	// Compress writes the file gzip-compressed, compressed and plain files are both read
+	Compress bool
+
+	mutex sync.Mutex
+	// saved is the dataset of the file, nil until it is read
+	saved *Dataset
+}
+
+// jsonDocument is the content of the data file
+type jsonDocument struct {
+	Format           string            `json:"format"`
+	Version          int               `json:"version"`
+	Revision         uint64            `json:"revision"`
+	Sequences        map[string]int    `json:"sequences"`
+	PomodoroSessions []PomodoroSession `json:"pomodoro_sessions"`
+	Goals            []Goal            `json:"goals"`
+	Settings         *Settings         `json:"settings,omitempty"`
+	Todos            []jsonTodo        `json:"todos"`
+}
+
+// plainTodo has the fields of a Todo without its methods, it is encoded without the computed fields of the API
+type plainTodo Todo
+
+// jsonTodo is a stored todo, unlike the API it keeps the fields maintained by the backend only
+type jsonTodo struct {
+	plainTodo
+	HabitDays []string   `json:"habit_days,omitempty"`
+	NudgedAt  *time.Time `json:"nudged_at,omitempty"`
+}
+
+// Load reads the todos from the JSON file.
+// A corrupted file is not loaded, the backup of the previous save is read instead.
+func (s *JsonStorage) Load() (map[string]Todo, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.saved = nil
+	dataset, err := s.dataset()
+	return dataset.Todos, err
+}
+
+// Save replaces the stored todos and keeps the side data
+func (s *JsonStorage) Save(todos map[string]Todo) error {
+	return s.change(func(dataset *Dataset) {
+		dataset.Todos = todos
+	})
+}
+
+// SaveDataset replaces the stored dataset, nil Settings keep the saved settings
+func (s *JsonStorage) SaveDataset(dataset Dataset) error {
+	return s.change(func(saved *Dataset) {
+		settings := saved.Settings
+		*saved = dataset
+		if saved.Settings == nil {
+			saved.Settings = settings
+		}
+	})
+}
+
+// LoadSequences reads the sequences of the todo numbers
+func (s *JsonStorage) LoadSequences() (map[string]int, error) {
+	dataset, err := s.loadSide()
+	return dataset.Sequences, err
+}
+
+// SaveSequences replaces the sequences of the todo numbers
+func (s *JsonStorage) SaveSequences(sequences map[string]int) error {
+	return s.change(func(dataset *Dataset) {
+		dataset.Sequences = sequences
+	})
+}
+
+// LoadPomodoroSessions reads the pomodoro sessions
+func (s *JsonStorage) LoadPomodoroSessions() ([]PomodoroSession, error) {
+	dataset, err := s.loadSide()
+	return dataset.PomodoroSessions, err
+}
+
+// SavePomodoroSessions replaces the pomodoro sessions
+func (s *JsonStorage) SavePomodoroSessions(sessions []PomodoroSession) error {
+	return s.change(func(dataset *Dataset) {
+		dataset.PomodoroSessions = sessions
+	})
+}
+
+// LoadGoals reads the goals
+func (s *JsonStorage) LoadGoals() ([]Goal, error) {
+	dataset, err := s.loadSide()
+	return dataset.Goals, err
+}
+
+// SaveGoals replaces the goals
+func (s *JsonStorage) SaveGoals(goals []Goal) error {
+	return s.change(func(dataset *Dataset) {
+		dataset.Goals = goals
+	})
+}
+
+// LoadRevision reads the revision
+func (s *JsonStorage) LoadRevision() (uint64, error) {
+	dataset, err := s.loadSide()
+	return dataset.Revision, err
+}
+
+// SaveRevision replaces the revision
+func (s *JsonStorage) SaveRevision(revision uint64) error {
+	return s.change(func(dataset *Dataset) {
+		dataset.Revision = revision
+	})
+}
+
+// LoadSettings reads the settings, nil if they were never saved
+func (s *JsonStorage) LoadSettings() (*Settings, error) {
+	dataset, err := s.loadSide()
+	return dataset.Settings, err
+}
+
+// SaveSettings replaces the settings
+func (s *JsonStorage) SaveSettings(settings Settings) error {
+	return s.change(func(dataset *Dataset) {
+		dataset.Settings = &settings
+	})
+}
+
+// loadSide returns the saved dataset for the side data, a missing file has none
+func (s *JsonStorage) loadSide() (Dataset, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	dataset, err := s.dataset()
+	if errors.Is(err, os.ErrNotExist) {
+		return dataset, nil
+	}
+	return dataset, err
+}
+
+// change applies the change to the saved dataset and writes the file
+func (s *JsonStorage) change(apply func(dataset *Dataset)) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	dataset, err := s.dataset()
+	if err != nil && errors.Is(err, os.ErrNotExist) == false {
+		return err
+	}
+	apply(&dataset)
+	err = s.write(dataset)
+	if err != nil {
+		// the file is read again with the next change
+		s.saved = nil
+		return err
+	}
+	s.saved = &dataset
+	return nil
+}
+
+// dataset returns the saved dataset, reading it if necessary; the mutex must be held.
+// The file is looked up in this order: the JSON file, its backup, which is the only file if a save was
+// interrupted between the renames, the plain JSON file of a compressed storage and the legacy CSV file.
+func (s *JsonStorage) dataset() (Dataset, error) {
+	if s.saved != nil {
+		return *s.saved, nil
+	}
+
+	dataset, err := readJsonFile(s.FileName)
+	if errors.Is(err, os.ErrNotExist) {
+		dataset, err = readJsonFile(backupFileName(s.FileName))
+		if errors.Is(err, os.ErrNotExist) && s.Compress && strings.HasSuffix(s.FileName, ".gz") {
+			dataset, err = readJsonFile(strings.TrimSuffix(s.FileName, ".gz"))
+		}
+		if errors.Is(err, os.ErrNotExist) && s.LegacyFileName != "" {
+			return s.readLegacy()
+		}
+	} else if errors.Is(err, ErrCorruptData) {
+		backup := backupFileName(s.FileName)
+		log.Printf("Cannot load %s: %v, loading the backup %s instead", s.FileName, err, backup)
+		var backupErr error
+		dataset, backupErr = readJsonFile(backup)
+		if backupErr != nil {
+			return emptyDataset(), fmt.Errorf("%w, the backup cannot be loaded either: %v", err, backupErr)
+		}
+		err = nil
+	}
+	if err != nil {
+		return emptyDataset(), err
+	}
+	s.saved = &dataset
+	return dataset, nil
+}
+
+// readLegacy reads the dataset of the legacy CSV file, the mutex must be held
+func (s *JsonStorage) readLegacy() (Dataset, error) {
+	legacy := CsvStorage{FileName: s.LegacyFileName}
+	if _, err := os.Stat(legacy.FileName); err != nil {
+		return emptyDataset(), err
+	}
+	dataset, err := ReadDataset(legacy)
+	if err != nil {
+		return emptyDataset(), err
+	}
+	log.Printf("Reading the data from %s, it is saved to %s from now on", s.LegacyFileName, s.FileName)
+	s.saved = &dataset
+	return dataset, nil
+}
+
+// write replaces the file atomically, the previous file is kept as backup
+func (s *JsonStorage) write(dataset Dataset) error {
+	document := jsonDocument{Format: jsonFormat, Version: JsonFormatVersion, Revision: dataset.Revision,
+		Sequences: dataset.Sequences, PomodoroSessions: dataset.PomodoroSessions, Goals: dataset.Goals,
+		Settings: dataset.Settings, Todos: []jsonTodo{}}
+	for _, todo := range dataset.Todos {
+		todo = sealTodo(todo)
+		document.Todos = append(document.Todos, jsonTodo{plainTodo: plainTodo(todo), HabitDays: todo.HabitDays,
+			NudgedAt: todo.NudgedAt})
+	}
+	sort.Slice(document.Todos, func(i, j int) bool {
+		left, _ := strconv.Atoi(document.Todos[i].Id)
+		right, _ := strconv.Atoi(document.Todos[j].Id)
+		return left < right
+	})
+	if document.Sequences == nil {
+		document.Sequences = map[string]int{}
+	}
+	if document.PomodoroSessions == nil {
+		document.PomodoroSessions = []PomodoroSession{}
+	}
+	if document.Goals == nil {
+		document.Goals = []Goal{}
+	}
+
+	content, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return err
+	}
+	content = append(content, '\n')
+	if s.Compress {
+		var compressed bytes.Buffer
+		compressor := gzip.NewWriter(&compressed)
+		_, err = compressor.Write(content)
+		if err == nil {
+			err = compressor.Close()
+		}
+		if err != nil {
+			return err
+		}
+		content = compressed.Bytes()
+	}
+	return replaceDataFile(s.FileName, content)
+}
+
+// readJsonFile reads the dataset of a JSON data file, which may be gzip-compressed
+func readJsonFile(fileName string) (Dataset, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return emptyDataset(), err
+	}
+	defer file.Close()
+	reader, err := decompressed(file)
+	if err != nil {
+		return emptyDataset(), fmt.Errorf("%s: %w: %v", fileName, ErrCorruptData, err)
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return emptyDataset(), fmt.Errorf("%s: %w: %v", fileName, ErrCorruptData, err)
+	}
+	var document jsonDocument
+	err = json.Unmarshal(content, &document)
+	if err != nil {
+		return emptyDataset(), fmt.Errorf("%s: %w: %v", fileName, ErrCorruptData, err)
+	}
+	if document.Format != jsonFormat {
+		return emptyDataset(), fmt.Errorf("%s: %w: the format is %q instead of %q", fileName, ErrCorruptData,
+			document.Format, jsonFormat)
+	}
+	if document.Version > JsonFormatVersion {
+		return emptyDataset(), fmt.Errorf("%s: %w: version %d, this version reads up to %d", fileName,
+			ErrUnsupportedVersion, document.Version, JsonFormatVersion)
+	}
+
+	dataset := Dataset{Todos: make(map[string]Todo), Sequences: document.Sequences,
+		PomodoroSessions: document.PomodoroSessions, Goals: document.Goals, Revision: document.Revision,
+		Settings: document.Settings}
+	if dataset.Sequences == nil {
+		dataset.Sequences = make(map[string]int)
+	}
+	for _, stored := range document.Todos {
+		todo, err := openTodo(Todo(stored.plainTodo))
+		if err != nil {
+			return emptyDataset(), fmt.Errorf("%s: %w", fileName, err)
+		}
+		todo.HabitDays, todo.NudgedAt = stored.HabitDays, stored.NudgedAt
+		dataset.Todos[todo.Id] = todo
+	}
+	return dataset, nil
+}
+
+func emptyDataset() Dataset {
+	return Dataset{Todos: make(map[string]Todo), Sequences: make(map[string]int)}
+}
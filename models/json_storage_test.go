@@ -0,0 +1,105 @@
+package models
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJsonStorage_MigratesTheCsvFile(t *testing.T) {
+	// Arrange
+	//
+	dir := t.TempDir()
+	legacy := CsvStorage{FileName: filepath.Join(dir, "data.csv")}
+	nudgedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	legacy.Save(map[string]Todo{"7": {Id: "7", Title: "Water, \"the\" plants", List: "home", Number: 3, Tags: []string{},
+		HabitSince: "2024-04-01", Habit: true, HabitDays: []string{"2024-04-30"}, NudgedAt: &nudgedAt}})
+	legacy.SaveSequences(map[string]int{"home": 3})
+	legacy.SaveRevision(12)
+	storage := &JsonStorage{FileName: filepath.Join(dir, "data.json"), LegacyFileName: legacy.FileName}
+
+	// Act
+	//
+	migrated, errMigrated := ReadDataset(storage)
+	errSaved := storage.Save(migrated.Todos)
+	content, _ := os.ReadFile(storage.FileName)
+	reloaded, errReloaded := ReadDataset(&JsonStorage{FileName: storage.FileName})
+
+	// Assert
+	//
+	if errMigrated != nil || len(migrated.Todos) != 1 || migrated.Sequences["home"] != 3 || migrated.Revision != 12 {
+		t.Error("Fehler", errMigrated, migrated)
+	}
+	if errSaved != nil || strings.HasPrefix(string(content), "{\n  \"format\": \"todo-backend\",\n  \"version\": 1,") == false {
+		t.Error("Fehler", errSaved, string(content))
+	}
+	if errReloaded != nil || reloaded.Checksum() != migrated.Checksum() {
+		t.Error("Fehler", errReloaded, reloaded)
+	}
+}
+
+func TestJsonStorage_KeepsThePreviousFile(t *testing.T) {
+	// Arrange
+	//
+	storage := &JsonStorage{FileName: filepath.Join(t.TempDir(), "data.json")}
+	storage.Save(map[string]Todo{"0": {Id: "0", Title: "Backup", Tags: []string{}}})
+	storage.Save(map[string]Todo{"0": {Id: "0", Title: "Current", Tags: []string{}}})
+	current, _ := os.ReadFile(storage.FileName)
+
+	// Act
+	//
+	// a crash between the renames leaves only the backup
+	os.Remove(storage.FileName)
+	interrupted, errInterrupted := storage.Load()
+	os.WriteFile(storage.FileName, current[:len(current)/2], 0600)
+	truncated, errTruncated := storage.Load()
+	os.WriteFile(storage.FileName, []byte(`{"format": "todo-backend", "version": 2, "todos": []}`), 0600)
+	_, errNewer := storage.Load()
+	_, errTemporary := os.Stat(filepath.Join(filepath.Dir(storage.FileName), ".data.json.tmp"))
+
+	// Assert
+	//
+	if errInterrupted != nil || interrupted["0"].Title != "Backup" {
+		t.Error("Fehler", errInterrupted, interrupted)
+	}
+	if errTruncated != nil || truncated["0"].Title != "Backup" {
+		t.Error("Fehler", errTruncated, truncated)
+	}
+	if errors.Is(errNewer, ErrUnsupportedVersion) == false {
+		t.Error("Fehler", errNewer)
+	}
+	if os.IsNotExist(errTemporary) == false {
+		t.Error("Fehler", errTemporary)
+	}
+}
+
+func TestJsonStorage_CompressedRoundTrip(t *testing.T) {
+	// Arrange
+	//
+	dir := t.TempDir()
+	plain := &JsonStorage{FileName: filepath.Join(dir, "data.json")}
+	plain.Save(map[string]Todo{"0": {Id: "0", Title: "Plain", Tags: []string{}}})
+	storage := &JsonStorage{FileName: filepath.Join(dir, "data.json.gz"), Compress: true}
+
+	// Act
+	//
+	loaded, loadErr := storage.Load()
+	saveErr := storage.Save(map[string]Todo{"0": {Id: "0", Title: "Compressed", Tags: []string{}}})
+	reloaded, reloadErr := (&JsonStorage{FileName: storage.FileName}).Load()
+	content, _ := os.ReadFile(storage.FileName)
+
+	// Assert
+	//
+	if loadErr != nil || loaded["0"].Title != "Plain" {
+		t.Error("Fehler", loadErr, loaded)
+	}
+	if saveErr != nil || reloadErr != nil || reloaded["0"].Title != "Compressed" {
+		t.Error("Fehler", saveErr, reloadErr, reloaded)
+	}
+	if len(content) < 2 || content[0] != 0x1f || content[1] != 0x8b {
+		t.Error("file is not gzip-compressed")
+	}
+}
@@ -0,0 +1,170 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// DefaultList is the list of todos created without list
+const DefaultList = "inbox"
+
+// listSequences holds the last number assigned in each list and the next todo id. Numbers of deleted todos
+// are not reused, so a number always refers to the same todo.
+var listSequences = make(map[string]int)
+
+// todoIdSequence is the key of the next todo id in the sequences, the empty name never names a list.
+// Ids of removed todos are not reused either, so an id always refers to the same todo.
+const todoIdSequence = ""
+
+// nextTodoId assigns the id of a new todo
+func nextTodoId() string {
+	id := listSequences[todoIdSequence]
+	listSequences[todoIdSequence]++
+	return strconv.Itoa(id)
+}
+
+// SequenceStorage is implemented by storages persisting the sequences of the todo numbers
+type SequenceStorage interface {
+	LoadSequences() (map[string]int, error)
+	SaveSequences(sequences map[string]int) error
+}
+
+// nextNumber assigns the next number of the list
+func nextNumber(list string) int {
+	listSequences[list]++
+	return listSequences[list]
+}
+
+// nextPosition returns the position after the last todo of the list
+func nextPosition(list string) int {
+	last := 0
+	for _, todo := range listTodos() {
+		if todo.List == list && todo.Position > last {
+			last = todo.Position
+		}
+	}
+	return last + 1
+}
+
+// ListTodos returns the todos of the list in their order.
+// Todos stored before lists could be ordered have no position and are ordered by number.
+func ListTodos(list string) []Todo {
+	todos := []Todo{}
+	for _, todo := range listTodos() {
+		if todo.List == list {
+			todos = append(todos, todo)
+		}
+	}
+	sort.Slice(todos, func(i, j int) bool {
+		if todos[i].Position != todos[j].Position {
+			return todos[i].Position < todos[j].Position
+		}
+		return todos[i].Number < todos[j].Number
+	})
+	return todos
+}
+
+// ErrInvalidOrder is returned for an order not containing every todo of the list exactly once
+var ErrInvalidOrder = errors.New("the order must contain every todo of the list exactly once")
+
+// ReorderList sets the positions of the todos of the list to the order of the ids.
+// The list is left unchanged if the order is invalid.
+func ReorderList(list string, ids []string) ([]Todo, error) {
+	current := ListTodos(list)
+	if len(ids) != len(current) {
+		return nil, ErrInvalidOrder
+	}
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		todo, ok := getTodo(id)
+		if ok == false || todo.List != list || seen[id] {
+			return nil, ErrInvalidOrder
+		}
+		seen[id] = true
+	}
+
+	reordered := make([]Todo, 0, len(ids))
+	for i, id := range ids {
+		todo, _ := getTodo(id)
+		todo.Position = i + 1
+		reordered = append(reordered, touchTodo(todo))
+	}
+	return reordered, nil
+}
+
+// FindTodoByNumber returns the todo with the number in the list
+func FindTodoByNumber(list string, number int) (Todo, bool) {
+	for _, todo := range listTodos() {
+		if todo.List == list && todo.Number == number {
+			return todo, true
+		}
+	}
+	return Todo{}, false
+}
+
+// initializeNumbers continues the loaded sequences and numbers todos stored before they had numbers in id order.
+// The next todo id follows the highest id of the todos stored before the ids had a sequence. The todos of all
+// owners and the ones in the trash count, so that their ids and numbers are not reused.
+func initializeNumbers(sequences map[string]int) {
+	listSequences = make(map[string]int)
+	for list, sequence := range sequences {
+		listSequences[list] = sequence
+	}
+
+	var unnumbered []Todo
+	for _, todo := range repository.List() {
+		if id, err := strconv.Atoi(todo.Id); err == nil && id >= listSequences[todoIdSequence] {
+			listSequences[todoIdSequence] = id + 1
+		}
+		if todo.List == "" {
+			todo.List = DefaultList
+			storeTodo(todo)
+		}
+		if todo.Number == 0 {
+			unnumbered = append(unnumbered, todo)
+		} else if todo.Number > listSequences[todo.List] {
+			listSequences[todo.List] = todo.Number
+		}
+	}
+
+	sort.Slice(unnumbered, func(i, j int) bool {
+		leftValueAsInt, _ := strconv.Atoi(unnumbered[i].Id)
+		rightValueAsInt, _ := strconv.Atoi(unnumbered[j].Id)
+		return leftValueAsInt < rightValueAsInt
+	})
+	for _, todo := range unnumbered {
+		todo.Number = nextNumber(todo.List)
+		storeTodo(todo)
+	}
+}
+
+// sequencesFileName stores the sequences of a CSV storage next to its data file
+func (s CsvStorage) sequencesFileName() string {
+	return s.FileName + ".sequences"
+}
+
+// LoadSequences reads the sequences of the todo numbers, a missing file has no sequences
+func (s CsvStorage) LoadSequences() (map[string]int, error) {
+	sequences := make(map[string]int)
+	content, err := readDataFile(s.sequencesFileName())
+	if errors.Is(err, os.ErrNotExist) {
+		return sequences, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(content, &sequences)
+	return sequences, err
+}
+
+// SaveSequences writes the sequences of the todo numbers as JSON object
+func (s CsvStorage) SaveSequences(sequences map[string]int) error {
+	content, err := json.Marshal(sequences)
+	if err != nil {
+		return err
+	}
+	return replaceDataFile(s.sequencesFileName(), content)
+}
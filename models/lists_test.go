@@ -0,0 +1,78 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestTodo_NumbersAreNotReusedAfterDelete(t *testing.T) {
+	// Arrange
+	//
+	DeleteAllTodos()
+	defer DeleteAllTodos()
+	first := AddTodo(Todo{Title: "First", List: "errands"})
+	second := AddTodo(Todo{Title: "Second", List: "errands"})
+
+	// Act
+	//
+	RemoveTodo(second.Id)
+	third := AddTodo(Todo{Title: "Third", List: "errands"})
+	found, ok := FindTodoByNumber("errands", first.Number)
+
+	// Assert
+	//
+	if third.Number != second.Number+1 {
+		t.Errorf("got number %d after deleting number %d", third.Number, second.Number)
+	}
+	if ok == false || found.Title != "First" {
+		t.Error("Fehler", found)
+	}
+}
+
+func TestReorderList(t *testing.T) {
+	// Arrange
+	//
+	DeleteAllTodos()
+	defer DeleteAllTodos()
+	first := AddTodo(Todo{Title: "First", List: "work"})
+	second := AddTodo(Todo{Title: "Second", List: "work"})
+	other := AddTodo(Todo{Title: "Other", List: "home"})
+
+	// Act
+	//
+	_, invalidErr := ReorderList("work", []string{second.Id, other.Id})
+	_, err := ReorderList("work", []string{second.Id, first.Id})
+	got := ListTodos("work")
+
+	// Assert
+	//
+	if invalidErr != ErrInvalidOrder || err != nil {
+		t.Error("Fehler", invalidErr, err)
+	}
+	if len(got) != 2 || got[0].Title != "Second" || got[1].Title != "First" {
+		t.Error("Fehler", got)
+	}
+}
+
+func TestInitializeNumbers_ContinuesAfterTheTodosInTheTrash(t *testing.T) {
+	// Arrange
+	//
+	DeleteAllTodos()
+	defer DeleteAllTodos()
+	defer initializeNumbers(nil)
+	deletedAt := Now()
+	err := repository.Add(Todo{Id: "907", Title: "Trashed", List: "errands", Number: 12, DeletedAt: &deletedAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	//
+	initializeNumbers(nil)
+	added := AddTodo(Todo{Title: "New", List: "errands"})
+
+	// Assert
+	//
+	if added.Id != "908" || added.Number != 13 {
+		t.Error("Fehler", added.Id, added.Number)
+	}
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"strconv"
+)
+
+// earthRadius is the mean radius of the earth in meters
+const earthRadius = 6371000.0
+
+// Location is the place a todo is done at, given by coordinates, a free-text place or both
+type Location struct {
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+	Place     string   `json:"place,omitempty"`
+}
+
+// HasCoordinates tells whether the location has latitude and longitude
+func (l *Location) HasCoordinates() bool {
+	return l != nil && l.Latitude != nil && l.Longitude != nil
+}
+
+// Validate checks that latitude and longitude are given together and within their ranges
+func (l *Location) Validate() error {
+	if l == nil {
+		return nil
+	}
+	if (l.Latitude == nil) != (l.Longitude == nil) {
+		return errors.New("latitude and longitude must be given together")
+	}
+	if l.Latitude != nil && (math.Abs(*l.Latitude) > 90 || math.Abs(*l.Longitude) > 180) {
+		return errors.New("latitude must be between -90 and 90, longitude between -180 and 180")
+	}
+	return nil
+}
+
+// DistanceTo returns the great-circle distance in meters to the coordinates
+func (l *Location) DistanceTo(latitude float64, longitude float64) float64 {
+	lat1, lat2 := *l.Latitude*math.Pi/180, latitude*math.Pi/180
+	deltaLat := lat2 - lat1
+	deltaLon := (longitude - *l.Longitude) * math.Pi / 180
+
+	a := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(deltaLon/2)*math.Sin(deltaLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
+
+// serializeLocation returns the latitude, longitude and place columns
+func serializeLocation(l *Location) []string {
+	if l == nil {
+		return []string{"", "", ""}
+	}
+	return []string{formatCoordinate(l.Latitude), formatCoordinate(l.Longitude), l.Place}
+}
+
+func formatCoordinate(coordinate *float64) string {
+	if coordinate == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*coordinate, 'f', -1, 64)
+}
+
+// parseLocation reads the latitude, longitude and place columns, a todo without them has no location
+func parseLocation(latitude string, longitude string, place string) *Location {
+	location := &Location{Latitude: parseCoordinate(latitude), Longitude: parseCoordinate(longitude), Place: place}
+	if location.Latitude == nil && location.Longitude == nil && place == "" {
+		return nil
+	}
+	return location
+}
+
+func parseCoordinate(field string) *float64 {
+	coordinate, err := strconv.ParseFloat(field, 64)
+	if err != nil {
+		return nil
+	}
+	return &coordinate
+}
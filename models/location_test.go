@@ -0,0 +1,23 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLocation_DistanceTo(t *testing.T) {
+	// Arrange
+	//
+	latitude, longitude := 47.3769, 8.5417
+	zurich := &Location{Latitude: &latitude, Longitude: &longitude}
+
+	// Act
+	//
+	got := zurich.DistanceTo(46.9480, 7.4474)
+
+	// Assert
+	//
+	if math.Abs(got-95500) > 1000 {
+		t.Errorf("distance Zurich-Bern %.0f m, want about 95.5 km", got)
+	}
+}
@@ -0,0 +1,237 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// ErrTargetNotEmpty is returned for migrations into a store that already holds todos
+var ErrTargetNotEmpty = errors.New("the target store already holds todos")
+
+// Dataset is everything a store keeps: the todos, the sequences of the list numbers, the pomodoro sessions,
+// the goals, the revision and the settings
+type Dataset struct {
+	Todos            map[string]Todo
+	Sequences        map[string]int
+	PomodoroSessions []PomodoroSession
+	Goals            []Goal
+	Revision         uint64
+	// Settings is nil if the store has no saved settings
+	Settings *Settings
+}
+
+// DatasetCounts are the numbers of records of a dataset
+type DatasetCounts struct {
+	Todos            int `json:"todos"`
+	Lists            int `json:"lists"`
+	PomodoroSessions int `json:"pomodoro_sessions"`
+	Goals            int `json:"goals"`
+}
+
+// MigrationReport describes a verified migration
+type MigrationReport struct {
+	Counts   DatasetCounts `json:"counts"`
+	Checksum string        `json:"checksum"`
+}
+
+// ReadDataset reads the dataset of a Storage or a Repository, the side data is read from the storages
+// the store implements
+func ReadDataset(store interface{}) (Dataset, error) {
+	dataset := Dataset{Todos: make(map[string]Todo), Sequences: make(map[string]int)}
+	var err error
+	switch store := store.(type) {
+	case Storage:
+		dataset.Todos, err = store.Load()
+		if err != nil {
+			return dataset, err
+		}
+	case Repository:
+		for _, todo := range store.List() {
+			dataset.Todos[todo.Id] = todo
+		}
+	default:
+		return dataset, fmt.Errorf("%T is neither a storage nor a repository", store)
+	}
+
+	if sequenceStorage, ok := store.(SequenceStorage); ok {
+		dataset.Sequences, err = sequenceStorage.LoadSequences()
+		if err != nil {
+			return dataset, err
+		}
+	}
+	if pomodoroStorage, ok := store.(PomodoroStorage); ok {
+		dataset.PomodoroSessions, err = pomodoroStorage.LoadPomodoroSessions()
+		if err != nil {
+			return dataset, err
+		}
+	}
+	if goalStorage, ok := store.(GoalStorage); ok {
+		dataset.Goals, err = goalStorage.LoadGoals()
+		if err != nil {
+			return dataset, err
+		}
+	}
+	if revisionStorage, ok := store.(RevisionStorage); ok {
+		dataset.Revision, err = revisionStorage.LoadRevision()
+		if err != nil {
+			return dataset, err
+		}
+	}
+	if settingsStorage, ok := store.(SettingsStorage); ok {
+		dataset.Settings, err = settingsStorage.LoadSettings()
+	}
+	return dataset, err
+}
+
+// WriteDataset replaces the dataset of a Storage or a Repository, side data the store cannot keep is dropped
+func WriteDataset(store interface{}, dataset Dataset) error {
+	if datasetStorage, ok := store.(DatasetStorage); ok {
+		return datasetStorage.SaveDataset(dataset)
+	}
+	var err error
+	switch store := store.(type) {
+	case Storage:
+		err = store.Save(dataset.Todos)
+	case Repository:
+		err = store.DeleteAll()
+		for _, todo := range dataset.Todos {
+			if err != nil {
+				break
+			}
+			err = store.Add(todo)
+		}
+	default:
+		err = fmt.Errorf("%T is neither a storage nor a repository", store)
+	}
+	if err != nil {
+		return err
+	}
+	return writeSideData(store, dataset)
+}
+
+// writeSideData writes the side data of the dataset to the storages the store implements
+func writeSideData(store interface{}, dataset Dataset) error {
+	var err error
+	if sequenceStorage, ok := store.(SequenceStorage); ok {
+		err = sequenceStorage.SaveSequences(dataset.Sequences)
+		if err != nil {
+			return err
+		}
+	}
+	if pomodoroStorage, ok := store.(PomodoroStorage); ok {
+		err = pomodoroStorage.SavePomodoroSessions(dataset.PomodoroSessions)
+		if err != nil {
+			return err
+		}
+	}
+	if goalStorage, ok := store.(GoalStorage); ok {
+		err = goalStorage.SaveGoals(dataset.Goals)
+		if err != nil {
+			return err
+		}
+	}
+	if revisionStorage, ok := store.(RevisionStorage); ok {
+		err = revisionStorage.SaveRevision(dataset.Revision)
+		if err != nil {
+			return err
+		}
+	}
+	if settingsStorage, ok := store.(SettingsStorage); ok && dataset.Settings != nil {
+		err = settingsStorage.SaveSettings(*dataset.Settings)
+	}
+	return err
+}
+
+// Counts returns the numbers of records of the dataset
+func (d Dataset) Counts() DatasetCounts {
+	return DatasetCounts{Todos: len(d.Todos), Lists: len(d.Sequences), PomodoroSessions: len(d.PomodoroSessions),
+		Goals: len(d.Goals)}
+}
+
+// Checksum is the SHA-256 of the dataset in a canonical form: the todos as CSV rows ordered by id and the
+// side data as JSON. Equal datasets have equal checksums in every store.
+func (d Dataset) Checksum() string {
+	hash := sha256.New()
+	var ids []string
+	for id := range d.Todos {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		row, _ := json.Marshal(d.Todos[id].Serialize())
+		hash.Write(append(row, '\n'))
+	}
+
+	goals := append([]Goal{}, d.Goals...)
+	sort.Slice(goals, func(i, j int) bool {
+		return goals[i].Id < goals[j].Id
+	})
+	// empty and missing side data are the same
+	sequences := d.Sequences
+	if len(sequences) == 0 {
+		sequences = nil
+	}
+	sessions := d.PomodoroSessions
+	if len(sessions) == 0 {
+		sessions = nil
+	}
+	if len(goals) == 0 {
+		goals = nil
+	}
+	side, _ := json.Marshal([]interface{}{sequences, sessions, goals, d.Revision, d.Settings})
+	hash.Write(side)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// MigrateStore copies the dataset from one store to another and verifies the copy by reading it back:
+// the counts and the checksums of both datasets must match. A target holding todos is only overwritten
+// with overwrite set.
+func MigrateStore(from interface{}, to interface{}, overwrite bool) (MigrationReport, error) {
+	dataset, err := ReadDataset(from)
+	if err != nil {
+		return MigrationReport{}, fmt.Errorf("cannot read the source: %w", err)
+	}
+	report := MigrationReport{Counts: dataset.Counts(), Checksum: dataset.Checksum()}
+
+	if overwrite == false && holdsTodos(to) {
+		return report, ErrTargetNotEmpty
+	}
+	err = WriteDataset(to, dataset)
+	if err != nil {
+		return report, fmt.Errorf("cannot write the target: %w", err)
+	}
+
+	copied, err := ReadDataset(to)
+	if err != nil {
+		return report, fmt.Errorf("cannot read the target back: %w", err)
+	}
+	if copied.Counts() != report.Counts {
+		return report, fmt.Errorf("the target holds %+v instead of %+v", copied.Counts(), report.Counts)
+	}
+	if copied.Checksum() != report.Checksum {
+		return report, errors.New("the checksum of the target differs from the source")
+	}
+	return report, nil
+}
+
+// holdsTodos tells whether the store has todos of its own, a compressed CSV storage or a JSON storage reading
+// the file it replaces has none yet
+func holdsTodos(store interface{}) bool {
+	if csv, ok := store.(CsvStorage); ok {
+		if _, err := os.Stat(csv.FileName); err != nil {
+			return false
+		}
+	}
+	if json, ok := store.(*JsonStorage); ok {
+		if _, err := os.Stat(json.FileName); err != nil {
+			return false
+		}
+	}
+	existing, err := ReadDataset(store)
+	return err == nil && len(existing.Todos) > 0
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMigrateStore_CsvToSqlite(t *testing.T) {
+	// Arrange
+	//
+	dir := t.TempDir()
+	source := CsvStorage{FileName: filepath.Join(dir, FileName)}
+	created := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+	dataset := Dataset{
+		Todos: map[string]Todo{
+			"0": {Id: "0", Title: "Buy milk", Tags: []string{"home"}, List: "shopping", Number: 1, CreatedAt: &created},
+			"3": {Id: "3", Title: "Call Anna", List: "inbox", Number: 1, Priority: PriorityHigh},
+		},
+		Sequences:        map[string]int{"shopping": 1, "inbox": 1},
+		PomodoroSessions: []PomodoroSession{{TodoId: "0", StartedAt: created, Completed: true}},
+		Goals:            []Goal{{Id: "0", Title: "Healthy"}},
+		Revision:         7,
+		Settings:         &Settings{DefaultList: "inbox", DefaultTags: []string{}},
+	}
+	err := WriteDataset(source, dataset)
+	if err != nil {
+		t.Fatal("Fehler", err)
+	}
+	target, err := OpenSqliteRepository(filepath.Join(dir, "todos.db"))
+	if err != nil {
+		t.Fatal("Fehler", err)
+	}
+	defer target.Close()
+
+	// Act
+	//
+	report, err := MigrateStore(source, target, false)
+
+	// Assert
+	//
+	if err != nil || report.Counts != (DatasetCounts{Todos: 2, Lists: 2, PomodoroSessions: 1, Goals: 1}) {
+		t.Fatal("Fehler", report, err)
+	}
+	copied, err := ReadDataset(target)
+	if err != nil || copied.Checksum() != dataset.Checksum() || copied.Revision != 7 {
+		t.Error("Fehler", copied, err)
+	}
+}
+
+func TestMigrateStore_RefusesTargetWithTodos(t *testing.T) {
+	// Arrange
+	//
+	dir := t.TempDir()
+	source := CsvStorage{FileName: filepath.Join(dir, FileName)}
+	target := CsvStorage{FileName: filepath.Join(dir, CompressedFileName), Compress: true}
+	WriteDataset(source, Dataset{Todos: map[string]Todo{"0": {Id: "0", Title: "New"}}})
+	WriteDataset(target, Dataset{Todos: map[string]Todo{"0": {Id: "0", Title: "Old"}}})
+
+	// Act
+	//
+	_, err := MigrateStore(source, target, false)
+
+	// Assert
+	//
+	if errors.Is(err, ErrTargetNotEmpty) == false {
+		t.Error("Fehler", err)
+	}
+}
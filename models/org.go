@@ -0,0 +1,139 @@
+package models
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// orgDateFormat is the format of the dates of org timestamps like <2024-05-07 Tue>, orgTimeFormat adds the time
+const (
+	orgDateFormat = "2006-01-02 Mon"
+	orgTimeFormat = "2006-01-02 Mon 15:04"
+)
+
+var orgHeadline = regexp.MustCompile(`^\*+\s+(?:(TODO|DONE)\s+)?(.*?)(?:\s+(:(?:[\w@#%]+:)+))?\s*$`)
+var orgDeadline = regexp.MustCompile(`^\s*DEADLINE:\s*<(\d{4}-\d{2}-\d{2})(?:\s+[^\s\d>]+)?(?:\s+(\d{1,2}:\d{2}))?[^>]*>`)
+var orgProperty = regexp.MustCompile(`^\s*:(\w+):\s*(.*?)\s*$`)
+var orgTagInvalid = regexp.MustCompile(`[^\w@#%]`)
+
+// WriteOrg writes the todos as an Emacs org-mode file with one TODO/DONE headline per todo
+func WriteOrg(writer io.Writer, todos []Todo) error {
+	_, err := io.WriteString(writer, "#+TITLE: Todos\n#+TODO: TODO | DONE\n\n")
+	if err != nil {
+		return err
+	}
+
+	for _, todo := range todos {
+		_, err = io.WriteString(writer, formatOrgEntry(todo))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatOrgEntry(todo Todo) string {
+	var builder strings.Builder
+
+	keyword := "TODO"
+	if todo.Terminated {
+		keyword = "DONE"
+	}
+	// org headlines are single lines
+	title := strings.Join(strings.Fields(todo.Title), " ")
+	fmt.Fprintf(&builder, "* %s %s", keyword, title)
+	// org tags consist of letters, digits, _, @, # and %, other characters are replaced by _
+	if len(todo.Tags) > 0 {
+		tags := make([]string, len(todo.Tags))
+		for i, tag := range todo.Tags {
+			tags[i] = orgTagInvalid.ReplaceAllString(tag, "_")
+		}
+		fmt.Fprintf(&builder, " :%s:", strings.Join(tags, ":"))
+	}
+	builder.WriteString("\n")
+
+	if at, dayOnly, err := ParseDue(todo.DueDate); todo.DueDate != "" && err == nil {
+		format := orgTimeFormat
+		if dayOnly {
+			format = orgDateFormat
+		}
+		fmt.Fprintf(&builder, "  DEADLINE: <%s>\n", at.Local().Format(format))
+	}
+
+	fmt.Fprintf(&builder, "  :PROPERTIES:\n  :ID: %s\n  :END:\n", todo.Id)
+
+	// The body is indented so that lines starting with "*" are not read as headlines
+	description := strings.TrimSpace(todo.Description)
+	if description != "" {
+		for _, line := range strings.Split(description, "\n") {
+			builder.WriteString("  " + strings.TrimRight(line, " \r\t") + "\n")
+		}
+	}
+
+	return builder.String()
+}
+
+// ReadOrg reads the todos of an org-mode file written by WriteOrg: the headlines with their keyword, tags,
+// deadline, the ID property and the body as description. Lines before the first headline are skipped.
+func ReadOrg(reader io.Reader) ([]Todo, error) {
+	var todos []Todo
+	var description []string
+	inDrawer := false
+	finish := func() {
+		if len(todos) > 0 {
+			todos[len(todos)-1].Description = strings.TrimSpace(strings.Join(description, "\n"))
+		}
+		description = nil
+	}
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := orgHeadline.FindStringSubmatch(line); match != nil {
+			finish()
+			todo := Todo{Title: match[2], Terminated: match[1] == "DONE"}
+			if match[3] != "" {
+				todo.Tags = strings.Split(strings.Trim(match[3], ":"), ":")
+			}
+			todos = append(todos, todo)
+			inDrawer = false
+			continue
+		}
+		if len(todos) == 0 {
+			continue
+		}
+
+		todo := &todos[len(todos)-1]
+		property := orgProperty.FindStringSubmatch(line)
+		switch {
+		case property != nil && property[1] == "PROPERTIES":
+			inDrawer = true
+		case property != nil && property[1] == "END":
+			inDrawer = false
+		case inDrawer:
+			if property != nil && property[1] == "ID" {
+				todo.Id = property[2]
+			}
+		case len(description) == 0 && orgDeadline.MatchString(line):
+			match := orgDeadline.FindStringSubmatch(line)
+			todo.DueDate = match[1]
+			if match[2] != "" {
+				at, err := time.ParseInLocation("2006-01-02 15:04", match[1]+" "+match[2], time.Local)
+				if err == nil {
+					todo.DueDate = at.Format(time.RFC3339)
+				}
+			}
+		default:
+			description = append(description, strings.TrimPrefix(line, "  "))
+		}
+	}
+	finish()
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormatOrgEntry(t *testing.T) {
+	// Arrange
+	//
+	todo := Todo{Id: "3", Title: "Archive\nmails", Description: "* not a headline", Terminated: true}
+	want := "* DONE Archive mails\n  :PROPERTIES:\n  :ID: 3\n  :END:\n  * not a headline\n"
+
+	// Act
+	//
+	got := formatOrgEntry(todo)
+
+	// Assert
+	//
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFormatOrgEntry_WritesTheDeadlineAndTags(t *testing.T) {
+	// Arrange
+	//
+	todo := Todo{Id: "4", Title: "Pay rent", DueDate: "2024-05-07", Tags: []string{"home", "bills due"}}
+	want := "* TODO Pay rent :home:bills_due:\n  DEADLINE: <2024-05-07 Tue>\n  :PROPERTIES:\n  :ID: 4\n  :END:\n"
+
+	// Act
+	//
+	got := formatOrgEntry(todo)
+
+	// Assert
+	//
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestReadOrg_ReadsTheExportBack(t *testing.T) {
+	// Arrange
+	//
+	todos := []Todo{
+		{Id: "4", Title: "Pay rent", DueDate: "2024-05-07", Tags: []string{"home", "bills"}, Description: "Transfer\n* not a headline"},
+		{Id: "5", Title: "Archive mails", Terminated: true},
+	}
+	var builder strings.Builder
+	if err := WriteOrg(&builder, todos); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	//
+	got, err := ReadOrg(strings.NewReader(builder.String()))
+
+	// Assert
+	//
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(got, todos) == false {
+		t.Errorf("got %+v, want %+v", got, todos)
+	}
+}
@@ -0,0 +1,65 @@
+package models
+
+// owner is the user the store functions act for: they only find, list, change and remove the todos of the
+// owner and new todos belong to the owner. An empty owner acts for all users, like the background jobs.
+// The owner is set for the duration of a request by callers handling the requests one at a time.
+var owner string
+
+// defaultOwner owns the todos without owner, the todos stored before users were configured
+var defaultOwner string
+
+// SetOwner sets the user the store functions act for, an empty owner acts for all users
+func SetOwner(user string) {
+	owner = user
+}
+
+// Owner returns the user the store functions act for
+func Owner() string {
+	return owner
+}
+
+// SetDefaultOwner sets the user owning the todos stored without owner
+func SetDefaultOwner(user string) {
+	defaultOwner = user
+}
+
+// OwnerOf returns the user the todo belongs to, the default owner for todos stored without owner
+func OwnerOf(todo Todo) string {
+	if todo.Owner == "" {
+		return defaultOwner
+	}
+	return todo.Owner
+}
+
+// visible tells whether the todo belongs to the owner the store functions act for
+func visible(todo Todo) bool {
+	return owner == "" || todo.Owner == owner || todo.Owner == "" && owner == defaultOwner
+}
+
+// getTodo returns the todo with the id if it belongs to the owner and is not in the trash
+func getTodo(id string) (Todo, bool) {
+	todo, ok := repository.Get(id)
+	if ok == false || visible(todo) == false || todo.DeletedAt != nil {
+		return Todo{}, false
+	}
+	return todo, true
+}
+
+// listTodos returns the todos of the owner in no particular order, the todos in the trash are left out
+func listTodos() []Todo {
+	return ownedTodos(func(todo Todo) bool {
+		return todo.DeletedAt == nil
+	})
+}
+
+// ownedTodos returns the todos of the owner matching in no particular order, including the ones in the trash
+func ownedTodos(matching func(todo Todo) bool) []Todo {
+	todos := repository.List()
+	owned := make([]Todo, 0, len(todos))
+	for _, todo := range todos {
+		if visible(todo) && matching(todo) {
+			owned = append(owned, todo)
+		}
+	}
+	return owned
+}
@@ -0,0 +1,28 @@
+package models
+
+import "testing"
+
+func TestDeleteAllTodos_KeepsTodosOfOtherUsers(t *testing.T) {
+	// Arrange
+	//
+	DeleteAllTodos()
+	defer DeleteAllTodos()
+	defer SetOwner("")
+	SetOwner("alice")
+	AddTodo(Todo{Title: "Buy milk"})
+	SetOwner("bob")
+	AddTodo(Todo{Title: "Call Anna"})
+
+	// Act
+	//
+	DeleteAllTodos()
+	ofBob := AllTodos()
+	SetOwner("alice")
+	ofAlice := AllTodos()
+
+	// Assert
+	//
+	if len(ofBob) != 0 || len(ofAlice) != 1 || ofAlice[0].Owner != "alice" {
+		t.Error("Fehler", ofBob, ofAlice)
+	}
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+)
+
+// fileMode is the permission of created data files, they contain the todos of the users and are private by default
+var fileMode os.FileMode = 0600
+
+// SetFileMode parses an octal permission like "0640" for the data files, an empty string keeps 0600
+func SetFileMode(mode string) error {
+	if mode == "" {
+		return nil
+	}
+	parsed, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || parsed > 0777 {
+		return fmt.Errorf("invalid file mode %q, expected an octal permission like 0600", mode)
+	}
+	fileMode = os.FileMode(parsed)
+	return nil
+}
+
+// openDataFile opens a data file with the configured permission.
+// The permission is also set on existing files, which keep their mode when opened otherwise.
+func openDataFile(fileName string, flag int) (*os.File, error) {
+	file, err := os.OpenFile(fileName, flag, fileMode)
+	if err != nil {
+		return nil, err
+	}
+	if runtime.GOOS != "windows" {
+		err = file.Chmod(fileMode)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return file, nil
+}
+
+// writeDataFile replaces the content of a data file
+func writeDataFile(fileName string, content []byte) error {
+	file, err := openDataFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(content)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// replaceDataFile replaces a data file atomically: the content is written to a temporary file next to it,
+// synced and renamed over the file. The previous file is kept as backup; a crash between the renames leaves
+// only the backup, never a partly written file.
+func replaceDataFile(fileName string, content []byte) error {
+	temporary, err := writeTemporaryFile(fileName, content)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(fileName, backupFileName(fileName))
+	if err != nil && errors.Is(err, os.ErrNotExist) == false {
+		return err
+	}
+	err = os.Rename(temporary, fileName)
+	if err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(fileName))
+}
+
+// readDataFile reads a data file written by replaceDataFile, the backup is read if a crash interrupted the
+// replacement between its renames
+func readDataFile(fileName string) ([]byte, error) {
+	content, err := os.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return os.ReadFile(backupFileName(fileName))
+	}
+	return content, err
+}
+
+// writeTemporaryFile writes the content synced to a temporary file next to the data file, which is renamed over it
+func writeTemporaryFile(fileName string, content []byte) (string, error) {
+	temporary := filepath.Join(filepath.Dir(fileName), "."+filepath.Base(fileName)+".tmp")
+	file, err := openDataFile(temporary, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return "", err
+	}
+	_, err = file.Write(content)
+	if err == nil {
+		err = file.Sync()
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(temporary)
+		return "", err
+	}
+	err = file.Close()
+	if err != nil {
+		os.Remove(temporary)
+		return "", err
+	}
+	return temporary, nil
+}
+
+// syncDir makes the renames in a directory durable, directories cannot be synced on Windows
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	directory, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer directory.Close()
+	return directory.Sync()
+}
+
+// WarnAboutPermissions logs a warning for every data file or data directory other users can read
+func WarnAboutPermissions() {
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	paths := []string{DataPath(".")}
+	for _, fileName := range []string{FileName, CompressedFileName, JsonFileName, CompressedJsonFileName, ArchiveFileName} {
+		paths = append(paths, DataPath(fileName), DataPath(backupFileName(fileName)), DataPath(manifestFileName(fileName)))
+	}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if info.Mode().Perm()&0004 != 0 {
+			log.Printf("Warning: %s is readable by all users (mode %04o), restrict it with chmod o-rwx",
+				filepath.Clean(path), info.Mode().Perm())
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+)
+
+// PomodoroDuration is the length of a focus session
+const PomodoroDuration = 25 * time.Minute
+
+// PomodoroSession is a focus session on a todo
+type PomodoroSession struct {
+	// TodoId is empty once the todo is deleted, its focus time still counts for the day
+	TodoId    string    `json:"todo_id"`
+	StartedAt time.Time `json:"started_at"`
+	// EndedAt is nil while the session runs
+	EndedAt *time.Time `json:"ended_at,omitempty"`
+	// Completed sessions ran the full duration, the others were stopped early
+	Completed bool `json:"completed"`
+	// Owner is the owner of the todo, the sessions are scoped to the owner like the todos
+	Owner string `json:"owner,omitempty"`
+}
+
+// FocusTime is the time spent in the session so far
+func (s PomodoroSession) FocusTime() time.Duration {
+	if s.EndedAt == nil {
+		return Now().Sub(s.StartedAt)
+	}
+	return s.EndedAt.Sub(s.StartedAt)
+}
+
+// PomodoroStorage is implemented by storages persisting the pomodoro sessions
+type PomodoroStorage interface {
+	LoadPomodoroSessions() ([]PomodoroSession, error)
+	SavePomodoroSessions(sessions []PomodoroSession) error
+}
+
+var pomodoroSessions []PomodoroSession
+
+// Errors of starting and stopping sessions
+var (
+	ErrPomodoroRunning    = errors.New("a pomodoro session is already running")
+	ErrNoPomodoroRunning  = errors.New("no pomodoro session is running for the todo")
+	ErrPomodoroTerminated = errors.New("the todo is terminated")
+)
+
+// finishedSessions returns the sessions with the running session completed once its duration is over.
+// Readers get a copy, so that they can run concurrently, the changes of StartPomodoro and StopPomodoro keep it.
+func finishedSessions() []PomodoroSession {
+	sessions := make([]PomodoroSession, len(pomodoroSessions))
+	copy(sessions, pomodoroSessions)
+	for i := range sessions {
+		session := &sessions[i]
+		if session.EndedAt == nil && Now().Sub(session.StartedAt) >= PomodoroDuration {
+			endedAt := session.StartedAt.Add(PomodoroDuration)
+			session.EndedAt = &endedAt
+			session.Completed = true
+		}
+	}
+	return sessions
+}
+
+// ownSession tells whether the session belongs to the owner the store functions act for, see visible
+func ownSession(session PomodoroSession) bool {
+	return visible(Todo{Owner: session.Owner})
+}
+
+// RunningPomodoro returns the running session of the owner, only one session of an owner runs at a time
+func RunningPomodoro() (PomodoroSession, bool) {
+	for _, session := range finishedSessions() {
+		if session.EndedAt == nil && ownSession(session) {
+			return session, true
+		}
+	}
+	return PomodoroSession{}, false
+}
+
+// StartPomodoro starts a focus session on the todo
+func StartPomodoro(id string) (PomodoroSession, error) {
+	todo, ok := getTodo(id)
+	if ok == false {
+		return PomodoroSession{}, ErrTodoNotFound
+	}
+	if todo.Terminated {
+		return PomodoroSession{}, ErrPomodoroTerminated
+	}
+	if _, running := RunningPomodoro(); running {
+		return PomodoroSession{}, ErrPomodoroRunning
+	}
+
+	pomodoroSessions = finishedSessions()
+	session := PomodoroSession{TodoId: id, StartedAt: Now().UTC().Truncate(time.Second), Owner: todo.Owner}
+	pomodoroSessions = append(pomodoroSessions, session)
+	changed()
+	return session, nil
+}
+
+// StopPomodoro ends the running session of the todo before its duration is over
+func StopPomodoro(id string) (PomodoroSession, error) {
+	running, ok := RunningPomodoro()
+	if ok == false || running.TodoId != id {
+		return PomodoroSession{}, ErrNoPomodoroRunning
+	}
+
+	pomodoroSessions = finishedSessions()
+	for i := range pomodoroSessions {
+		session := &pomodoroSessions[i]
+		if session.EndedAt == nil && ownSession(*session) {
+			endedAt := Now().UTC().Truncate(time.Second)
+			session.EndedAt = &endedAt
+			changed()
+			return *session, nil
+		}
+	}
+	return PomodoroSession{}, ErrNoPomodoroRunning
+}
+
+// PomodoroSessionsOf returns the sessions of the todo in the order they were started
+func PomodoroSessionsOf(id string) []PomodoroSession {
+	sessions := []PomodoroSession{}
+	for _, session := range finishedSessions() {
+		if session.TodoId == id {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions
+}
+
+// PomodoroSessionsOn returns the sessions of the owner started on the day in the form 2006-01-02
+func PomodoroSessionsOn(date string) []PomodoroSession {
+	sessions := []PomodoroSession{}
+	for _, session := range finishedSessions() {
+		if session.StartedAt.Local().Format(DateFormat) == date && ownSession(session) {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions
+}
+
+// renamePomodoroTodos follows the new ids of the todos after a removal, sessions of removed todos lose their todo.
+// The running session of a removed todo ends.
+func renamePomodoroTodos(newIds map[string]string) {
+	pomodoroSessions = finishedSessions()
+	for i := range pomodoroSessions {
+		session := &pomodoroSessions[i]
+		session.TodoId = newIds[session.TodoId]
+		if session.TodoId == "" && session.EndedAt == nil {
+			endedAt := Now().UTC().Truncate(time.Second)
+			session.EndedAt = &endedAt
+		}
+	}
+}
+
+// pomodoroFileName stores the sessions of a CSV storage next to its data file
+func (s CsvStorage) pomodoroFileName() string {
+	return s.FileName + ".pomodoro"
+}
+
+// LoadPomodoroSessions reads the pomodoro sessions, a missing file has no sessions
+func (s CsvStorage) LoadPomodoroSessions() ([]PomodoroSession, error) {
+	var sessions []PomodoroSession
+	content, err := readDataFile(s.pomodoroFileName())
+	if errors.Is(err, os.ErrNotExist) {
+		return sessions, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(content, &sessions)
+	return sessions, err
+}
+
+// SavePomodoroSessions writes the pomodoro sessions as JSON array
+func (s CsvStorage) SavePomodoroSessions(sessions []PomodoroSession) error {
+	content, err := json.Marshal(sessions)
+	if err != nil {
+		return err
+	}
+	return replaceDataFile(s.pomodoroFileName(), content)
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"testing"
+	"time"
+	"todo-rest-backend/clock"
+)
+
+func TestPomodoro_CompletesAfterDuration(t *testing.T) {
+	// Arrange
+	//
+	DeleteAllTodos()
+	defer DeleteAllTodos()
+	defer SetClock(clk)
+	fake := clock.NewFake(time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC))
+	SetClock(fake)
+	todo := AddTodo(Todo{Title: "Write report"})
+
+	// Act
+	//
+	_, err := StartPomodoro(todo.Id)
+	_, errSecondStart := StartPomodoro(todo.Id)
+	fake.Advance(30 * time.Minute)
+	sessions := PomodoroSessionsOf(todo.Id)
+
+	// Assert
+	//
+	if err != nil || errSecondStart != ErrPomodoroRunning {
+		t.Fatal("Fehler", err, errSecondStart)
+	}
+	if len(sessions) != 1 || sessions[0].Completed == false || sessions[0].FocusTime() != PomodoroDuration {
+		t.Error("Fehler", sessions)
+	}
+}
+
+func TestPomodoro_Stop(t *testing.T) {
+	// Arrange
+	//
+	DeleteAllTodos()
+	defer DeleteAllTodos()
+	defer SetClock(clk)
+	fake := clock.NewFake(time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC))
+	SetClock(fake)
+	todo := AddTodo(Todo{Title: "Write report"})
+	StartPomodoro(todo.Id)
+	fake.Advance(10 * time.Minute)
+
+	// Act
+	//
+	session, err := StopPomodoro(todo.Id)
+	_, errSecondStop := StopPomodoro(todo.Id)
+
+	// Assert
+	//
+	if err != nil || session.Completed || session.FocusTime() != 10*time.Minute {
+		t.Error("Fehler", session, err)
+	}
+	if errSecondStop != ErrNoPomodoroRunning {
+		t.Error("Fehler", errSecondStop)
+	}
+}
+
+func TestPomodoro_ScopesTheSessionsToTheOwner(t *testing.T) {
+	// Arrange
+	//
+	DeleteAllTodos()
+	defer DeleteAllTodos()
+	defer SetClock(clk)
+	defer SetOwner("")
+	SetClock(clock.NewFake(time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC)))
+	SetOwner("alice")
+	ofAlice := AddTodo(Todo{Title: "Write report"})
+	StartPomodoro(ofAlice.Id)
+	SetOwner("bob")
+	ofBob := AddTodo(Todo{Title: "Review the budget"})
+
+	// Act
+	//
+	_, err := StartPomodoro(ofBob.Id)
+	sessionsOfBob := PomodoroSessionsOn("2024-05-06")
+	SetOwner("alice")
+	running, ok := RunningPomodoro()
+	sessionsOfAlice := PomodoroSessionsOn("2024-05-06")
+
+	// Assert
+	//
+	if err != nil || len(sessionsOfBob) != 1 || sessionsOfBob[0].TodoId != ofBob.Id {
+		t.Error("Fehler", err, sessionsOfBob)
+	}
+	if ok == false || running.TodoId != ofAlice.Id || len(sessionsOfAlice) != 1 || sessionsOfAlice[0].Owner != "alice" {
+		t.Error("Fehler", running, sessionsOfAlice)
+	}
+}
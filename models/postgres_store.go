@@ -0,0 +1,84 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	_ "github.com/lib/pq"
+	"strconv"
+	"time"
+)
+
+// postgresMigrations are the schema versions of the PostgreSQL database, new versions are appended
+var postgresMigrations = []string{
+	`CREATE TABLE todos (
+		id TEXT PRIMARY KEY,
+		title TEXT NOT NULL,
+		description TEXT NOT NULL,
+		terminated BOOLEAN NOT NULL,
+		external_ref TEXT NOT NULL,
+		tags TEXT NOT NULL,
+		auto_tags TEXT NOT NULL,
+		completed_at TIMESTAMPTZ,
+		latitude DOUBLE PRECISION,
+		longitude DOUBLE PRECISION,
+		place TEXT NOT NULL,
+		list TEXT NOT NULL,
+		number INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		status TEXT NOT NULL,
+		due_date TEXT NOT NULL,
+		estimate_minutes INTEGER NOT NULL,
+		habit_since TEXT NOT NULL,
+		habit_days TEXT NOT NULL,
+		goal_id TEXT NOT NULL,
+		waiting_on TEXT NOT NULL,
+		waiting_since TIMESTAMPTZ,
+		nudged_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ
+	);
+	CREATE TABLE documents (
+		name TEXT PRIMARY KEY,
+		content TEXT NOT NULL
+	);`,
+	`ALTER TABLE todos ADD COLUMN priority TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE todos ADD COLUMN items TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE todos ADD COLUMN owner TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE todos ADD COLUMN deleted_at TIMESTAMPTZ`,
+	`ALTER TABLE todos ADD COLUMN updated_at TIMESTAMPTZ`,
+}
+
+// PostgresHealthCheckTimeout is how long the startup health check waits for the database
+const PostgresHealthCheckTimeout = 5 * time.Second
+
+// OpenPostgresRepository connects to the PostgreSQL database of the URL with a pool of at most maxConnections
+// connections, migrates its schema and prepares the statements. An unreachable database fails after
+// PostgresHealthCheckTimeout.
+func OpenPostgresRepository(url string, maxConnections int) (*SqlRepository, error) {
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(maxConnections)
+	db.SetMaxIdleConns(maxConnections)
+	db.SetConnMaxIdleTime(5 * time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), PostgresHealthCheckTimeout)
+	defer cancel()
+	err = db.PingContext(ctx)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("database unreachable: %w", err)
+	}
+
+	repository := &SqlRepository{db: db, placeholder: func(index int) string { return "$" + strconv.Itoa(index) }}
+	err = repository.migrate(postgresMigrations)
+	if err == nil {
+		err = repository.prepare()
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return repository, nil
+}
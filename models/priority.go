@@ -0,0 +1,35 @@
+package models
+
+import "errors"
+
+// Priorities of todos, todos without priority come after low priority todos
+const (
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+)
+
+// ErrInvalidPriority is returned for a priority other than low, medium and high
+var ErrInvalidPriority = errors.New("priority must be low, medium or high")
+
+// ValidatePriority checks a priority, the empty priority is valid
+func ValidatePriority(priority string) error {
+	switch priority {
+	case "", PriorityLow, PriorityMedium, PriorityHigh:
+		return nil
+	}
+	return ErrInvalidPriority
+}
+
+// PriorityRank orders the priorities, the higher the rank the more important
+func PriorityRank(priority string) int {
+	switch priority {
+	case PriorityHigh:
+		return 3
+	case PriorityMedium:
+		return 2
+	case PriorityLow:
+		return 1
+	}
+	return 0
+}
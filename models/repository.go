@@ -0,0 +1,144 @@
+package models
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Repository holds the todos. The store functions of this package, like AddTodo and UpdateTodo, keep the
+// numbers, statuses and timestamps of the todos consistent and read and write them through the repository,
+// so that a database can replace the in-memory store.
+//
+// Write errors are raised as panics by the store functions, like failed saves in the request handlers.
+// Implementations must be safe for concurrent use. The store functions change several todos one after the
+// other, callers changing the store concurrently serialize them, like the request handlers do.
+type Repository interface {
+	// Get returns the todo with the id
+	Get(id string) (Todo, bool)
+	// List returns all todos in no particular order
+	List() []Todo
+	// Add stores a new todo under its id
+	Add(todo Todo) error
+	// Update replaces the todo with the same id
+	Update(todo Todo) error
+	// Delete removes the todo with the id
+	Delete(id string) error
+	// DeleteAll removes all todos
+	DeleteAll() error
+}
+
+// PersistentRepository is implemented by repositories storing the todos themselves, like the SQLite
+// repository. They are configured without file persistence and keep the sequences, pomodoro sessions, goals,
+// revision and settings as well.
+type PersistentRepository interface {
+	Repository
+	SequenceStorage
+	PomodoroStorage
+	GoalStorage
+	RevisionStorage
+	SettingsStorage
+}
+
+// MemoryRepository keeps the todos in a map guarded by a read-write mutex, so it can be used concurrently.
+// With file persistence enabled Initialize loads them from the storage and UpdateDataInFile saves them.
+type MemoryRepository struct {
+	mutex sync.RWMutex
+	todos map[string]Todo
+}
+
+// NewMemoryRepository returns an empty in-memory repository
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{todos: make(map[string]Todo)}
+}
+
+func (r *MemoryRepository) Get(id string) (Todo, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	todo, ok := r.todos[id]
+	return todo, ok
+}
+
+func (r *MemoryRepository) List() []Todo {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	todos := make([]Todo, 0, len(r.todos))
+	for _, todo := range r.todos {
+		todos = append(todos, todo)
+	}
+	return todos
+}
+
+func (r *MemoryRepository) Add(todo Todo) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.todos[todo.Id] = todo
+	return nil
+}
+
+func (r *MemoryRepository) Update(todo Todo) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.todos[todo.Id] = todo
+	return nil
+}
+
+func (r *MemoryRepository) Delete(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.todos, id)
+	return nil
+}
+
+func (r *MemoryRepository) DeleteAll() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.todos = make(map[string]Todo)
+	return nil
+}
+
+// repository is used by the store functions
+var repository Repository = NewMemoryRepository()
+
+// SetRepository replaces the repository of the todos
+func SetRepository(r Repository) {
+	repository = r
+}
+
+// FindTodo returns the todo with the id
+func FindTodo(id string) (Todo, bool) {
+	return getTodo(id)
+}
+
+// AllTodos returns all todos in no particular order
+func AllTodos() []Todo {
+	return listTodos()
+}
+
+// touchTodo writes a todo changed by a user or an integration and records the time of the change in UpdatedAt,
+// a todo equal to the stored one is not written. Maintenance like numbering loaded todos uses storeTodo.
+func touchTodo(todo Todo) Todo {
+	stored, ok := repository.Get(todo.Id)
+	if ok {
+		todo.UpdatedAt = stored.UpdatedAt
+		if reflect.DeepEqual(stored, todo) {
+			return todo
+		}
+	}
+	updatedAt := clk.Now().UTC().Truncate(time.Second)
+	todo.UpdatedAt = &updatedAt
+	storeTodo(todo)
+	return todo
+}
+
+// storeTodo writes a changed todo to the repository, a todo equal to the stored one is not written
+func storeTodo(todo Todo) {
+	if stored, ok := repository.Get(todo.Id); ok && reflect.DeepEqual(stored, todo) {
+		return
+	}
+	err := repository.Update(todo)
+	if err != nil {
+		panic(err)
+	}
+	changed()
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// revision counts the changes of the store, savedRevision is the revision written by SaveData.
+// Changes leaving the store as it was, like an update with the same fields, do not count.
+var revision uint64
+var savedRevision uint64
+
+// RevisionStorage is implemented by storages persisting the revision, so that it keeps increasing across restarts
+type RevisionStorage interface {
+	LoadRevision() (uint64, error)
+	SaveRevision(revision uint64) error
+}
+
+// RevisionMeta is the meta information of the responses to changes
+type RevisionMeta struct {
+	// Revision is the revision of the store after the change
+	Revision uint64 `json:"revision"`
+}
+
+// Revision returns the number of changes of the store, it only increases
+func Revision() uint64 {
+	return revision
+}
+
+// revisionChange is closed by the next change of the store, it is created for the first waiter
+var revisionChange chan struct{}
+var revisionChangeMutex sync.Mutex
+
+// RevisionChanged returns a channel closed by the next change of the store
+func RevisionChanged() <-chan struct{} {
+	revisionChangeMutex.Lock()
+	defer revisionChangeMutex.Unlock()
+	if revisionChange == nil {
+		revisionChange = make(chan struct{})
+	}
+	return revisionChange
+}
+
+// changed counts a change of the store and wakes up the waiters for it
+func changed() {
+	revision++
+	revisionChangeMutex.Lock()
+	if revisionChange != nil {
+		close(revisionChange)
+		revisionChange = nil
+	}
+	revisionChangeMutex.Unlock()
+}
+
+// unsaved tells whether the store changed since it was saved last
+func unsaved() bool {
+	return revision != savedRevision
+}
+
+// revisionFileName stores the revision of a CSV storage next to its data file
+func (s CsvStorage) revisionFileName() string {
+	return s.FileName + ".revision"
+}
+
+// LoadRevision reads the revision, a missing file is revision 0
+func (s CsvStorage) LoadRevision() (uint64, error) {
+	content, err := readDataFile(s.revisionFileName())
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+}
+
+// SaveRevision writes the revision as decimal number
+func (s CsvStorage) SaveRevision(revision uint64) error {
+	return replaceDataFile(s.revisionFileName(), []byte(strconv.FormatUint(revision, 10)+"\n"))
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// countingStorage counts the saves of the todos
+type countingStorage struct {
+	saves int
+}
+
+func (s *countingStorage) Load() (map[string]Todo, error) {
+	return map[string]Todo{}, nil
+}
+
+func (s *countingStorage) Save(map[string]Todo) error {
+	s.saves++
+	return nil
+}
+
+func TestUpdateDataInFile_SkipsUnchangedStore(t *testing.T) {
+	// Arrange
+	//
+	counting := &countingStorage{}
+	SetStorage(counting)
+	EnableFilePersistence()
+	defer SetStorage(CsvStorage{FileName: FileName})
+	defer DisableFilePersistence()
+	todo := AddTodo(Todo{Title: "Buy milk", Tags: []string{"shopping"}})
+	UpdateDataInFile()
+	before := Revision()
+
+	// Act
+	//
+	UpdateTodo(todo.Id, Todo{Title: "Buy milk", Tags: []string{"shopping"}})
+	errUnchanged := UpdateDataInFile()
+	unchangedRevision := Revision()
+	UpdateTodo(todo.Id, Todo{Title: "Buy oat milk", Tags: []string{"shopping"}})
+	errChanged := UpdateDataInFile()
+
+	// Assert
+	//
+	if errUnchanged != nil || errChanged != nil || counting.saves != 2 {
+		t.Error("Fehler", counting.saves, errUnchanged, errChanged)
+	}
+	if unchangedRevision != before || Revision() != before+1 {
+		t.Error("Fehler", before, unchangedRevision, Revision())
+	}
+}
+
+func TestTakePendingSave_KeepsLaterChangesUnsaved(t *testing.T) {
+	// Arrange
+	//
+	counting := &countingStorage{}
+	SetStorage(counting)
+	EnableFilePersistence()
+	DeferSaving(true)
+	defer SetStorage(CsvStorage{FileName: FileName})
+	defer DisableFilePersistence()
+	defer DeferSaving(false)
+	todo := AddTodo(Todo{Title: "Buy milk", Tags: []string{"shopping"}})
+
+	// Act
+	//
+	errDeferred := UpdateDataInFile()
+	deferred := counting.saves
+	pending, taken := TakePendingSave()
+	// a change while the copy is written is saved with the next copy
+	UpdateTodo(todo.Id, Todo{Title: "Buy oat milk", Tags: []string{"shopping"}})
+	errWritten := pending.Write()
+	pending.MarkSaved()
+	_, takenAgain := TakePendingSave()
+	errFlushed := SaveData()
+	_, takenAfterFlush := TakePendingSave()
+
+	// Assert
+	//
+	if errDeferred != nil || deferred != 0 || taken == false || errWritten != nil || counting.saves != 2 {
+		t.Error("Fehler", errDeferred, deferred, taken, errWritten, counting.saves)
+	}
+	if takenAgain == false || errFlushed != nil || takenAfterFlush {
+		t.Error("Fehler", takenAgain, errFlushed, takenAfterFlush)
+	}
+}
+
+func TestCsvStorage_RevisionSurvivesInterruptedSave(t *testing.T) {
+	// Arrange
+	//
+	storage := CsvStorage{FileName: filepath.Join(t.TempDir(), "data.csv")}
+	storage.SaveRevision(7)
+	storage.SaveRevision(8)
+	storage.SaveSequences(map[string]int{DefaultList: 3})
+	storage.SaveSequences(map[string]int{DefaultList: 4})
+	// the crash happened after the files were renamed to their backups
+	os.Remove(storage.revisionFileName())
+	os.Remove(storage.sequencesFileName())
+
+	// Act
+	//
+	revision, err := storage.LoadRevision()
+	sequences, sequencesErr := storage.LoadSequences()
+
+	// Assert
+	//
+	if err != nil || revision != 7 {
+		t.Error("Fehler", err, revision)
+	}
+	if sequencesErr != nil || sequences[DefaultList] != 3 {
+		t.Error("Fehler", sequencesErr, sequences)
+	}
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+)
+
+// Settings are the defaults applied to new todos omitting the fields
+type Settings struct {
+	DefaultList string   `json:"default_list"`
+	DefaultTags []string `json:"default_tags"`
+	// DefaultPriority is the priority of new todos omitting it, empty for none
+	DefaultPriority string `json:"default_priority,omitempty"`
+}
+
+// SettingsStorage is implemented by storages persisting the settings
+type SettingsStorage interface {
+	LoadSettings() (*Settings, error)
+	SaveSettings(settings Settings) error
+}
+
+var settings = Settings{DefaultList: DefaultList, DefaultTags: []string{}}
+
+// CurrentSettings returns the defaults of new todos
+func CurrentSettings() Settings {
+	current := settings
+	current.DefaultTags = append([]string{}, settings.DefaultTags...)
+	return current
+}
+
+// SetSettings replaces the defaults of new todos, an empty default list is DefaultList
+func SetSettings(s Settings) error {
+	s.DefaultList = strings.TrimSpace(s.DefaultList)
+	if s.DefaultList == "" {
+		s.DefaultList = DefaultList
+	}
+	tags := []string{}
+	for _, tag := range s.DefaultTags {
+		tag = strings.TrimSpace(tag)
+		err := ValidateTag(tag)
+		if err != nil {
+			return err
+		}
+		tags = append(tags, tag)
+	}
+	s.DefaultTags = tags
+	err := ValidatePriority(s.DefaultPriority)
+	if err != nil {
+		return err
+	}
+	settings = s
+	return nil
+}
+
+// PersistSettings writes the settings to the storage, they replace the configured defaults from then on
+func PersistSettings() error {
+	if settingsStorage, ok := sideStorage().(SettingsStorage); ok {
+		err := settingsStorage.SaveSettings(settings)
+		if err != nil {
+			return err
+		}
+	}
+	if dual, ok := dualWrite(); ok {
+		dual.mirrorSettings(settings)
+	}
+	return nil
+}
+
+// ApplyDefaults sets the default list, tags and priority of a new todo omitting them.
+// A todo created with an empty tag array keeps it.
+func ApplyDefaults(todo Todo) Todo {
+	if todo.List == "" {
+		todo.List = settings.DefaultList
+	}
+	if todo.Tags == nil {
+		todo.Tags = append([]string{}, settings.DefaultTags...)
+	}
+	if todo.Priority == "" {
+		todo.Priority = settings.DefaultPriority
+	}
+	return todo
+}
+
+// settingsFileName stores the settings of a CSV storage next to its data file
+func (s CsvStorage) settingsFileName() string {
+	return s.FileName + ".settings"
+}
+
+// LoadSettings reads the settings, nil if they were never saved
+func (s CsvStorage) LoadSettings() (*Settings, error) {
+	content, err := readDataFile(s.settingsFileName())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var loaded Settings
+	err = json.Unmarshal(content, &loaded)
+	if err != nil {
+		return nil, err
+	}
+	return &loaded, nil
+}
+
+// SaveSettings writes the settings as JSON object
+func (s CsvStorage) SaveSettings(settings Settings) error {
+	content, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	return replaceDataFile(s.settingsFileName(), content)
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	// Arrange
+	//
+	defer SetSettings(Settings{})
+	err := SetSettings(Settings{DefaultList: "work", DefaultTags: []string{"triage"}})
+
+	// Act
+	//
+	omitted := ApplyDefaults(Todo{Title: "Plan sprint"})
+	given := ApplyDefaults(Todo{Title: "Buy milk", List: "shopping", Tags: []string{}})
+
+	// Assert
+	//
+	if err != nil || omitted.List != "work" || reflect.DeepEqual(omitted.Tags, []string{"triage"}) == false {
+		t.Error("Fehler", omitted, err)
+	}
+	if given.List != "shopping" || len(given.Tags) != 0 {
+		t.Error("Fehler", given)
+	}
+}
@@ -0,0 +1,312 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ShardsDirName is the directory of the shards below the data directory
+const ShardsDirName = "shards"
+
+// shardExtension ends the file names of the shards, the backups and manifests next to them end differently
+const shardExtension = ".csv"
+
+// ErrNotSharded is returned for the shard functions if the storage is not sharded
+var ErrNotSharded = errors.New("the storage is not sharded")
+
+// ErrShardHealthy is returned for resetting a shard that was loaded
+var ErrShardHealthy = errors.New("the shard was loaded, only failed shards are reset")
+
+// ErrShardConflict is returned for reloading a shard with todos whose ids belong to other owners
+var ErrShardConflict = errors.New("the shard has todos whose ids belong to other owners")
+
+// ShardedStorage stores the todos of every owner in a CSV file of their own, so that a huge or corrupted
+// dataset of one owner does not affect the others. The todos without owner and the side data are kept in
+// the CSV storage it embeds, the shards of the owners in Dir. A corrupted shard is left out when loading
+// and not written until it is reloaded or reset, the other shards are loaded and saved as usual.
+type ShardedStorage struct {
+	CsvStorage
+	Dir string
+
+	mutex sync.Mutex
+	// saved are the todos last loaded or saved per owner, unchanged shards are not written again
+	saved map[string]map[string]Todo
+	// failed are the errors of the shards that could not be loaded
+	failed map[string]error
+}
+
+// ShardInfo describes the shard of an owner, Error is set for a shard that could not be loaded
+type ShardInfo struct {
+	Owner string `json:"owner"`
+	File  string `json:"file"`
+	Todos int    `json:"todos"`
+	Size  int64  `json:"size"`
+	Error string `json:"error,omitempty"`
+}
+
+// tracked makes sure the saved and failed shards are tracked, the mutex must be held
+func (s *ShardedStorage) tracked() {
+	if s.saved == nil {
+		s.saved = make(map[string]map[string]Todo)
+	}
+	if s.failed == nil {
+		s.failed = make(map[string]error)
+	}
+}
+
+// shardFileName returns the file of the shard of the owner, the owner is escaped to a single file name
+func (s *ShardedStorage) shardFileName(owner string) string {
+	if owner == "" {
+		return s.FileName
+	}
+	escaped := strings.ReplaceAll(url.PathEscape(owner), ".", "%2E")
+	return filepath.Join(s.Dir, escaped+shardExtension)
+}
+
+// owners returns the owners with a shard file and the owner of the todos without owner
+func (s *ShardedStorage) owners() ([]string, error) {
+	owners := []string{""}
+	entries, err := os.ReadDir(s.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return owners, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasSuffix(name, shardExtension) == false {
+			continue
+		}
+		owner, err := url.PathUnescape(strings.TrimSuffix(name, shardExtension))
+		if err != nil || owner == "" {
+			log.Printf("Skipping the file %s in the shards directory, it names no owner", name)
+			continue
+		}
+		owners = append(owners, owner)
+	}
+	return owners, nil
+}
+
+// loadShard reads the todos of the shard of the owner, a missing shard has none
+func (s *ShardedStorage) loadShard(owner string) (map[string]Todo, error) {
+	todos, err := CsvStorage{FileName: s.shardFileName(owner)}.Load()
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]Todo), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// a todo belongs to the shard it is stored in
+	for id, todo := range todos {
+		todo.Owner = owner
+		todos[id] = todo
+	}
+	return todos, nil
+}
+
+// Load reads the todos of all shards. A corrupted shard is logged and left out, errors that would affect all
+// shards like a missing encryption key are returned.
+func (s *ShardedStorage) Load() (map[string]Todo, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	owners, err := s.owners()
+	if err != nil {
+		return nil, err
+	}
+	s.saved = make(map[string]map[string]Todo)
+	s.failed = make(map[string]error)
+	todos := make(map[string]Todo)
+	for _, owner := range owners {
+		shard, err := s.loadShard(owner)
+		if errors.Is(err, ErrCorruptData) {
+			log.Printf("Cannot load the shard of %q, leaving it out until it is reloaded or reset: %v", owner, err)
+			s.failed[owner] = err
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.saved[owner] = shard
+		for id, todo := range shard {
+			todos[id] = todo
+		}
+	}
+	return todos, nil
+}
+
+// Save writes the shards whose todos changed, the shards that failed to load are not written
+func (s *ShardedStorage) Save(todos map[string]Todo) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.tracked()
+
+	shards := make(map[string]map[string]Todo)
+	for owner := range s.saved {
+		shards[owner] = make(map[string]Todo)
+	}
+	for id, todo := range todos {
+		if shards[todo.Owner] == nil {
+			shards[todo.Owner] = make(map[string]Todo)
+		}
+		shards[todo.Owner][id] = todo
+	}
+
+	for owner, shard := range shards {
+		if err, ok := s.failed[owner]; ok {
+			log.Printf("Not saving the shard of %q, it failed to load: %v", owner, err)
+			continue
+		}
+		if saved, ok := s.saved[owner]; ok && reflect.DeepEqual(saved, shard) {
+			continue
+		}
+		if owner != "" {
+			err := os.MkdirAll(s.Dir, 0700)
+			if err != nil {
+				return err
+			}
+		}
+		err := CsvStorage{FileName: s.shardFileName(owner)}.Save(shard)
+		if err != nil {
+			return fmt.Errorf("cannot save the shard of %q: %w", owner, err)
+		}
+		s.saved[owner] = shard
+	}
+	return nil
+}
+
+// info describes the shard of the owner, the mutex must be held
+func (s *ShardedStorage) info(owner string) ShardInfo {
+	info := ShardInfo{Owner: owner, File: s.shardFileName(owner), Todos: len(s.saved[owner])}
+	if stat, err := os.Stat(info.File); err == nil {
+		info.Size = stat.Size()
+	}
+	if err, ok := s.failed[owner]; ok {
+		info.Error = err.Error()
+	}
+	return info
+}
+
+// shardedStorage returns the storage if the todos are saved to a sharded storage
+func shardedStorage() (*ShardedStorage, bool) {
+	sharded, ok := storage.(*ShardedStorage)
+	return sharded, ok && filePersistence
+}
+
+// Shards describes the shards of the sharded storage ordered by owner, the todos without owner come first
+func Shards() ([]ShardInfo, error) {
+	sharded, ok := shardedStorage()
+	if ok == false {
+		return nil, ErrNotSharded
+	}
+	owners, err := sharded.owners()
+	if err != nil {
+		return nil, err
+	}
+
+	sharded.mutex.Lock()
+	defer sharded.mutex.Unlock()
+	for owner := range sharded.saved {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+	var shards []ShardInfo
+	for i, owner := range owners {
+		if i > 0 && owners[i-1] == owner {
+			continue
+		}
+		shards = append(shards, sharded.info(owner))
+	}
+	return shards, nil
+}
+
+// ReloadShard reads the shard of the owner again, like after its file was restored, and replaces the todos
+// of the owner in the store with its todos. The store must be held.
+func ReloadShard(shardOwner string) (ShardInfo, error) {
+	sharded, ok := shardedStorage()
+	if ok == false {
+		return ShardInfo{}, ErrNotSharded
+	}
+
+	sharded.mutex.Lock()
+	defer sharded.mutex.Unlock()
+	sharded.tracked()
+	// reloading a missing shard would remove the todos of the owner
+	if _, err := os.Stat(sharded.shardFileName(shardOwner)); err != nil {
+		return sharded.info(shardOwner), err
+	}
+	shard, err := sharded.loadShard(shardOwner)
+	if err != nil {
+		if errors.Is(err, ErrCorruptData) {
+			sharded.failed[shardOwner] = err
+		}
+		return sharded.info(shardOwner), err
+	}
+	for id := range shard {
+		if stored, ok := repository.Get(id); ok && stored.Owner != shardOwner {
+			return sharded.info(shardOwner), fmt.Errorf("%w: %s", ErrShardConflict, id)
+		}
+	}
+
+	for _, todo := range repository.List() {
+		if todo.Owner != shardOwner {
+			continue
+		}
+		if _, ok := shard[todo.Id]; ok == false {
+			err = repository.Delete(todo.Id)
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+	for _, todo := range shard {
+		err = repository.Update(todo)
+		if err != nil {
+			panic(err)
+		}
+	}
+	changed()
+	delete(sharded.failed, shardOwner)
+	sharded.saved[shardOwner] = shard
+	sequences := make(map[string]int)
+	for list, sequence := range listSequences {
+		sequences[list] = sequence
+	}
+	initializeNumbers(sequences)
+	return sharded.info(shardOwner), nil
+}
+
+// ResetShard gives up the shard of the owner that failed to load: its file is kept with the suffix .corrupt
+// and the shard is written with the todos the owner has in the store from the next save on.
+func ResetShard(shardOwner string) (ShardInfo, error) {
+	sharded, ok := shardedStorage()
+	if ok == false {
+		return ShardInfo{}, ErrNotSharded
+	}
+
+	sharded.mutex.Lock()
+	defer sharded.mutex.Unlock()
+	sharded.tracked()
+	if _, failed := sharded.failed[shardOwner]; failed == false {
+		return sharded.info(shardOwner), ErrShardHealthy
+	}
+	fileName := sharded.shardFileName(shardOwner)
+	err := os.Rename(fileName, fileName+".corrupt")
+	if err != nil && errors.Is(err, os.ErrNotExist) == false {
+		return sharded.info(shardOwner), err
+	}
+	log.Printf("The corrupted shard of %q is kept as %s.corrupt", shardOwner, fileName)
+	delete(sharded.failed, shardOwner)
+	delete(sharded.saved, shardOwner)
+	changed()
+	return sharded.info(shardOwner), nil
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestShardedStorage_LeavesOutTheCorruptedShard(t *testing.T) {
+	// Arrange
+	//
+	dir := t.TempDir()
+	storage := &ShardedStorage{CsvStorage: CsvStorage{FileName: filepath.Join(dir, FileName)}, Dir: filepath.Join(dir, ShardsDirName)}
+	err := storage.Save(map[string]Todo{
+		"0": {Id: "0", Title: "Shared", Tags: []string{}},
+		"1": {Id: "1", Title: "Of Anna", Owner: "anna", Tags: []string{}},
+		"2": {Id: "2", Title: "Of Bob", Owner: "bob", Tags: []string{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobsShard := filepath.Join(storage.Dir, "bob.csv")
+	os.WriteFile(bobsShard, []byte("2,Trunc"), 0600)
+	reopened := &ShardedStorage{CsvStorage: storage.CsvStorage, Dir: storage.Dir}
+
+	// Act
+	//
+	loaded, loadErr := reopened.Load()
+	saveErr := reopened.Save(map[string]Todo{
+		"0": {Id: "0", Title: "Shared", Tags: []string{}},
+		"1": {Id: "1", Title: "Of Anna, changed", Owner: "anna", Tags: []string{}},
+		"3": {Id: "3", Title: "New of Bob", Owner: "bob", Tags: []string{}},
+	})
+	bob, _ := os.ReadFile(bobsShard)
+	anna, annaErr := CsvStorage{FileName: filepath.Join(storage.Dir, "anna.csv")}.Load()
+
+	// Assert
+	//
+	if loadErr != nil || len(loaded) != 2 || loaded["1"].Owner != "anna" || loaded["0"].Owner != "" {
+		t.Error("Fehler", loadErr, loaded)
+	}
+	if saveErr != nil || string(bob) != "2,Trunc" {
+		t.Error("Fehler", saveErr, string(bob))
+	}
+	if annaErr != nil || anna["1"].Title != "Of Anna, changed" {
+		t.Error("Fehler", annaErr, anna)
+	}
+}
+
+func TestResetShard_SavesTheTodosOfTheOwnerAnew(t *testing.T) {
+	// Arrange
+	//
+	dir := t.TempDir()
+	sharded := &ShardedStorage{CsvStorage: CsvStorage{FileName: filepath.Join(dir, FileName)}, Dir: filepath.Join(dir, ShardsDirName)}
+	os.Mkdir(sharded.Dir, 0700)
+	os.WriteFile(filepath.Join(sharded.Dir, "bob.csv"), []byte("2,Trunc"), 0600)
+	defer SetRepository(NewMemoryRepository())
+	defer SetStorage(CsvStorage{FileName: FileName})
+	defer DisableFilePersistence()
+	SetRepository(NewMemoryRepository())
+	SetStorage(sharded)
+	EnableFilePersistence()
+	Initialize()
+	SetOwner("bob")
+	AddTodo(Todo{Title: "Call Anna"})
+	SetOwner("")
+
+	// Act
+	//
+	healthy, healthyErr := ResetShard("anna")
+	shard, err := ResetShard("bob")
+	saveErr := SaveData()
+	corrupt, _ := os.ReadFile(filepath.Join(sharded.Dir, "bob.csv.corrupt"))
+	bob, bobErr := CsvStorage{FileName: filepath.Join(sharded.Dir, "bob.csv")}.Load()
+
+	// Assert
+	//
+	if healthyErr != ErrShardHealthy || healthy.Owner != "anna" {
+		t.Error("Fehler", healthy, healthyErr)
+	}
+	if err != nil || shard.Error != "" || saveErr != nil || string(corrupt) != "2,Trunc" {
+		t.Error("Fehler", shard, err, saveErr, string(corrupt))
+	}
+	if bobErr != nil || len(bob) != 1 {
+		t.Error("Fehler", bobErr, bob)
+	}
+}
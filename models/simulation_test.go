@@ -0,0 +1,160 @@
+package models
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+	"todo-rest-backend/clock"
+)
+
+// simulationTexts are titles and descriptions testing the escaping of the storages
+var simulationTexts = []string{"Buy milk", "", "Call \"Anna\", then Ben", "Line one\nline two", "Äpfel & Birnen",
+	"  padded  ", "comma,separated", "emoji 🚀", `back\slash`}
+
+var simulationTags = []string{"work", "home", "urgent", "zürich", "a b"}
+
+// randomTodo returns a todo with random fields clients can set
+func randomTodo(random *rand.Rand) Todo {
+	todo := Todo{
+		Title:       simulationTexts[random.Intn(len(simulationTexts))],
+		Description: simulationTexts[random.Intn(len(simulationTexts))],
+		Terminated:  random.Intn(3) == 0,
+		Tags:        []string{},
+	}
+	for _, tag := range simulationTags {
+		if random.Intn(3) == 0 {
+			todo.Tags = append(todo.Tags, tag)
+		}
+	}
+	if random.Intn(2) == 0 {
+		todo.Priority = []string{PriorityLow, PriorityMedium, PriorityHigh}[random.Intn(3)]
+	}
+	if random.Intn(2) == 0 {
+		todo.DueDate = fmt.Sprintf("2024-05-%02d", 1+random.Intn(28))
+	}
+	if random.Intn(4) == 0 {
+		todo.List = []string{"work", "shopping"}[random.Intn(2)]
+	}
+	return todo
+}
+
+// TestSimulation applies random sequences of changes and restarts to the store and checks its invariants:
+// ids are unique and never reused, the store holds what the changes left and a restart loads exactly what was
+// saved. The seeds are fixed, so that failures can be reproduced; TODO_SIMULATION_SEED and
+// TODO_SIMULATION_STEPS run other sequences.
+func TestSimulation(t *testing.T) {
+	seeds := []int64{1, 2, 3, 4, 5}
+	if seed := os.Getenv("TODO_SIMULATION_SEED"); seed != "" {
+		parsed, err := strconv.ParseInt(seed, 10, 64)
+		if err != nil {
+			t.Fatal("TODO_SIMULATION_SEED must be a number")
+		}
+		seeds = []int64{parsed}
+	}
+	steps := 300
+	if value := os.Getenv("TODO_SIMULATION_STEPS"); value != "" {
+		steps, _ = strconv.Atoi(value)
+	}
+
+	defer SetRepository(NewMemoryRepository())
+	defer SetStorage(CsvStorage{FileName: FileName})
+	defer DisableFilePersistence()
+	defer SetClock(clk)
+
+	for _, seed := range seeds {
+		t.Run(fmt.Sprint("seed ", seed), func(t *testing.T) {
+			simulate(t, rand.New(rand.NewSource(seed)), steps)
+		})
+	}
+}
+
+func simulate(t *testing.T, random *rand.Rand, steps int) {
+	fake := clock.NewFake(time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC))
+	SetClock(fake)
+	SetStorage(CsvStorage{FileName: filepath.Join(t.TempDir(), "data.csv")})
+	EnableFilePersistence()
+	SetRepository(NewMemoryRepository())
+	listSequences = make(map[string]int)
+	Initialize()
+
+	// expected holds the fields set by the clients, assigned the ids ever handed out
+	expected := make(map[string]Todo)
+	assigned := make(map[string]bool)
+	randomId := func() string {
+		ids := []string{"unknown"}
+		for id := range expected {
+			ids = append(ids, id)
+		}
+		// map order is random, sorted ids keep the simulation deterministic
+		sort.Strings(ids[1:])
+		return ids[random.Intn(len(ids))]
+	}
+
+	for step := 0; step < steps; step++ {
+		fake.Advance(time.Duration(random.Intn(3600)) * time.Second)
+		operation := random.Intn(20)
+		switch {
+		case operation < 8:
+			added := AddTodo(randomTodo(random))
+			if assigned[added.Id] {
+				t.Fatalf("step %d: id %s was assigned again", step, added.Id)
+			}
+			assigned[added.Id] = true
+			expected[added.Id] = added
+		case operation < 13:
+			id := randomId()
+			updated, ok := UpdateTodo(id, randomTodo(random))
+			if ok != (id != "unknown") {
+				t.Fatalf("step %d: updating %s returned %v", step, id, ok)
+			}
+			if ok {
+				expected[id] = updated
+			}
+		case operation < 17:
+			id := randomId()
+			if RemoveTodo(id) != (id != "unknown") {
+				t.Fatalf("step %d: removing %s failed", step, id)
+			}
+			delete(expected, id)
+		case operation < 18:
+			DeleteAllTodos()
+			expected = make(map[string]Todo)
+		default:
+			before := TodoStore()
+			err := UpdateDataInFile()
+			if err != nil {
+				t.Fatalf("step %d: saving failed: %v", step, err)
+			}
+			// a restart starts with an empty store and loads the saved todos
+			SetRepository(NewMemoryRepository())
+			listSequences = make(map[string]int)
+			Initialize()
+			if after := TodoStore(); reflect.DeepEqual(before, after) == false {
+				t.Fatalf("step %d: the restart changed the todos\nbefore %v\nafter  %v", step, before, after)
+			}
+		}
+
+		// removed todos stay in the trash, they are not listed
+		todos := make(map[string]Todo)
+		for _, todo := range AllTodos() {
+			todos[todo.Id] = todo
+		}
+		if len(todos) != len(expected) {
+			t.Fatalf("step %d: the store has %d todos, want %d", step, len(todos), len(expected))
+		}
+		for id, want := range expected {
+			got, ok := todos[id]
+			if ok == false || got.Title != want.Title || got.Description != want.Description ||
+				got.Terminated != want.Terminated || reflect.DeepEqual(got.Tags, want.Tags) == false ||
+				got.Priority != want.Priority || got.DueDate != want.DueDate || got.List != want.List {
+				t.Fatalf("step %d: todo %s is %v, want %v", step, id, got, want)
+			}
+		}
+	}
+}
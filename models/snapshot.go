@@ -0,0 +1,185 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SnapshotDirName is the directory of the snapshots taken before deleting all todos, below the data directory
+const SnapshotDirName = "snapshots"
+
+var (
+	// ErrUnknownSnapshot is returned for snapshots that were never taken, already restored or purged
+	ErrUnknownSnapshot = errors.New("unknown snapshot")
+	// ErrSnapshotExpired is returned for snapshots older than the restore window
+	ErrSnapshotExpired = errors.New("the restore window of the snapshot is over")
+)
+
+// snapshotWindow is how long a snapshot can be restored
+var snapshotWindow = 24 * time.Hour
+
+// SetSnapshotWindow sets how long a snapshot can be restored
+func SetSnapshotWindow(window time.Duration) {
+	snapshotWindow = window
+}
+
+// snapshotIdPattern are the ids handed out by TakeSnapshot, it keeps other paths out of the snapshot directory
+var snapshotIdPattern = regexp.MustCompile(`^[0-9]{14}-[0-9a-f]{8}$`)
+
+// Snapshot describes a snapshot of the todos
+type Snapshot struct {
+	Id        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Todos     int       `json:"todos"`
+}
+
+// snapshotFile is the content of a snapshot, the todos are kept as the rows of the CSV storage
+type snapshotFile struct {
+	CreatedAt        time.Time         `json:"created_at"`
+	Owner            string            `json:"owner,omitempty"`
+	Todos            [][]string        `json:"todos"`
+	Sequences        map[string]int    `json:"sequences"`
+	PomodoroSessions []PomodoroSession `json:"pomodoro_sessions"`
+}
+
+func snapshotPath(id string) string {
+	return DataPath(filepath.Join(SnapshotDirName, id+".json"))
+}
+
+// TakeSnapshot writes the todos with their list sequences and pomodoro sessions to a recovery file,
+// expired snapshots are removed
+func TakeSnapshot() (Snapshot, error) {
+	purgeSnapshots()
+
+	random := make([]byte, 4)
+	_, err := rand.Read(random)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	createdAt := Now().UTC().Truncate(time.Second)
+	snapshot := Snapshot{Id: createdAt.Format("20060102150405") + "-" + hex.EncodeToString(random),
+		CreatedAt: createdAt, ExpiresAt: createdAt.Add(snapshotWindow)}
+
+	content := snapshotFile{CreatedAt: createdAt, Owner: owner, Sequences: listSequences, PomodoroSessions: pomodoroSessions}
+	// the trash is part of the snapshot, restored todos stay in it
+	for _, todo := range ownedTodos(func(Todo) bool { return true }) {
+		content.Todos = append(content.Todos, sealTodo(todo).Serialize())
+	}
+	snapshot.Todos = len(content.Todos)
+	encoded, err := json.Marshal(content)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	err = os.MkdirAll(DataPath(SnapshotDirName), 0700)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return snapshot, writeDataFile(snapshotPath(snapshot.Id), encoded)
+}
+
+// RestoreSnapshot adds the todos of the snapshot back to the store, returns them and removes the snapshot.
+// Todos created since keep their ids and list numbers, the sequences continued after the snapshot.
+// The todos restored before a failure stay in the store, callers undo them with a Transaction.
+func RestoreSnapshot(id string) (Snapshot, []Todo, error) {
+	if snapshotIdPattern.MatchString(id) == false {
+		return Snapshot{}, nil, ErrUnknownSnapshot
+	}
+	encoded, err := os.ReadFile(snapshotPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return Snapshot{}, nil, ErrUnknownSnapshot
+	}
+	if err != nil {
+		return Snapshot{}, nil, err
+	}
+	var content snapshotFile
+	err = json.Unmarshal(encoded, &content)
+	if err != nil {
+		return Snapshot{}, nil, err
+	}
+	// the snapshots of other users are not revealed
+	if owner != "" && content.Owner != owner {
+		return Snapshot{}, nil, ErrUnknownSnapshot
+	}
+	snapshot := Snapshot{Id: id, CreatedAt: content.CreatedAt, ExpiresAt: content.CreatedAt.Add(snapshotWindow),
+		Todos: len(content.Todos)}
+	if Now().After(snapshot.ExpiresAt) {
+		os.Remove(snapshotPath(id))
+		return snapshot, nil, ErrSnapshotExpired
+	}
+
+	var restoredTodos []Todo
+	restored := make(map[string]bool)
+	for _, row := range content.Todos {
+		todo, err := openTodo(parseTodoData(row))
+		if err != nil {
+			return snapshot, nil, err
+		}
+		if _, ok := repository.Get(todo.Id); ok {
+			continue
+		}
+		err = repository.Add(todo)
+		if err != nil {
+			return snapshot, nil, err
+		}
+		changed()
+		restored[todo.Id] = true
+		restoredTodos = append(restoredTodos, todo)
+	}
+	for list, sequence := range content.Sequences {
+		if sequence > listSequences[list] {
+			listSequences[list] = sequence
+		}
+	}
+	// the removal cut the sessions off from their todos, they are linked again
+	for _, session := range content.PomodoroSessions {
+		if restored[session.TodoId] == false {
+			continue
+		}
+		linked := false
+		for i := range pomodoroSessions {
+			current := &pomodoroSessions[i]
+			if current.TodoId == "" && current.StartedAt.Equal(session.StartedAt) {
+				current.TodoId, linked = session.TodoId, true
+				break
+			}
+		}
+		if linked == false {
+			if session.EndedAt == nil {
+				session.EndedAt = &content.CreatedAt
+			}
+			pomodoroSessions = append(pomodoroSessions, session)
+		}
+	}
+
+	err = os.Remove(snapshotPath(id))
+	if err != nil {
+		return snapshot, nil, err
+	}
+	return snapshot, restoredTodos, nil
+}
+
+// purgeSnapshots removes the snapshots whose restore window is over
+func purgeSnapshots() {
+	entries, err := os.ReadDir(DataPath(SnapshotDirName))
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		createdAt, err := time.Parse("20060102150405", strings.SplitN(entry.Name(), "-", 2)[0])
+		if err == nil && Now().After(createdAt.Add(snapshotWindow)) {
+			err = os.Remove(filepath.Join(DataPath(SnapshotDirName), entry.Name()))
+			if err != nil {
+				log.Println("Cannot remove the expired snapshot:", err)
+			}
+		}
+	}
+}
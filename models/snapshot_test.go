@@ -0,0 +1,71 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"todo-rest-backend/clock"
+)
+
+func TestRestoreSnapshot_AddsDeletedTodosBack(t *testing.T) {
+	// Arrange
+	//
+	DeleteAllTodos()
+	defer DeleteAllTodos()
+	defer func() { dataDir = "" }()
+	dataDir = t.TempDir()
+	defer SetClock(clk)
+	fake := clock.NewFake(time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC))
+	SetClock(fake)
+	deleted := AddTodo(Todo{Title: "Buy milk", List: "shopping"})
+	snapshot, err := TakeSnapshot()
+	DeleteAllTodos()
+	created := AddTodo(Todo{Title: "Call Anna", List: "shopping"})
+
+	// Act
+	//
+	fake.Advance(time.Hour)
+	restored, todos, errRestore := RestoreSnapshot(snapshot.Id)
+	_, _, errAgain := RestoreSnapshot(snapshot.Id)
+
+	// Assert
+	//
+	if err != nil || errRestore != nil || restored.Todos != 1 || len(todos) != 1 || todos[0].Id != deleted.Id {
+		t.Fatal("Fehler", restored, todos, err, errRestore)
+	}
+	if todo, ok := FindTodo(deleted.Id); ok == false || todo.Number != deleted.Number || len(AllTodos()) != 2 {
+		t.Error("Fehler", AllTodos())
+	}
+	if todo, _ := FindTodo(created.Id); todo.Number == deleted.Number {
+		t.Error("Fehler: the list numbers collide", todo)
+	}
+	if errors.Is(errAgain, ErrUnknownSnapshot) == false {
+		t.Error("Fehler", errAgain)
+	}
+}
+
+func TestRestoreSnapshot_RefusesExpiredSnapshot(t *testing.T) {
+	// Arrange
+	//
+	DeleteAllTodos()
+	defer DeleteAllTodos()
+	defer func() { dataDir = "" }()
+	dataDir = t.TempDir()
+	defer SetClock(clk)
+	fake := clock.NewFake(time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC))
+	SetClock(fake)
+	AddTodo(Todo{Title: "Buy milk"})
+	snapshot, _ := TakeSnapshot()
+
+	// Act
+	//
+	fake.Advance(25 * time.Hour)
+	_, _, err := RestoreSnapshot(snapshot.Id)
+	_, _, errPath := RestoreSnapshot("../data")
+
+	// Assert
+	//
+	if errors.Is(err, ErrSnapshotExpired) == false || errors.Is(errPath, ErrUnknownSnapshot) == false {
+		t.Error("Fehler", err, errPath)
+	}
+}
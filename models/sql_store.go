@@ -0,0 +1,331 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// todoColumns are the columns of the todos table in the order of the queries
+var todoColumns = []string{"id", "title", "description", "terminated", "external_ref", "tags", "auto_tags",
+	"completed_at", "latitude", "longitude", "place", "list", "number", "position", "status", "due_date",
+	"estimate_minutes", "habit_since", "habit_days", "goal_id", "waiting_on", "waiting_since", "nudged_at",
+	"created_at", "priority", "items", "owner", "deleted_at", "updated_at"}
+
+// SqlRepository stores the todos in a SQL database, one row per todo. The sequences, pomodoro sessions,
+// goals, revision and settings are stored as JSON documents in the documents table.
+//
+// Database errors are raised as panics, see Repository.
+type SqlRepository struct {
+	db *sql.DB
+	// placeholder returns the parameter placeholder of the dialect for the 1-based index
+	placeholder func(index int) string
+	// statements of the CRUD operations, prepared by prepare
+	get, list, add, update, remove, removeAll *sql.Stmt
+}
+
+// migrate brings the schema to the latest version. The version is recorded in the schema_version table,
+// every migration runs in its own transaction.
+func (r *SqlRepository) migrate(migrations []string) error {
+	_, err := r.db.Exec("CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL)")
+	if err != nil {
+		return err
+	}
+
+	version := 0
+	err = r.db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&version)
+	if err != nil {
+		return err
+	}
+	for next := version + 1; next <= len(migrations); next++ {
+		tx, err := r.db.Begin()
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(migrations[next-1])
+		if err == nil {
+			_, err = tx.Exec("INSERT INTO schema_version (version) VALUES ("+r.placeholder(1)+")", next)
+		}
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("schema migration %d failed: %w", next, err)
+		}
+		err = tx.Commit()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prepare prepares the statements of the CRUD operations once the schema is migrated
+func (r *SqlRepository) prepare() error {
+	var assignments []string
+	for i, column := range todoColumns[1:] {
+		assignments = append(assignments, column+" = "+r.placeholder(i+1))
+	}
+	columns := strings.Join(todoColumns, ", ")
+
+	queries := []struct {
+		statement **sql.Stmt
+		query     string
+	}{
+		{&r.get, "SELECT " + columns + " FROM todos WHERE id = " + r.placeholder(1)},
+		{&r.list, "SELECT " + columns + " FROM todos"},
+		{&r.add, "INSERT INTO todos (" + columns + ") VALUES (" + r.placeholders(len(todoColumns)) + ")"},
+		{&r.update, "UPDATE todos SET " + strings.Join(assignments, ", ") +
+			" WHERE id = " + r.placeholder(len(todoColumns))},
+		{&r.remove, "DELETE FROM todos WHERE id = " + r.placeholder(1)},
+		{&r.removeAll, "DELETE FROM todos"},
+	}
+	for _, q := range queries {
+		statement, err := r.db.Prepare(q.query)
+		if err != nil {
+			return err
+		}
+		*q.statement = statement
+	}
+	return nil
+}
+
+// placeholders returns the placeholders for count parameters separated by commas
+func (r *SqlRepository) placeholders(count int) string {
+	var placeholders []string
+	for i := 1; i <= count; i++ {
+		placeholders = append(placeholders, r.placeholder(i))
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+// Close closes the database
+func (r *SqlRepository) Close() error {
+	return r.db.Close()
+}
+
+func (r *SqlRepository) Get(id string) (Todo, bool) {
+	todo, err := scanTodo(r.get.QueryRow(id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Todo{}, false
+	}
+	if err != nil {
+		panic(err)
+	}
+	return todo, true
+}
+
+func (r *SqlRepository) List() []Todo {
+	rows, err := r.list.Query()
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+
+	todos := []Todo{}
+	for rows.Next() {
+		todo, err := scanTodo(rows)
+		if err != nil {
+			panic(err)
+		}
+		todos = append(todos, todo)
+	}
+	if rows.Err() != nil {
+		panic(rows.Err())
+	}
+	return todos
+}
+
+func (r *SqlRepository) Add(todo Todo) error {
+	_, err := r.add.Exec(todoValues(todo)...)
+	return err
+}
+
+func (r *SqlRepository) Update(todo Todo) error {
+	_, err := r.update.Exec(append(todoValues(todo)[1:], todo.Id)...)
+	return err
+}
+
+func (r *SqlRepository) Delete(id string) error {
+	_, err := r.remove.Exec(id)
+	return err
+}
+
+func (r *SqlRepository) DeleteAll() error {
+	_, err := r.removeAll.Exec()
+	return err
+}
+
+// todoValues returns the values of the todo in the order of todoColumns, the description encrypted
+func todoValues(t Todo) []interface{} {
+	t = sealTodo(t)
+	var latitude, longitude *float64
+	place := ""
+	if t.Location != nil {
+		latitude, longitude, place = t.Location.Latitude, t.Location.Longitude, t.Location.Place
+	}
+	return []interface{}{t.Id, t.Title, t.Description, t.Terminated, t.ExternalRef, jsonList(t.Tags),
+		jsonList(t.AutoTags), nullTime(t.CompletedAt), latitude, longitude, place, t.List, t.Number, t.Position,
+		t.Status, t.DueDate, t.EstimateMinutes, t.HabitSince, jsonList(t.HabitDays), t.GoalId, t.WaitingOn,
+		nullTime(t.WaitingSince), nullTime(t.NudgedAt), nullTime(t.CreatedAt),
+		t.Priority, serializeItems(t.Items), t.Owner, nullTime(t.DeletedAt), nullTime(t.UpdatedAt)}
+}
+
+// rowScanner is a single row or the current row of a query
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTodo reads a row selected with todoColumns
+func scanTodo(row rowScanner) (Todo, error) {
+	var t Todo
+	var tags, autoTags, habitDays, items string
+	var completedAt, waitingSince, nudgedAt, createdAt, deletedAt, updatedAt sql.NullTime
+	var latitude, longitude sql.NullFloat64
+	var place string
+	err := row.Scan(&t.Id, &t.Title, &t.Description, &t.Terminated, &t.ExternalRef, &tags, &autoTags,
+		&completedAt, &latitude, &longitude, &place, &t.List, &t.Number, &t.Position, &t.Status, &t.DueDate,
+		&t.EstimateMinutes, &t.HabitSince, &habitDays, &t.GoalId, &t.WaitingOn, &waitingSince, &nudgedAt,
+		&createdAt, &t.Priority, &items, &t.Owner, &deletedAt, &updatedAt)
+	if err != nil {
+		return Todo{}, err
+	}
+
+	t, err = openTodo(t)
+	if err != nil {
+		return Todo{}, err
+	}
+	t.Tags = parseJsonList(tags)
+	if t.Tags == nil {
+		t.Tags = []string{}
+	}
+	t.AutoTags = parseJsonList(autoTags)
+	t.HabitDays = parseJsonList(habitDays)
+	t.Items = parseItems(items)
+	t.Habit = t.HabitSince != ""
+	t.CompletedAt = timeOf(completedAt)
+	t.WaitingSince = timeOf(waitingSince)
+	t.NudgedAt = timeOf(nudgedAt)
+	t.CreatedAt = timeOf(createdAt)
+	t.DeletedAt = timeOf(deletedAt)
+	t.UpdatedAt = timeOf(updatedAt)
+	if latitude.Valid || longitude.Valid || place != "" {
+		t.Location = &Location{Place: place}
+		if latitude.Valid && longitude.Valid {
+			t.Location.Latitude, t.Location.Longitude = &latitude.Float64, &longitude.Float64
+		}
+	}
+	return t, nil
+}
+
+// jsonList encodes a list as JSON array, nil and empty lists are both "[]"
+func jsonList(values []string) string {
+	if len(values) == 0 {
+		return "[]"
+	}
+	encoded, _ := json.Marshal(values)
+	return string(encoded)
+}
+
+// parseJsonList decodes a JSON array, an empty array is nil
+func parseJsonList(encoded string) []string {
+	var values []string
+	json.Unmarshal([]byte(encoded), &values)
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func timeOf(t sql.NullTime) *time.Time {
+	if t.Valid == false {
+		return nil
+	}
+	value := t.Time.UTC()
+	return &value
+}
+
+// loadDocument decodes the JSON document with the name into value, a missing document leaves value unchanged
+func (r *SqlRepository) loadDocument(name string, value interface{}) (bool, error) {
+	var content string
+	err := r.db.QueryRow("SELECT content FROM documents WHERE name = "+r.placeholder(1), name).Scan(&content)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal([]byte(content), value)
+}
+
+// saveDocument stores value as JSON document with the name
+func (r *SqlRepository) saveDocument(name string, value interface{}) error {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec("INSERT INTO documents (name, content) VALUES ("+r.placeholders(2)+")"+
+		" ON CONFLICT (name) DO UPDATE SET content = excluded.content", name, string(content))
+	return err
+}
+
+func (r *SqlRepository) LoadSequences() (map[string]int, error) {
+	sequences := make(map[string]int)
+	_, err := r.loadDocument("sequences", &sequences)
+	return sequences, err
+}
+
+func (r *SqlRepository) SaveSequences(sequences map[string]int) error {
+	return r.saveDocument("sequences", sequences)
+}
+
+func (r *SqlRepository) LoadPomodoroSessions() ([]PomodoroSession, error) {
+	var sessions []PomodoroSession
+	_, err := r.loadDocument("pomodoro", &sessions)
+	return sessions, err
+}
+
+func (r *SqlRepository) SavePomodoroSessions(sessions []PomodoroSession) error {
+	return r.saveDocument("pomodoro", sessions)
+}
+
+func (r *SqlRepository) LoadGoals() ([]Goal, error) {
+	var goals []Goal
+	_, err := r.loadDocument("goals", &goals)
+	return goals, err
+}
+
+func (r *SqlRepository) SaveGoals(goals []Goal) error {
+	return r.saveDocument("goals", goals)
+}
+
+func (r *SqlRepository) LoadRevision() (uint64, error) {
+	var revision uint64
+	_, err := r.loadDocument("revision", &revision)
+	return revision, err
+}
+
+func (r *SqlRepository) SaveRevision(revision uint64) error {
+	return r.saveDocument("revision", revision)
+}
+
+func (r *SqlRepository) LoadSettings() (*Settings, error) {
+	var settings Settings
+	found, err := r.loadDocument("settings", &settings)
+	if err != nil || found == false {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (r *SqlRepository) SaveSettings(settings Settings) error {
+	return r.saveDocument("settings", settings)
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSqliteRepository_RoundTrip(t *testing.T) {
+	// Arrange
+	//
+	path := filepath.Join(t.TempDir(), "todos.db")
+	repository, err := OpenSqliteRepository(path)
+	if err != nil {
+		t.Fatal("Fehler", err)
+	}
+	latitude, longitude := 47.37, 8.54
+	created := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+	todo := Todo{Id: "1", Title: "Buy milk", Description: "2 litres", Tags: []string{"home", "shop"},
+		Location: &Location{Latitude: &latitude, Longitude: &longitude, Place: "Zurich"}, List: "shopping",
+		Number: 3, Status: "todo", DueDate: "2024-03-02", EstimateMinutes: 15, Habit: true,
+		HabitSince: "2024-03-01", HabitDays: []string{"2024-03-01"}, CreatedAt: &created, Priority: PriorityHigh,
+		Items: []ChecklistItem{{Id: "1", Title: "Oat milk", Done: true}}}
+
+	// Act
+	//
+	err = repository.Add(todo)
+	if err == nil {
+		err = repository.SaveSequences(map[string]int{"shopping": 3})
+	}
+	repository.Close()
+	reopened, openErr := OpenSqliteRepository(path)
+	if openErr != nil {
+		t.Fatal("Fehler", openErr)
+	}
+	defer reopened.Close()
+	loaded, ok := reopened.Get("1")
+	sequences, sequencesErr := reopened.LoadSequences()
+
+	// Assert
+	//
+	if err != nil || ok == false || reflect.DeepEqual(loaded, todo) == false {
+		t.Error("Fehler", loaded, err)
+	}
+	if sequencesErr != nil || sequences["shopping"] != 3 {
+		t.Error("Fehler", sequences, sequencesErr)
+	}
+}
+
+func TestOpenPostgresRepository_Unreachable(t *testing.T) {
+	// Arrange
+	//
+	url := "postgres://todos@127.0.0.1:1/todos?sslmode=disable"
+
+	// Act
+	//
+	started := time.Now()
+	repository, err := OpenPostgresRepository(url, 2)
+
+	// Assert
+	//
+	if err == nil || repository != nil || time.Since(started) > PostgresHealthCheckTimeout {
+		t.Error("Fehler", err)
+	}
+}
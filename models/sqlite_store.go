@@ -0,0 +1,66 @@
+package models
+
+import (
+	"database/sql"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteMigrations are the schema versions of the SQLite database, new versions are appended
+var sqliteMigrations = []string{
+	`CREATE TABLE todos (
+		id TEXT PRIMARY KEY,
+		title TEXT NOT NULL,
+		description TEXT NOT NULL,
+		terminated BOOLEAN NOT NULL,
+		external_ref TEXT NOT NULL,
+		tags TEXT NOT NULL,
+		auto_tags TEXT NOT NULL,
+		completed_at TIMESTAMP,
+		latitude REAL,
+		longitude REAL,
+		place TEXT NOT NULL,
+		list TEXT NOT NULL,
+		number INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		status TEXT NOT NULL,
+		due_date TEXT NOT NULL,
+		estimate_minutes INTEGER NOT NULL,
+		habit_since TEXT NOT NULL,
+		habit_days TEXT NOT NULL,
+		goal_id TEXT NOT NULL,
+		waiting_on TEXT NOT NULL,
+		waiting_since TIMESTAMP,
+		nudged_at TIMESTAMP,
+		created_at TIMESTAMP
+	);
+	CREATE TABLE documents (
+		name TEXT PRIMARY KEY,
+		content TEXT NOT NULL
+	);`,
+	`ALTER TABLE todos ADD COLUMN priority TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE todos ADD COLUMN items TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE todos ADD COLUMN owner TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE todos ADD COLUMN deleted_at TIMESTAMP`,
+	`ALTER TABLE todos ADD COLUMN updated_at TIMESTAMP`,
+}
+
+// OpenSqliteRepository opens the SQLite database at the path, creating it if needed, and migrates its schema
+func OpenSqliteRepository(path string) (*SqlRepository, error) {
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_foreign_keys=on")
+	if err != nil {
+		return nil, err
+	}
+	// a single connection serializes the writes, SQLite allows one writer at a time
+	db.SetMaxOpenConns(1)
+
+	repository := &SqlRepository{db: db, placeholder: func(int) string { return "?" }}
+	err = repository.migrate(sqliteMigrations)
+	if err == nil {
+		err = repository.prepare()
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return repository, nil
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxTagLength is the maximum number of characters of a tag
+const MaxTagLength = 50
+
+// ErrInvalidTag is returned for tags that are empty, too long or contain a comma, the separator of the CSV file
+var ErrInvalidTag = errors.New("tags must not be empty, longer than 50 characters or contain commas")
+
+// ValidateTag checks the name of a tag
+func ValidateTag(tag string) error {
+	if strings.TrimSpace(tag) == "" || utf8.RuneCountInString(tag) > MaxTagLength || strings.Contains(tag, ",") {
+		return ErrInvalidTag
+	}
+	return nil
+}
+
+// TagCount is a tag with the number of todos tagged with it
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// TagCounts returns the tags of the todos, the most used first and tags used equally often by name
+func TagCounts(todos []Todo) []TagCount {
+	counts := make(map[string]int)
+	for _, todo := range todos {
+		for _, tag := range todo.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := []TagCount{}
+	for tag, count := range counts {
+		tags = append(tags, TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+	return tags
+}
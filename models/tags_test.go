@@ -0,0 +1,54 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTagCounts(t *testing.T) {
+	// Arrange
+	//
+	todos := []Todo{
+		{Title: "Write report", Tags: []string{"work", "urgent"}},
+		{Title: "Plan sprint", Tags: []string{"work"}},
+		{Title: "Buy milk", Tags: []string{"shopping"}},
+	}
+
+	// Act
+	//
+	got := TagCounts(todos)
+
+	// Assert
+	//
+	want := []TagCount{{"work", 2}, {"shopping", 1}, {"urgent", 1}}
+	if len(got) != len(want) {
+		t.Fatal("Fehler", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Error("Fehler", got)
+		}
+	}
+}
+
+func TestValidateTag(t *testing.T) {
+	for tag, valid := range map[string]bool{
+		"work":                  true,
+		"":                      false,
+		"  ":                    false,
+		"a,b":                   false,
+		strings.Repeat("x", 50): true,
+		strings.Repeat("x", 51): false,
+		strings.Repeat("ä", 50): true,
+	} {
+		// Act
+		//
+		err := ValidateTag(tag)
+
+		// Assert
+		//
+		if (err == nil) != valid {
+			t.Error("Fehler", tag, err)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// trailingPunctuation is removed from the end of normalized titles, closing brackets and quotes are kept
+const trailingPunctuation = ".,;:!?"
+
+// NormalizeTitle suggests a cleaned up title: whitespace is collapsed, the first letter is capitalized
+// and trailing punctuation is stripped, e.g. "  buy  milk!! " becomes "Buy milk"
+func NormalizeTitle(title string) string {
+	normalized := strings.Join(strings.Fields(title), " ")
+	normalized = strings.TrimRight(normalized, trailingPunctuation+" ")
+
+	first, size := utf8.DecodeRuneInString(normalized)
+	if first == utf8.RuneError {
+		return normalized
+	}
+	return string(unicode.ToUpper(first)) + normalized[size:]
+}
+
+// CreationMeta is the meta information of the response to a created todo
+type CreationMeta struct {
+	// SuggestedTitle is the normalized title if it differs from the title
+	SuggestedTitle string `json:"suggested_title,omitempty"`
+	// Revision is the revision of the store after the creation
+	Revision uint64 `json:"revision"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	for title, want := range map[string]string{
+		"  buy  milk!! ": "Buy milk",
+		"call (mom).":    "Call (mom)",
+		"ärger klären":   "Ärger klären",
+		"Already fine":   "Already fine",
+		"...":            "",
+	} {
+		// Act
+		//
+		got := NormalizeTitle(title)
+
+		// Assert
+		//
+		if got != want {
+			t.Errorf("NormalizeTitle(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
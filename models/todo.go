@@ -2,11 +2,15 @@
 package models
 
 import (
-	"encoding/csv"
-	"io"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+	"todo-rest-backend/clock"
 )
 
 type Todo struct {
@@ -15,13 +19,97 @@ type Todo struct {
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	Terminated  bool   `json:"terminated"`
+	// Reference of the mirrored issue in an external tracker, e.g. "github:owner/repo#12".
+	// It is maintained by the issue sync and cannot be set by clients.
+	ExternalRef string   `json:"external_ref,omitempty"`
+	Tags        []string `json:"tags"`
+	// The tags assigned by the classifier when the todo was created and not removed by the user since.
+	// It is maintained by the store and cannot be set by clients.
+	AutoTags []string `json:"auto_tags,omitempty"`
+	// The time the todo was terminated, it is maintained by the store and cannot be set by clients
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	// The place the todo is done at, e.g. for errands
+	Location *Location `json:"location,omitempty"`
+	// The list the todo belongs to, DefaultList if not set
+	List string `json:"list"`
+	// The number of the todo in its list. Unlike the id it does not change when other todos are deleted.
+	// It is maintained by the store and cannot be set by clients.
+	Number int `json:"number"`
+	// The position of the todo in its list, it is changed by reordering the list and cannot be set by clients
+	Position int `json:"position"`
+	// The board column of the todo, todos in the last column are terminated
+	Status string `json:"status"`
+	// The day the todo is due in the form 2006-01-02 or the time it is due in RFC 3339
+	DueDate string `json:"due_date,omitempty"`
+	// The estimated effort in minutes
+	EstimateMinutes int `json:"estimate_minutes,omitempty"`
+	// The priority low, medium or high, empty without priority
+	Priority string `json:"priority,omitempty"`
+	// The checklist of subtasks, it is changed with AddItem, UpdateItem and RemoveItem and cannot be set by clients
+	Items []ChecklistItem `json:"items,omitempty"`
+	// Habits repeat every day, the days they are done on are recorded instead of terminating them
+	Habit bool `json:"habit,omitempty"`
+	// The day the todo became a habit, it is maintained by the store and cannot be set by clients
+	HabitSince string `json:"habit_since,omitempty"`
+	// The days the habit was done on in ascending order, see RecordHabitDay
+	HabitDays []string `json:"-"`
+	// The goal the todo contributes to
+	GoalId string `json:"goal_id,omitempty"`
+	// The person or external reference the todo is waiting on, e.g. "alice" or "ticket #42"
+	WaitingOn string `json:"waiting_on,omitempty"`
+	// The time the todo started waiting, it is maintained by the store and cannot be set by clients
+	WaitingSince *time.Time `json:"waiting_since,omitempty"`
+	// The time of the last nudge reminder, see DueNudges
+	NudgedAt *time.Time `json:"-"`
+	// The time the todo was created, it is maintained by the store and cannot be set by clients.
+	// Todos stored before the creation time was recorded have none.
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	// The time the todo was last changed, it is maintained by the store and cannot be set by clients.
+	// Todos stored before the update time was recorded have none until their next change.
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	// The user the todo belongs to, empty for todos stored before users were configured.
+	// It is maintained by the store and cannot be set by clients.
+	Owner string `json:"owner,omitempty"`
+	// The time the todo was moved to the trash, trashed todos are not found or listed until they are restored.
+	// It is maintained by the store and cannot be set by clients.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 func (t Todo) Serialize() []string {
-	todoSerialized := []string{t.Id, t.Title, t.Description, strconv.FormatBool(t.Terminated)}
+	todoSerialized := []string{t.Id, t.Title, t.Description, strconv.FormatBool(t.Terminated), t.ExternalRef,
+		strings.Join(t.Tags, ","), strings.Join(t.AutoTags, ","), formatTime(t.CompletedAt)}
+	todoSerialized = append(todoSerialized, serializeLocation(t.Location)...)
+	todoSerialized = append(todoSerialized, t.List, strconv.Itoa(t.Number), strconv.Itoa(t.Position), t.Status,
+		t.DueDate, strconv.Itoa(t.EstimateMinutes), t.HabitSince, strings.Join(t.HabitDays, ","),
+		t.GoalId, t.WaitingOn, formatTime(t.WaitingSince), formatTime(t.NudgedAt),
+		formatTime(t.CreatedAt), t.Priority, serializeItems(t.Items), t.Owner, formatTime(t.DeletedAt),
+		formatTime(t.UpdatedAt))
 	return todoSerialized
 }
 
+// Version identifies the content of the todo, every change of the todo changes it. It is the ETag of the todo.
+func (t Todo) Version() string {
+	sum := sha256.Sum256([]byte(strings.Join(t.Serialize(), "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// HasTag tells whether the todo is tagged with tag
+func (t Todo) HasTag(tag string) bool {
+	for _, current := range t.Tags {
+		if current == tag {
+			return true
+		}
+	}
+	return false
+}
+
 type JsonExtendedResponse struct {
 	// Reserved field to add some meta information to the API response
 	Meta interface{} `json:"meta"`
@@ -29,7 +117,9 @@ type JsonExtendedResponse struct {
 }
 
 type JsonDataResponse struct {
-	Data []Todo `json:"data"`
+	// Meta is the revision of the store the todos were listed at and further information about the list
+	Meta interface{} `json:"meta,omitempty"`
+	Data []Todo      `json:"data"`
 }
 
 type JsonErrorResponse struct {
@@ -37,12 +127,14 @@ type JsonErrorResponse struct {
 }
 
 type ApiError struct {
-	Status int16  `json:"status"`
-	Title  string `json:"title"`
+	Status int16 `json:"status"`
+	// Code identifies the kind of the error for programs, the title is for humans
+	Code  ErrorCode `json:"code"`
+	Title string    `json:"title"`
+	// Details lists the violations of an invalid request body
+	Details []FieldError `json:"details,omitempty"`
 }
 
-const FileName = "data.csv"
-
 // Todo persistence
 var filePersistence = false
 
@@ -56,41 +148,83 @@ func DisableFilePersistence() {
 	filePersistence = false
 }
 
-// A map to store the todos with the ID as the key
-// This acts as the storage in lieu of an actual database
-var todoStore = make(map[string]Todo)
+// storage is used by Initialize and UpdateDataInFile when the file persistence is enabled
+var storage Storage = CsvStorage{FileName: FileName}
 
-// TodoStore Getter method
-func TodoStore() map[string]Todo {
-	// Note that maps and slices are descriptors. If you return a map value, it will refer to the same underlying data structures.
-	// Therefore, a clone is created.
-	return clone(todoStore)
+// SetStorage replaces the storage of the todos
+func SetStorage(s Storage) {
+	storage = s
+}
+
+// clk provides the timestamps of the store, tests replace it with a fake clock
+var clk clock.Clock = clock.NewSystem()
+
+// SetClock replaces the clock of the store
+func SetClock(c clock.Clock) {
+	clk = c
 }
 
-func clone(m map[string]Todo) map[string]Todo {
-	m2 := make(map[string]Todo, len(m))
+// Now returns the current time of the store clock, time dependent logic uses it instead of time.Now
+func Now() time.Time {
+	return clk.Now()
+}
 
-	for k, v := range m {
-		m2[k] = v
+// TodoStore returns the todos of the repository by id
+func TodoStore() map[string]Todo {
+	todos := make(map[string]Todo)
+	for _, todo := range repository.List() {
+		todos[todo.Id] = todo
 	}
-	return m2
+	return todos
 }
 
 // AddTodo adds a todo to the store
 func AddTodo(todo Todo) Todo {
-	indexAsInt := len(todoStore)
-	indexAsString := strconv.Itoa(indexAsInt)
-
-	todo.Id = indexAsString
-	todoStore[indexAsString] = todo
+	todo.Id = nextTodoId()
+	createdAt := clk.Now().UTC().Truncate(time.Second)
+	todo.CreatedAt = &createdAt
+	todo.UpdatedAt = &createdAt
+	if todo.Tags == nil {
+		todo.Tags = []string{}
+	}
+	todo = reconcileStatus(todo, nil)
+	todo.CompletedAt = completionTime(todo.Terminated, nil)
+	if todo.List == "" {
+		todo.List = DefaultList
+	}
+	todo.Number = nextNumber(todo.List)
+	todo.Position = nextPosition(todo.List)
+	todo = keepHabit(todo, nil)
+	todo = keepWaiting(todo, nil)
+	todo.Owner = owner
+	err := repository.Add(todo)
+	if err != nil {
+		panic(err)
+	}
+	changed()
 
 	return todo
 }
 
+// ImportTodo adds a todo read from an export, unlike AddTodo it keeps the creation time and the checklist
+func ImportTodo(todo Todo) Todo {
+	createdAt, items := todo.CreatedAt, todo.Items
+	added := AddTodo(todo)
+	if createdAt == nil && len(items) == 0 {
+		return added
+	}
+	if createdAt != nil {
+		added.CreatedAt = createdAt
+	}
+	added.Items = items
+	storeTodo(added)
+	return added
+}
+
 // UpdateTodo allows to set a todo
 // If id not equals to todo.Id, then the todo.Id is set based on id.
 func UpdateTodo(id string, todo Todo) (Todo, bool) {
-	_, ok := todoStore[id]
+	previous, ok := getTodo(id)
 	if ok == false {
 		return Todo{}, false
 	}
@@ -99,97 +233,230 @@ func UpdateTodo(id string, todo Todo) (Todo, bool) {
 		todo.Id = id
 	}
 
-	todoStore[id] = todo
+	// The external reference is owned by the issue sync, see SetExternalRef
+	todo.ExternalRef = previous.ExternalRef
+	todo.CreatedAt = previous.CreatedAt
+	todo.Items = previous.Items
+	todo.Owner = previous.Owner
+
+	// Auto-assigned tags the user removed are no longer reported as auto-assigned
+	if todo.Tags == nil {
+		todo.Tags = []string{}
+	}
+	todo.AutoTags = nil
+	for _, tag := range previous.AutoTags {
+		if todo.HasTag(tag) {
+			todo.AutoTags = append(todo.AutoTags, tag)
+		}
+	}
+
+	todo = reconcileStatus(todo, &previous)
+	todo = keepHabit(todo, &previous)
+	todo = keepWaiting(todo, &previous)
+	todo.CompletedAt = completionTime(todo.Terminated, previous.CompletedAt)
+
+	// A todo moved to another list gets the next number of that list
+	if todo.List == "" {
+		todo.List = DefaultList
+	}
+	todo.Number = previous.Number
+	todo.Position = previous.Position
+	if todo.List != previous.List {
+		todo.Number = nextNumber(todo.List)
+		todo.Position = nextPosition(todo.List)
+	}
+
+	todo = touchTodo(todo)
 
 	return todo, true
 }
 
-// RemoveTodo removes a todo from the store
+// completionTime keeps the completion time of a todo that stays terminated and sets it when it becomes terminated
+func completionTime(terminated bool, completedAt *time.Time) *time.Time {
+	if terminated == false {
+		return nil
+	}
+	if completedAt != nil {
+		return completedAt
+	}
+	now := clk.Now().UTC().Truncate(time.Second)
+	return &now
+}
+
+// SetExternalRef links the todo to an issue in an external tracker
+func SetExternalRef(id string, ref string) (Todo, bool) {
+	todo, ok := getTodo(id)
+	if ok == false {
+		return Todo{}, false
+	}
+
+	todo.ExternalRef = ref
+	todo = touchTodo(todo)
+
+	return todo, true
+}
+
+// FindTodoByExternalRef returns the todo linked to the given external issue reference
+func FindTodoByExternalRef(ref string) (Todo, bool) {
+	if ref == "" {
+		return Todo{}, false
+	}
+	for _, todo := range listTodos() {
+		if todo.ExternalRef == ref {
+			return todo, true
+		}
+	}
+	return Todo{}, false
+}
+
+// ErrTodoNotFound is returned for actions on a todo that does not exist
+var ErrTodoNotFound = errors.New("todo not found")
+
+// RemoveTodo moves a todo to the trash, see RestoreTodo and PurgeTrash
 func RemoveTodo(id string) bool {
-	_, ok := todoStore[id]
+	todo, ok := getTodo(id)
 	if ok == false {
 		return false
 	}
 
-	var tempTodoStore = make(map[string]Todo)
-	var index int = 0
+	deletedAt := clk.Now().UTC().Truncate(time.Second)
+	todo.DeletedAt = &deletedAt
+	touchTodo(todo)
 
-	for _, currentTodo := range todoStore {
-		if id != currentTodo.Id {
-			// Add todo's from the original store to the temp store except the one to be deleted
-			indexAsString := strconv.Itoa(index)
-			currentTodo.Id = indexAsString
-			tempTodoStore[indexAsString] = currentTodo
-			index += 1
+	return true
+}
+
+// RemoveTodos removes all todos matching from the store and returns them, the todos in the trash included.
+// The remaining todos keep their ids, ids of removed todos are not reused.
+func RemoveTodos(matching func(todo Todo) bool) []Todo {
+	var removed []Todo
+	newIds := make(map[string]string)
+
+	for _, currentTodo := range repository.List() {
+		if visible(currentTodo) == false || matching(currentTodo) == false {
+			newIds[currentTodo.Id] = currentTodo.Id
+			continue
+		}
+		err := repository.Delete(currentTodo.Id)
+		if err != nil {
+			panic(err)
 		}
+		changed()
+		removed = append(removed, currentTodo)
+	}
+	if len(removed) > 0 {
+		renamePomodoroTodos(newIds)
 	}
 
-	todoStore = tempTodoStore
+	return removed
+}
 
-	return true
+// sideStorage returns the storage of the sequences, pomodoro sessions, goals and settings: the storage of the
+// todos with file persistence enabled, otherwise a repository persisting the todos itself or nil
+func sideStorage() interface{} {
+	if filePersistence {
+		return storage
+	}
+	if persistent, ok := repository.(PersistentRepository); ok {
+		return persistent
+	}
+	return nil
 }
 
 // Initialize does the initialization of the repository
 func Initialize() {
-	if filePersistence {
-		todoStore, _ = getDataFromFile()
+	BeginWarmup()
+	defer finishWarmup()
+
+	side := sideStorage()
+	// the new store of the dual-write mode gets a copy of what was loaded
+	if dual, ok := dualWrite(); ok {
+		defer dual.synchronize(side)
+	}
+	if side == nil {
+		return
 	}
-}
 
-func getDataFromFile() (map[string]Todo, error) {
-	// open file
-	//
-	file, err := os.Open(FileName)
-	if err != nil {
-		return nil, err
+	// saved settings replace the defaults of the configuration
+	if settingsStorage, ok := side.(SettingsStorage); ok {
+		saved, err := settingsStorage.LoadSettings()
+		if err != nil {
+			log.Println("Cannot load the settings:", err)
+		}
+		if saved != nil {
+			SetSettings(*saved)
+		}
 	}
 
-	var readTodos = make(map[string]Todo)
+	// changes made while initializing, like numbering old todos, count after the saved revision
+	if revisionStorage, ok := side.(RevisionStorage); ok {
+		saved, err := revisionStorage.LoadRevision()
+		if err != nil {
+			log.Println("Cannot load the revision:", err)
+		}
+		revision, savedRevision = saved, saved
+	}
 
-	// read csv values using csv.Reader
-	//
-	csvReader := csv.NewReader(file)
-	rowIndex := 0
-	for {
-		records, err := csvReader.Read()
-		if err == io.EOF {
-			break
+	if filePersistence {
+		todos, err := storage.Load()
+		if errors.Is(err, ErrCorruptData) {
+			// starting with an empty store would overwrite the data with the next save
+			log.Fatal("Refusing to start with corrupted data, restore the data file or remove it: ", err)
+		}
+		if errors.Is(err, ErrEncryptionKey) {
+			log.Fatal("Refusing to start without the encryption key of the data, set it as previous key: ", err)
+		}
+		if errors.Is(err, ErrUnsupportedVersion) {
+			log.Fatal("Refusing to start with data of a newer version, upgrade the backend: ", err)
 		}
 		if err != nil {
-			return nil, err
+			if errors.Is(err, os.ErrNotExist) == false {
+				log.Println("Cannot load todos:", err)
+			}
+			return
+		}
+		indexing(len(todos))
+		err = repository.DeleteAll()
+		if err != nil {
+			log.Fatal("Cannot load todos: ", err)
+		}
+		for _, todo := range todos {
+			err = repository.Add(todo)
+			if err != nil {
+				log.Fatal("Cannot load todos: ", err)
+			}
+			indexed()
 		}
-
-		rowIndexAsString := strconv.Itoa(rowIndex)
-
-		// Add todo to map
-		//
-		readTodos[rowIndexAsString] = parseTodoData(records)
-		rowIndex = rowIndex + 1
 	}
 
-	// remember to close the file at the end
-	//
-	err = file.Close()
-
-	if err != nil {
-		return nil, err
+	var err error
+	sequences := make(map[string]int)
+	if sequenceStorage, ok := side.(SequenceStorage); ok {
+		sequences, err = sequenceStorage.LoadSequences()
+		if err != nil {
+			log.Println("Cannot load the sequences of the todo numbers:", err)
+		}
 	}
+	initializeNumbers(sequences)
+	initializeStatuses()
 
-	return readTodos, nil
-}
-
-func parseTodoData(rec []string) Todo {
-	// Parse todo
-	//
-	id := rec[0]
-	title := rec[1]
-	description := rec[2]
-	terminated := ToBool(rec[3])
+	if pomodoroStorage, ok := side.(PomodoroStorage); ok {
+		pomodoroSessions, err = pomodoroStorage.LoadPomodoroSessions()
+		if err != nil {
+			log.Println("Cannot load the pomodoro sessions:", err)
+		}
+	}
 
-	// Create new todo based on parsed values
-	//
-	todo := Todo{Id: id, Title: title, Description: description, Terminated: terminated}
-	return todo
+	if goalStorage, ok := side.(GoalStorage); ok {
+		goals, err := goalStorage.LoadGoals()
+		if err != nil {
+			log.Println("Cannot load the goals:", err)
+		}
+		goalStore = make(map[string]Goal)
+		for _, goal := range goals {
+			goalStore[goal.Id] = goal
+		}
+	}
 }
 
 // ToBool converts a string to a boolean value
@@ -198,37 +465,116 @@ func ToBool(info string) bool {
 	return aBool
 }
 
-// UpdateDataInFile updates the data in the file by writing todo store to file.
+// deferredSaving leaves the saving to a background worker, see DeferSaving
+var deferredSaving = false
+
+// DeferSaving makes UpdateDataInFile return without saving, a background worker saves the changes with
+// TakePendingSave instead and SaveData saves them on shutdown
+func DeferSaving(enabled bool) {
+	deferredSaving = enabled
+}
+
+// UpdateDataInFile updates the data in the file by writing todo store to the storage.
+// A repository persisting the todos itself only saves the sequences, pomodoro sessions and goals.
+// Nothing is written if the store did not change since the last save, or if the saving is deferred.
 func UpdateDataInFile() error {
-	if filePersistence == false {
+	if deferredSaving {
 		return nil
 	}
+	return SaveData()
+}
 
-	file, err := os.OpenFile(FileName, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0755)
-	checkError("Cannot open file", err)
-	writer := csv.NewWriter(file)
-
-	for _, todo := range todoStore {
-		err := writer.Write(todo.Serialize())
-		checkError("Cannot write to file", err)
+// SaveData writes the changes of the store to the storage like UpdateDataInFile, also if the saving is deferred
+func SaveData() error {
+	pending, ok := TakePendingSave()
+	if ok == false {
+		return nil
 	}
-
-	writer.Flush()
-	err = file.Close()
-
+	err := pending.Write()
 	if err != nil {
 		return err
 	}
-
+	pending.MarkSaved()
 	return nil
 }
 
-func checkError(message string, err error) {
-	if err != nil {
-		log.Fatal(message, err)
+// PendingSave is a copy of the store changed since the last save, it is written without holding the store
+type PendingSave struct {
+	side    interface{}
+	dataset Dataset
+}
+
+// TakePendingSave copies the store for writing it, false if it did not change since the last save.
+// The store must be held while copying, not while writing the copy.
+func TakePendingSave() (PendingSave, bool) {
+	side := sideStorage()
+	if side == nil || unsaved() == false {
+		return PendingSave{}, false
+	}
+	dataset := Dataset{Sequences: make(map[string]int), Goals: Goals(), Revision: revision,
+		PomodoroSessions: append([]PomodoroSession{}, pomodoroSessions...)}
+	for list, sequence := range listSequences {
+		dataset.Sequences[list] = sequence
+	}
+	if filePersistence {
+		dataset.Todos = TodoStore()
 	}
+	return PendingSave{side: side, dataset: dataset}, true
+}
+
+// saveObserver is told how long the writes of the store took, see ObserveSaves
+var saveObserver func(duration time.Duration, err error)
+
+// ObserveSaves calls the observer after every write of the store to the storage with its duration and error
+func ObserveSaves(observer func(duration time.Duration, err error)) {
+	saveObserver = observer
+}
+
+// Write writes the copy to the storage
+func (p PendingSave) Write() (err error) {
+	if saveObserver != nil {
+		start := time.Now()
+		defer func() { saveObserver(time.Since(start), err) }()
+	}
+	// a storage keeping the whole dataset in one file writes it at once
+	if datasetStorage, ok := p.side.(DatasetStorage); ok && p.dataset.Todos != nil {
+		err := datasetStorage.SaveDataset(p.dataset)
+		if err != nil {
+			return err
+		}
+	} else {
+		err := writeSideData(p.side, p.dataset)
+		if err != nil {
+			return err
+		}
+		if p.dataset.Todos != nil {
+			err = storage.Save(p.dataset.Todos)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if dual, ok := dualWrite(); ok {
+		dual.mirrorSideData(Dataset{Sequences: p.dataset.Sequences, PomodoroSessions: p.dataset.PomodoroSessions,
+			Goals: p.dataset.Goals, Revision: p.dataset.Revision})
+	}
+	return nil
+}
+
+// MarkSaved records the revision of the written copy as saved, the store must be held
+func (p PendingSave) MarkSaved() {
+	savedRevision = p.dataset.Revision
 }
 
 func DeleteAllTodos() {
-	todoStore = make(map[string]Todo)
+	if owner != "" {
+		RemoveTodos(func(Todo) bool { return true })
+		return
+	}
+	err := repository.DeleteAll()
+	if err != nil {
+		panic(err)
+	}
+	changed()
+	renamePomodoroTodos(nil)
 }
@@ -1,12 +1,18 @@
 package models
 
-import "testing"
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+	"todo-rest-backend/clock"
+)
 
 func TestTodo_Serialize(t *testing.T) {
 	// Arrange
 	//
 	todoTest := Todo{Id: "99", Title: "Test1", Description: "Beschrieb", Terminated: false}
-	var want []string = []string{"99", "Test1", "Beschrieb", "false"}
+	var want []string = []string{"99", "Test1", "Beschrieb", "false", "", "", "", "", "", "", "", "", "0", "0", "", "", "0", "", "", "", "", "", "", "", "", "", "", "", ""}
 
 	// Act
 	//
@@ -22,8 +28,18 @@ func TestTodo_Serialize(t *testing.T) {
 func TestTodo_AddTodo(t *testing.T) {
 	// Arrange
 	//
-	todoTest := Todo{Id: "0", Title: "Test1", Description: "Beschrieb", Terminated: false}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	defer SetClock(clk)
+	SetClock(clock.NewFake(now))
+	todoTest := Todo{Title: "Test1", Description: "Beschrieb", Terminated: false, Tags: []string{}}
 	var want Todo = todoTest
+	want.Id = strconv.Itoa(listSequences[todoIdSequence])
+	want.CreatedAt = &now
+	want.UpdatedAt = &now
+	want.List = DefaultList
+	want.Number = listSequences[DefaultList] + 1
+	want.Position = nextPosition(DefaultList)
+	want.Status = StatusTodo
 
 	// Act
 	//
@@ -31,11 +47,86 @@ func TestTodo_AddTodo(t *testing.T) {
 
 	// Assert
 	//
-	if got != want {
+	if reflect.DeepEqual(got, want) == false {
 		t.Error("Fehler")
 	}
 }
 
+func TestTodo_AddTodoCompletedAtUsesClock(t *testing.T) {
+	// Arrange
+	//
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	defer SetClock(clk)
+	SetClock(clock.NewFake(now))
+
+	// Act
+	//
+	got := AddTodo(Todo{Title: "Done", Terminated: true})
+
+	// Assert
+	//
+	if got.CompletedAt == nil || got.CompletedAt.Equal(now) == false {
+		t.Error("Fehler", got.CompletedAt)
+	}
+}
+
+func TestTodo_RemoveTodoKeepsIds(t *testing.T) {
+	// Arrange
+	//
+	DeleteAllTodos()
+	defer DeleteAllTodos()
+	first := AddTodo(Todo{Title: "First"})
+	second := AddTodo(Todo{Title: "Second"})
+	third := AddTodo(Todo{Title: "Third"})
+
+	// Act
+	//
+	RemoveTodo(third.Id)
+	RemoveTodo(first.Id)
+	added := AddTodo(Todo{Title: "Fourth"})
+
+	// Assert
+	//
+	if kept, ok := FindTodo(second.Id); ok == false || kept.Title != "Second" {
+		t.Error("Fehler", kept)
+	}
+	if added.Id == first.Id || added.Id == second.Id || added.Id == third.Id {
+		t.Error("Fehler", added.Id)
+	}
+}
+
+func TestTodo_UpdateTodoRecordsTheUpdateTime(t *testing.T) {
+	// Arrange
+	//
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(created)
+	defer SetClock(clk)
+	SetClock(fake)
+	changed := AddTodo(Todo{Title: "Book flights"})
+	unchanged := AddTodo(Todo{Title: "Book hotel"})
+	fake.Advance(time.Hour)
+	updatedSince := created.Add(time.Minute)
+
+	// Act
+	//
+	changed, _ = UpdateTodo(changed.Id, Todo{Title: "Book flights to Rome", Tags: []string{}})
+	unchanged, _ = UpdateTodo(unchanged.Id, unchanged)
+	updated := FilterTodos([]Todo{changed, unchanged}, TodoFilter{UpdatedSince: &updatedSince})
+
+	// Assert
+	//
+	if changed.UpdatedAt == nil || changed.UpdatedAt.Equal(created.Add(time.Hour)) == false ||
+		changed.CreatedAt.Equal(created) == false {
+		t.Error("Fehler", changed.CreatedAt, changed.UpdatedAt)
+	}
+	if unchanged.UpdatedAt == nil || unchanged.UpdatedAt.Equal(created) == false {
+		t.Error("Fehler", unchanged.UpdatedAt)
+	}
+	if len(updated) != 1 || updated[0].Id != changed.Id {
+		t.Error("Fehler", updated)
+	}
+}
+
 // areStringSlicesEqual tells whether a and b contain the same elements.
 func areStringSlicesEqual(a, b []string) bool {
 	if len(a) != len(b) {
@@ -0,0 +1,204 @@
+package models
+
+import (
+	"bufio"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// TodoTxtItem is a single line of a todo.txt file.
+// See https://github.com/todotxt/todo.txt for the format description.
+type TodoTxtItem struct {
+	Done           bool
+	Priority       string
+	CompletionDate string
+	CreationDate   string
+	// Text is the remaining description including the @context and +project tokens
+	Text     string
+	Contexts []string
+	Projects []string
+}
+
+var todoTxtPriority = regexp.MustCompile(`^\(([A-Z])\) `)
+var todoTxtDate = regexp.MustCompile(`^\d{4}-\d{2}-\d{2} `)
+
+// ParseTodoTxtLine parses one line of a todo.txt file.
+// Empty lines return false.
+func ParseTodoTxtLine(line string) (TodoTxtItem, bool) {
+	var item TodoTxtItem
+	rest := strings.TrimSpace(line)
+	if rest == "" {
+		return item, false
+	}
+	rest += " "
+
+	if strings.HasPrefix(rest, "x ") {
+		item.Done = true
+		rest = rest[2:]
+		if date := todoTxtDate.FindString(rest); date != "" {
+			item.CompletionDate = strings.TrimSpace(date)
+			rest = rest[len(date):]
+		}
+	} else if match := todoTxtPriority.FindStringSubmatch(rest); match != nil {
+		item.Priority = match[1]
+		rest = rest[len(match[0]):]
+	}
+
+	if date := todoTxtDate.FindString(rest); date != "" {
+		item.CreationDate = strings.TrimSpace(date)
+		rest = rest[len(date):]
+	}
+
+	item.setText(rest)
+	return item, true
+}
+
+// setText sets the text and collects the @context and +project tokens of it
+func (item *TodoTxtItem) setText(text string) {
+	item.Text = strings.TrimSpace(text)
+	item.Contexts = nil
+	item.Projects = nil
+	for _, word := range strings.Fields(item.Text) {
+		if len(word) > 1 && word[0] == '@' {
+			item.Contexts = append(item.Contexts, word[1:])
+		}
+		if len(word) > 1 && word[0] == '+' {
+			item.Projects = append(item.Projects, word[1:])
+		}
+	}
+}
+
+// String formats the item as a todo.txt line
+func (item TodoTxtItem) String() string {
+	var parts []string
+	if item.Done {
+		parts = append(parts, "x")
+		if item.CompletionDate != "" {
+			parts = append(parts, item.CompletionDate)
+		}
+	} else if item.Priority != "" {
+		parts = append(parts, "("+item.Priority+")")
+	}
+	if item.CreationDate != "" {
+		parts = append(parts, item.CreationDate)
+	}
+	parts = append(parts, item.Text)
+	return strings.Join(parts, " ")
+}
+
+// todoTxtDescriptionKey and todoTxtPriorityKey are the key:value tags of the description and of the priority
+// of completed todos, todo.txt has no priority slot for them
+const (
+	todoTxtDescriptionKey = "description:"
+	todoTxtPriorityKey    = "pri:"
+)
+
+// todoTxtPriorities are the todo.txt priorities of the priorities, (D) to (Z) are read as low
+var todoTxtPriorities = map[string]string{PriorityHigh: "A", PriorityMedium: "B", PriorityLow: "C"}
+
+// priorityOfTodoTxt returns the priority of a todo.txt priority letter
+func priorityOfTodoTxt(letter string) string {
+	switch letter {
+	case "":
+		return ""
+	case "A":
+		return PriorityHigh
+	case "B":
+		return PriorityMedium
+	}
+	return PriorityLow
+}
+
+// escapeTodoTxtTitle escapes the words of the title that would be read as something else with a backslash:
+// a first word read as completion mark, priority or date and the words starting like a key:value tag of the
+// backend or with a backslash
+func escapeTodoTxtTitle(title string) string {
+	words := strings.Fields(title)
+	for i, word := range words {
+		if strings.HasPrefix(word, `\`) || strings.HasPrefix(word, todoTxtDescriptionKey) ||
+			strings.HasPrefix(word, todoTxtPriorityKey) {
+			words[i] = `\` + word
+		}
+	}
+	if len(words) > 0 && (words[0] == "x" || todoTxtPriority.MatchString(words[0]+" ") || todoTxtDate.MatchString(words[0]+" ")) {
+		words[0] = `\` + words[0]
+	}
+	return strings.Join(words, " ")
+}
+
+// TodoTxtItemFromTodo converts a todo into a todo.txt item.
+// The priority takes the priority slot, (A) for high to (C) for low, the description follows the title as
+// description: tag with its special characters percent-encoded.
+func TodoTxtItemFromTodo(todo Todo) TodoTxtItem {
+	// todo.txt is line based, so line breaks in the title are collapsed
+	words := []string{escapeTodoTxtTitle(todo.Title)}
+	item := TodoTxtItem{Done: todo.Terminated}
+
+	// completed tasks carry no priority in todo.txt, it is kept in a pri: tag instead
+	letter := todoTxtPriorities[todo.Priority]
+	if letter != "" && item.Done {
+		words = append(words, todoTxtPriorityKey+letter)
+	} else {
+		item.Priority = letter
+	}
+	if todo.Description != "" {
+		words = append(words, todoTxtDescriptionKey+url.PathEscape(todo.Description))
+	}
+	item.setText(strings.Join(words, " "))
+	return item
+}
+
+// ToTodo converts a todo.txt item into a todo.
+// The priority and the description are read from their slot and tags, contexts and projects stay inline.
+func (item TodoTxtItem) ToTodo() Todo {
+	todo := Todo{Terminated: item.Done, Priority: priorityOfTodoTxt(item.Priority)}
+	var words []string
+	for _, word := range strings.Fields(item.Text) {
+		switch {
+		case strings.HasPrefix(word, `\`):
+			words = append(words, word[1:])
+		case strings.HasPrefix(word, todoTxtDescriptionKey):
+			description, err := url.PathUnescape(strings.TrimPrefix(word, todoTxtDescriptionKey))
+			if err != nil {
+				description = strings.TrimPrefix(word, todoTxtDescriptionKey)
+			}
+			todo.Description = description
+		case strings.HasPrefix(word, todoTxtPriorityKey) && todo.Priority == "":
+			todo.Priority = priorityOfTodoTxt(strings.TrimPrefix(word, todoTxtPriorityKey))
+		default:
+			words = append(words, word)
+		}
+	}
+	todo.Title = strings.Join(words, " ")
+	return todo
+}
+
+// WriteTodoTxt writes the todos in todo.txt format, one todo per line
+func WriteTodoTxt(writer io.Writer, todos []Todo) error {
+	for _, todo := range todos {
+		_, err := io.WriteString(writer, TodoTxtItemFromTodo(todo).String()+"\n")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadTodoTxt reads todos from a todo.txt formatted reader
+func ReadTodoTxt(reader io.Reader) ([]Todo, error) {
+	var todos []Todo
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		item, ok := ParseTodoTxtLine(scanner.Text())
+		if ok == false {
+			continue
+		}
+		todos = append(todos, item.ToTodo())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
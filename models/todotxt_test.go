@@ -0,0 +1,104 @@
+package models
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTodoTxtLine(t *testing.T) {
+	// Arrange
+	//
+	line := "(A) 2022-05-01 Call mom @phone +family"
+
+	// Act
+	//
+	got, ok := ParseTodoTxtLine(line)
+
+	// Assert
+	//
+	if ok == false || got.Priority != "A" || got.CreationDate != "2022-05-01" || got.Text != "Call mom @phone +family" {
+		t.Errorf("unexpected item %+v", got)
+	}
+	if areStringSlicesEqual(got.Contexts, []string{"phone"}) == false || areStringSlicesEqual(got.Projects, []string{"family"}) == false {
+		t.Errorf("unexpected contexts %v or projects %v", got.Contexts, got.Projects)
+	}
+}
+
+func TestParseTodoTxtLine_Done(t *testing.T) {
+	// Act
+	//
+	got, _ := ParseTodoTxtLine("x 2022-05-02 2022-05-01 Pay bills")
+
+	// Assert
+	//
+	if got.Done == false || got.CompletionDate != "2022-05-02" || got.CreationDate != "2022-05-01" || got.Text != "Pay bills" {
+		t.Errorf("unexpected item %+v", got)
+	}
+}
+
+func TestTodoTxt_RoundTrip(t *testing.T) {
+	// Arrange
+	//
+	todos := []Todo{
+		{Title: "(B) Write report +work", Terminated: false},
+		{Title: "Water plants @home", Terminated: true},
+		{Title: "Write report +work", Priority: PriorityMedium, Description: "Due friday,\nsee 50% draft"},
+		{Title: "Pay rent", Priority: PriorityHigh, Terminated: true},
+		{Title: "x marks the spot", Description: "description:not a tag"},
+		{Title: "2024-05-01 plan \\ review pri:A"},
+	}
+	var buffer bytes.Buffer
+
+	// Act
+	//
+	err := WriteTodoTxt(&buffer, todos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadTodoTxt(&buffer)
+
+	// Assert
+	//
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(todos) {
+		t.Fatalf("got %d todos, want %d", len(got), len(todos))
+	}
+	for i := range todos {
+		if reflect.DeepEqual(got[i], todos[i]) == false {
+			t.Errorf("got %+v, want %+v", got[i], todos[i])
+		}
+	}
+}
+
+func TestTodoTxtItemFromTodo_MapsThePriority(t *testing.T) {
+	// Act
+	//
+	open := TodoTxtItemFromTodo(Todo{Title: "Call mom", Priority: PriorityHigh}).String()
+	done := TodoTxtItemFromTodo(Todo{Title: "Call mom", Priority: PriorityLow, Terminated: true}).String()
+	escaped := TodoTxtItemFromTodo(Todo{Title: "x-ray at 9"}).String()
+
+	// Assert
+	//
+	if open != "(A) Call mom" || done != "x Call mom pri:C" || escaped != "x-ray at 9" {
+		t.Errorf("got %q, %q and %q", open, done, escaped)
+	}
+}
+
+func TestReadTodoTxt_MapsThePriority(t *testing.T) {
+	// Act
+	//
+	got, err := ReadTodoTxt(strings.NewReader("(A) Call mom\n(D) Sort the photos\n"))
+
+	// Assert
+	//
+	if err != nil || len(got) != 2 {
+		t.Fatal(err, got)
+	}
+	if got[0].Title != "Call mom" || got[0].Priority != PriorityHigh || got[1].Priority != PriorityLow {
+		t.Errorf("got %+v", got)
+	}
+}
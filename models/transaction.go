@@ -0,0 +1,53 @@
+package models
+
+import "reflect"
+
+// Transaction is the state of the store when it began, the changes made since can be undone as a whole.
+// The store must not be changed by others until the transaction ended, the callers hold it exclusively.
+type Transaction struct {
+	todos     map[string]Todo
+	sequences map[string]int
+	sessions  []PomodoroSession
+	revision  uint64
+}
+
+// BeginTransaction remembers the todos of all users with their list sequences and pomodoro sessions,
+// the changes made since can be undone with Rollback
+func BeginTransaction() *Transaction {
+	transaction := &Transaction{todos: TodoStore(), sequences: make(map[string]int, len(listSequences)),
+		sessions: make([]PomodoroSession, len(pomodoroSessions)), revision: revision}
+	for list, sequence := range listSequences {
+		transaction.sequences[list] = sequence
+	}
+	copy(transaction.sessions, pomodoroSessions)
+	return transaction
+}
+
+// Rollback undoes the changes made since the transaction began: todos added since are removed, changed and
+// removed todos are stored as they were. The revision is reset, the store is as if nothing happened.
+func (t *Transaction) Rollback() error {
+	for _, todo := range repository.List() {
+		if _, ok := t.todos[todo.Id]; ok == false {
+			err := repository.Delete(todo.Id)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for id, todo := range t.todos {
+		current, ok := repository.Get(id)
+		var err error
+		if ok == false {
+			err = repository.Add(todo)
+		} else if reflect.DeepEqual(current, todo) == false {
+			err = repository.Update(todo)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	listSequences = t.sequences
+	pomodoroSessions = t.sessions
+	revision = t.revision
+	return nil
+}
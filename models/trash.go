@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+)
+
+// TrashedTodos returns the todos of the owner in the trash in no particular order
+func TrashedTodos() []Todo {
+	return ownedTodos(func(todo Todo) bool {
+		return todo.DeletedAt != nil
+	})
+}
+
+// RestoreTodo takes the todo with the id out of the trash. It keeps its id and number and is put at the end of
+// its list, the positions of the list changed while it was in the trash.
+func RestoreTodo(id string) (Todo, bool) {
+	todo, ok := repository.Get(id)
+	if ok == false || visible(todo) == false || todo.DeletedAt == nil {
+		return Todo{}, false
+	}
+
+	todo.DeletedAt = nil
+	todo.Position = nextPosition(todo.List)
+	todo = touchTodo(todo)
+
+	return todo, true
+}
+
+// PurgeTrash removes the todos of the owner moved to the trash before the time from the store and returns them,
+// a zero time purges the whole trash
+func PurgeTrash(before time.Time) []Todo {
+	return RemoveTodos(func(todo Todo) bool {
+		return todo.DeletedAt != nil && (before.IsZero() || todo.DeletedAt.Before(before))
+	})
+}
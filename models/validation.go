@@ -0,0 +1,78 @@
+package models
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Maximum lengths of the texts of a todo
+const (
+	MaxTitleLength       = 200
+	MaxDescriptionLength = 10000
+)
+
+// FieldError is a violation of a field of a request body
+type FieldError struct {
+	// Field is the name of the field in the JSON body, nested fields are separated by dots, e.g. "tags.1"
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors are all violations of a request body
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	var messages []string
+	for _, violation := range e {
+		messages = append(messages, violation.Field+": "+violation.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validate checks the fields clients can set and returns all violations as ValidationErrors, nil for a valid todo
+func (t Todo) Validate() error {
+	var violations ValidationErrors
+	violation := func(field string, message string) {
+		violations = append(violations, FieldError{Field: field, Message: message})
+	}
+
+	if strings.TrimSpace(t.Title) == "" {
+		violation("title", "is required")
+	} else if utf8.RuneCountInString(t.Title) > MaxTitleLength {
+		violation("title", "must have at most 200 characters")
+	}
+	if utf8.RuneCountInString(t.Description) > MaxDescriptionLength {
+		violation("description", "must have at most 10000 characters")
+	}
+	for _, tag := range t.Tags {
+		if ValidateTag(tag) != nil {
+			violation("tags", ErrInvalidTag.Error())
+			break
+		}
+	}
+	if t.Status != "" && IsBoardColumn(t.Status) == false {
+		violation("status", ErrUnknownColumn.Error())
+	}
+	if t.DueDate != "" {
+		if _, _, err := ParseDue(t.DueDate); err != nil {
+			violation("due_date", err.Error())
+		}
+	}
+	if t.EstimateMinutes < 0 {
+		violation("estimate_minutes", "must not be negative")
+	}
+	if ValidatePriority(t.Priority) != nil {
+		violation("priority", ErrInvalidPriority.Error())
+	}
+	if _, ok := FindGoal(t.GoalId); t.GoalId != "" && ok == false {
+		violation("goal_id", ErrGoalNotFound.Error())
+	}
+	if err := t.Location.Validate(); err != nil {
+		violation("location", err.Error())
+	}
+
+	if len(violations) > 0 {
+		return violations
+	}
+	return nil
+}
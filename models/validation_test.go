@@ -0,0 +1,47 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTodo_Validate_ReportsEveryField(t *testing.T) {
+	// Arrange
+	//
+	todo := Todo{Title: " ", Description: strings.Repeat("x", MaxDescriptionLength+1), DueDate: "tomorrow", EstimateMinutes: -5}
+
+	// Act
+	//
+	err := todo.Validate()
+
+	// Assert
+	//
+	var violations ValidationErrors
+	if errors.As(err, &violations) == false {
+		t.Fatal("Fehler", err)
+	}
+	var fields []string
+	for _, violation := range violations {
+		fields = append(fields, violation.Field)
+	}
+	if strings.Join(fields, ",") != "title,description,due_date,estimate_minutes" {
+		t.Error("Fehler", fields)
+	}
+}
+
+func TestTodo_Validate_AcceptsValidTodo(t *testing.T) {
+	// Arrange
+	//
+	todo := Todo{Title: "Buy milk", Tags: []string{"shopping"}, DueDate: "2024-05-01", Priority: PriorityHigh}
+
+	// Act
+	//
+	err := todo.Validate()
+
+	// Assert
+	//
+	if err != nil {
+		t.Error("Fehler", err)
+	}
+}
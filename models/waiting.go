@@ -0,0 +1,71 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// keepWaiting keeps the waiting start of a todo still waiting on the same person or reference,
+// a todo starting to wait on someone else starts now
+func keepWaiting(todo Todo, previous *Todo) Todo {
+	todo.WaitingOn = strings.TrimSpace(todo.WaitingOn)
+	todo.WaitingSince = nil
+	todo.NudgedAt = nil
+	if todo.WaitingOn == "" {
+		return todo
+	}
+	if previous != nil && previous.WaitingOn == todo.WaitingOn && previous.WaitingSince != nil {
+		todo.WaitingSince = previous.WaitingSince
+		todo.NudgedAt = previous.NudgedAt
+		return todo
+	}
+	now := clk.Now().UTC().Truncate(time.Second)
+	todo.WaitingSince = &now
+	return todo
+}
+
+// WaitingDays returns the number of full days the todo is waiting
+func (t Todo) WaitingDays() int {
+	if t.WaitingSince == nil {
+		return 0
+	}
+	return int(Now().Sub(*t.WaitingSince).Hours() / 24)
+}
+
+// WaitingTodos returns the open todos waiting on someone, the longest waiting first
+func WaitingTodos() []Todo {
+	todos := []Todo{}
+	for _, todo := range listTodos() {
+		if todo.Terminated == false && todo.WaitingSince != nil {
+			todos = append(todos, todo)
+		}
+	}
+	sort.Slice(todos, func(i, j int) bool {
+		if todos[i].WaitingSince.Equal(*todos[j].WaitingSince) {
+			return todos[i].Number < todos[j].Number
+		}
+		return todos[i].WaitingSince.Before(*todos[j].WaitingSince)
+	})
+	return todos
+}
+
+// DueNudges returns the waiting todos to send a reminder for and records the reminder.
+// A todo is nudged after waiting the given number of days and again every time as many days passed.
+func DueNudges(days int) []Todo {
+	var nudges []Todo
+	now := clk.Now().UTC().Truncate(time.Second)
+	for _, todo := range WaitingTodos() {
+		last := *todo.WaitingSince
+		if todo.NudgedAt != nil {
+			last = *todo.NudgedAt
+		}
+		if now.Sub(last) < time.Duration(days)*24*time.Hour {
+			continue
+		}
+		todo.NudgedAt = &now
+		storeTodo(todo)
+		nudges = append(nudges, todo)
+	}
+	return nudges
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"testing"
+	"time"
+	"todo-rest-backend/clock"
+)
+
+func TestDueNudges(t *testing.T) {
+	// Arrange
+	//
+	DeleteAllTodos()
+	defer DeleteAllTodos()
+	defer SetClock(clk)
+	fake := clock.NewFake(time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC))
+	SetClock(fake)
+	todo := AddTodo(Todo{Title: "Contract", WaitingOn: "legal"})
+	AddTodo(Todo{Title: "Invoice", WaitingOn: "accounting", Terminated: true})
+
+	// Act
+	//
+	fake.Advance(2 * 24 * time.Hour)
+	early := DueNudges(3)
+	fake.Advance(24 * time.Hour)
+	first := DueNudges(3)
+	repeated := DueNudges(3)
+	fake.Advance(3 * 24 * time.Hour)
+	second := DueNudges(3)
+
+	// Assert
+	//
+	if len(early) != 0 || len(first) != 1 || len(repeated) != 0 || len(second) != 1 {
+		t.Error("Fehler", early, first, repeated, second)
+	}
+	if second[0].Id != todo.Id || second[0].WaitingDays() != 6 {
+		t.Error("Fehler", second[0])
+	}
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"sync"
+	"time"
+)
+
+// Phases of loading the todos at startup
+const (
+	WarmupLoading  = "loading"
+	WarmupIndexing = "indexing"
+	WarmupReady    = "ready"
+)
+
+// WarmupStatus is the progress of Initialize. While loading, the storage is read; while indexing, the todos
+// read are added to the repository and numbered.
+type WarmupStatus struct {
+	Phase     string     `json:"phase"`
+	Loaded    int        `json:"loaded"`
+	Total     int        `json:"total"`
+	Percent   int        `json:"percent"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	ReadyAt   *time.Time `json:"ready_at,omitempty"`
+}
+
+// warmup is ready until Initialize starts, it is read by requests while Initialize runs in the background
+var warmup = WarmupStatus{Phase: WarmupReady}
+var warmupMutex sync.Mutex
+
+// Warmup returns the progress of loading the todos
+func Warmup() WarmupStatus {
+	warmupMutex.Lock()
+	defer warmupMutex.Unlock()
+	status := warmup
+	if status.Phase == WarmupReady {
+		status.Percent = 100
+	} else if status.Total > 0 {
+		status.Percent = status.Loaded * 100 / status.Total
+	}
+	return status
+}
+
+// Ready tells whether the todos are loaded
+func Ready() bool {
+	return Warmup().Phase == WarmupReady
+}
+
+// BeginWarmup marks the todos as loading, callers running Initialize in the background call it before
+// starting it, so that no request finds the store ready in between
+func BeginWarmup() {
+	warmupMutex.Lock()
+	defer warmupMutex.Unlock()
+	if warmup.Phase == WarmupLoading {
+		return
+	}
+	now := Now()
+	warmup = WarmupStatus{Phase: WarmupLoading, StartedAt: &now}
+}
+
+// indexing marks the todos read from the storage as being added to the repository
+func indexing(total int) {
+	warmupMutex.Lock()
+	defer warmupMutex.Unlock()
+	warmup.Phase = WarmupIndexing
+	warmup.Total = total
+}
+
+// indexed counts a todo added to the repository
+func indexed() {
+	warmupMutex.Lock()
+	defer warmupMutex.Unlock()
+	warmup.Loaded++
+}
+
+// finishWarmup marks the todos as loaded
+func finishWarmup() {
+	warmupMutex.Lock()
+	defer warmupMutex.Unlock()
+	now := Now()
+	warmup.Phase = WarmupReady
+	warmup.ReadyAt = &now
+}
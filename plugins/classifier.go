@@ -0,0 +1,125 @@
+package plugins
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"todo-rest-backend/models"
+)
+
+// Classifier proposes tags for a todo
+type Classifier interface {
+	Classify(todo models.Todo) ([]string, error)
+}
+
+// ClassifierHook is a write hook adding the tags proposed by a classifier to created todos.
+// The added tags are recorded as auto-assigned so that users can tell them apart and remove them.
+type ClassifierHook struct {
+	Classifier Classifier
+}
+
+// BeforeWrite classifies created todos. A failing classifier is logged and leaves the todo untagged.
+func (h *ClassifierHook) BeforeWrite(action string, todo models.Todo) (models.Todo, error) {
+	if action != ActionCreate {
+		return todo, nil
+	}
+
+	tags, err := h.Classifier.Classify(todo)
+	if err != nil {
+		log.Println("Classifier failed:", err)
+		return todo, nil
+	}
+
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || todo.HasTag(tag) {
+			continue
+		}
+		todo.Tags = append(todo.Tags, tag)
+		todo.AutoTags = append(todo.AutoTags, tag)
+	}
+	return todo, nil
+}
+
+// KeywordClassifier assigns a tag if title or description contain one of its keywords, ignoring case
+type KeywordClassifier struct {
+	Keywords map[string][]string
+}
+
+// LoadKeywordClassifier reads a JSON file mapping tags to keywords, e.g. {"shopping": ["buy", "groceries"]}
+func LoadKeywordClassifier(fileName string) (*KeywordClassifier, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	classifier := &KeywordClassifier{}
+	err = json.Unmarshal(content, &classifier.Keywords)
+	if err != nil {
+		return nil, fmt.Errorf("invalid classifier keywords file %s: %w", fileName, err)
+	}
+	return classifier, nil
+}
+
+// Classify returns the tags whose keywords occur in the todo, sorted by name
+func (c *KeywordClassifier) Classify(todo models.Todo) ([]string, error) {
+	text := strings.ToLower(todo.Title + "\n" + todo.Description)
+
+	var tags []string
+	for tag, keywords := range c.Keywords {
+		for _, keyword := range keywords {
+			if keyword != "" && strings.Contains(text, strings.ToLower(keyword)) {
+				tags = append(tags, tag)
+				break
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// HttpClassifier asks an external service, e.g. a machine learning model, for tags.
+// The todo is posted as JSON and the service answers with {"tags": ["..."]}.
+type HttpClassifier struct {
+	Url    string
+	Client *http.Client
+}
+
+// NewHttpClassifier creates a classifier for the service with a timeout of two seconds,
+// classification must not slow down the creation of todos noticeably
+func NewHttpClassifier(url string) *HttpClassifier {
+	return &HttpClassifier{Url: url, Client: &http.Client{Timeout: 2 * time.Second}}
+}
+
+// Classify posts the todo to the service
+func (c *HttpClassifier) Classify(todo models.Todo) ([]string, error) {
+	body, err := json.Marshal(todo)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.Client.Post(c.Url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("classifier %s answered %s", c.Url, response.Status)
+	}
+
+	var result struct {
+		Tags []string `json:"tags"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	return result.Tags, nil
+}
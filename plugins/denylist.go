@@ -0,0 +1,173 @@
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"todo-rest-backend/models"
+)
+
+// Denylist rule actions
+const (
+	DenylistBlock = "block"
+	DenylistFlag  = "flag"
+)
+
+// SensitiveTag is the auto-assigned tag of todos matching a flagging rule or stored with an override
+const SensitiveTag = "sensitive"
+
+// DenylistRule recognizes sensitive content like API keys or credit card numbers in a todo
+type DenylistRule struct {
+	Name string `json:"name"`
+	// Pattern is a regular expression, Keywords are matched ignoring case; one of them must be given
+	Pattern  string   `json:"pattern,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+	// Luhn only counts matches of the pattern whose digits pass the Luhn check of credit card numbers
+	Luhn bool `json:"luhn,omitempty"`
+	// Action is DenylistBlock (default) rejecting the todo or DenylistFlag storing it tagged with SensitiveTag
+	Action string `json:"action,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// Denylist is a write hook keeping sensitive content out of the todos. It checks the title and the
+// description of created and updated todos.
+type Denylist struct {
+	Rules []DenylistRule
+}
+
+var denylistOverridden bool
+
+// LoadDenylist reads the rules from a JSON file containing an array of rules
+func LoadDenylist(fileName string) (*Denylist, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []DenylistRule
+	err = json.Unmarshal(content, &rules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid denylist file %s: %w", fileName, err)
+	}
+	return NewDenylist(rules)
+}
+
+// NewDenylist compiles the patterns and checks the actions of the rules
+func NewDenylist(rules []DenylistRule) (*Denylist, error) {
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		if rule.Action == "" {
+			rule.Action = DenylistBlock
+		}
+		if rule.Action != DenylistBlock && rule.Action != DenylistFlag {
+			return nil, fmt.Errorf("%s: unknown action %q, use block or flag", rule.Name, rule.Action)
+		}
+		if rule.Pattern == "" && len(rule.Keywords) == 0 {
+			return nil, fmt.Errorf("%s: a pattern or keywords are required", rule.Name)
+		}
+		if rule.Pattern != "" {
+			pattern, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", rule.Name, err)
+			}
+			rule.pattern = pattern
+		}
+	}
+	return &Denylist{Rules: rules}, nil
+}
+
+// OverrideDenylist lets the denylists store todos they would reject, flagged with SensitiveTag. It is set for
+// the duration of a request by callers handling the changing requests one at a time.
+func OverrideDenylist(overridden bool) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	denylistOverridden = overridden
+}
+
+// Matches returns the rules matching the title or the description of the todo
+func (d *Denylist) Matches(todo models.Todo) []DenylistRule {
+	text := todo.Title + "\n" + todo.Description
+	var matches []DenylistRule
+	for _, rule := range d.Rules {
+		if rule.matches(text) {
+			matches = append(matches, rule)
+		}
+	}
+	return matches
+}
+
+// BeforeWrite rejects todos matching a blocking rule and tags todos matching a flagging rule.
+// The rejection names the rules but not the content, it must not end up in the logs.
+func (d *Denylist) BeforeWrite(action string, todo models.Todo) (models.Todo, error) {
+	mutex.Lock()
+	overridden := denylistOverridden
+	mutex.Unlock()
+
+	var blocking []string
+	for _, rule := range d.Matches(todo) {
+		if rule.Action == DenylistBlock {
+			blocking = append(blocking, rule.Name)
+		}
+		if todo.HasTag(SensitiveTag) == false {
+			todo.Tags = append(todo.Tags, SensitiveTag)
+			todo.AutoTags = append(todo.AutoTags, SensitiveTag)
+		}
+	}
+	if len(blocking) > 0 && overridden == false {
+		return todo, &RejectedError{Reason: "the todo contains sensitive content (" + strings.Join(blocking, ", ") + ")"}
+	}
+	if len(blocking) > 0 {
+		log.Printf("Denylist overridden on %s of a todo matching %s", action, strings.Join(blocking, ", "))
+	}
+	return todo, nil
+}
+
+func (r DenylistRule) matches(text string) bool {
+	lowered := strings.ToLower(text)
+	for _, keyword := range r.Keywords {
+		if keyword != "" && strings.Contains(lowered, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	if r.pattern == nil {
+		return false
+	}
+	if r.Luhn == false {
+		return r.pattern.MatchString(text)
+	}
+	for _, match := range r.pattern.FindAllString(text, -1) {
+		if passesLuhn(match) {
+			return true
+		}
+	}
+	return false
+}
+
+// passesLuhn tells whether the digits of the value are a number with a valid Luhn check digit,
+// the other characters like spaces and dashes are skipped
+func passesLuhn(value string) bool {
+	sum, digits := 0, 0
+	for i := len(value) - 1; i >= 0; i-- {
+		if value[i] < '0' || value[i] > '9' {
+			continue
+		}
+		digit := int(value[i] - '0')
+		if digits%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		digits++
+	}
+	return digits >= 12 && sum%10 == 0
+}
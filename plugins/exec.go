@@ -0,0 +1,103 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const defaultExecHookTimeout = 5 * time.Second
+
+// ExecHook is an event sink running an external command for todo events.
+// The event is passed as JSON on stdin, its type and todo id in the TODO_EVENT and TODO_ID environment variables.
+type ExecHook struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+	// Events limits the hook to the given event types, all events are passed if empty
+	Events []string `json:"events"`
+	// Timeout after which the command is killed, e.g. "2s", defaults to five seconds
+	Timeout string `json:"timeout"`
+}
+
+// LoadExecHooks reads the hooks from a JSON file containing an array of hooks
+func LoadExecHooks(fileName string) ([]*ExecHook, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var hooks []*ExecHook
+	err = json.Unmarshal(content, &hooks)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hooks file %s: %w", fileName, err)
+	}
+
+	for _, hook := range hooks {
+		if hook.Command == "" {
+			return nil, fmt.Errorf("invalid hooks file %s: hook without command", fileName)
+		}
+		if _, err := hook.timeout(); err != nil {
+			return nil, fmt.Errorf("invalid hooks file %s: %w", fileName, err)
+		}
+	}
+	return hooks, nil
+}
+
+// HandleEvent runs the command if the hook is interested in the event.
+// A command exceeding the timeout is killed, a non-zero exit code is returned as error.
+func (h *ExecHook) HandleEvent(event Event) error {
+	if h.accepts(event.Type) == false {
+		return nil
+	}
+
+	input, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	timeout, err := h.timeout()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	command := exec.CommandContext(ctx, h.Command, h.Args...)
+	command.Stdin = bytes.NewReader(input)
+	command.Stderr = &stderr
+	command.Env = append(os.Environ(), "TODO_EVENT="+event.Type, "TODO_ID="+event.Todo.Id)
+
+	err = command.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%s timed out after %s", h.Command, timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w: %s", h.Command, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func (h *ExecHook) accepts(eventType string) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, accepted := range h.Events {
+		if accepted == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *ExecHook) timeout() (time.Duration, error) {
+	if h.Timeout == "" {
+		return defaultExecHookTimeout, nil
+	}
+	return time.ParseDuration(h.Timeout)
+}
@@ -0,0 +1,306 @@
+package plugins
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"todo-rest-backend/models"
+)
+
+// SlackNotifierName names the built-in notifier posting to a Slack incoming webhook, see ListNotifier
+const SlackNotifierName = "slack"
+
+// ListIntegrations are the webhooks and notifiers of the todos of a list. They receive the events and the
+// notifications of the list in addition to the globally registered sinks and notifiers.
+type ListIntegrations struct {
+	Webhooks  []ListWebhook  `json:"webhooks"`
+	Notifiers []ListNotifier `json:"notifiers"`
+}
+
+// ListWebhook posts the events of the todos of a list to an URL like the WebhookSink
+type ListWebhook struct {
+	Url string `json:"url"`
+	// Secret signs the posted events, it is not returned by the API
+	Secret string `json:"secret,omitempty"`
+	// Events limits the webhook to the event types, all events are posted if empty
+	Events []string `json:"events,omitempty"`
+	// Tags limits the webhook to the todos tagged with one of the tags
+	Tags []string `json:"tags,omitempty"`
+}
+
+// ListNotifier passes the notifications about the todos of a list to a notifier
+type ListNotifier struct {
+	// Notifier is SlackNotifierName, posting to Url, or the name of a notifier registered with RegisterNamedNotifier
+	Notifier string `json:"notifier"`
+	Url      string `json:"url,omitempty"`
+	// Tags limits the notifier to the todos tagged with one of the tags
+	Tags []string `json:"tags,omitempty"`
+}
+
+// Integrations keeps the integrations of the lists of every owner, they are saved to the file if it is named.
+// It is an event sink posting the events to the webhooks of the lists. The zero value keeps them in memory.
+type Integrations struct {
+	FileName string
+
+	mutex sync.Mutex
+	lists map[integrationKey]ListIntegrations
+}
+
+// integrationKey separates the lists of the owners, users naming their lists alike do not see each other's todos
+type integrationKey struct {
+	owner string
+	list  string
+}
+
+// integrationsFile is the content of the integrations file
+type integrationsFile struct {
+	Lists []ownedIntegrations `json:"lists"`
+}
+
+type ownedIntegrations struct {
+	Owner string `json:"owner,omitempty"`
+	List  string `json:"list"`
+	ListIntegrations
+}
+
+var namedNotifiers = make(map[string]Notifier)
+var listIntegrations *Integrations
+
+// NewIntegrations returns the integrations saved in the file
+func NewIntegrations(fileName string) (*Integrations, error) {
+	integrations := &Integrations{FileName: fileName}
+	content, err := os.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return integrations, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var saved integrationsFile
+	err = json.Unmarshal(content, &saved)
+	if err != nil {
+		return nil, err
+	}
+	integrations.lists = make(map[integrationKey]ListIntegrations)
+	for _, owned := range saved.Lists {
+		integrations.lists[integrationKey{owned.Owner, owned.List}] = owned.ListIntegrations
+	}
+	return integrations, nil
+}
+
+// RegisterIntegrations makes Notify pass the notifications to the notifiers of the lists as well
+func RegisterIntegrations(integrations *Integrations) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	listIntegrations = integrations
+}
+
+// RegisterNamedNotifier makes a notifier available to the lists under the given name. Unlike the notifiers
+// added with RegisterNotifier it only receives the notifications of the lists naming it.
+// It panics if the name is registered twice.
+func RegisterNamedNotifier(name string, notifier Notifier) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if _, ok := namedNotifiers[name]; ok || name == SlackNotifierName {
+		panic("plugins: notifier " + name + " registered twice")
+	}
+	namedNotifiers[name] = notifier
+}
+
+// Get returns the integrations of the list of the owner
+func (i *Integrations) Get(owner string, list string) ListIntegrations {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	integrations := i.lists[integrationKey{owner, list}]
+	if integrations.Webhooks == nil {
+		integrations.Webhooks = []ListWebhook{}
+	}
+	if integrations.Notifiers == nil {
+		integrations.Notifiers = []ListNotifier{}
+	}
+	return integrations
+}
+
+// Set replaces the integrations of the list of the owner and saves them, see Validate
+func (i *Integrations) Set(owner string, list string, integrations ListIntegrations) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if i.lists == nil {
+		i.lists = make(map[integrationKey]ListIntegrations)
+	}
+	key := integrationKey{owner, list}
+	if len(integrations.Webhooks) == 0 && len(integrations.Notifiers) == 0 {
+		delete(i.lists, key)
+	} else {
+		i.lists[key] = integrations
+	}
+	return i.save()
+}
+
+// Accepts tells whether a webhook of the list of the todo is interested in the event
+func (i *Integrations) Accepts(event Event) bool {
+	return len(i.webhooksFor(event)) > 0
+}
+
+// HandleEvent posts the event to the webhooks of the list of the todo. The event is posted again to all of
+// them if one fails, their receivers must tolerate an event delivered twice.
+func (i *Integrations) HandleEvent(event Event) error {
+	var failures []error
+	for _, webhook := range i.webhooksFor(event) {
+		sink := NewWebhookSink(webhook.Url)
+		sink.Secret = webhook.Secret
+		err := sink.HandleEvent(event)
+		if err != nil {
+			failures = append(failures, err)
+		}
+	}
+	return errors.Join(failures...)
+}
+
+// webhooksFor returns the webhooks of the list of the todo interested in the event
+func (i *Integrations) webhooksFor(event Event) []ListWebhook {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	var webhooks []ListWebhook
+	for _, webhook := range i.lists[keyOf(event.Todo)].Webhooks {
+		if (len(webhook.Events) == 0 || contains(webhook.Events, event.Type)) && taggedWithAny(event.Todo, webhook.Tags) {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	return webhooks
+}
+
+// notifiersFor returns the notifiers of the list of the todo
+func (i *Integrations) notifiersFor(todo models.Todo) []Notifier {
+	i.mutex.Lock()
+	configured := i.lists[keyOf(todo)].Notifiers
+	i.mutex.Unlock()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	var notifiers []Notifier
+	for _, notifier := range configured {
+		if taggedWithAny(todo, notifier.Tags) == false {
+			continue
+		}
+		if notifier.Notifier == SlackNotifierName {
+			notifiers = append(notifiers, NewSlackNotifier(notifier.Url))
+		} else if named, ok := namedNotifiers[notifier.Notifier]; ok {
+			notifiers = append(notifiers, named)
+		}
+	}
+	return notifiers
+}
+
+// save replaces the integrations file, a crash while writing leaves the previous file, the mutex must be held
+func (i *Integrations) save() error {
+	if i.FileName == "" {
+		return nil
+	}
+	saved := integrationsFile{Lists: []ownedIntegrations{}}
+	for key, integrations := range i.lists {
+		saved.Lists = append(saved.Lists, ownedIntegrations{Owner: key.owner, List: key.list, ListIntegrations: integrations})
+	}
+	sort.Slice(saved.Lists, func(a, b int) bool {
+		if saved.Lists[a].Owner != saved.Lists[b].Owner {
+			return saved.Lists[a].Owner < saved.Lists[b].Owner
+		}
+		return saved.Lists[a].List < saved.Lists[b].List
+	})
+	content, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	temporary := filepath.Join(filepath.Dir(i.FileName), "."+filepath.Base(i.FileName)+".tmp")
+	err = os.WriteFile(temporary, content, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(temporary, i.FileName)
+}
+
+// Validate checks the URLs, the event types, the notifiers and the tags and returns all violations as
+// models.ValidationErrors, nil for valid integrations
+func (l ListIntegrations) Validate() error {
+	var violations models.ValidationErrors
+	violation := func(field string, message string) {
+		violations = append(violations, models.FieldError{Field: field, Message: message})
+	}
+	validateTags := func(field string, tags []string) {
+		for j, tag := range tags {
+			if models.ValidateTag(tag) != nil {
+				violation(field+".tags."+strconv.Itoa(j), "is not a valid tag")
+			}
+		}
+	}
+
+	for i, webhook := range l.Webhooks {
+		field := "webhooks." + strconv.Itoa(i)
+		if isWebUrl(webhook.Url) == false {
+			violation(field+".url", "must be an http or https URL")
+		}
+		for j, eventType := range webhook.Events {
+			if contains([]string{TodoCreated, TodoUpdated, TodoDeleted}, eventType) == false {
+				violation(field+".events."+strconv.Itoa(j), "is not an event type")
+			}
+		}
+		validateTags(field, webhook.Tags)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	for i, notifier := range l.Notifiers {
+		field := "notifiers." + strconv.Itoa(i)
+		_, named := namedNotifiers[notifier.Notifier]
+		if notifier.Notifier == SlackNotifierName && isWebUrl(notifier.Url) == false {
+			violation(field+".url", "must be the http or https URL of the Slack webhook")
+		} else if notifier.Notifier != SlackNotifierName && named == false {
+			violation(field+".notifier", "is not a registered notifier")
+		}
+		validateTags(field, notifier.Tags)
+	}
+
+	if len(violations) > 0 {
+		return violations
+	}
+	return nil
+}
+
+// keyOf returns the key of the integrations of the list of the todo
+func keyOf(todo models.Todo) integrationKey {
+	return integrationKey{models.OwnerOf(todo), todo.List}
+}
+
+// taggedWithAny tells whether the todo is tagged with one of the tags, every todo is for no tags
+func taggedWithAny(todo models.Todo, tags []string) bool {
+	for _, tag := range tags {
+		if todo.HasTag(tag) {
+			return true
+		}
+	}
+	return len(tags) == 0
+}
+
+func isWebUrl(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+func contains(values []string, value string) bool {
+	for _, current := range values {
+		if current == value {
+			return true
+		}
+	}
+	return false
+}
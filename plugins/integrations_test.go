@@ -0,0 +1,96 @@
+package plugins
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"todo-rest-backend/models"
+)
+
+// recordingNotifier keeps the subjects of the notifications
+type recordingNotifier struct {
+	subjects []string
+}
+
+func (n *recordingNotifier) Notify(notification Notification) error {
+	n.subjects = append(n.subjects, notification.Subject)
+	return nil
+}
+
+func TestIntegrations_RouteEventsAndNotificationsByList(t *testing.T) {
+	// Arrange
+	//
+	var posted []Event
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var event Event
+		json.NewDecoder(request.Body).Decode(&event)
+		posted = append(posted, event)
+	}))
+	defer server.Close()
+	fileName := filepath.Join(t.TempDir(), "integrations.json")
+	integrations, _ := NewIntegrations(fileName)
+	home := &recordingNotifier{}
+	RegisterNamedNotifier("test-home", home)
+	defer delete(namedNotifiers, "test-home")
+	RegisterIntegrations(integrations)
+	defer RegisterIntegrations(nil)
+	work := models.Todo{Id: "1", Title: "Write report", List: "work", Tags: []string{"urgent"}}
+	untagged := models.Todo{Id: "2", Title: "File expenses", List: "work", Tags: []string{}}
+	chores := models.Todo{Id: "3", Title: "Mow the lawn", List: "home", Tags: []string{}}
+
+	// Act
+	//
+	err := integrations.Set("", "work", ListIntegrations{Webhooks: []ListWebhook{
+		{Url: server.URL, Events: []string{TodoCreated}, Tags: []string{"urgent"}},
+	}})
+	integrations.Set("", "home", ListIntegrations{Notifiers: []ListNotifier{{Notifier: "test-home"}}})
+	reloaded, errReloaded := NewIntegrations(fileName)
+	accepted := []bool{
+		integrations.Accepts(Event{Type: TodoCreated, Todo: work}),
+		integrations.Accepts(Event{Type: TodoUpdated, Todo: work}),
+		integrations.Accepts(Event{Type: TodoCreated, Todo: untagged}),
+		integrations.Accepts(Event{Type: TodoCreated, Todo: chores}),
+	}
+	errDelivery := reloaded.HandleEvent(Event{Type: TodoCreated, Todo: work})
+	Notify(Notification{Subject: "Still waiting", Todo: chores})
+	Notify(Notification{Subject: "Still waiting", Todo: work})
+
+	// Assert
+	//
+	if err != nil || errReloaded != nil || len(reloaded.Get("", "home").Notifiers) != 1 {
+		t.Error("Fehler", err, errReloaded, reloaded.Get("", "home"))
+	}
+	if accepted[0] == false || accepted[1] || accepted[2] || accepted[3] {
+		t.Error("Fehler", accepted)
+	}
+	if errDelivery != nil || len(posted) != 1 || posted[0].Todo.Id != "1" {
+		t.Error("Fehler", errDelivery, posted)
+	}
+	if len(home.subjects) != 1 {
+		t.Error("Fehler", home.subjects)
+	}
+}
+
+func TestListIntegrations_Validate(t *testing.T) {
+	// Arrange
+	//
+	integrations := ListIntegrations{
+		Webhooks:  []ListWebhook{{Url: "ftp://example.org", Events: []string{"todo.archived"}}},
+		Notifiers: []ListNotifier{{Notifier: SlackNotifierName}, {Notifier: "pager"}},
+	}
+
+	// Act
+	//
+	err := integrations.Validate()
+
+	// Assert
+	//
+	violations, ok := err.(models.ValidationErrors)
+	if ok == false || len(violations) != 4 || violations[0].Field != "webhooks.0.url" ||
+		violations[1].Field != "webhooks.0.events.0" || violations[2].Field != "notifiers.0.url" ||
+		violations[3].Field != "notifiers.1.notifier" {
+		t.Error("Fehler", err)
+	}
+}
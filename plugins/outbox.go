@@ -0,0 +1,355 @@
+package plugins
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"todo-rest-backend/models"
+)
+
+// Outbox delivers the events to a sink reliably: an event is written to the outbox file when it is emitted,
+// before the request is answered, and removed once the sink accepted it. Failed deliveries are retried
+// with exponential backoff, pending events survive restarts. Events failing MaxAttempts times are moved to
+// the dead letters, they are only delivered again by Redeliver. The sink must tolerate an event delivered
+// twice, e.g. after a crash right after the delivery.
+type Outbox struct {
+	Name     string
+	Sink     EventSink
+	FileName string
+	// MinBackoff is the wait after the first failed attempt, it doubles with every further one up to MaxBackoff
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// MaxAttempts is the number of deliveries before an event is given up, 0 retries until it is delivered
+	MaxAttempts int
+	// Filter limits the outbox to the events it accepts, all events are written to the outbox if nil
+	Filter func(event Event) bool
+
+	mutex   sync.Mutex
+	content outboxFile
+	wake    chan struct{}
+	stop    chan struct{}
+}
+
+// OutboxEntry is an event waiting for its delivery
+type OutboxEntry struct {
+	Id            string    `json:"id"`
+	Event         Event     `json:"event"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LastError     string    `json:"last_error,omitempty"`
+	// GivenUpAt is set for the dead letters
+	GivenUpAt *time.Time `json:"given_up_at,omitempty"`
+}
+
+// OutboxStatus describes the deliveries of an outbox
+type OutboxStatus struct {
+	Name        string        `json:"name"`
+	Pending     int           `json:"pending"`
+	Delivered   int           `json:"delivered"`
+	DeadLetters int           `json:"dead_letters"`
+	Entries     []OutboxEntry `json:"entries"`
+}
+
+// DeadLetter is an event an outbox gave up, its id names the outbox, e.g. "webhook-12"
+type DeadLetter struct {
+	OutboxEntry
+	Id     string `json:"id"`
+	Outbox string `json:"outbox"`
+}
+
+// ErrUnknownDeadLetter is returned for redeliveries of events that are no dead letters
+var ErrUnknownDeadLetter = errors.New("unknown dead letter")
+
+// outboxFile is the content of the outbox file
+type outboxFile struct {
+	NextId      int           `json:"next_id"`
+	Delivered   int           `json:"delivered"`
+	Entries     []OutboxEntry `json:"entries"`
+	DeadLetters []OutboxEntry `json:"dead_letters"`
+}
+
+var outboxes []*Outbox
+
+// NewOutbox returns an outbox for the sink with the events left pending in the file,
+// the backoff starts at a second and grows up to an hour
+func NewOutbox(name string, sink EventSink, fileName string) (*Outbox, error) {
+	outbox := &Outbox{Name: name, Sink: sink, FileName: fileName, MinBackoff: time.Second, MaxBackoff: time.Hour,
+		wake: make(chan struct{}, 1), stop: make(chan struct{})}
+	content, err := os.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return outbox, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(content, &outbox.content)
+	if err != nil {
+		return nil, err
+	}
+	err = openEntries(outbox.content.Entries)
+	if err == nil {
+		err = openEntries(outbox.content.DeadLetters)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(outbox.content.Entries) > 0 {
+		log.Printf("Outbox %s: %d events pending", name, len(outbox.content.Entries))
+	}
+	return outbox, nil
+}
+
+// RegisterOutbox adds an outbox receiving all todo events and starts its delivery
+func RegisterOutbox(outbox *Outbox) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	outboxes = append(outboxes, outbox)
+	go outbox.deliver()
+}
+
+// Outboxes returns the status of the registered outboxes
+func Outboxes() []OutboxStatus {
+	mutex.Lock()
+	registered := outboxes
+	mutex.Unlock()
+
+	statuses := []OutboxStatus{}
+	for _, outbox := range registered {
+		statuses = append(statuses, outbox.Status())
+	}
+	return statuses
+}
+
+// Status describes the pending events and counts the delivered ones
+func (o *Outbox) Status() OutboxStatus {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	entries := make([]OutboxEntry, len(o.content.Entries))
+	copy(entries, o.content.Entries)
+	return OutboxStatus{Name: o.Name, Pending: len(entries), Delivered: o.content.Delivered,
+		DeadLetters: len(o.content.DeadLetters), Entries: entries}
+}
+
+// DeadLetters returns the events the registered outboxes gave up, the oldest first per outbox
+func DeadLetters() []DeadLetter {
+	mutex.Lock()
+	registered := outboxes
+	mutex.Unlock()
+
+	letters := []DeadLetter{}
+	for _, outbox := range registered {
+		outbox.mutex.Lock()
+		for _, entry := range outbox.content.DeadLetters {
+			letters = append(letters, DeadLetter{OutboxEntry: entry, Id: outbox.Name + "-" + entry.Id, Outbox: outbox.Name})
+		}
+		outbox.mutex.Unlock()
+	}
+	return letters
+}
+
+// Redeliver moves the dead letter with the id back into its outbox, it is delivered with a fresh count of
+// attempts, e.g. after the receiver was fixed
+func Redeliver(id string) (DeadLetter, error) {
+	mutex.Lock()
+	registered := outboxes
+	mutex.Unlock()
+
+	for _, outbox := range registered {
+		entryId, found := strings.CutPrefix(id, outbox.Name+"-")
+		if found == false {
+			continue
+		}
+		entry, ok := outbox.redeliver(entryId)
+		if ok {
+			return DeadLetter{OutboxEntry: entry, Id: id, Outbox: outbox.Name}, nil
+		}
+	}
+	return DeadLetter{}, ErrUnknownDeadLetter
+}
+
+// redeliver moves the dead letter back to the pending entries
+func (o *Outbox) redeliver(id string) (OutboxEntry, bool) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	for i, entry := range o.content.DeadLetters {
+		if entry.Id != id {
+			continue
+		}
+		o.content.DeadLetters = append(o.content.DeadLetters[:i], o.content.DeadLetters[i+1:]...)
+		entry.Attempts, entry.GivenUpAt, entry.NextAttemptAt = 0, nil, time.Now()
+		o.content.Entries = append(o.content.Entries, entry)
+		err := o.save()
+		if err != nil {
+			log.Printf("Outbox %s: cannot save: %v", o.Name, err)
+		}
+		o.wakeUp()
+		return entry, true
+	}
+	return OutboxEntry{}, false
+}
+
+// Stop stops the delivery, the pending events stay in the file
+func (o *Outbox) Stop() {
+	close(o.stop)
+}
+
+// enqueue writes the event to the outbox file and wakes up the delivery
+func (o *Outbox) enqueue(event Event) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.content.NextId++
+	o.content.Entries = append(o.content.Entries, OutboxEntry{Id: strconv.Itoa(o.content.NextId), Event: event,
+		NextAttemptAt: time.Now()})
+	err := o.save()
+	o.wakeUp()
+	return err
+}
+
+// wakeUp makes the delivery look for due events
+func (o *Outbox) wakeUp() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliver passes the due events to the sink in their order until the outbox is stopped
+func (o *Outbox) deliver() {
+	for {
+		entry, wait, ok := o.next()
+		if ok && wait <= 0 {
+			err := handleEventIsolated(o.Sink, entry.Event)
+			o.attempted(entry.Id, err)
+			continue
+		}
+		var due <-chan time.Time
+		var timer *time.Timer
+		if ok {
+			timer = time.NewTimer(wait)
+			due = timer.C
+		}
+		select {
+		case <-o.stop:
+			return
+		case <-o.wake:
+		case <-due:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// next returns the entry to deliver next and how long it is to wait for
+func (o *Outbox) next() (OutboxEntry, time.Duration, bool) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if len(o.content.Entries) == 0 {
+		return OutboxEntry{}, 0, false
+	}
+	next := o.content.Entries[0]
+	for _, entry := range o.content.Entries[1:] {
+		if entry.NextAttemptAt.Before(next.NextAttemptAt) {
+			next = entry
+		}
+	}
+	return next, time.Until(next.NextAttemptAt), true
+}
+
+// attempted removes a delivered entry or schedules the next attempt of a failed one
+func (o *Outbox) attempted(id string, err error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	for i := range o.content.Entries {
+		entry := &o.content.Entries[i]
+		if entry.Id != id {
+			continue
+		}
+		if err == nil {
+			o.content.Entries = append(o.content.Entries[:i], o.content.Entries[i+1:]...)
+			o.content.Delivered++
+			break
+		}
+		entry.Attempts++
+		entry.LastError = err.Error()
+		entry.NextAttemptAt = time.Now().Add(o.backoff(entry.Attempts))
+		log.Printf("Outbox %s: delivery %d of %s of todo %s failed: %v", o.Name, entry.Attempts, entry.Event.Type,
+			entry.Event.Todo.Id, err)
+		if o.MaxAttempts > 0 && entry.Attempts >= o.MaxAttempts {
+			givenUpAt := time.Now().UTC()
+			dead := *entry
+			dead.GivenUpAt = &givenUpAt
+			o.content.DeadLetters = append(o.content.DeadLetters, dead)
+			o.content.Entries = append(o.content.Entries[:i], o.content.Entries[i+1:]...)
+			log.Printf("Outbox %s: gave up %s of todo %s, it is dead letter %s-%s", o.Name, dead.Event.Type,
+				dead.Event.Todo.Id, o.Name, dead.Id)
+		}
+		break
+	}
+	err = o.save()
+	if err != nil {
+		log.Printf("Outbox %s: cannot save: %v", o.Name, err)
+	}
+}
+
+// backoff is the wait after the failed attempts
+func (o *Outbox) backoff(attempts int) time.Duration {
+	backoff := o.MinBackoff
+	for i := 1; i < attempts && backoff < o.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > o.MaxBackoff {
+		return o.MaxBackoff
+	}
+	return backoff
+}
+
+// save replaces the outbox file, a crash while writing leaves the previous file, the mutex must be held.
+// The descriptions of the todos are encrypted like in the data files.
+func (o *Outbox) save() error {
+	stored := o.content
+	stored.Entries = sealEntries(o.content.Entries)
+	stored.DeadLetters = sealEntries(o.content.DeadLetters)
+	content, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	temporary := filepath.Join(filepath.Dir(o.FileName), "."+filepath.Base(o.FileName)+".tmp")
+	err = os.WriteFile(temporary, content, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(temporary, o.FileName)
+}
+
+// sealEntries returns copies of the entries with the descriptions of their todos encrypted
+func sealEntries(entries []OutboxEntry) []OutboxEntry {
+	if entries == nil {
+		return nil
+	}
+	sealed := make([]OutboxEntry, len(entries))
+	for i, entry := range entries {
+		entry.Event.Todo = models.SealTodo(entry.Event.Todo)
+		sealed[i] = entry
+	}
+	return sealed
+}
+
+// openEntries decrypts the descriptions of the todos of the entries read from the outbox file
+func openEntries(entries []OutboxEntry) error {
+	for i := range entries {
+		todo, err := models.OpenTodo(entries[i].Event.Todo)
+		if err != nil {
+			return err
+		}
+		entries[i].Event.Todo = todo
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package plugins
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"todo-rest-backend/models"
+)
+
+// flakySink fails the first deliveries and passes the later ones on
+type flakySink struct {
+	failures  int
+	delivered chan Event
+}
+
+func (s *flakySink) HandleEvent(event Event) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("receiver unavailable")
+	}
+	s.delivered <- event
+	return nil
+}
+
+func TestOutbox_RetriesUntilDelivered(t *testing.T) {
+	// Arrange
+	//
+	fileName := filepath.Join(t.TempDir(), "outbox.json")
+	sink := &flakySink{failures: 2, delivered: make(chan Event, 1)}
+	outbox, _ := NewOutbox("test", sink, fileName)
+	outbox.MinBackoff = 10 * time.Millisecond
+	defer outbox.Stop()
+
+	// Act
+	//
+	outbox.enqueue(Event{Type: TodoCreated, Todo: models.Todo{Id: "7"}})
+	restarted, err := NewOutbox("test", sink, fileName)
+	go outbox.deliver()
+
+	// Assert
+	//
+	if err != nil || restarted.Status().Pending != 1 {
+		t.Fatal("Fehler", err, restarted.Status())
+	}
+	select {
+	case event := <-sink.delivered:
+		if event.Todo.Id != "7" {
+			t.Error("Fehler", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Fehler", outbox.Status())
+	}
+	time.Sleep(10 * time.Millisecond)
+	status := outbox.Status()
+	if status.Pending != 0 || status.Delivered != 1 {
+		t.Error("Fehler", status)
+	}
+}
+
+func TestOutbox_Backoff(t *testing.T) {
+	// Arrange
+	//
+	outbox := &Outbox{MinBackoff: time.Second, MaxBackoff: time.Minute}
+
+	// Act
+	//
+	first, third, capped := outbox.backoff(1), outbox.backoff(3), outbox.backoff(20)
+
+	// Assert
+	//
+	if first != time.Second || third != 4*time.Second || capped != time.Minute {
+		t.Error("Fehler", first, third, capped)
+	}
+}
+
+func TestOutbox_GivesUpAfterMaxAttempts(t *testing.T) {
+	// Arrange
+	//
+	sink := &flakySink{failures: 2, delivered: make(chan Event, 1)}
+	outbox, _ := NewOutbox("test", sink, filepath.Join(t.TempDir(), "outbox.json"))
+	outbox.MinBackoff, outbox.MaxAttempts = time.Millisecond, 2
+	defer outbox.Stop()
+	go outbox.deliver()
+
+	// Act
+	//
+	outbox.enqueue(Event{Type: TodoDeleted, Todo: models.Todo{Id: "3"}})
+	for i := 0; i < 500 && outbox.Status().DeadLetters == 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	given := outbox.Status()
+	_, unknown := outbox.redeliver("99")
+	entry, redelivered := outbox.redeliver("1")
+
+	// Assert
+	//
+	if given.DeadLetters != 1 || given.Pending != 0 || unknown {
+		t.Fatal("Fehler", given, unknown)
+	}
+	if redelivered == false || entry.Attempts != 0 || entry.GivenUpAt != nil {
+		t.Error("Fehler", redelivered, entry)
+	}
+	select {
+	case event := <-sink.delivered:
+		if event.Todo.Id != "3" {
+			t.Error("Fehler", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Fehler", outbox.Status())
+	}
+}
+
+func TestOutbox_EncryptsTheDescriptions(t *testing.T) {
+	// Arrange
+	//
+	defer models.SetEncryptionKeys("", nil)
+	models.SetEncryptionKeys("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=", nil)
+	fileName := filepath.Join(t.TempDir(), "outbox.json")
+	outbox, _ := NewOutbox("test", &flakySink{delivered: make(chan Event, 1)}, fileName)
+
+	// Act
+	//
+	outbox.enqueue(Event{Type: TodoCreated, Todo: models.Todo{Id: "5", Description: "PIN 4711"}})
+	content, _ := os.ReadFile(fileName)
+	restarted, err := NewOutbox("test", outbox.Sink, fileName)
+
+	// Assert
+	//
+	if strings.Contains(string(content), "4711") {
+		t.Error("Fehler, the description is not encrypted:", string(content))
+	}
+	if err != nil || len(restarted.Status().Entries) != 1 || restarted.Status().Entries[0].Event.Todo.Description != "PIN 4711" {
+		t.Error("Fehler", err, restarted.Status())
+	}
+	if outbox.Status().Entries[0].Event.Todo.Description != "PIN 4711" {
+		t.Error("Fehler", outbox.Status())
+	}
+}
@@ -0,0 +1,205 @@
+// Package plugins contains the extension points to compile custom storage backends,
+// notifiers and event sinks into the todo backend.
+//
+// Plugins register themselves in an init function and are activated by importing their package
+// for its side effects in main:
+//
+//	import _ "example.com/todo-redis-storage"
+package plugins
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+	"todo-rest-backend/models"
+)
+
+// StoragePlugin persists the todo store, see models.Storage
+type StoragePlugin = models.Storage
+
+// Event types emitted for changes of todos
+const (
+	TodoCreated = "todo.created"
+	TodoUpdated = "todo.updated"
+	TodoDeleted = "todo.deleted"
+)
+
+// Event describes a change of a todo
+type Event struct {
+	Type string      `json:"type"`
+	Todo models.Todo `json:"todo"`
+	Time time.Time   `json:"time"`
+}
+
+// EventSink receives every todo event, e.g. to forward it to a message queue
+type EventSink interface {
+	HandleEvent(event Event) error
+}
+
+// Notification is a message addressed to the user of the backend
+type Notification struct {
+	Subject string      `json:"subject"`
+	Message string      `json:"message"`
+	Todo    models.Todo `json:"todo"`
+}
+
+// Notifier delivers notifications, e.g. by mail or chat
+type Notifier interface {
+	Notify(notification Notification) error
+}
+
+var mutex sync.Mutex
+var storageFactories = make(map[string]func() (StoragePlugin, error))
+var eventSinks []EventSink
+var notifiers []Notifier
+
+// events decouples the request handlers from slow sinks, a single worker keeps the event order
+var events = make(chan Event, 256)
+var startWorker sync.Once
+
+// RegisterStorage makes a storage backend available under the given name.
+// It panics if the name is registered twice.
+func RegisterStorage(name string, factory func() (StoragePlugin, error)) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if _, ok := storageFactories[name]; ok {
+		panic("plugins: storage " + name + " registered twice")
+	}
+	storageFactories[name] = factory
+}
+
+// NewStorage creates the storage registered under the given name
+func NewStorage(name string) (StoragePlugin, error) {
+	mutex.Lock()
+	factory, ok := storageFactories[name]
+	mutex.Unlock()
+
+	if ok == false {
+		return nil, fmt.Errorf("unknown storage %q, registered are %v", name, StorageNames())
+	}
+	return factory()
+}
+
+// StorageNames returns the names of the registered storage backends
+func StorageNames() []string {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	var names []string
+	for name := range storageFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RegisterEventSink adds a sink receiving all todo events
+func RegisterEventSink(sink EventSink) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	eventSinks = append(eventSinks, sink)
+}
+
+// RegisterNotifier adds a notifier receiving all notifications
+func RegisterNotifier(notifier Notifier) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	notifiers = append(notifiers, notifier)
+}
+
+// Emit writes the event to the registered outboxes and passes it to the registered sinks in the background.
+// Errors of the sinks are logged. Emit does not wait for slow sinks, while the sinks are behind by the capacity
+// of the queue the event is dropped and logged; the outboxes keep it for their sinks anyway.
+func Emit(eventType string, todo models.Todo) {
+	startWorker.Do(func() {
+		go dispatchEvents()
+	})
+	event := Event{Type: eventType, Todo: todo, Time: models.Now()}
+
+	mutex.Lock()
+	registered := outboxes
+	mutex.Unlock()
+	for _, outbox := range registered {
+		if outbox.Filter != nil && outbox.Filter(event) == false {
+			continue
+		}
+		err := outbox.enqueue(event)
+		if err != nil {
+			log.Printf("Outbox %s cannot keep %s of todo %s: %v", outbox.Name, event.Type, event.Todo.Id, err)
+		}
+	}
+	select {
+	case events <- event:
+	default:
+		log.Printf("Event sinks are behind, %s of todo %s is dropped", event.Type, event.Todo.Id)
+	}
+}
+
+func dispatchEvents() {
+	for event := range events {
+		mutex.Lock()
+		sinks := eventSinks
+		mutex.Unlock()
+
+		for _, sink := range sinks {
+			err := handleEventIsolated(sink, event)
+			if err != nil {
+				log.Printf("Event sink %T failed for %s of todo %s: %v", sink, event.Type, event.Todo.Id, err)
+			}
+		}
+	}
+}
+
+// handleEventIsolated keeps a panicking sink from stopping the delivery to the other sinks
+func handleEventIsolated(sink EventSink, event Event) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return sink.HandleEvent(event)
+}
+
+// Notify passes the notification to all registered notifiers and to the notifiers of the list of the todo
+func Notify(notification Notification) {
+	mutex.Lock()
+	registered := notifiers
+	integrations := listIntegrations
+	mutex.Unlock()
+
+	if integrations != nil {
+		registered = append(registered[:len(registered):len(registered)], integrations.notifiersFor(notification.Todo)...)
+	}
+	for _, notifier := range registered {
+		err := notifier.Notify(notification)
+		if err != nil {
+			log.Printf("Notifier %T failed for %q: %v", notifier, notification.Subject, err)
+		}
+	}
+}
+
+func init() {
+	RegisterStorage("csv", func() (StoragePlugin, error) {
+		return models.CsvStorage{FileName: models.DataPath(models.FileName)}, nil
+	})
+	RegisterStorage("csv.gz", func() (StoragePlugin, error) {
+		return models.CsvStorage{FileName: models.DataPath(models.CompressedFileName), Compress: true}, nil
+	})
+	RegisterStorage("sharded", func() (StoragePlugin, error) {
+		return &models.ShardedStorage{CsvStorage: models.CsvStorage{FileName: models.DataPath(models.FileName)},
+			Dir: models.DataPath(models.ShardsDirName)}, nil
+	})
+	RegisterStorage("json", func() (StoragePlugin, error) {
+		return &models.JsonStorage{FileName: models.DataPath(models.JsonFileName),
+			LegacyFileName: models.DataPath(models.FileName)}, nil
+	})
+	RegisterStorage("json.gz", func() (StoragePlugin, error) {
+		return &models.JsonStorage{FileName: models.DataPath(models.CompressedJsonFileName),
+			LegacyFileName: models.DataPath(models.FileName), Compress: true}, nil
+	})
+}
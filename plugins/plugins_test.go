@@ -0,0 +1,174 @@
+package plugins
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+	"todo-rest-backend/models"
+)
+
+type channelSink chan Event
+
+func (s channelSink) HandleEvent(event Event) error {
+	s <- event
+	return nil
+}
+
+func TestEmit(t *testing.T) {
+	// Arrange
+	//
+	sink := make(channelSink, 1)
+	RegisterEventSink(sink)
+
+	// Act
+	//
+	Emit(TodoCreated, models.Todo{Id: "7"})
+
+	// Assert
+	//
+	select {
+	case event := <-sink:
+		if event.Type != TodoCreated || event.Todo.Id != "7" {
+			t.Errorf("unexpected event %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Error("event was not delivered")
+	}
+}
+
+// blockingSink handles the events once it is released
+type blockingSink chan struct{}
+
+func (s blockingSink) HandleEvent(Event) error {
+	<-s
+	return nil
+}
+
+func TestEmit_DoesNotWaitForSlowSinks(t *testing.T) {
+	// Arrange
+	//
+	sink := make(blockingSink)
+	defer close(sink)
+	RegisterEventSink(sink)
+	emitted := make(chan bool)
+
+	// Act
+	//
+	go func() {
+		for i := 0; i < 2*cap(events); i++ {
+			Emit(TodoUpdated, models.Todo{Id: "7"})
+		}
+		emitted <- true
+	}()
+
+	// Assert
+	//
+	select {
+	case <-emitted:
+	case <-time.After(time.Second):
+		t.Error("Emit waited for the sinks")
+	}
+}
+
+func TestRegisterStorage_Twice(t *testing.T) {
+	// Assert
+	//
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a storage name twice must panic")
+		}
+	}()
+
+	// Act
+	//
+	RegisterStorage("csv", nil)
+}
+
+func TestNewStorage_CompressedJson(t *testing.T) {
+	// Act
+	//
+	storage, err := NewStorage("json.gz")
+
+	// Assert
+	//
+	json, ok := storage.(*models.JsonStorage)
+	if err != nil || ok == false || json.Compress == false || filepath.Base(json.FileName) != models.CompressedJsonFileName {
+		t.Errorf("json.gz is %#v, %v", storage, err)
+	}
+}
+
+func TestExecHook_HandleEvent(t *testing.T) {
+	// Arrange
+	//
+	hook := &ExecHook{Command: "sh", Args: []string{"-c", `grep -q '"id":"7"' && test "$TODO_EVENT" = todo.created`}}
+
+	// Act
+	//
+	err := hook.HandleEvent(Event{Type: TodoCreated, Todo: models.Todo{Id: "7"}})
+
+	// Assert
+	//
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExecHook_Timeout(t *testing.T) {
+	// Arrange
+	//
+	hook := &ExecHook{Command: "sleep", Args: []string{"5"}, Timeout: "50ms"}
+
+	// Act
+	//
+	err := hook.HandleEvent(Event{Type: TodoCreated})
+
+	// Assert
+	//
+	if err == nil {
+		t.Error("expected the hook to time out")
+	}
+}
+
+func TestScriptHook_BeforeWrite(t *testing.T) {
+	// Arrange
+	//
+	hook, err := NewScriptHook("test.tengo", []byte(`
+text := import("text")
+if text.has_prefix(todo.title, "!") { todo.title = text.trim_prefix(todo.title, "!") + " (urgent)" }
+if todo.title == "" { reject = "title is required" }
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	//
+	got, err := hook.BeforeWrite(ActionCreate, models.Todo{Title: "!Call"})
+	_, rejectErr := hook.BeforeWrite(ActionCreate, models.Todo{})
+
+	// Assert
+	//
+	if err != nil || got.Title != "Call (urgent)" {
+		t.Errorf("got %+v, %v", got, err)
+	}
+	if _, ok := rejectErr.(*RejectedError); ok == false {
+		t.Errorf("got %v, want the todo to be rejected", rejectErr)
+	}
+}
+
+func TestClassifierHook_BeforeWrite(t *testing.T) {
+	// Arrange
+	//
+	classifier := &KeywordClassifier{Keywords: map[string][]string{"shopping": {"buy"}, "work": {"report"}}}
+	hook := &ClassifierHook{Classifier: classifier}
+
+	// Act
+	//
+	got, _ := hook.BeforeWrite(ActionCreate, models.Todo{Title: "Buy milk", Tags: []string{"home"}})
+
+	// Assert
+	//
+	if len(got.Tags) != 2 || got.Tags[1] != "shopping" || len(got.AutoTags) != 1 || got.AutoTags[0] != "shopping" {
+		t.Errorf("got tags %v and auto tags %v", got.Tags, got.AutoTags)
+	}
+}
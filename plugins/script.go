@@ -0,0 +1,187 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"github.com/d5/tengo/v2"
+	"github.com/d5/tengo/v2/stdlib"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+	"todo-rest-backend/models"
+)
+
+// Write actions passed to the write hooks
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+)
+
+// scriptTimeout bounds a single script run so that a looping script cannot block a request
+const scriptTimeout = time.Second
+
+// WriteHook can change or reject a todo before it is stored
+type WriteHook interface {
+	BeforeWrite(action string, todo models.Todo) (models.Todo, error)
+}
+
+// RejectedError is returned by a write hook refusing to store a todo
+type RejectedError struct {
+	Reason string
+}
+
+func (e *RejectedError) Error() string {
+	return "todo rejected: " + e.Reason
+}
+
+var writeHooks []WriteHook
+
+// RegisterWriteHook adds a hook run before todos are created or updated
+func RegisterWriteHook(hook WriteHook) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	writeHooks = append(writeHooks, hook)
+}
+
+// BeforeWrite runs the registered write hooks in registration order.
+// A *RejectedError is returned if a hook refuses the todo.
+func BeforeWrite(action string, todo models.Todo) (models.Todo, error) {
+	mutex.Lock()
+	hooks := writeHooks
+	mutex.Unlock()
+
+	for _, hook := range hooks {
+		var err error
+		todo, err = hook.BeforeWrite(action, todo)
+		if err != nil {
+			return todo, err
+		}
+	}
+	return todo, nil
+}
+
+// ScriptHook runs a Tengo script (https://github.com/d5/tengo) before a todo is written.
+//
+// The script sees the variables "action" ("create" or "update") and "todo", a map with the keys
+// id, title, description, terminated, tags and priority. Changes of the map are stored, setting the variable
+// "reject" to a non-empty string refuses the todo with that reason:
+//
+//	text := import("text")
+//	if text.contains(todo.title, "urgent") { todo.title = "(A) " + todo.title }
+//	if todo.title == "" { reject = "title is required" }
+type ScriptHook struct {
+	Name     string
+	compiled *tengo.Compiled
+}
+
+// NewScriptHook compiles the script. The os module of the standard library is not available to scripts.
+func NewScriptHook(name string, source []byte) (*ScriptHook, error) {
+	script := tengo.NewScript(source)
+	script.SetImports(stdlib.GetModuleMap("math", "text", "times", "rand", "fmt", "json", "enum"))
+	for name, value := range map[string]interface{}{"action": "", "todo": map[string]interface{}{}, "reject": ""} {
+		err := script.Add(name, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	compiled, err := script.Compile()
+	if err != nil {
+		return nil, fmt.Errorf("script %s: %w", name, err)
+	}
+	return &ScriptHook{Name: name, compiled: compiled}, nil
+}
+
+// LoadScriptHooks compiles all *.tengo files of the directory in lexical order
+func LoadScriptHooks(dir string) ([]*ScriptHook, error) {
+	fileNames, err := filepath.Glob(filepath.Join(dir, "*.tengo"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(fileNames)
+
+	var hooks []*ScriptHook
+	for _, fileName := range fileNames {
+		source, err := os.ReadFile(fileName)
+		if err != nil {
+			return nil, err
+		}
+		hook, err := NewScriptHook(filepath.Base(fileName), source)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
+
+// BeforeWrite runs the script for the todo
+func (h *ScriptHook) BeforeWrite(action string, todo models.Todo) (models.Todo, error) {
+	// compiled scripts hold their globals, every run needs its own copy
+	run := h.compiled.Clone()
+	values := map[string]interface{}{
+		"action": action,
+		"todo": map[string]interface{}{
+			"id":          todo.Id,
+			"title":       todo.Title,
+			"description": todo.Description,
+			"terminated":  todo.Terminated,
+			"tags":        stringsToInterfaces(todo.Tags),
+			"priority":    todo.Priority,
+		},
+		"reject": "",
+	}
+	for name, value := range values {
+		err := run.Set(name, value)
+		if err != nil {
+			return todo, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), scriptTimeout)
+	defer cancel()
+	err := run.RunContext(ctx)
+	if err != nil {
+		return todo, fmt.Errorf("script %s: %w", h.Name, err)
+	}
+
+	if reason := run.Get("reject").String(); reason != "" {
+		return todo, &RejectedError{Reason: reason}
+	}
+
+	changed := run.Get("todo").Map()
+	if title, ok := changed["title"].(string); ok {
+		todo.Title = title
+	}
+	if description, ok := changed["description"].(string); ok {
+		todo.Description = description
+	}
+	if terminated, ok := changed["terminated"].(bool); ok {
+		todo.Terminated = terminated
+	}
+	if tags, ok := changed["tags"].([]interface{}); ok {
+		todo.Tags = []string{}
+		for _, tag := range tags {
+			todo.Tags = append(todo.Tags, fmt.Sprint(tag))
+		}
+	}
+	if priority, ok := changed["priority"].(string); ok {
+		err = models.ValidatePriority(priority)
+		if err != nil {
+			return todo, fmt.Errorf("script %s: %w", h.Name, err)
+		}
+		todo.Priority = priority
+	}
+	return todo, nil
+}
+
+// stringsToInterfaces converts a string slice into a slice tengo turns into an array
+func stringsToInterfaces(values []string) []interface{} {
+	converted := []interface{}{}
+	for _, value := range values {
+		converted = append(converted, value)
+	}
+	return converted
+}
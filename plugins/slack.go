@@ -0,0 +1,39 @@
+package plugins
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SlackNotifier posts notifications to a Slack incoming webhook
+type SlackNotifier struct {
+	Url    string
+	Client *http.Client
+}
+
+// NewSlackNotifier creates a Slack notifier with a request timeout of ten seconds
+func NewSlackNotifier(url string) *SlackNotifier {
+	return &SlackNotifier{Url: url, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Notify posts the subject in bold followed by the message
+func (n *SlackNotifier) Notify(notification Notification) error {
+	body, err := json.Marshal(map[string]string{"text": "*" + notification.Subject + "*\n" + notification.Message})
+	if err != nil {
+		return err
+	}
+
+	response, err := n.Client.Post(n.Url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("slack webhook answered %s", response.Status)
+	}
+	return nil
+}
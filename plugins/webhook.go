@@ -0,0 +1,50 @@
+package plugins
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"todo-rest-backend/webhooks"
+)
+
+// WebhookSink posts every event as JSON to an URL. With a secret the events are signed in the
+// webhooks.SignatureHeader, so that the receiver can reject forged and replayed events.
+type WebhookSink struct {
+	Url    string
+	Secret string
+	Client *http.Client
+}
+
+// NewWebhookSink creates a webhook sink with a request timeout of ten seconds
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{Url: url, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// HandleEvent posts the event
+func (s *WebhookSink) HandleEvent(event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, s.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if s.Secret != "" {
+		request.Header.Set(webhooks.SignatureHeader, webhooks.Sign(s.Secret, body, time.Now(), webhooks.NewNonce()))
+	}
+	response, err := s.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s answered %s", s.Url, response.Status)
+	}
+	return nil
+}
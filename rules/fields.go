@@ -0,0 +1,66 @@
+package rules
+
+import (
+	"fmt"
+	"todo-rest-backend/models"
+)
+
+// field reads and writes a todo attribute usable in rules
+type field struct {
+	get func(todo models.Todo) interface{}
+	set func(todo *models.Todo, value interface{}) error
+}
+
+var fields = map[string]field{
+	"title": {
+		get: func(todo models.Todo) interface{} { return todo.Title },
+		set: func(todo *models.Todo, value interface{}) error {
+			todo.Title = fmt.Sprint(value)
+			return nil
+		},
+	},
+	"description": {
+		get: func(todo models.Todo) interface{} { return todo.Description },
+		set: func(todo *models.Todo, value interface{}) error {
+			todo.Description = fmt.Sprint(value)
+			return nil
+		},
+	},
+	"tags": {
+		get: func(todo models.Todo) interface{} { return todo.Tags },
+		set: func(todo *models.Todo, value interface{}) error {
+			tags, ok := value.([]interface{})
+			if ok == false {
+				return fmt.Errorf("tags must be set to a list, not %v", value)
+			}
+			todo.Tags = []string{}
+			for _, tag := range tags {
+				todo.Tags = append(todo.Tags, fmt.Sprint(tag))
+			}
+			return nil
+		},
+	},
+	"terminated": {
+		get: func(todo models.Todo) interface{} { return todo.Terminated },
+		set: func(todo *models.Todo, value interface{}) error {
+			terminated, ok := value.(bool)
+			if ok == false {
+				return fmt.Errorf("terminated must be set to true or false, not %v", value)
+			}
+			todo.Terminated = terminated
+			return nil
+		},
+	},
+	"priority": {
+		get: func(todo models.Todo) interface{} { return todo.Priority },
+		set: func(todo *models.Todo, value interface{}) error {
+			priority := fmt.Sprint(value)
+			err := models.ValidatePriority(priority)
+			if err != nil {
+				return err
+			}
+			todo.Priority = priority
+			return nil
+		},
+	},
+}
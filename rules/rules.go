@@ -0,0 +1,249 @@
+// Package rules evaluates declarative validation and automation rules for todos
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"todo-rest-backend/models"
+	"todo-rest-backend/plugins"
+)
+
+// Condition compares a todo field with a value
+type Condition struct {
+	Field string `json:"field"`
+	// Op is one of equals, not_equals, contains, not_contains, empty, not_empty, matches, min_length and max_length
+	Op    string      `json:"op"`
+	Value interface{} `json:"value,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// Assignment sets a todo field to a value
+type Assignment struct {
+	Field string      `json:"field"`
+	Value interface{} `json:"value"`
+}
+
+// Rule applies to the todos matching all When conditions.
+// Require conditions that do not hold are violations, Set assignments are applied to the todo.
+type Rule struct {
+	Name string `json:"name"`
+	// On limits the rule to the given write actions ("create", "update"), it applies to both if empty
+	On      []string     `json:"on,omitempty"`
+	When    []Condition  `json:"when,omitempty"`
+	Require []Condition  `json:"require,omitempty"`
+	Set     []Assignment `json:"set,omitempty"`
+	// Message is reported for violations, a generated description is used if empty
+	Message string `json:"message,omitempty"`
+}
+
+// Violation is a rule a todo does not satisfy
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Engine evaluates a set of rules, it is a write hook rejecting todos with violations
+type Engine struct {
+	Rules []Rule
+}
+
+// LoadFile reads the rules from a JSON file containing an array of rules
+func LoadFile(fileName string) (*Engine, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []Rule
+	err = json.Unmarshal(content, &rules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rules file %s: %w", fileName, err)
+	}
+	return NewEngine(rules)
+}
+
+// NewEngine checks the fields and operators of the rules
+func NewEngine(rules []Rule) (*Engine, error) {
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		for _, conditions := range [][]Condition{rule.When, rule.Require} {
+			for j := range conditions {
+				err := conditions[j].prepare()
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", rule.Name, err)
+				}
+			}
+		}
+		for _, assignment := range rule.Set {
+			_, ok := fields[assignment.Field]
+			if ok == false {
+				return nil, fmt.Errorf("%s: unknown field %q", rule.Name, assignment.Field)
+			}
+		}
+	}
+	return &Engine{Rules: rules}, nil
+}
+
+// Evaluate applies the rules to the todo.
+// It returns the todo with the assignments of the matching rules and the violations.
+func (e *Engine) Evaluate(action string, todo models.Todo) (models.Todo, []Violation) {
+	violations := []Violation{}
+	for _, rule := range e.Rules {
+		if rule.appliesTo(action) == false || allHold(rule.When, todo) == false {
+			continue
+		}
+
+		for _, assignment := range rule.Set {
+			err := fields[assignment.Field].set(&todo, assignment.Value)
+			if err != nil {
+				violations = append(violations, Violation{Rule: rule.Name, Message: err.Error()})
+			}
+		}
+
+		for _, condition := range rule.Require {
+			if condition.holds(todo) == false {
+				message := rule.Message
+				if message == "" {
+					message = condition.String()
+				}
+				violations = append(violations, Violation{Rule: rule.Name, Message: message})
+			}
+		}
+	}
+	return todo, violations
+}
+
+// BeforeWrite rejects todos violating a rule and applies the assignments of the rules otherwise
+func (e *Engine) BeforeWrite(action string, todo models.Todo) (models.Todo, error) {
+	todo, violations := e.Evaluate(action, todo)
+	if len(violations) > 0 {
+		var messages []string
+		for _, violation := range violations {
+			messages = append(messages, violation.Message)
+		}
+		return todo, &plugins.RejectedError{Reason: strings.Join(messages, "; ")}
+	}
+	return todo, nil
+}
+
+func (r Rule) appliesTo(action string) bool {
+	if len(r.On) == 0 {
+		return true
+	}
+	for _, on := range r.On {
+		if on == action {
+			return true
+		}
+	}
+	return false
+}
+
+func allHold(conditions []Condition, todo models.Todo) bool {
+	for _, condition := range conditions {
+		if condition.holds(todo) == false {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Condition) prepare() error {
+	_, ok := fields[c.Field]
+	if ok == false {
+		return fmt.Errorf("unknown field %q", c.Field)
+	}
+
+	switch c.Op {
+	case "equals", "not_equals", "contains", "not_contains", "empty", "not_empty":
+	case "matches":
+		pattern, err := regexp.Compile(fmt.Sprint(c.Value))
+		if err != nil {
+			return err
+		}
+		c.pattern = pattern
+	case "min_length", "max_length":
+		if _, ok := c.Value.(float64); ok == false {
+			return fmt.Errorf("%s of %s needs a number", c.Op, c.Field)
+		}
+	default:
+		return fmt.Errorf("unknown operator %q", c.Op)
+	}
+	return nil
+}
+
+func (c Condition) holds(todo models.Todo) bool {
+	actual := fields[c.Field].get(todo)
+	expected := fmt.Sprint(c.Value)
+
+	// lists contain their elements, the length of a list is its number of elements
+	if list, ok := actual.([]string); ok {
+		switch c.Op {
+		case "contains", "not_contains":
+			found := false
+			for _, element := range list {
+				found = found || element == expected
+			}
+			return found == (c.Op == "contains")
+		case "empty", "not_empty":
+			return (len(list) == 0) == (c.Op == "empty")
+		case "min_length":
+			return float64(len(list)) >= c.Value.(float64)
+		case "max_length":
+			return float64(len(list)) <= c.Value.(float64)
+		}
+		actual = strings.Join(list, ",")
+	}
+	text := fmt.Sprint(actual)
+
+	switch c.Op {
+	case "equals":
+		return text == expected
+	case "not_equals":
+		return text != expected
+	case "contains":
+		return strings.Contains(text, expected)
+	case "not_contains":
+		return strings.Contains(text, expected) == false
+	case "empty":
+		return strings.TrimSpace(text) == ""
+	case "not_empty":
+		return strings.TrimSpace(text) != ""
+	case "matches":
+		return c.pattern.MatchString(text)
+	case "min_length":
+		return float64(len([]rune(text))) >= c.Value.(float64)
+	case "max_length":
+		return float64(len([]rune(text))) <= c.Value.(float64)
+	}
+	return false
+}
+
+// String describes the condition, it is used as message of violations without a rule message
+func (c Condition) String() string {
+	field := strings.ReplaceAll(c.Field, "_", " ")
+	switch c.Op {
+	case "empty":
+		return field + " must be empty"
+	case "not_empty":
+		return field + " is required"
+	case "min_length":
+		return fmt.Sprintf("%s must have at least %v characters", field, c.Value)
+	case "max_length":
+		return fmt.Sprintf("%s must have at most %v characters", field, c.Value)
+	}
+	verbs := map[string]string{
+		"equals":       "be",
+		"not_equals":   "not be",
+		"contains":     "contain",
+		"not_contains": "not contain",
+		"matches":      "match",
+	}
+	return fmt.Sprintf("%s must %s %q", field, verbs[c.Op], fmt.Sprint(c.Value))
+}
@@ -0,0 +1,52 @@
+package rules
+
+import (
+	"testing"
+	"todo-rest-backend/models"
+)
+
+func TestEngine_Evaluate(t *testing.T) {
+	// Arrange
+	//
+	engine, err := NewEngine([]Rule{
+		{
+			Name:    "urgent-needs-description",
+			When:    []Condition{{Field: "title", Op: "contains", Value: "urgent"}},
+			Require: []Condition{{Field: "description", Op: "not_empty"}},
+		},
+		{
+			Name: "done-prefix",
+			When: []Condition{{Field: "title", Op: "matches", Value: "^DONE "}},
+			Set:  []Assignment{{Field: "terminated", Value: true}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	//
+	_, violations := engine.Evaluate("create", models.Todo{Title: "urgent call"})
+	done, doneViolations := engine.Evaluate("create", models.Todo{Title: "DONE call"})
+
+	// Assert
+	//
+	if len(violations) != 1 || violations[0].Message != "description is required" {
+		t.Errorf("unexpected violations %+v", violations)
+	}
+	if len(doneViolations) != 0 || done.Terminated == false {
+		t.Errorf("got %+v with %+v, want a terminated todo", done, doneViolations)
+	}
+}
+
+func TestNewEngine_UnknownField(t *testing.T) {
+	// Act
+	//
+	_, err := NewEngine([]Rule{{Require: []Condition{{Field: "colour", Op: "empty"}}}})
+
+	// Assert
+	//
+	if err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
@@ -0,0 +1,242 @@
+// Package schemas publishes the JSON Schemas of the bodies of the todo API and validates request bodies
+// against them. The validator supports the keywords the schemas use: $ref to another schema of the package,
+// type, properties, required, items, enum, minimum, maximum, minLength, maxLength and pattern. Annotations like
+// readOnly and format are ignored.
+package schemas
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+//go:embed *.json
+var files embed.FS
+
+// schema is the subset of JSON Schema understood by Validate
+type schema struct {
+	Ref        string             `json:"$ref"`
+	Type       typeList           `json:"type"`
+	Properties map[string]*schema `json:"properties"`
+	Required   []string           `json:"required"`
+	Items      *schema            `json:"items"`
+	Enum       []interface{}      `json:"enum"`
+	Minimum    *float64           `json:"minimum"`
+	Maximum    *float64           `json:"maximum"`
+	MinLength  *int               `json:"minLength"`
+	MaxLength  *int               `json:"maxLength"`
+	Pattern    string             `json:"pattern"`
+}
+
+// typeList is the type keyword, a single type or a list of types
+type typeList []string
+
+func (t *typeList) UnmarshalJSON(data []byte) error {
+	var single string
+	if json.Unmarshal(data, &single) == nil {
+		*t = typeList{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// ValidationError is a violation of a schema, Path points to the offending value, e.g. "/location/latitude"
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// ValidationErrors are the violations of a body
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	var messages []string
+	for _, violation := range e {
+		messages = append(messages, violation.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Names returns the file names of the schemas, e.g. "todo.json"
+func Names() []string {
+	entries, _ := files.ReadDir(".")
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the schema with the file name
+func Get(name string) ([]byte, bool) {
+	if strings.Contains(name, "/") {
+		return nil, false
+	}
+	content, err := files.ReadFile(name)
+	return content, err == nil
+}
+
+// Validate checks the JSON body against the schema with the file name, the violations are returned as
+// ValidationErrors and other errors for bodies that are no JSON
+func Validate(name string, body []byte) error {
+	s, err := load(name)
+	if err != nil {
+		return err
+	}
+
+	var value interface{}
+	err = json.Unmarshal(body, &value)
+	if err != nil {
+		return err
+	}
+	var violations ValidationErrors
+	err = s.validate("", value, &violations)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return violations
+	}
+	return nil
+}
+
+func load(name string) (*schema, error) {
+	content, ok := Get(name)
+	if ok == false {
+		return nil, fmt.Errorf("unknown schema %s", name)
+	}
+	var s schema
+	err := json.Unmarshal(content, &s)
+	return &s, err
+}
+
+// validate appends the violations of the value to violations, the error is returned for unknown references
+func (s *schema) validate(path string, value interface{}, violations *ValidationErrors) error {
+	violation := func(format string, args ...interface{}) {
+		*violations = append(*violations, &ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	if s.Ref != "" {
+		referenced, err := load(s.Ref)
+		if err != nil {
+			return err
+		}
+		return referenced.validate(path, value, violations)
+	}
+	if len(s.Type) > 0 && s.hasType(value) == false {
+		violation("must be of type %s", strings.Join(s.Type, " or "))
+		return nil
+	}
+	if len(s.Enum) > 0 && s.inEnum(value) == false {
+		violation("must be one of %v", s.Enum)
+		return nil
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := value[name]; ok == false {
+				*violations = append(*violations, &ValidationError{Path: path + "/" + name, Message: "is required"})
+			}
+		}
+		// the properties are checked in the order of their names to report the violations in the same order
+		var names []string
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := value[name]; ok {
+				err := s.Properties[name].validate(path+"/"+name, property, violations)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range value {
+				err := s.Items.validate(fmt.Sprintf("%s/%d", path, i), item, violations)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		length := utf8.RuneCountInString(value)
+		if s.MinLength != nil && length < *s.MinLength {
+			if length == 0 {
+				violation("must not be empty")
+			} else {
+				violation("must have at least %d characters", *s.MinLength)
+			}
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			violation("must have at most %d characters", *s.MaxLength)
+		}
+		if s.Pattern != "" && regexp.MustCompile(s.Pattern).MatchString(value) == false {
+			violation("must match %s", s.Pattern)
+		}
+	case float64:
+		if s.Minimum != nil && value < *s.Minimum {
+			violation("must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && value > *s.Maximum {
+			violation("must be at most %v", *s.Maximum)
+		}
+	}
+	return nil
+}
+
+func (s *schema) hasType(value interface{}) bool {
+	for _, t := range s.Type {
+		switch value := value.(type) {
+		case nil:
+			if t == "null" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case float64:
+			if t == "number" || t == "integer" && value == math.Trunc(value) {
+				return true
+			}
+		case []interface{}:
+			if t == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if t == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *schema) inEnum(value interface{}) bool {
+	for _, allowed := range s.Enum {
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package schemas
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	for body, wantPath := range map[string]string{
+		`{"title": "Buy milk", "tags": ["shopping"], "location": null, "estimate_minutes": 30}`: "",
+		`{"title": 42}`: "/title",
+		`{"title": "Buy milk", "tags": "shopping"}`:                      "/tags",
+		`{"title": "Buy milk", "tags": ["a", 1]}`:                        "/tags/1",
+		`{"estimate_minutes": 1.5}`:                                      "/estimate_minutes",
+		`{"estimate_minutes": -1}`:                                       "/estimate_minutes",
+		`{"location": {"latitude": 91}}`:                                 "/location/latitude",
+		`{"due_date": "tomorrow"}`:                                       "/due_date",
+		`{"title": "Buy milk", "due_date": "2024-05-06T17:00:00+02:00"}`: "",
+		`{"items": [{"id": "1", "done": true}]}`:                         "/items/0/title",
+	} {
+		// Act
+		//
+		err := Validate("todo.json", []byte(body))
+
+		// Assert
+		//
+		var violations ValidationErrors
+		if wantPath == "" && err != nil {
+			t.Error("Fehler", body, err)
+		}
+		if wantPath != "" && (errors.As(err, &violations) == false || violations[len(violations)-1].Path != wantPath) {
+			t.Error("Fehler", body, err)
+		}
+	}
+}
+
+func TestValidate_RequiredProperty(t *testing.T) {
+	// Act
+	//
+	err := Validate("goal.json", []byte(`{"description": "Run a marathon"}`))
+
+	// Assert
+	//
+	if err == nil || err.Error() != "/title: is required" {
+		t.Error("Fehler", err)
+	}
+}
+
+func TestValidate_ReportsAllViolations(t *testing.T) {
+	// Act
+	//
+	err := Validate("todo.json", []byte(`{"title": "", "priority": "urgent", "tags": ["a,b", 1]}`))
+
+	// Assert
+	//
+	want := "/priority: must be one of [ low medium high]; /tags/0: must match ^[^,]*$; " +
+		"/tags/1: must be of type string; /title: must not be empty"
+	if err == nil || err.Error() != want {
+		t.Error("Fehler", err)
+	}
+}
+
+func TestGet_PublishesEverySchema(t *testing.T) {
+	for _, name := range Names() {
+		// Act
+		//
+		err := Validate(name, []byte(`{}`))
+
+		// Assert
+		//
+		var violations ValidationErrors
+		if err != nil && errors.As(err, &violations) == false {
+			t.Error("Fehler", name, err)
+		}
+	}
+	if _, ok := Get("../schemas.go"); ok {
+		t.Error("Fehler")
+	}
+}
@@ -0,0 +1,46 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Similarity compares two texts by their trigrams, ignoring case, punctuation and repeated spaces.
+// It returns the Jaccard index of the trigram sets, 1 for equal texts and 0 for texts without common trigrams.
+func Similarity(a string, b string) float64 {
+	left, right := trigrams(a), trigrams(b)
+	if len(left) == 0 || len(right) == 0 {
+		if normalize(a) == normalize(b) {
+			return 1
+		}
+		return 0
+	}
+
+	common := 0
+	for trigram := range left {
+		if right[trigram] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(left)+len(right)-common)
+}
+
+// normalize lowercases the text and reduces it to words separated by single spaces
+func normalize(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return unicode.IsLetter(r) == false && unicode.IsNumber(r) == false
+	})
+	return strings.Join(words, " ")
+}
+
+// trigrams returns the trigrams of the normalized text, words are padded so that short words have trigrams
+func trigrams(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(normalize(text)) {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			set[string(runes[i:i+3])] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,19 @@
+package search
+
+import (
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	// Act
+	//
+	same := Similarity("Buy milk!", "buy  MILK")
+	close := Similarity("Buy milk", "Buy milk today")
+	different := Similarity("Buy milk", "Call mom")
+
+	// Assert
+	//
+	if same != 1 || close < 0.5 || different > 0.2 {
+		t.Error("Fehler", same, close, different)
+	}
+}
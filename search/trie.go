@@ -0,0 +1,86 @@
+// Package search contains in-memory indexes for searching todos
+package search
+
+import (
+	"sort"
+	"strings"
+)
+
+// Trie maps case-insensitive key prefixes to ranked values
+type Trie struct {
+	root *trieNode
+}
+
+type trieNode struct {
+	children map[rune]*trieNode
+	// values ending at the node with their rank
+	values map[string]int
+}
+
+func newTrieNode() *trieNode {
+	return &trieNode{children: make(map[rune]*trieNode), values: make(map[string]int)}
+}
+
+// NewTrie creates an empty trie
+func NewTrie() *Trie {
+	return &Trie{root: newTrieNode()}
+}
+
+// Insert adds the value under the key. A value inserted several times keeps its highest rank.
+func (t *Trie) Insert(key string, value string, rank int) {
+	node := t.root
+	for _, r := range strings.ToLower(key) {
+		child, ok := node.children[r]
+		if ok == false {
+			child = newTrieNode()
+			node.children[r] = child
+		}
+		node = child
+	}
+	previous, ok := node.values[value]
+	if ok == false || rank > previous {
+		node.values[value] = rank
+	}
+}
+
+// Complete returns at most limit values with a key starting with the prefix, the highest rank first
+func (t *Trie) Complete(prefix string, limit int) []string {
+	node := t.root
+	for _, r := range strings.ToLower(prefix) {
+		child, ok := node.children[r]
+		if ok == false {
+			return []string{}
+		}
+		node = child
+	}
+
+	ranks := make(map[string]int)
+	node.collect(ranks)
+
+	values := make([]string, 0, len(ranks))
+	for value := range ranks {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if ranks[values[i]] != ranks[values[j]] {
+			return ranks[values[i]] > ranks[values[j]]
+		}
+		return values[i] < values[j]
+	})
+	if len(values) > limit {
+		values = values[:limit]
+	}
+	return values
+}
+
+func (n *trieNode) collect(ranks map[string]int) {
+	for value, rank := range n.values {
+		previous, ok := ranks[value]
+		if ok == false || rank > previous {
+			ranks[value] = rank
+		}
+	}
+	for _, child := range n.children {
+		child.collect(ranks)
+	}
+}
@@ -0,0 +1,30 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrie_Complete(t *testing.T) {
+	// Arrange
+	//
+	trie := NewTrie()
+	trie.Insert("Buy milk", "Buy milk", 1)
+	trie.Insert("Bake bread", "Bake bread", 3)
+	trie.Insert("bread", "Bake bread", 3)
+	trie.Insert("Book flight", "Book flight", 2)
+
+	// Act
+	//
+	got := trie.Complete("B", 2)
+	words := trie.Complete("bre", 10)
+
+	// Assert
+	//
+	if reflect.DeepEqual(got, []string{"Bake bread", "Book flight"}) == false {
+		t.Error("Fehler", got)
+	}
+	if reflect.DeepEqual(words, []string{"Bake bread"}) == false {
+		t.Error("Fehler", words)
+	}
+}
@@ -0,0 +1,158 @@
+// Package weather annotates outdoor todos with a weather advisory for their location
+package weather
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rainLikely is the precipitation probability in percent from which outdoor todos are advised against
+const rainLikely = 50
+
+// Advisory is the forecast of a day at a location
+type Advisory struct {
+	Date string `json:"date"`
+	// PrecipitationProbability is the maximal probability of precipitation of the day in percent
+	PrecipitationProbability int     `json:"precipitation_probability"`
+	TemperatureMin           float64 `json:"temperature_min"`
+	TemperatureMax           float64 `json:"temperature_max"`
+	Advice                   string  `json:"advice"`
+}
+
+// Provider forecasts the weather of a day at a location
+type Provider interface {
+	Forecast(latitude float64, longitude float64, day time.Time) (Advisory, error)
+}
+
+// OpenMeteo is the forecast API of https://open-meteo.com, it needs no API key
+type OpenMeteo struct {
+	// ApiUrl is https://api.open-meteo.com/v1/forecast or the URL of a self-hosted instance
+	ApiUrl string
+	Client *http.Client
+}
+
+// NewOpenMeteo creates a provider for the API with a timeout of two seconds
+func NewOpenMeteo(apiUrl string) *OpenMeteo {
+	return &OpenMeteo{ApiUrl: apiUrl, Client: &http.Client{Timeout: 2 * time.Second}}
+}
+
+// Forecast requests the daily forecast of the day
+func (p *OpenMeteo) Forecast(latitude float64, longitude float64, day time.Time) (Advisory, error) {
+	date := day.Format("2006-01-02")
+	query := url.Values{
+		"latitude":   {strconv.FormatFloat(latitude, 'f', 4, 64)},
+		"longitude":  {strconv.FormatFloat(longitude, 'f', 4, 64)},
+		"daily":      {"precipitation_probability_max,temperature_2m_min,temperature_2m_max"},
+		"start_date": {date},
+		"end_date":   {date},
+		"timezone":   {"auto"},
+	}
+	response, err := p.Client.Get(p.ApiUrl + "?" + query.Encode())
+	if err != nil {
+		return Advisory{}, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return Advisory{}, fmt.Errorf("weather provider %s answered %s", p.ApiUrl, response.Status)
+	}
+
+	var forecast struct {
+		Daily struct {
+			PrecipitationProbability []int     `json:"precipitation_probability_max"`
+			TemperatureMin           []float64 `json:"temperature_2m_min"`
+			TemperatureMax           []float64 `json:"temperature_2m_max"`
+		} `json:"daily"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&forecast)
+	if err != nil {
+		return Advisory{}, err
+	}
+	daily := forecast.Daily
+	if len(daily.PrecipitationProbability) == 0 || len(daily.TemperatureMin) == 0 || len(daily.TemperatureMax) == 0 {
+		return Advisory{}, fmt.Errorf("weather provider %s has no forecast for %s", p.ApiUrl, date)
+	}
+
+	advisory := Advisory{
+		Date:                     date,
+		PrecipitationProbability: daily.PrecipitationProbability[0],
+		TemperatureMin:           daily.TemperatureMin[0],
+		TemperatureMax:           daily.TemperatureMax[0],
+	}
+	advisory.Advice = advice(advisory)
+	return advisory, nil
+}
+
+func advice(advisory Advisory) string {
+	if advisory.PrecipitationProbability >= rainLikely {
+		return fmt.Sprintf("Rain likely (%d%%), consider another day", advisory.PrecipitationProbability)
+	}
+	return "Good weather for outdoor todos"
+}
+
+// cacheKey identifies a location rounded to about a kilometer and a day
+type cacheKey struct {
+	latitude  string
+	longitude string
+	date      string
+}
+
+// CachedProvider asks its provider once per location and day
+type CachedProvider struct {
+	Provider Provider
+
+	mutex      sync.Mutex
+	advisories map[cacheKey]Advisory
+}
+
+// NewCachedProvider caches the forecasts of the provider
+func NewCachedProvider(provider Provider) *CachedProvider {
+	return &CachedProvider{Provider: provider, advisories: make(map[cacheKey]Advisory)}
+}
+
+func keyOf(latitude float64, longitude float64, day time.Time) cacheKey {
+	return cacheKey{
+		latitude:  strconv.FormatFloat(latitude, 'f', 2, 64),
+		longitude: strconv.FormatFloat(longitude, 'f', 2, 64),
+		date:      day.Format("2006-01-02"),
+	}
+}
+
+// Cached returns the cached forecast without asking the provider
+func (p *CachedProvider) Cached(latitude float64, longitude float64, day time.Time) (Advisory, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	advisory, ok := p.advisories[keyOf(latitude, longitude, day)]
+	return advisory, ok
+}
+
+// Forecast returns the cached forecast or asks the provider, failures are not cached
+func (p *CachedProvider) Forecast(latitude float64, longitude float64, day time.Time) (Advisory, error) {
+	key := keyOf(latitude, longitude, day)
+	advisory, ok := p.Cached(latitude, longitude, day)
+	if ok {
+		return advisory, nil
+	}
+
+	advisory, err := p.Provider.Forecast(latitude, longitude, day)
+	if err != nil {
+		return Advisory{}, err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	// forecasts of past days are no longer needed
+	today := time.Now().Format("2006-01-02")
+	for cached := range p.advisories {
+		if cached.date < today {
+			delete(p.advisories, cached)
+		}
+	}
+	p.advisories[key] = advisory
+	return advisory, nil
+}
@@ -0,0 +1,36 @@
+package weather
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCachedProvider_AsksOncePerLocationAndDay(t *testing.T) {
+	// Arrange
+	//
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests++
+		fmt.Fprint(writer, `{"daily": {"precipitation_probability_max": [80], "temperature_2m_min": [4.5], "temperature_2m_max": [11]}}`)
+	}))
+	defer server.Close()
+	provider := NewCachedProvider(NewOpenMeteo(server.URL))
+	day := time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	//
+	first, err := provider.Forecast(47.3769, 8.5417, day)
+	second, _ := provider.Forecast(47.3771, 8.5419, day)
+
+	// Assert
+	//
+	if err != nil || first.PrecipitationProbability != 80 || first.Advice != "Rain likely (80%), consider another day" {
+		t.Error("Fehler", first, err)
+	}
+	if requests != 1 || second != first {
+		t.Errorf("provider asked %d times, want 1", requests)
+	}
+}
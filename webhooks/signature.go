@@ -0,0 +1,132 @@
+// Package webhooks signs the webhooks of the todo backend and verifies them. Receivers of the outgoing
+// webhooks can use it to check the X-Todo-Signature header:
+//
+//	guard := webhooks.NewReplayGuard(2 * webhooks.DefaultTolerance)
+//	...
+//	signature, err := webhooks.Verify(secret, request.Header.Get(webhooks.SignatureHeader), body, time.Now(), webhooks.DefaultTolerance)
+//	if err != nil || guard.Seen(signature.Nonce, time.Now()) {
+//		// reject the request
+//	}
+//
+// The signature is an HMAC-SHA256 of the timestamp, the nonce and the body, so that a captured request
+// cannot be sent again after the tolerance, and not within it either when the nonces are remembered.
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SignatureHeader is the header carrying the signature, like "t=1700000000,nonce=5f2b...,v1=9a41..."
+const SignatureHeader = "X-Todo-Signature"
+
+// DefaultTolerance is how far the timestamp of a signature may be off
+const DefaultTolerance = 5 * time.Minute
+
+var (
+	// ErrMalformedSignature is returned for missing signatures or signatures without timestamp, nonce or HMAC
+	ErrMalformedSignature = errors.New("malformed webhook signature")
+	// ErrInvalidSignature is returned for signatures not matching the body
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrStaleSignature is returned for signatures whose timestamp is outside the tolerance
+	ErrStaleSignature = errors.New("webhook signature timestamp outside the tolerance")
+)
+
+// Signature is a verified signature
+type Signature struct {
+	Timestamp time.Time
+	Nonce     string
+}
+
+// NewNonce returns a random nonce for Sign
+func NewNonce() string {
+	random := make([]byte, 16)
+	rand.Read(random)
+	return hex.EncodeToString(random)
+}
+
+// Sign returns the value of the SignatureHeader for the body sent at the timestamp
+func Sign(secret string, body []byte, timestamp time.Time, nonce string) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + unix + ",nonce=" + nonce + ",v1=" + mac(secret, unix, nonce, body)
+}
+
+// Verify checks the value of the SignatureHeader against the body and the timestamp against now
+func Verify(secret string, header string, body []byte, now time.Time, tolerance time.Duration) (Signature, error) {
+	var unix, nonce, given string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			unix = value
+		case "nonce":
+			nonce = value
+		case "v1":
+			given = value
+		}
+	}
+	seconds, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil || nonce == "" || given == "" {
+		return Signature{}, ErrMalformedSignature
+	}
+	if hmac.Equal([]byte(given), []byte(mac(secret, unix, nonce, body))) == false {
+		return Signature{}, ErrInvalidSignature
+	}
+	signature := Signature{Timestamp: time.Unix(seconds, 0), Nonce: nonce}
+	if signature.Timestamp.Before(now.Add(-tolerance)) || signature.Timestamp.After(now.Add(tolerance)) {
+		return signature, ErrStaleSignature
+	}
+	return signature, nil
+}
+
+func mac(secret string, unix string, nonce string, body []byte) string {
+	hash := hmac.New(sha256.New, []byte(secret))
+	hash.Write([]byte(unix + "." + nonce + "."))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// ReplayGuard remembers the nonces of the requests within a window, requests sent earlier are rejected by
+// their timestamp. It is safe for concurrent use.
+type ReplayGuard struct {
+	window time.Duration
+	mutex  sync.Mutex
+	seen   map[string]time.Time
+}
+
+// NewReplayGuard returns a guard remembering the nonces for the window. Verify accepts a signature for the
+// tolerance before and after its timestamp, the window must be twice the tolerance to cover that.
+func NewReplayGuard(window time.Duration) *ReplayGuard {
+	return &ReplayGuard{window: window, seen: make(map[string]time.Time)}
+}
+
+// Seen tells whether the nonce was seen within the window before and remembers it, at is the time of the
+// request. Nonces outside the window are forgotten.
+func (g *ReplayGuard) Seen(nonce string, at time.Time) bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	for remembered, seenAt := range g.seen {
+		if at.Sub(seenAt) > g.window {
+			delete(g.seen, remembered)
+		}
+	}
+	if _, ok := g.seen[nonce]; ok {
+		return true
+	}
+	g.seen[nonce] = at
+	return false
+}
+
+// Forget forgets the nonce, e.g. of a request that failed and may be sent again
+func (g *ReplayGuard) Forget(nonce string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	delete(g.seen, nonce)
+}
@@ -0,0 +1,58 @@
+package webhooks
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerify_AcceptsRecentSignatures(t *testing.T) {
+	// Arrange
+	//
+	sentAt := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"type": "todo.created"}`)
+	header := Sign("s3cret", body, sentAt, "n1")
+
+	// Act
+	//
+	signature, err := Verify("s3cret", header, body, sentAt.Add(time.Minute), DefaultTolerance)
+	_, tampered := Verify("s3cret", header, []byte(`{"type": "todo.deleted"}`), sentAt, DefaultTolerance)
+	_, otherSecret := Verify("guess", header, body, sentAt, DefaultTolerance)
+	_, stale := Verify("s3cret", header, body, sentAt.Add(time.Hour), DefaultTolerance)
+	_, malformed := Verify("s3cret", "v1=abc", body, sentAt, DefaultTolerance)
+
+	// Assert
+	//
+	if err != nil || signature.Nonce != "n1" || signature.Timestamp.Equal(sentAt) == false {
+		t.Error("Fehler", err, signature)
+	}
+	if errors.Is(tampered, ErrInvalidSignature) == false || errors.Is(otherSecret, ErrInvalidSignature) == false {
+		t.Error("Fehler", tampered, otherSecret)
+	}
+	if errors.Is(stale, ErrStaleSignature) == false || errors.Is(malformed, ErrMalformedSignature) == false {
+		t.Error("Fehler", stale, malformed)
+	}
+}
+
+func TestReplayGuard_RejectsNoncesWithinTheWindow(t *testing.T) {
+	// Arrange
+	//
+	guard := NewReplayGuard(10 * time.Minute)
+	now := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+
+	// Act
+	//
+	first := guard.Seen("n1", now)
+	replayed := guard.Seen("n1", now.Add(time.Minute))
+	expired := guard.Seen("n1", now.Add(time.Hour))
+	guard.Forget("n2")
+	guard.Seen("n2", now.Add(time.Hour))
+	guard.Forget("n2")
+	retried := guard.Seen("n2", now.Add(time.Hour))
+
+	// Assert
+	//
+	if first || replayed == false || expired || retried {
+		t.Error("Fehler", first, replayed, expired, retried)
+	}
+}